@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"strconv"
+
+	"github.com/steipete/gogcli/internal/safetyprofile"
+)
+
+const usage = `Usage: gen-mcp-risk <readonly.yaml> <output.go>` + "\n"
+
+// gen-mcp-risk turns the readonly safety profile into the command risk table
+// that classifies schema-generated MCP tools. Commands the profile allows are
+// read tools; everything else, including commands it never mentions, is a
+// write tool.
+func main() {
+	args := os.Args[1:]
+	if len(args) != 2 {
+		_, _ = fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	raw, err := os.ReadFile(args[0]) // #nosec G304 G703 -- build helper intentionally reads the requested profile path.
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "read profile: %v\n", err)
+		os.Exit(1)
+	}
+
+	profile, err := safetyprofile.Parse(string(raw))
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "parse profile: %v\n", err)
+		os.Exit(1)
+	}
+
+	out, err := generate(profile)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "format output: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(args[1], out, 0o600); err != nil { // #nosec G306 G703 -- build helper intentionally writes the requested generated Go path.
+		_, _ = fmt.Fprintf(os.Stderr, "write output: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func generate(profile *safetyprofile.Profile) ([]byte, error) {
+	var out bytes.Buffer
+	out.WriteString("// Code generated by cmd/gen-mcp-risk; DO NOT EDIT.\n")
+	out.WriteString("// Source: safety-profiles/readonly.yaml\n\n")
+	out.WriteString("package cmd\n\n")
+	writeRules(&out, "mcpReadCommandRules", profile.AllowRules)
+	out.WriteString("\n")
+	writeRules(&out, "mcpWriteCommandRules", profile.DenyRules)
+	return format.Source(out.Bytes())
+}
+
+func writeRules(out *bytes.Buffer, name string, rules []string) {
+	fmt.Fprintf(out, "var %s = map[string]bool{\n", name)
+	for _, rule := range rules {
+		fmt.Fprintf(out, "\t%s: true,\n", strconv.Quote(rule))
+	}
+	out.WriteString("}\n")
+}
@@ -32,7 +32,10 @@ type Manifest struct {
 	Services   []string       `json:"services,omitempty"`
 	Accounts   []string       `json:"accounts,omitempty"`
 	Counts     map[string]int `json:"counts,omitempty"`
-	Shards     []ShardEntry   `json:"shards"`
+	// Cursors holds per-service sync positions such as the Gmail history ID
+	// the snapshot was taken at, keyed by CursorKey.
+	Cursors map[string]string `json:"cursors,omitempty"`
+	Shards  []ShardEntry      `json:"shards"`
 }
 
 type Checkpoint struct {
@@ -70,6 +73,9 @@ type ShardEntry struct {
 	Rows    int    `json:"rows"`
 	SHA256  string `json:"sha256"`
 	Bytes   int64  `json:"bytes"`
+	// CipherSHA256 hashes the encrypted shard file, so a cached copy can be
+	// checked without the age identity. Older manifests leave it empty.
+	CipherSHA256 string `json:"cipher_sha256,omitempty"`
 }
 
 type PlainShard struct {
@@ -88,6 +94,7 @@ type Snapshot struct {
 	Services []string
 	Accounts []string
 	Counts   map[string]int
+	Cursors  map[string]string
 	Shards   []PlainShard
 }
 
@@ -114,13 +121,17 @@ func Init(ctx context.Context, opts Options) (Config, string, error) {
 	if err := opts.ConfigStore.Save(opts.ConfigPath, cfg); err != nil {
 		return Config{}, "", err
 	}
-	if err := ensureRepo(ctx, cfg); err != nil {
+	storage, err := storageFor(cfg)
+	if err != nil {
+		return Config{}, "", err
+	}
+	if err := storage.Prepare(ctx, cfg); err != nil {
 		return Config{}, "", err
 	}
 	if err := writeBackupReadme(cfg.Repo); err != nil {
 		return Config{}, "", err
 	}
-	_, err = commitAndPush(ctx, cfg, "docs: describe encrypted gog backup", opts.Push)
+	_, err = storage.Publish(ctx, cfg, "docs: describe encrypted gog backup", opts.Push)
 	return cfg, recipient, err
 }
 
@@ -142,7 +153,11 @@ func PushSnapshot(ctx context.Context, snapshot Snapshot, opts Options) (Result,
 		}
 		cfg.Recipients = []string{recipient}
 	}
-	if err := ensureRepo(ctx, cfg); err != nil {
+	storage, err := storageFor(cfg)
+	if err != nil {
+		return Result{}, err
+	}
+	if err := storage.Prepare(ctx, cfg); err != nil {
 		return Result{}, err
 	}
 	if err := writeBackupReadme(cfg.Repo); err != nil {
@@ -162,7 +177,7 @@ func PushSnapshot(ctx context.Context, snapshot Snapshot, opts Options) (Result,
 	if err != nil {
 		return Result{}, err
 	}
-	changed, err := commitAndPush(ctx, cfg, "sync: update encrypted gog backup", opts.Push)
+	changed, err := storage.Publish(ctx, cfg, "sync: update encrypted gog backup", opts.Push)
 	if err != nil {
 		return Result{}, err
 	}
@@ -182,6 +197,13 @@ func PushCheckpoint(ctx context.Context, snapshot Snapshot, checkpoint Checkpoin
 		}
 		cfg.Recipients = []string{recipient}
 	}
+	storageKind, err := StorageKind(cfg)
+	if err != nil {
+		return Result{}, err
+	}
+	if storageKind != StorageGit {
+		return writeLocalCheckpoint(ctx, cfg, snapshot, checkpoint)
+	}
 	if opts.Push {
 		if err := asyncPushError(cfg.Repo); err != nil {
 			return Result{}, err
@@ -213,6 +235,27 @@ func PushCheckpoint(ctx context.Context, snapshot Snapshot, checkpoint Checkpoin
 			return Result{}, err
 		}
 	}
+	return Result{Repo: cfg.Repo, Changed: changed, Encrypted: true, Shards: len(manifest.Shards), Counts: checkpointCounts(manifest)}, nil
+}
+
+// writeLocalCheckpoint keeps checkpoints in the local working directory for
+// non-git targets. They only speed up resuming an interrupted run on the same
+// host; the published target only ever receives complete snapshots.
+func writeLocalCheckpoint(ctx context.Context, cfg Config, snapshot Snapshot, checkpoint Checkpoint) (Result, error) {
+	if err := ensureWorkDir(cfg); err != nil {
+		return Result{}, err
+	}
+	manifest, err := writeCheckpoint(ctx, cfg, snapshot, checkpoint)
+	if err != nil {
+		return Result{}, err
+	}
+	if err := removeTempShardFiles(cfg.Repo); err != nil {
+		return Result{}, err
+	}
+	return Result{Repo: cfg.Repo, Changed: true, Encrypted: true, Shards: len(manifest.Shards), Counts: checkpointCounts(manifest)}, nil
+}
+
+func checkpointCounts(manifest CheckpointManifest) map[string]int {
 	counts := map[string]int{}
 	for _, shard := range manifest.Shards {
 		key := shard.Service
@@ -221,7 +264,7 @@ func PushCheckpoint(ctx context.Context, snapshot Snapshot, checkpoint Checkpoin
 		}
 		counts[key] += shard.Rows
 	}
-	return Result{Repo: cfg.Repo, Changed: changed, Encrypted: true, Shards: len(manifest.Shards), Counts: counts}, nil
+	return counts
 }
 
 func Verify(ctx context.Context, opts Options) (Result, error) {
@@ -437,6 +480,7 @@ func writeSnapshot(ctx context.Context, cfg Config, snapshot Snapshot, old Manif
 		Services:   mergedManifestStrings(old.Services, snapshot.Services, reuseEncrypted),
 		Accounts:   mergedManifestStrings(old.Accounts, snapshot.Accounts, reuseEncrypted),
 		Counts:     mergedManifestCounts(old.Counts, snapshot.Counts, updatedServices, reuseEncrypted),
+		Cursors:    mergedManifestCursors(old.Cursors, snapshot.Cursors, updatedServices, reuseEncrypted),
 		Shards:     shards,
 	}
 	if manifest.Counts == nil {
@@ -505,6 +549,50 @@ func mergedManifestCounts(old, next map[string]int, updatedServices map[string]s
 	return out
 }
 
+func mergedManifestCursors(old, next map[string]string, updatedServices map[string]struct{}, preserveOld bool) map[string]string {
+	out := map[string]string{}
+	if preserveOld {
+		for key, value := range old {
+			service, _, _ := strings.Cut(key, "/")
+			if _, ok := updatedServices[service]; ok {
+				continue
+			}
+			out[key] = value
+		}
+	}
+	for key, value := range next {
+		if strings.TrimSpace(value) != "" {
+			out[key] = value
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// CursorKey names a manifest cursor for one service account.
+func CursorKey(service, account string) string {
+	return strings.TrimSpace(service) + "/" + strings.TrimSpace(account)
+}
+
+// LocalCursor reads a cursor from the local working copy's manifest without
+// pulling. It returns an empty string when there is no snapshot yet.
+func LocalCursor(opts Options, key string) (string, error) {
+	cfg, err := ResolveOptions(opts)
+	if err != nil {
+		return "", err
+	}
+	manifest, err := readManifest(cfg.Repo)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return manifest.Cursors[key], nil
+}
+
 func writeShard(cfg Config, old Manifest, shard PlainShard, reuseEncrypted bool) (ShardEntry, error) {
 	if strings.TrimSpace(shard.Service) == "" {
 		return ShardEntry{}, fmt.Errorf("backup shard service is required")
@@ -544,7 +632,7 @@ func writeShard(cfg Config, old Manifest, shard PlainShard, reuseEncrypted bool)
 			return ShardEntry{}, fmt.Errorf("reuse encrypted backup shard %s: size changed from %d to %d", entry.Path, entry.Bytes, info.Size())
 		}
 		entry.Bytes = info.Size()
-		return entry, nil
+		return withCipherSHA256(entry, path)
 	}
 	hash, err := shardPlaintextHash(shard)
 	if err != nil {
@@ -560,7 +648,7 @@ func writeShard(cfg Config, old Manifest, shard PlainShard, reuseEncrypted bool)
 		}
 		if info, err := os.Stat(path); err == nil {
 			oldEntry.Bytes = info.Size()
-			return oldEntry, nil
+			return withCipherSHA256(oldEntry, path)
 		}
 	}
 	if err := rejectSymlinkPath(cfg.Repo, path); err != nil {
@@ -576,17 +664,36 @@ func writeShard(cfg Config, old Manifest, shard PlainShard, reuseEncrypted bool)
 	if err != nil {
 		return ShardEntry{}, err
 	}
+	cipherHash, _, err := fileSHA256(path)
+	if err != nil {
+		return ShardEntry{}, err
+	}
 	return ShardEntry{
-		Service: shard.Service,
-		Kind:    shard.Kind,
-		Account: shard.Account,
-		Path:    shard.Path,
-		Rows:    shard.Rows,
-		SHA256:  hash,
-		Bytes:   bytesWritten,
+		Service:      shard.Service,
+		Kind:         shard.Kind,
+		Account:      shard.Account,
+		Path:         shard.Path,
+		Rows:         shard.Rows,
+		SHA256:       hash,
+		Bytes:        bytesWritten,
+		CipherSHA256: cipherHash,
 	}, nil
 }
 
+// withCipherSHA256 fills in the encrypted-file hash for an entry carried over
+// from a manifest written before the field existed.
+func withCipherSHA256(entry ShardEntry, path string) (ShardEntry, error) {
+	if entry.CipherSHA256 != "" {
+		return entry, nil
+	}
+	hash, _, err := fileSHA256(path)
+	if err != nil {
+		return ShardEntry{}, err
+	}
+	entry.CipherSHA256 = hash
+	return entry, nil
+}
+
 func cleanupPlainShardFiles(snapshot Snapshot) {
 	for _, shard := range snapshot.Shards {
 		if strings.TrimSpace(shard.PlaintextPath) != "" {
@@ -833,6 +940,7 @@ func equivalentManifest(a, b Manifest) bool {
 		!sameStrings(a.Services, b.Services) ||
 		!sameStrings(a.Accounts, b.Accounts) ||
 		!reflect.DeepEqual(a.Counts, b.Counts) ||
+		!reflect.DeepEqual(a.Cursors, b.Cursors) ||
 		len(a.Shards) != len(b.Shards) {
 		return false
 	}
@@ -386,6 +386,36 @@ func TestCatAndDecryptSnapshotVerifyPlaintext(t *testing.T) {
 	}
 }
 
+func TestWalkSelectedShardsDecryptsOnlyIncludedShards(t *testing.T) {
+	ctx, _, config, _ := initTestBackup(t)
+	labels, err := NewJSONLShard("gmail", "labels", "acct", "data/gmail/acct/labels.jsonl.gz.age", []map[string]string{{"id": "Label_1"}})
+	if err != nil {
+		t.Fatalf("NewJSONLShard: %v", err)
+	}
+	messages := mustGmailMessageShard(t, "data/gmail/acct/messages/2026/04/part-0001.jsonl.gz.age", []map[string]string{{"id": "m1"}})
+	if _, err := PushSnapshot(ctx, Snapshot{
+		Services: []string{"gmail"},
+		Accounts: []string{"acct"},
+		Shards:   []PlainShard{labels, messages},
+	}, testOptions(t, Options{ConfigPath: config})); err != nil {
+		t.Fatalf("PushSnapshot: %v", err)
+	}
+
+	var visited []string
+	manifest, _, err := WalkSelectedShards(ctx, testOptions(t, Options{ConfigPath: config, SkipPull: true}), func(entry ShardEntry) bool {
+		return entry.Kind == "labels"
+	}, func(_ Manifest, _ string, shard PlainShard) error {
+		visited = append(visited, shard.Path)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WalkSelectedShards: %v", err)
+	}
+	if len(manifest.Shards) != 2 || len(visited) != 1 || visited[0] != labels.Path {
+		t.Fatalf("visited=%v manifest shards=%d", visited, len(manifest.Shards))
+	}
+}
+
 func TestCatRejectsShardOutsideManifest(t *testing.T) {
 	ctx, _, config, _ := initTestBackup(t)
 	pushSingleShard(t, ctx, config, mustGmailMessageShard(t, "data/gmail/acct/messages/2026/04/part-0001.jsonl.gz.age", []map[string]string{{"id": "m1"}}))
@@ -1010,3 +1040,36 @@ func readFile(t *testing.T, path string) []byte {
 	}
 	return data
 }
+
+func TestSnapshotCursorsPersistAcrossServicePushes(t *testing.T) {
+	ctx, _, config, _ := initTestBackup(t)
+	opts := testOptions(t, Options{ConfigPath: config})
+	push := func(service, cursor string) {
+		t.Helper()
+		shard, err := NewJSONLShard(service, "labels", "acct", "data/"+service+"/acct/labels.jsonl.gz.age", []map[string]string{{"id": cursor}})
+		if err != nil {
+			t.Fatalf("NewJSONLShard: %v", err)
+		}
+		if _, err := PushSnapshot(ctx, Snapshot{
+			Services: []string{service},
+			Accounts: []string{"acct"},
+			Cursors:  map[string]string{CursorKey(service, "acct"): cursor},
+			Shards:   []PlainShard{shard},
+		}, opts); err != nil {
+			t.Fatalf("PushSnapshot: %v", err)
+		}
+	}
+	push("gmail", "100")
+	push("tasks", "t1")
+	push("gmail", "200")
+
+	for key, want := range map[string]string{"gmail/acct": "200", "tasks/acct": "t1", "drive/acct": ""} {
+		got, err := LocalCursor(opts, key)
+		if err != nil {
+			t.Fatalf("LocalCursor(%s): %v", key, err)
+		}
+		if got != want {
+			t.Fatalf("LocalCursor(%s) = %q, want %q", key, got, want)
+		}
+	}
+}
@@ -9,6 +9,9 @@ type Config struct {
 	Remote     string   `json:"remote"`
 	Identity   string   `json:"identity"`
 	Recipients []string `json:"recipients"`
+	// Target selects where snapshots are published: empty or "git" for the
+	// Git remote, an absolute directory or .tar path, or s3://bucket/prefix.
+	Target string `json:"target,omitempty"`
 }
 
 type Options struct {
@@ -18,6 +21,7 @@ type Options struct {
 	Remote                string
 	Identity              string
 	Recipients            []string
+	Target                string
 	SuppressDefaultRemote bool
 	Push                  bool
 	SkipPull              bool
@@ -107,6 +107,9 @@ func (s *ConfigStore) ResolveOptions(opts Options) (Config, error) {
 	if len(opts.Recipients) > 0 {
 		cfg.Recipients = opts.Recipients
 	}
+	if strings.TrimSpace(opts.Target) != "" {
+		cfg.Target = strings.TrimSpace(opts.Target)
+	}
 	cfg.Repo, err = s.expandHome(cfg.Repo)
 	if err != nil {
 		return Config{}, err
@@ -115,6 +118,10 @@ func (s *ConfigStore) ResolveOptions(opts Options) (Config, error) {
 	if err != nil {
 		return Config{}, err
 	}
+	cfg.Target, err = s.expandHome(cfg.Target)
+	if err != nil {
+		return Config{}, err
+	}
 	return cfg, nil
 }
 
@@ -52,14 +52,7 @@ func ensureRepo(ctx context.Context, cfg Config) error {
 	return nil
 }
 
-func prepareReadRepo(ctx context.Context, cfg Config, skipPull bool) error {
-	if strings.TrimSpace(cfg.Repo) == "" {
-		return fmt.Errorf("backup repo path is required")
-	}
-	if skipPull {
-		return nil
-	}
-
+func fetchGitRepo(ctx context.Context, cfg Config) error {
 	gitPath := filepath.Join(cfg.Repo, ".git")
 	if _, err := os.Stat(gitPath); err == nil {
 		return pullRepo(ctx, cfg.Repo)
@@ -47,6 +47,7 @@ type ListState struct {
 	PageToken        string    `json:"pageToken,omitempty"`
 	IDs              []string  `json:"ids"`
 	Complete         bool      `json:"complete"`
+	HistoryID        string    `json:"historyId,omitempty"`
 	Updated          time.Time `json:"updated"`
 }
 
@@ -181,6 +182,16 @@ func (c Cache) ReadListState(selection Selection) (ListState, bool, error) {
 }
 
 func (c Cache) WriteListState(selection Selection, ids []string, pageToken string, complete bool) error {
+	return c.writeListState(selection, ids, pageToken, complete, "")
+}
+
+// WriteSyncedListState records a complete ID list together with the mailbox
+// history ID it is current as of, so the next run can replay history from it.
+func (c Cache) WriteSyncedListState(selection Selection, ids []string, historyID string) error {
+	return c.writeListState(selection, ids, "", true, historyID)
+}
+
+func (c Cache) writeListState(selection Selection, ids []string, pageToken string, complete bool, historyID string) error {
 	path, ok := c.ListStatePath(selection)
 	if !ok {
 		return errCacheListStatePathMissing
@@ -195,6 +206,7 @@ func (c Cache) WriteListState(selection Selection, ids []string, pageToken strin
 		PageToken:        pageToken,
 		IDs:              append([]string(nil), ids...),
 		Complete:         complete,
+		HistoryID:        historyID,
 		Updated:          time.Now().UTC(),
 	}
 
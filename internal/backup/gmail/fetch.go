@@ -27,6 +27,7 @@ type CacheStore interface {
 	WriteMessage(accountHash string, msg Message) error
 	ReadListState(selection Selection) (ListState, bool, error)
 	WriteListState(selection Selection, ids []string, pageToken string, complete bool) error
+	WriteSyncedListState(selection Selection, ids []string, historyID string) error
 }
 
 type Event struct {
@@ -0,0 +1,303 @@
+//nolint:wsl_v5 // History replay keeps per-message state transitions together.
+package gmailbackup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"google.golang.org/api/gmail/v1"
+	"google.golang.org/api/googleapi"
+)
+
+const DefaultHistoryPageSize = int64(500)
+
+// ErrHistoryExpired reports a start history ID that Gmail no longer retains.
+// Callers fall back to a full listing.
+var ErrHistoryExpired = errors.New("gmail history ID expired")
+
+type HistoryRequest struct {
+	StartHistoryID string
+	PageToken      string
+	MaxResults     int64
+}
+
+// HistoryChange is one history record entry for a message. LabelIDs holds the
+// message labels as of that record when HasLabels is set.
+type HistoryChange struct {
+	MessageID string
+	HistoryID string
+	Added     bool
+	Deleted   bool
+	HasLabels bool
+	LabelIDs  []string
+}
+
+type HistoryPage struct {
+	Changes       []HistoryChange
+	HistoryID     string
+	NextPageToken string
+}
+
+// HistorySource is implemented by sources that can replay mailbox changes.
+type HistorySource interface {
+	CurrentHistoryID(context.Context) (string, error)
+	ListHistory(context.Context, HistoryRequest) (HistoryPage, error)
+}
+
+type SyncResult struct {
+	IDs         []string
+	HistoryID   string
+	Incremental bool
+	Added       int
+	Changed     int
+	Deleted     int
+}
+
+func (s *ServiceSource) CurrentHistoryID(ctx context.Context) (string, error) {
+	profile, err := s.service.Users.GetProfile("me").Fields("historyId").Context(ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("read Gmail backup history ID: %w", err)
+	}
+	return strconv.FormatUint(profile.HistoryId, 10), nil
+}
+
+func (s *ServiceSource) ListHistory(ctx context.Context, req HistoryRequest) (HistoryPage, error) {
+	start, err := strconv.ParseUint(strings.TrimSpace(req.StartHistoryID), 10, 64)
+	if err != nil {
+		return HistoryPage{}, fmt.Errorf("%w: invalid start %q", ErrHistoryExpired, req.StartHistoryID)
+	}
+	if req.MaxResults <= 0 {
+		req.MaxResults = DefaultHistoryPageSize
+	}
+	call := s.service.Users.History.List("me").
+		StartHistoryId(start).
+		MaxResults(req.MaxResults).
+		Fields("history(id,messagesAdded/message(id,labelIds),messagesDeleted/message(id),labelsAdded/message(id,labelIds),labelsRemoved/message(id,labelIds)),historyId,nextPageToken").
+		Context(ctx)
+	if strings.TrimSpace(req.PageToken) != "" {
+		call = call.PageToken(req.PageToken)
+	}
+	resp, err := call.Do()
+	if err != nil {
+		var gerr *googleapi.Error
+		if errors.As(err, &gerr) && gerr.Code == http.StatusNotFound {
+			return HistoryPage{}, fmt.Errorf("%w: %w", ErrHistoryExpired, err)
+		}
+		return HistoryPage{}, fmt.Errorf("list Gmail backup history: %w", err)
+	}
+	page := HistoryPage{HistoryID: strconv.FormatUint(resp.HistoryId, 10), NextPageToken: resp.NextPageToken}
+	for _, record := range resp.History {
+		if record == nil {
+			continue
+		}
+		historyID := strconv.FormatUint(record.Id, 10)
+		add := func(message *gmail.Message, change HistoryChange) {
+			if message == nil || strings.TrimSpace(message.Id) == "" {
+				return
+			}
+			change.MessageID = message.Id
+			change.HistoryID = historyID
+			if !change.Deleted {
+				change.HasLabels = true
+				change.LabelIDs = append([]string(nil), message.LabelIds...)
+			}
+			page.Changes = append(page.Changes, change)
+		}
+		for _, item := range record.MessagesAdded {
+			if item != nil {
+				add(item.Message, HistoryChange{Added: true})
+			}
+		}
+		for _, item := range record.LabelsAdded {
+			if item != nil {
+				add(item.Message, HistoryChange{})
+			}
+		}
+		for _, item := range record.LabelsRemoved {
+			if item != nil {
+				add(item.Message, HistoryChange{})
+			}
+		}
+		for _, item := range record.MessagesDeleted {
+			if item != nil {
+				add(item.Message, HistoryChange{Deleted: true})
+			}
+		}
+	}
+	return page, nil
+}
+
+// SyncMessageIDs returns the message IDs for a full-mailbox selection. When
+// the cached ID list was completed at startHistoryID, only the history since
+// then is replayed: new messages are added, deleted ones dropped, and label
+// changes patched into cached messages. Otherwise it falls back to
+// ListMessageIDs. Either way the result carries the history ID the returned
+// list is current as of, or an empty string when the source or selection
+// cannot be synced incrementally.
+func SyncMessageIDs(ctx context.Context, source Source, opts ListOptions, startHistoryID string) (SyncResult, error) {
+	history, ok := source.(HistorySource)
+	if !ok || !historySyncable(opts) {
+		ids, err := ListMessageIDs(ctx, source, opts)
+		return SyncResult{IDs: ids}, err
+	}
+	current, err := history.CurrentHistoryID(ctx)
+	if err != nil {
+		return SyncResult{}, err
+	}
+	startHistoryID = strings.TrimSpace(startHistoryID)
+	if startHistoryID != "" && !opts.Refresh {
+		state, found, err := opts.Cache.ReadListState(opts.Selection)
+		if err != nil {
+			return SyncResult{}, fmt.Errorf("read Gmail backup list state: %w", err)
+		}
+		if found && state.Complete && state.HistoryID == startHistoryID {
+			result, err := replayHistory(ctx, history, opts, state.IDs, startHistoryID)
+			if err == nil {
+				return result, nil
+			}
+			if !errors.Is(err, ErrHistoryExpired) {
+				return SyncResult{}, err
+			}
+		}
+	}
+	// The history ID is read before listing so changes made while a long
+	// listing runs are replayed next time rather than lost.
+	opts.Refresh = true
+	ids, err := ListMessageIDs(ctx, source, opts)
+	if err != nil {
+		return SyncResult{}, err
+	}
+	if err := opts.Cache.WriteSyncedListState(opts.Selection, ids, current); err != nil {
+		return SyncResult{}, fmt.Errorf("write Gmail backup list state: %w", err)
+	}
+	return SyncResult{IDs: ids, HistoryID: current}, nil
+}
+
+func historySyncable(opts ListOptions) bool {
+	return opts.UseCache && opts.Cache != nil &&
+		strings.TrimSpace(opts.Selection.Query) == "" && opts.Selection.Max == 0
+}
+
+func replayHistory(ctx context.Context, source HistorySource, opts ListOptions, ids []string, startHistoryID string) (SyncResult, error) {
+	type messageState struct {
+		deleted   bool
+		hasLabels bool
+		labelIDs  []string
+		historyID string
+	}
+	states := map[string]*messageState{}
+	order := []string{}
+	result := SyncResult{Incremental: true, HistoryID: startHistoryID}
+	pageToken := ""
+	for {
+		if err := ctx.Err(); err != nil {
+			return SyncResult{}, fmt.Errorf("replay Gmail backup history: %w", err)
+		}
+		page, err := source.ListHistory(ctx, HistoryRequest{StartHistoryID: startHistoryID, PageToken: pageToken})
+		if err != nil {
+			return SyncResult{}, err
+		}
+		for _, change := range page.Changes {
+			state, ok := states[change.MessageID]
+			if !ok {
+				state = &messageState{}
+				states[change.MessageID] = state
+				order = append(order, change.MessageID)
+			}
+			switch {
+			case change.Deleted:
+				state.deleted = true
+			case change.Added:
+				state.deleted = false
+			}
+			if change.HasLabels {
+				state.hasLabels = true
+				state.labelIDs = change.LabelIDs
+				state.historyID = change.HistoryID
+			}
+		}
+		if strings.TrimSpace(page.HistoryID) != "" {
+			result.HistoryID = page.HistoryID
+		}
+		emitEvent(opts.Progress, Event{Phase: EventPhaseList, Resume: "history", Done: len(states)})
+		if strings.TrimSpace(page.NextPageToken) == "" {
+			break
+		}
+		pageToken = page.NextPageToken
+	}
+
+	present := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		present[id] = true
+	}
+	accountHash := opts.Selection.AccountHash
+	added := []string{}
+	for _, id := range order {
+		state := states[id]
+		keep := !state.deleted
+		if keep && state.hasLabels && !opts.Selection.IncludeSpamTrash && hasSpamOrTrash(state.labelIDs) {
+			keep = false
+		}
+		switch {
+		case !keep:
+			if present[id] {
+				delete(present, id)
+				result.Deleted++
+			}
+			continue
+		case !present[id]:
+			present[id] = true
+			added = append(added, id)
+			result.Added++
+		default:
+			result.Changed++
+		}
+		if !state.hasLabels {
+			continue
+		}
+		msg, found, err := opts.Cache.ReadMessage(accountHash, id)
+		if err != nil {
+			return SyncResult{}, fmt.Errorf("read Gmail backup cache %s: %w", id, err)
+		}
+		if !found {
+			continue
+		}
+		msg.LabelIDs = append([]string(nil), state.labelIDs...)
+		if state.historyID != "" {
+			msg.HistoryID = state.historyID
+		}
+		if err := opts.Cache.WriteMessage(accountHash, msg); err != nil {
+			return SyncResult{}, fmt.Errorf("write Gmail backup cache %s: %w", id, err)
+		}
+	}
+
+	// Gmail lists newest first; new messages lead the list.
+	next := make([]string, 0, len(ids)+len(added))
+	for i := len(added) - 1; i >= 0; i-- {
+		next = append(next, added[i])
+	}
+	for _, id := range ids {
+		if present[id] {
+			next = append(next, id)
+		}
+	}
+	result.IDs = uniqueIDs(next)
+	if err := opts.Cache.WriteSyncedListState(opts.Selection, result.IDs, result.HistoryID); err != nil {
+		return SyncResult{}, fmt.Errorf("write Gmail backup list state: %w", err)
+	}
+	emitEvent(opts.Progress, Event{Phase: EventPhaseList, Resume: "complete", Done: len(result.IDs)})
+	return result, nil
+}
+
+func hasSpamOrTrash(labelIDs []string) bool {
+	for _, label := range labelIDs {
+		if label == "SPAM" || label == "TRASH" {
+			return true
+		}
+	}
+	return false
+}
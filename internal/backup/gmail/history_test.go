@@ -0,0 +1,148 @@
+//nolint:wsl_v5 // Test setup and assertions stay grouped for scanability.
+package gmailbackup
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestSyncMessageIDsListsFullyWithoutMatchingCursor(t *testing.T) {
+	t.Parallel()
+	cache := newPlannerCache(t)
+	selection := Selection{AccountHash: "accthash", IncludeSpamTrash: true}
+	// A complete list from an older run must not be trusted without a cursor.
+	if err := cache.WriteListState(selection, []string{"stale"}, "", true); err != nil {
+		t.Fatalf("WriteListState: %v", err)
+	}
+	source := &fakeHistorySource{
+		fakeSource: fakeSource{listPages: map[string]ListPage{"": {IDs: []string{"m2", "m1"}}}},
+		current:    "100",
+	}
+	result, err := SyncMessageIDs(context.Background(), source, ListOptions{Selection: selection, Cache: cache, UseCache: true}, "")
+	if err != nil {
+		t.Fatalf("SyncMessageIDs: %v", err)
+	}
+	if result.Incremental || result.HistoryID != "100" || fmt.Sprint(result.IDs) != "[m2 m1]" {
+		t.Fatalf("result = %+v", result)
+	}
+	state, found, err := cache.ReadListState(selection)
+	if err != nil || !found || state.HistoryID != "100" || !state.Complete {
+		t.Fatalf("state = %+v found=%t err=%v", state, found, err)
+	}
+}
+
+func TestSyncMessageIDsReplaysHistorySinceCursor(t *testing.T) {
+	t.Parallel()
+	cache := newPlannerCache(t)
+	selection := Selection{AccountHash: "accthash"}
+	if err := cache.WriteSyncedListState(selection, []string{"m3", "m2", "m1"}, "100"); err != nil {
+		t.Fatalf("WriteSyncedListState: %v", err)
+	}
+	if err := cache.WriteMessage("accthash", Message{ID: "m2", LabelIDs: []string{"INBOX"}, Raw: "raw-2"}); err != nil {
+		t.Fatalf("WriteMessage: %v", err)
+	}
+	source := &fakeHistorySource{
+		current: "180",
+		pages: map[string]HistoryPage{
+			"": {
+				Changes: []HistoryChange{
+					{MessageID: "m4", HistoryID: "110", Added: true, HasLabels: true, LabelIDs: []string{"INBOX"}},
+					{MessageID: "m2", HistoryID: "120", HasLabels: true, LabelIDs: []string{"STARRED"}},
+				},
+				NextPageToken: "h2",
+			},
+			"h2": {
+				Changes: []HistoryChange{
+					{MessageID: "m1", HistoryID: "130", Deleted: true},
+					{MessageID: "m3", HistoryID: "140", HasLabels: true, LabelIDs: []string{"TRASH"}},
+					{MessageID: "m5", HistoryID: "150", Added: true, HasLabels: true, LabelIDs: []string{"SPAM"}},
+				},
+				HistoryID: "175",
+			},
+		},
+	}
+	result, err := SyncMessageIDs(context.Background(), source, ListOptions{Selection: selection, Cache: cache, UseCache: true}, "100")
+	if err != nil {
+		t.Fatalf("SyncMessageIDs: %v", err)
+	}
+	if !result.Incremental || result.HistoryID != "175" || fmt.Sprint(result.IDs) != "[m4 m2]" {
+		t.Fatalf("result = %+v", result)
+	}
+	if result.Added != 1 || result.Changed != 1 || result.Deleted != 2 {
+		t.Fatalf("counts = %+v", result)
+	}
+	if len(source.listRequests) != 0 {
+		t.Fatalf("incremental sync listed messages: %+v", source.listRequests)
+	}
+	msg, found, err := cache.ReadMessage("accthash", "m2")
+	if err != nil || !found || fmt.Sprint(msg.LabelIDs) != "[STARRED]" || msg.HistoryID != "120" {
+		t.Fatalf("patched message = %+v found=%t err=%v", msg, found, err)
+	}
+	state, _, err := cache.ReadListState(selection)
+	if err != nil || state.HistoryID != "175" || fmt.Sprint(state.IDs) != "[m4 m2]" {
+		t.Fatalf("state = %+v err=%v", state, err)
+	}
+}
+
+func TestSyncMessageIDsFallsBackWhenHistoryExpired(t *testing.T) {
+	t.Parallel()
+	cache := newPlannerCache(t)
+	selection := Selection{AccountHash: "accthash", IncludeSpamTrash: true}
+	if err := cache.WriteSyncedListState(selection, []string{"m1"}, "100"); err != nil {
+		t.Fatalf("WriteSyncedListState: %v", err)
+	}
+	source := &fakeHistorySource{
+		fakeSource: fakeSource{listPages: map[string]ListPage{"": {IDs: []string{"m9"}}}},
+		current:    "900",
+		expired:    true,
+	}
+	result, err := SyncMessageIDs(context.Background(), source, ListOptions{Selection: selection, Cache: cache, UseCache: true}, "100")
+	if err != nil {
+		t.Fatalf("SyncMessageIDs: %v", err)
+	}
+	if result.Incremental || result.HistoryID != "900" || fmt.Sprint(result.IDs) != "[m9]" {
+		t.Fatalf("result = %+v", result)
+	}
+}
+
+func TestSyncMessageIDsSkipsHistoryForQueries(t *testing.T) {
+	t.Parallel()
+	cache := newPlannerCache(t)
+	source := &fakeHistorySource{
+		fakeSource: fakeSource{listPages: map[string]ListPage{"": {IDs: []string{"m1"}}}},
+		current:    "100",
+	}
+	result, err := SyncMessageIDs(context.Background(), source, ListOptions{
+		Selection: Selection{AccountHash: "accthash", Query: "newer_than:7d"},
+		Cache:     cache,
+		UseCache:  true,
+	}, "50")
+	if err != nil {
+		t.Fatalf("SyncMessageIDs: %v", err)
+	}
+	if result.HistoryID != "" || source.historyCalls != 0 {
+		t.Fatalf("query selection used history: %+v calls=%d", result, source.historyCalls)
+	}
+}
+
+type fakeHistorySource struct {
+	fakeSource
+	current      string
+	pages        map[string]HistoryPage
+	expired      bool
+	historyCalls int
+}
+
+func (s *fakeHistorySource) CurrentHistoryID(context.Context) (string, error) {
+	s.historyCalls++
+	return s.current, nil
+}
+
+func (s *fakeHistorySource) ListHistory(_ context.Context, req HistoryRequest) (HistoryPage, error) {
+	s.historyCalls++
+	if s.expired {
+		return HistoryPage{}, ErrHistoryExpired
+	}
+	return s.pages[req.PageToken], nil
+}
@@ -0,0 +1,390 @@
+//nolint:err113,wrapcheck,wsl_v5 // Contextual errors keep backup call sites readable.
+package backup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	RowAdded   = "added"
+	RowRemoved = "removed"
+	RowChanged = "changed"
+
+	manifestFile = "manifest.json"
+)
+
+// rowKeyFields are the identity fields checked on each JSONL row, first at the
+// top level and then one level down for wrapper rows such as calendar events
+// stored with their calendar ID.
+var rowKeyFields = []string{"id", "resourceName", "name"}
+
+type Revision struct {
+	Commit   string         `json:"commit"`
+	Time     time.Time      `json:"time"`
+	Subject  string         `json:"subject"`
+	Exported time.Time      `json:"exported"`
+	Services []string       `json:"services,omitempty"`
+	Accounts []string       `json:"accounts,omitempty"`
+	Shards   int            `json:"shards"`
+	Counts   map[string]int `json:"counts,omitempty"`
+}
+
+type DiffOptions struct {
+	Include func(ShardEntry) bool
+	Rows    bool
+}
+
+type RowChange struct {
+	Key    string `json:"key"`
+	Change string `json:"change"`
+}
+
+type DiffStat struct {
+	Service string      `json:"service"`
+	Kind    string      `json:"kind"`
+	Account string      `json:"account,omitempty"`
+	Added   int         `json:"added"`
+	Removed int         `json:"removed"`
+	Changed int         `json:"changed"`
+	Rows    []RowChange `json:"rows,omitempty"`
+}
+
+type DiffResult struct {
+	Repo  string     `json:"repo"`
+	From  string     `json:"from"`
+	To    string     `json:"to"`
+	Stats []DiffStat `json:"stats"`
+}
+
+// Log lists backup snapshots, newest first, by walking commits that changed
+// the manifest. Checkpoint commits do not touch the manifest and are skipped.
+func Log(ctx context.Context, opts Options, limit int) ([]Revision, string, error) {
+	cfg, err := ResolveOptions(opts)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := requireGitStorage(cfg, "backup log"); err != nil {
+		return nil, "", err
+	}
+	if repoErr := prepareReadRepo(ctx, cfg, opts.SkipPull); repoErr != nil {
+		return nil, "", repoErr
+	}
+	revisions, err := listRevisions(ctx, cfg.Repo, limit)
+	if err != nil {
+		return nil, "", err
+	}
+	return revisions, cfg.Repo, nil
+}
+
+func listRevisions(ctx context.Context, repo string, limit int) ([]Revision, error) {
+	if git(ctx, repo, "rev-parse", "--verify", "HEAD") != nil {
+		return []Revision{}, nil
+	}
+	args := []string{"log", "--format=%H%x1f%cI%x1f%s"}
+	if limit > 0 {
+		args = append(args, "-n", strconv.Itoa(limit))
+	}
+	out, err := gitOutput(ctx, repo, append(args, "--", manifestFile)...)
+	if err != nil {
+		return nil, err
+	}
+	revisions := []Revision{}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.SplitN(line, "\x1f", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		committed, err := time.Parse(time.RFC3339, fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("parse commit time for %s: %w", fields[0], err)
+		}
+		manifest, err := readManifestAt(ctx, repo, fields[0])
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, Revision{
+			Commit:   fields[0],
+			Time:     committed,
+			Subject:  fields[2],
+			Exported: manifest.Exported,
+			Services: manifest.Services,
+			Accounts: manifest.Accounts,
+			Shards:   len(manifest.Shards),
+			Counts:   manifest.Counts,
+		})
+	}
+	return revisions, nil
+}
+
+// Diff compares the decrypted rows of two snapshot revisions. Shard groups
+// whose plaintext hashes are identical in both manifests are not decrypted.
+func Diff(ctx context.Context, opts Options, from, to string, diffOpts DiffOptions) (DiffResult, error) {
+	cfg, err := ResolveOptions(opts)
+	if err != nil {
+		return DiffResult{}, err
+	}
+	if err := requireGitStorage(cfg, "backup diff"); err != nil {
+		return DiffResult{}, err
+	}
+	if repoErr := prepareReadRepo(ctx, cfg, opts.SkipPull); repoErr != nil {
+		return DiffResult{}, repoErr
+	}
+	fromCommit, err := resolveRevision(ctx, cfg.Repo, from)
+	if err != nil {
+		return DiffResult{}, err
+	}
+	toCommit, err := resolveRevision(ctx, cfg.Repo, to)
+	if err != nil {
+		return DiffResult{}, err
+	}
+	fromManifest, err := readSnapshotManifestAt(ctx, cfg.Repo, fromCommit)
+	if err != nil {
+		return DiffResult{}, err
+	}
+	toManifest, err := readSnapshotManifestAt(ctx, cfg.Repo, toCommit)
+	if err != nil {
+		return DiffResult{}, err
+	}
+	fromGroups := groupShards(fromManifest, diffOpts.Include)
+	toGroups := groupShards(toManifest, diffOpts.Include)
+	keys := make([]string, 0, len(fromGroups)+len(toGroups))
+	for key := range fromGroups {
+		keys = append(keys, key)
+	}
+	for key := range toGroups {
+		if _, ok := fromGroups[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	result := DiffResult{Repo: cfg.Repo, From: fromCommit, To: toCommit, Stats: []DiffStat{}}
+	for _, key := range keys {
+		select {
+		case <-ctx.Done():
+			return DiffResult{}, ctx.Err()
+		default:
+		}
+		before, after := fromGroups[key], toGroups[key]
+		if sameShardHashes(before, after) {
+			continue
+		}
+		beforeRows, err := readRowHashes(ctx, cfg, fromCommit, before)
+		if err != nil {
+			return DiffResult{}, err
+		}
+		afterRows, err := readRowHashes(ctx, cfg, toCommit, after)
+		if err != nil {
+			return DiffResult{}, err
+		}
+		sample := before
+		if len(sample) == 0 {
+			sample = after
+		}
+		stat := compareRows(beforeRows, afterRows, diffOpts.Rows)
+		stat.Service, stat.Kind, stat.Account = sample[0].Service, sample[0].Kind, sample[0].Account
+		if stat.Added+stat.Removed+stat.Changed > 0 {
+			result.Stats = append(result.Stats, stat)
+		}
+	}
+	return result, nil
+}
+
+func resolveRevision(ctx context.Context, repo, rev string) (string, error) {
+	rev = strings.TrimSpace(rev)
+	if rev == "" || strings.HasPrefix(rev, "-") {
+		return "", fmt.Errorf("invalid backup revision %q", rev)
+	}
+	out, err := gitOutput(ctx, repo, "rev-parse", "--verify", "--end-of-options", rev+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("resolve backup revision %q: %w", rev, err)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+func readManifestAt(ctx context.Context, repo, commit string) (Manifest, error) {
+	data, err := gitOutput(ctx, repo, "show", commit+":"+manifestFile)
+	if err != nil {
+		return Manifest{}, err
+	}
+	var manifest Manifest
+	if err := json.Unmarshal([]byte(data), &manifest); err != nil {
+		return Manifest{}, fmt.Errorf("decode manifest at %s: %w", commit, err)
+	}
+	return manifest, nil
+}
+
+func readSnapshotManifestAt(ctx context.Context, repo, commit string) (Manifest, error) {
+	manifest, err := readManifestAt(ctx, repo, commit)
+	if err != nil {
+		return Manifest{}, err
+	}
+	if manifest.Format != formatVersion {
+		return Manifest{}, fmt.Errorf("unsupported backup format %d at %s", manifest.Format, commit)
+	}
+	return manifest, nil
+}
+
+func groupShards(manifest Manifest, include func(ShardEntry) bool) map[string][]ShardEntry {
+	out := map[string][]ShardEntry{}
+	for _, shard := range manifest.Shards {
+		if include != nil && !include(shard) {
+			continue
+		}
+		key := shard.Service + "\x00" + shard.Kind + "\x00" + shard.Account
+		out[key] = append(out[key], shard)
+	}
+	return out
+}
+
+func sameShardHashes(left, right []ShardEntry) bool {
+	if len(left) != len(right) {
+		return false
+	}
+	hashes := func(shards []ShardEntry) []string {
+		out := make([]string, 0, len(shards))
+		for _, shard := range shards {
+			out = append(out, shard.SHA256)
+		}
+		sort.Strings(out)
+		return out
+	}
+	leftHashes, rightHashes := hashes(left), hashes(right)
+	for i := range leftHashes {
+		if leftHashes[i] != rightHashes[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func readRowHashes(ctx context.Context, cfg Config, commit string, shards []ShardEntry) (map[string]string, error) {
+	rows := map[string]string{}
+	keyless := 0
+	for _, shard := range shards {
+		if _, err := resolveShardPath(cfg.Repo, shard.Path); err != nil {
+			return nil, err
+		}
+		ciphertext, err := gitOutput(ctx, cfg.Repo, "show", commit+":"+shard.Path)
+		if err != nil {
+			return nil, err
+		}
+		plaintext, err := decryptShard([]byte(ciphertext), cfg.Identity)
+		if err != nil {
+			return nil, fmt.Errorf("decrypt %s at %s: %w", shard.Path, commit, err)
+		}
+		if err := verifyPlainShard(shard, plaintext); err != nil {
+			return nil, err
+		}
+		for _, line := range jsonlLines(plaintext) {
+			line = bytes.TrimSpace(line)
+			if len(line) == 0 {
+				continue
+			}
+			key, ok := rowKey(line)
+			if !ok {
+				keyless++
+				key = "#" + strconv.Itoa(keyless)
+			}
+			for base, n := key, 2; ; n++ {
+				if _, exists := rows[key]; !exists {
+					break
+				}
+				key = base + "#" + strconv.Itoa(n)
+			}
+			rows[key] = sha256Hex(line)
+		}
+	}
+	return rows, nil
+}
+
+// rowKey derives a stable identity for a JSONL row. Rows without one are keyed
+// by position, which keeps single-row settings shards comparable.
+func rowKey(line []byte) (string, bool) {
+	var row map[string]json.RawMessage
+	if err := json.Unmarshal(line, &row); err != nil {
+		return "", false
+	}
+	if key, ok := rowIDField(row); ok {
+		return key, true
+	}
+	names := make([]string, 0, len(row))
+	for name := range row {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	scope := []string{}
+	nested := ""
+	for _, name := range names {
+		var value string
+		if err := json.Unmarshal(row[name], &value); err == nil {
+			if value != "" {
+				scope = append(scope, value)
+			}
+			continue
+		}
+		var object map[string]json.RawMessage
+		if nested == "" && json.Unmarshal(row[name], &object) == nil {
+			if key, ok := rowIDField(object); ok {
+				nested = key
+			}
+		}
+	}
+	if nested == "" {
+		return "", false
+	}
+	return strings.Join(append(scope, nested), "/"), true
+}
+
+func rowIDField(row map[string]json.RawMessage) (string, bool) {
+	for _, field := range rowKeyFields {
+		var value string
+		if err := json.Unmarshal(row[field], &value); err == nil && strings.TrimSpace(value) != "" {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+func compareRows(before, after map[string]string, withRows bool) DiffStat {
+	stat := DiffStat{}
+	keys := make([]string, 0, len(before)+len(after))
+	for key := range before {
+		keys = append(keys, key)
+	}
+	for key := range after {
+		if _, ok := before[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		old, hadOld := before[key]
+		current, hasNew := after[key]
+		change := ""
+		switch {
+		case !hadOld:
+			stat.Added++
+			change = RowAdded
+		case !hasNew:
+			stat.Removed++
+			change = RowRemoved
+		case old != current:
+			stat.Changed++
+			change = RowChanged
+		default:
+			continue
+		}
+		if withRows {
+			stat.Rows = append(stat.Rows, RowChange{Key: key, Change: change})
+		}
+	}
+	return stat
+}
@@ -0,0 +1,87 @@
+package backup
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLogAndDiffReportRowChangesBetweenSnapshots(t *testing.T) {
+	ctx, _, config, _ := initTestBackup(t)
+	push := func(people []map[string]any) {
+		t.Helper()
+		contacts, err := NewJSONLShard("contacts", "people", "acct", "data/contacts/acct/people.jsonl.gz.age", people)
+		if err != nil {
+			t.Fatalf("NewJSONLShard contacts: %v", err)
+		}
+		lists, err := NewJSONLShard("tasks", "lists", "acct", "data/tasks/acct/lists.jsonl.gz.age", []map[string]string{{"id": "l1", "title": "Inbox"}})
+		if err != nil {
+			t.Fatalf("NewJSONLShard tasks: %v", err)
+		}
+		if _, err := PushSnapshot(ctx, Snapshot{
+			Services: []string{"contacts", "tasks"},
+			Accounts: []string{"acct"},
+			Shards:   []PlainShard{contacts, lists},
+		}, testOptions(t, Options{ConfigPath: config})); err != nil {
+			t.Fatalf("PushSnapshot: %v", err)
+		}
+	}
+	push([]map[string]any{
+		{"source": "connections", "person": map[string]any{"resourceName": "people/a", "names": "Ada"}},
+		{"source": "connections", "person": map[string]any{"resourceName": "people/b"}},
+	})
+	push([]map[string]any{
+		{"source": "connections", "person": map[string]any{"resourceName": "people/a", "names": "Ada L."}},
+		{"source": "other", "person": map[string]any{"resourceName": "people/c"}},
+	})
+
+	opts := testOptions(t, Options{ConfigPath: config, SkipPull: true})
+	revisions, _, err := Log(ctx, opts, 0)
+	if err != nil {
+		t.Fatalf("Log: %v", err)
+	}
+	if len(revisions) != 2 || revisions[0].Shards != 2 || revisions[0].Counts["contacts.people"] != 2 {
+		t.Fatalf("revisions = %+v", revisions)
+	}
+
+	result, err := Diff(ctx, opts, revisions[1].Commit, revisions[0].Commit, DiffOptions{Rows: true})
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+	want := []DiffStat{{
+		Service: "contacts",
+		Kind:    "people",
+		Account: "acct",
+		Added:   1,
+		Removed: 1,
+		Changed: 1,
+		Rows: []RowChange{
+			{Key: "connections/people/a", Change: RowChanged},
+			{Key: "connections/people/b", Change: RowRemoved},
+			{Key: "other/people/c", Change: RowAdded},
+		},
+	}}
+	if !reflect.DeepEqual(result.Stats, want) {
+		t.Fatalf("stats = %+v, want %+v", result.Stats, want)
+	}
+}
+
+func TestDiffRejectsOptionLikeRevision(t *testing.T) {
+	ctx, _, config, _ := initTestBackup(t)
+	if _, err := Diff(ctx, testOptions(t, Options{ConfigPath: config, SkipPull: true}), "--all", "HEAD", DiffOptions{}); err == nil {
+		t.Fatal("expected option-like revision to be rejected")
+	}
+}
+
+func TestRowKeyFallsBackToNestedIdentity(t *testing.T) {
+	for line, want := range map[string]string{
+		`{"id":"m1","threadId":"t1"}`:                                   "m1",
+		`{"calendarId":"primary","event":{"id":"e1"}}`:                  "primary/e1",
+		`{"taskListId":"l1","task":{"id":"t1","title":"x"}}`:            "l1/t1",
+		`{"filters":[{"id":"f1"}],"vacation":{"enableAutoReply":true}}`: "",
+	} {
+		got, ok := rowKey([]byte(line))
+		if got != want || ok != (want != "") {
+			t.Fatalf("rowKey(%s) = %q, %t; want %q", line, got, ok, want)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+//nolint:err113,wrapcheck,wsl_v5 // Contextual errors keep backup call sites readable.
+package backup
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// PrunePolicy selects which snapshots survive a prune. Each bucket keeps the
+// newest snapshot per calendar day, ISO week, or month, up to the given count.
+// The newest snapshot is always kept.
+type PrunePolicy struct {
+	KeepLast    int
+	KeepDaily   int
+	KeepWeekly  int
+	KeepMonthly int
+}
+
+func (p PrunePolicy) empty() bool {
+	return p.KeepLast <= 0 && p.KeepDaily <= 0 && p.KeepWeekly <= 0 && p.KeepMonthly <= 0
+}
+
+type PruneResult struct {
+	Repo    string     `json:"repo"`
+	Storage string     `json:"storage"`
+	Applied bool       `json:"applied"`
+	Kept    []Revision `json:"kept"`
+	Removed []Revision `json:"removed"`
+	Objects []string   `json:"objects,omitempty"`
+}
+
+// Prune plans, and with apply set performs, backup retention. Git targets get
+// a rewritten linear history containing only the retained snapshots, followed
+// by reflog expiry and gc so dropped shard blobs leave the repository. Object
+// targets only keep the latest snapshot, so pruning there deletes shard
+// objects the published manifest no longer references.
+func Prune(ctx context.Context, opts Options, policy PrunePolicy, apply bool) (PruneResult, error) {
+	cfg, err := ResolveOptions(opts)
+	if err != nil {
+		return PruneResult{}, err
+	}
+	storage, err := storageFor(cfg)
+	if err != nil {
+		return PruneResult{}, err
+	}
+	result := PruneResult{Repo: cfg.Repo, Storage: storage.Kind(), Kept: []Revision{}, Removed: []Revision{}}
+	switch s := storage.(type) {
+	case gitStorage:
+		if policy.empty() {
+			return PruneResult{}, fmt.Errorf("backup prune needs at least one --keep-* policy")
+		}
+		return pruneGit(ctx, cfg, opts, policy, apply, result)
+	case objectStorage:
+		if repoErr := prepareReadRepo(ctx, cfg, opts.SkipPull); repoErr != nil {
+			return PruneResult{}, repoErr
+		}
+		manifest, err := readManifest(cfg.Repo)
+		if err != nil {
+			return PruneResult{}, err
+		}
+		stale, err := s.staleObjects(ctx, manifest)
+		if err != nil {
+			return PruneResult{}, err
+		}
+		result.Objects = stale
+		if apply {
+			for _, key := range stale {
+				if err := s.store.delete(ctx, key); err != nil {
+					return PruneResult{}, fmt.Errorf("delete stale %s from %s: %w", key, s.store.describe(), err)
+				}
+			}
+			result.Applied = true
+		}
+		return result, nil
+	default:
+		// A tarball is rewritten from the manifest on every publish and never
+		// holds unreferenced shards.
+		result.Applied = apply
+		return result, nil
+	}
+}
+
+func pruneGit(ctx context.Context, cfg Config, opts Options, policy PrunePolicy, apply bool, result PruneResult) (PruneResult, error) {
+	if err := waitAsyncPushes(ctx, cfg.Repo, opts.Progress); err != nil {
+		return PruneResult{}, err
+	}
+	if repoErr := prepareReadRepo(ctx, cfg, opts.SkipPull); repoErr != nil {
+		return PruneResult{}, repoErr
+	}
+	revisions, err := listRevisions(ctx, cfg.Repo, 0)
+	if err != nil {
+		return PruneResult{}, err
+	}
+	keep := retainedRevisions(revisions, policy)
+	for _, revision := range revisions {
+		if keep[revision.Commit] {
+			result.Kept = append(result.Kept, revision)
+		} else {
+			result.Removed = append(result.Removed, revision)
+		}
+	}
+	if !apply || len(result.Removed) == 0 {
+		return result, nil
+	}
+	// A pruned branch that is never pushed diverges from the remote, and the
+	// next push's pull --rebase would replay it onto the old history,
+	// bringing the dropped snapshots back.
+	if !opts.Push && strings.TrimSpace(cfg.Remote) != "" {
+		return PruneResult{}, fmt.Errorf("backup prune with --no-push would leave remote %s with the old history; drop --no-push to force-push the pruned branch", cfg.Remote)
+	}
+	if err := rewriteHistory(ctx, cfg, result.Kept, opts.Push); err != nil {
+		return PruneResult{}, err
+	}
+	kept, err := listRevisions(ctx, cfg.Repo, 0)
+	if err != nil {
+		return PruneResult{}, err
+	}
+	result.Kept = kept
+	result.Applied = true
+	return result, nil
+}
+
+// retainedRevisions applies the policy to revisions ordered newest first.
+func retainedRevisions(revisions []Revision, policy PrunePolicy) map[string]bool {
+	keep := map[string]bool{}
+	if len(revisions) == 0 {
+		return keep
+	}
+	keep[revisions[0].Commit] = true
+	buckets := []struct {
+		limit int
+		key   func(Revision) string
+	}{
+		{policy.KeepLast, func(r Revision) string { return r.Commit }},
+		{policy.KeepDaily, func(r Revision) string { return r.Time.Format("2006-01-02") }},
+		{policy.KeepWeekly, func(r Revision) string {
+			year, week := r.Time.ISOWeek()
+			return fmt.Sprintf("%d-W%02d", year, week)
+		}},
+		{policy.KeepMonthly, func(r Revision) string { return r.Time.Format("2006-01") }},
+	}
+	for _, bucket := range buckets {
+		seen := map[string]struct{}{}
+		for _, revision := range revisions {
+			if len(seen) >= bucket.limit {
+				break
+			}
+			key := bucket.key(revision)
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			keep[revision.Commit] = true
+		}
+	}
+	return keep
+}
+
+// rewriteHistory replaces the current branch with one commit per kept
+// snapshot, reusing their trees, messages, and dates. HEAD's tree is always
+// the tip so the working tree and Verify are unaffected.
+func rewriteHistory(ctx context.Context, cfg Config, kept []Revision, push bool) error {
+	status, err := gitOutput(ctx, cfg.Repo, "status", "--porcelain")
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(status) != "" {
+		return fmt.Errorf("backup repo %s has uncommitted changes; commit or discard them before pruning", cfg.Repo)
+	}
+	branch, err := gitOutput(ctx, cfg.Repo, "symbolic-ref", "--quiet", "--short", "HEAD")
+	if err != nil || strings.TrimSpace(branch) == "" {
+		return fmt.Errorf("backup repo %s is not on a branch", cfg.Repo)
+	}
+	branch = strings.TrimSpace(branch)
+	head, err := gitOutput(ctx, cfg.Repo, "rev-parse", "HEAD")
+	if err != nil {
+		return err
+	}
+	head = strings.TrimSpace(head)
+
+	// kept is newest first; rebuild oldest first.
+	commits := make([]string, 0, len(kept)+1)
+	for i := len(kept) - 1; i >= 0; i-- {
+		commits = append(commits, kept[i].Commit)
+	}
+	if len(commits) == 0 || commits[len(commits)-1] != head {
+		commits = append(commits, head)
+	}
+
+	parent := ""
+	for _, commit := range commits {
+		next, err := copyCommit(ctx, cfg.Repo, commit, parent)
+		if err != nil {
+			return err
+		}
+		parent = next
+	}
+	// Push before touching the local branch: if the remote rejects the
+	// rewrite, the old history is still reachable locally and nothing is lost.
+	if push && strings.TrimSpace(cfg.Remote) != "" {
+		if err := git(ctx, cfg.Repo, "push", "--force-with-lease=refs/heads/"+branch+":"+head, "origin", parent+":refs/heads/"+branch); err != nil {
+			return err
+		}
+	}
+	if err := git(ctx, cfg.Repo, "update-ref", "-m", "backup prune", "refs/heads/"+branch, parent, head); err != nil {
+		return err
+	}
+	if err := git(ctx, cfg.Repo, "reflog", "expire", "--expire=now", "--all"); err != nil {
+		return err
+	}
+	return git(ctx, cfg.Repo, "gc", "--quiet", "--prune=now")
+}
+
+func copyCommit(ctx context.Context, repo, commit, parent string) (string, error) {
+	meta, err := gitOutput(ctx, repo, "log", "-1", "--format=%T%x1f%aI%x1f%cI%x1f%B", commit)
+	if err != nil {
+		return "", err
+	}
+	fields := strings.SplitN(meta, "\x1f", 4)
+	if len(fields) != 4 {
+		return "", fmt.Errorf("read backup commit %s: unexpected log output", commit)
+	}
+	args := []string{"commit-tree", fields[0]}
+	if parent != "" {
+		args = append(args, "-p", parent)
+	}
+	args = append(args, "-m", strings.TrimRight(fields[3], "\n"))
+	cmd := gitCommand(ctx, repo, args...)
+	cmd.Env = replaceEnvironment(cmd.Env, map[string]string{
+		"GIT_AUTHOR_DATE":    fields[1],
+		"GIT_COMMITTER_DATE": fields[2],
+	})
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", gitError(args, err, stderr.String())
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
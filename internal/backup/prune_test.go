@@ -0,0 +1,172 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPruneRewritesHistoryAndKeepsVerifyPassing(t *testing.T) {
+	ctx, _, config, _ := initTestBackup(t)
+	for i, when := range []string{
+		"2026-03-01T10:00:00Z",
+		"2026-03-02T09:00:00Z",
+		"2026-03-02T18:00:00Z",
+		"2026-03-03T08:00:00Z",
+	} {
+		t.Setenv("GIT_AUTHOR_DATE", when)
+		t.Setenv("GIT_COMMITTER_DATE", when)
+		shard, err := NewJSONLShard("tasks", "lists", "acct", "data/tasks/acct/lists.jsonl.gz.age", []map[string]any{{"id": "l1", "n": i}})
+		if err != nil {
+			t.Fatalf("NewJSONLShard: %v", err)
+		}
+		if _, err := PushSnapshot(ctx, Snapshot{
+			Services: []string{"tasks"},
+			Accounts: []string{"acct"},
+			Shards:   []PlainShard{shard},
+		}, testOptions(t, Options{ConfigPath: config})); err != nil {
+			t.Fatalf("PushSnapshot: %v", err)
+		}
+	}
+	opts := testOptions(t, Options{ConfigPath: config})
+	policy := PrunePolicy{KeepDaily: 2}
+
+	plan, err := Prune(ctx, opts, policy, false)
+	if err != nil {
+		t.Fatalf("Prune plan: %v", err)
+	}
+	if plan.Applied || len(plan.Kept) != 2 || len(plan.Removed) != 2 {
+		t.Fatalf("plan = %+v", plan)
+	}
+	if revisions, _, err := Log(ctx, opts, 0); err != nil || len(revisions) != 4 {
+		t.Fatalf("plan changed history: %d revisions, err=%v", len(revisions), err)
+	}
+
+	result, err := Prune(ctx, opts, policy, true)
+	if err != nil {
+		t.Fatalf("Prune apply: %v", err)
+	}
+	if !result.Applied {
+		t.Fatalf("result = %+v", result)
+	}
+	revisions, _, err := Log(ctx, opts, 0)
+	if err != nil {
+		t.Fatalf("Log: %v", err)
+	}
+	if len(revisions) != 2 {
+		t.Fatalf("revisions after prune = %d", len(revisions))
+	}
+	if got := revisions[1].Time.UTC(); !got.Equal(time.Date(2026, 3, 2, 18, 0, 0, 0, time.UTC)) {
+		t.Fatalf("oldest kept snapshot time = %s", got)
+	}
+	if _, err := Verify(ctx, opts); err != nil {
+		t.Fatalf("Verify after prune: %v", err)
+	}
+}
+
+func TestPruneKeepsLocalHistoryWhenPushFails(t *testing.T) {
+	ctx, repo, config, _ := initTestBackup(t)
+	for i := 0; i < 2; i++ {
+		shard, err := NewJSONLShard("tasks", "lists", "acct", "data/tasks/acct/lists.jsonl.gz.age", []map[string]any{{"id": "l1", "n": i}})
+		if err != nil {
+			t.Fatalf("NewJSONLShard: %v", err)
+		}
+		if _, err := PushSnapshot(ctx, Snapshot{
+			Services: []string{"tasks"},
+			Accounts: []string{"acct"},
+			Shards:   []PlainShard{shard},
+		}, testOptions(t, Options{ConfigPath: config})); err != nil {
+			t.Fatalf("PushSnapshot: %v", err)
+		}
+	}
+	if err := git(ctx, repo, "remote", "add", "origin", filepath.Join(t.TempDir(), "missing.git")); err != nil {
+		t.Fatalf("add remote: %v", err)
+	}
+	before, err := listRevisions(ctx, repo, 0)
+	if err != nil {
+		t.Fatalf("listRevisions: %v", err)
+	}
+	cfg := Config{Repo: repo, Remote: "missing"}
+	if err := rewriteHistory(ctx, cfg, before[:1], true); err == nil {
+		t.Fatal("expected push error")
+	}
+	after, err := listRevisions(ctx, repo, 0)
+	if err != nil {
+		t.Fatalf("listRevisions after failed push: %v", err)
+	}
+	if len(after) != len(before) || after[0].Commit != before[0].Commit {
+		t.Fatalf("failed push rewrote local history: %d -> %d revisions", len(before), len(after))
+	}
+}
+
+func TestPruneRefusesNoPushWithRemote(t *testing.T) {
+	ctx, repo, config, recipient := initTestBackup(t)
+	for i := 0; i < 2; i++ {
+		shard, err := NewJSONLShard("tasks", "lists", "acct", "data/tasks/acct/lists.jsonl.gz.age", []map[string]any{{"id": "l1", "n": i}})
+		if err != nil {
+			t.Fatalf("NewJSONLShard: %v", err)
+		}
+		if _, err := PushSnapshot(ctx, Snapshot{
+			Services: []string{"tasks"},
+			Accounts: []string{"acct"},
+			Shards:   []PlainShard{shard},
+		}, testOptions(t, Options{ConfigPath: config})); err != nil {
+			t.Fatalf("PushSnapshot: %v", err)
+		}
+	}
+	cfg := Config{Repo: repo, Remote: "git@example.com:backup.git", Recipients: []string{recipient}}
+	cfg.Identity = filepath.Join(filepath.Dir(config), "age.key")
+	saveTestConfig(t, config, cfg)
+
+	opts := testOptions(t, Options{ConfigPath: config, SkipPull: true})
+	before, _, err := Log(ctx, opts, 0)
+	if err != nil || len(before) != 2 {
+		t.Fatalf("revisions before prune = %d, err=%v", len(before), err)
+	}
+	if _, err = Prune(ctx, opts, PrunePolicy{KeepLast: 1}, true); err == nil || !strings.Contains(err.Error(), "--no-push") {
+		t.Fatalf("expected --no-push refusal, got %v", err)
+	}
+	if after, _, err := Log(ctx, opts, 0); err != nil || len(after) != len(before) {
+		t.Fatalf("refused prune changed history: %d revisions, err=%v", len(after), err)
+	}
+}
+
+func TestPruneRequiresPolicyForGit(t *testing.T) {
+	ctx, _, config, _ := initTestBackup(t)
+	if _, err := Prune(ctx, testOptions(t, Options{ConfigPath: config}), PrunePolicy{}, false); err == nil {
+		t.Fatal("expected missing policy error")
+	}
+}
+
+func TestPruneDeletesUnreferencedObjects(t *testing.T) {
+	target := filepath.Join(t.TempDir(), "target")
+	ctx, _, config := initStorageTestBackup(t, target)
+	pushStorageTestSnapshot(t, ctx, config, "labels-v1", "m1")
+	orphan := filepath.Join(t.TempDir(), "orphan.age")
+	if err := os.WriteFile(orphan, []byte("x"), 0o600); err != nil {
+		t.Fatalf("write orphan: %v", err)
+	}
+	if err := (dirStore{root: target}).put(ctx, "data/gmail/acct/messages/part-old.jsonl.gz.age", orphan); err != nil {
+		t.Fatalf("put orphan: %v", err)
+	}
+
+	result, err := Prune(ctx, testOptions(t, Options{ConfigPath: config}), PrunePolicy{}, true)
+	if err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+	if result.Storage != StorageDir || len(result.Objects) != 1 || result.Objects[0] != "data/gmail/acct/messages/part-old.jsonl.gz.age" {
+		t.Fatalf("result = %+v", result)
+	}
+	keys, err := (dirStore{root: target}).list(ctx, "data/")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(keys) != 2 {
+		t.Fatalf("objects after prune = %v", keys)
+	}
+	if _, err := Verify(ctx, testOptions(t, Options{ConfigPath: config})); err != nil {
+		t.Fatalf("Verify after prune: %v", err)
+	}
+}
@@ -41,6 +41,12 @@ func DecryptSnapshot(ctx context.Context, opts Options) (Manifest, string, []Pla
 }
 
 func WalkSnapshot(ctx context.Context, opts Options, visit func(Manifest, string, PlainShard) error) (Manifest, string, error) {
+	return WalkSelectedShards(ctx, opts, nil, visit)
+}
+
+// WalkSelectedShards decrypts only manifest shards accepted by include, so
+// callers that need one service do not pay for decrypting the whole backup.
+func WalkSelectedShards(ctx context.Context, opts Options, include func(ShardEntry) bool, visit func(Manifest, string, PlainShard) error) (Manifest, string, error) {
 	cfg, err := ResolveOptions(opts)
 	if err != nil {
 		return Manifest{}, "", err
@@ -61,6 +67,9 @@ func WalkSnapshot(ctx context.Context, opts Options, visit func(Manifest, string
 			return Manifest{}, "", ctx.Err()
 		default:
 		}
+		if include != nil && !include(shard) {
+			continue
+		}
 		plain, err := decryptManifestShard(cfg, shard)
 		if err != nil {
 			return Manifest{}, "", err
@@ -0,0 +1,119 @@
+//nolint:err113,wrapcheck,wsl_v5 // Storage errors carry target context for the CLI.
+package backup
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	StorageGit = "git"
+	StorageDir = "dir"
+	StorageTar = "tar"
+	StorageS3  = "s3"
+)
+
+// Storage moves the local backup working directory to and from a durable
+// target. Shards and manifests are always written to cfg.Repo first; a
+// storage backend only decides how that directory is fetched and published.
+type Storage interface {
+	Kind() string
+	// Prepare readies cfg.Repo for a write, fetching existing remote state so
+	// unchanged encrypted shards can be reused.
+	Prepare(ctx context.Context, cfg Config) error
+	// Fetch refreshes cfg.Repo before a read.
+	Fetch(ctx context.Context, cfg Config) error
+	// Publish records the current cfg.Repo state. When push is false the
+	// change stays local (a local commit for git, nothing for other targets).
+	Publish(ctx context.Context, cfg Config, message string, push bool) (bool, error)
+}
+
+// StorageKind reports which backend a resolved config uses.
+func StorageKind(cfg Config) (string, error) {
+	storage, err := storageFor(cfg)
+	if err != nil {
+		return "", err
+	}
+	return storage.Kind(), nil
+}
+
+func storageFor(cfg Config) (Storage, error) {
+	target := strings.TrimSpace(cfg.Target)
+	if target == "" || target == StorageGit {
+		return gitStorage{}, nil
+	}
+	if strings.HasPrefix(target, "s3://") {
+		store, err := newS3Store(target)
+		if err != nil {
+			return nil, err
+		}
+		return objectStorage{store: store}, nil
+	}
+	dir := target
+	if strings.HasPrefix(target, "file://") {
+		parsed, err := url.Parse(target)
+		if err != nil {
+			return nil, fmt.Errorf("invalid backup target %q: %w", target, err)
+		}
+		dir = parsed.Path
+	}
+	if !filepath.IsAbs(dir) {
+		return nil, fmt.Errorf("unsupported backup target %q (use git, an absolute path, file:///path, or s3://bucket/prefix)", target)
+	}
+	if strings.HasSuffix(dir, ".tar") {
+		return tarStorage{path: filepath.Clean(dir)}, nil
+	}
+	return objectStorage{store: dirStore{root: filepath.Clean(dir)}}, nil
+}
+
+func prepareReadRepo(ctx context.Context, cfg Config, skipPull bool) error {
+	if strings.TrimSpace(cfg.Repo) == "" {
+		return fmt.Errorf("backup repo path is required")
+	}
+	if skipPull {
+		return nil
+	}
+	storage, err := storageFor(cfg)
+	if err != nil {
+		return err
+	}
+	return storage.Fetch(ctx, cfg)
+}
+
+func requireGitStorage(cfg Config, operation string) error {
+	kind, err := StorageKind(cfg)
+	if err != nil {
+		return err
+	}
+	if kind != StorageGit {
+		return fmt.Errorf("%s requires git backup storage; target %q keeps only the latest snapshot", operation, cfg.Target)
+	}
+	return nil
+}
+
+type gitStorage struct{}
+
+func (gitStorage) Kind() string { return StorageGit }
+
+func (gitStorage) Prepare(ctx context.Context, cfg Config) error {
+	return ensureRepo(ctx, cfg)
+}
+
+func (gitStorage) Fetch(ctx context.Context, cfg Config) error {
+	return fetchGitRepo(ctx, cfg)
+}
+
+func (gitStorage) Publish(ctx context.Context, cfg Config, message string, push bool) (bool, error) {
+	return commitAndPush(ctx, cfg, message, push)
+}
+
+func ensureWorkDir(cfg Config) error {
+	if strings.TrimSpace(cfg.Repo) == "" {
+		return fmt.Errorf("backup repo path is required")
+	}
+	return os.MkdirAll(cfg.Repo, 0o700)
+}
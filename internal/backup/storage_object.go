@@ -0,0 +1,358 @@
+//nolint:err113,wrapcheck,wsl_v5 // Storage errors carry target context for the CLI.
+package backup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	appconfig "github.com/steipete/gogcli/internal/config"
+)
+
+const readmeFile = "README.md"
+
+// objectStore is the small key/value surface shared by the directory and
+// S3-compatible targets. Keys are slash-separated paths relative to the
+// backup root, matching ShardEntry.Path.
+type objectStore interface {
+	describe() string
+	get(ctx context.Context, key string) (io.ReadCloser, error)
+	put(ctx context.Context, key, file string) error
+	delete(ctx context.Context, key string) error
+	list(ctx context.Context, prefix string) ([]string, error)
+}
+
+// objectStorage mirrors the local working directory to an objectStore. Only
+// the latest snapshot is kept; shards whose manifest entry is unchanged on the
+// target are not uploaded again.
+type objectStorage struct {
+	store objectStore
+}
+
+func (s objectStorage) Kind() string {
+	switch s.store.(type) {
+	case dirStore:
+		return StorageDir
+	default:
+		return StorageS3
+	}
+}
+
+func (s objectStorage) Prepare(ctx context.Context, cfg Config) error {
+	return s.Fetch(ctx, cfg)
+}
+
+func (s objectStorage) Fetch(ctx context.Context, cfg Config) error {
+	if err := ensureWorkDir(cfg); err != nil {
+		return err
+	}
+	raw, err := s.readObject(ctx, manifestFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return fmt.Errorf("decode manifest from %s: %w", s.store.describe(), err)
+	}
+	for _, shard := range manifest.Shards {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		full, err := resolveShardPath(cfg.Repo, shard.Path)
+		if err != nil {
+			return err
+		}
+		if err := rejectSymlinkPath(cfg.Repo, full); err != nil {
+			return err
+		}
+		if cachedShardMatches(full, shard) {
+			continue
+		}
+		if err := s.download(ctx, shard.Path, full); err != nil {
+			return err
+		}
+		if shard.CipherSHA256 != "" {
+			if got, _, err := fileSHA256(full); err != nil || got != shard.CipherSHA256 {
+				_ = os.Remove(full)
+				return fmt.Errorf("backup shard hash mismatch for %s from %s", shard.Path, s.store.describe())
+			}
+		}
+	}
+	readme := filepath.Join(cfg.Repo, readmeFile)
+	if err := rejectSymlinkPath(cfg.Repo, readme); err != nil {
+		return err
+	}
+	if err := s.download(ctx, readmeFile, readme); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	manifestPath := filepath.Join(cfg.Repo, manifestFile)
+	if err := rejectSymlinkPath(cfg.Repo, manifestPath); err != nil {
+		return err
+	}
+	if err := appconfig.WriteFileAtomic(manifestPath, raw, 0o600); err != nil {
+		return err
+	}
+	return removeStaleShards(cfg.Repo, manifest.Shards)
+}
+
+// cachedShardMatches reports whether the local shard can be reused instead of
+// downloaded. Without a cipher hash in the manifest only a download is safe.
+func cachedShardMatches(full string, shard ShardEntry) bool {
+	if shard.CipherSHA256 == "" {
+		return false
+	}
+	if info, err := os.Stat(full); err != nil || info.Size() != shard.Bytes {
+		return false
+	}
+	got, _, err := fileSHA256(full)
+	return err == nil && got == shard.CipherSHA256
+}
+
+func (s objectStorage) Publish(ctx context.Context, cfg Config, _ string, push bool) (bool, error) {
+	if err := removeTempShardFiles(cfg.Repo); err != nil {
+		return false, err
+	}
+	localRaw, err := os.ReadFile(filepath.Join(cfg.Repo, manifestFile)) // #nosec G304 -- repo is the configured local backup repository.
+	if errors.Is(err, os.ErrNotExist) {
+		if !push {
+			return false, nil
+		}
+		return s.publishReadme(ctx, cfg)
+	}
+	if err != nil {
+		return false, err
+	}
+	var local Manifest
+	if err := json.Unmarshal(localRaw, &local); err != nil {
+		return false, err
+	}
+	remoteRaw, err := s.readObject(ctx, manifestFile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, err
+	}
+	if bytes.Equal(localRaw, remoteRaw) {
+		return false, nil
+	}
+	if !push {
+		return true, nil
+	}
+	var remote Manifest
+	if len(remoteRaw) > 0 {
+		if err := json.Unmarshal(remoteRaw, &remote); err != nil {
+			return false, fmt.Errorf("decode manifest from %s: %w", s.store.describe(), err)
+		}
+	}
+	sameRecipients := sameStrings(remote.Recipients, local.Recipients)
+	for _, shard := range local.Shards {
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		default:
+		}
+		if existing, ok := remote.entry(shard.Path); ok && sameRecipients && existing.SHA256 == shard.SHA256 &&
+			existing.CipherSHA256 == shard.CipherSHA256 && existing.Bytes == shard.Bytes {
+			continue
+		}
+		full, err := resolveShardPath(cfg.Repo, shard.Path)
+		if err != nil {
+			return false, err
+		}
+		if err := s.store.put(ctx, shard.Path, full); err != nil {
+			return false, fmt.Errorf("upload %s to %s: %w", shard.Path, s.store.describe(), err)
+		}
+	}
+	if _, err := s.publishReadme(ctx, cfg); err != nil {
+		return false, err
+	}
+	if err := s.store.put(ctx, manifestFile, filepath.Join(cfg.Repo, manifestFile)); err != nil {
+		return false, fmt.Errorf("upload manifest to %s: %w", s.store.describe(), err)
+	}
+	return true, s.removeStaleObjects(ctx, local)
+}
+
+func (s objectStorage) publishReadme(ctx context.Context, cfg Config) (bool, error) {
+	readme := filepath.Join(cfg.Repo, readmeFile)
+	if _, err := os.Stat(readme); err != nil {
+		return false, nil
+	}
+	body, err := s.store.get(ctx, readmeFile)
+	if err == nil {
+		_ = body.Close()
+		return false, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return false, err
+	}
+	if err := s.store.put(ctx, readmeFile, readme); err != nil {
+		return false, fmt.Errorf("upload %s to %s: %w", readmeFile, s.store.describe(), err)
+	}
+	return true, nil
+}
+
+// removeStaleObjects runs after the new manifest is uploaded so readers never
+// see a manifest that points at deleted shards.
+func (s objectStorage) removeStaleObjects(ctx context.Context, manifest Manifest) error {
+	stale, err := s.staleObjects(ctx, manifest)
+	if err != nil {
+		return err
+	}
+	for _, key := range stale {
+		if err := s.store.delete(ctx, key); err != nil {
+			return fmt.Errorf("delete stale %s from %s: %w", key, s.store.describe(), err)
+		}
+	}
+	return nil
+}
+
+// staleObjects lists shard objects on the target that the manifest no longer
+// references, such as leftovers from an interrupted publish.
+func (s objectStorage) staleObjects(ctx context.Context, manifest Manifest) ([]string, error) {
+	keep := map[string]struct{}{}
+	for _, shard := range manifest.Shards {
+		keep[shard.Path] = struct{}{}
+	}
+	keys, err := s.store.list(ctx, "data/")
+	if err != nil {
+		return nil, err
+	}
+	stale := []string{}
+	for _, key := range keys {
+		if _, ok := keep[key]; ok || !strings.HasSuffix(key, ".age") {
+			continue
+		}
+		stale = append(stale, key)
+	}
+	return stale, nil
+}
+
+func (s objectStorage) readObject(ctx context.Context, key string) ([]byte, error) {
+	body, err := s.store.get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = body.Close() }()
+	return io.ReadAll(body)
+}
+
+func (s objectStorage) download(ctx context.Context, key, full string) error {
+	body, err := s.store.get(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = body.Close() }()
+	return writeFileFromReader(full, body)
+}
+
+func writeFileFromReader(full string, body io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(full), 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(full), ".download-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer func() { _ = os.Remove(tmpName) }()
+	if _, err := io.Copy(tmp, body); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpName, full)
+}
+
+func cleanObjectKey(key string) (string, error) {
+	clean := path.Clean(strings.TrimSpace(key))
+	if clean == "." || clean == ".." || strings.HasPrefix(clean, "../") || path.IsAbs(clean) {
+		return "", fmt.Errorf("backup object key escapes backup root: %s", key)
+	}
+	return clean, nil
+}
+
+// dirStore writes the backup layout into a plain directory, for example a
+// mounted network share or removable disk.
+type dirStore struct {
+	root string
+}
+
+func (d dirStore) describe() string { return d.root }
+
+func (d dirStore) path(key string) (string, error) {
+	clean, err := cleanObjectKey(key)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(d.root, filepath.FromSlash(clean)), nil
+}
+
+func (d dirStore) get(_ context.Context, key string) (io.ReadCloser, error) {
+	full, err := d.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(full) // #nosec G304 -- key is confined below the configured backup target directory.
+}
+
+func (d dirStore) put(_ context.Context, key, file string) error {
+	full, err := d.path(key)
+	if err != nil {
+		return err
+	}
+	src, err := os.Open(file) // #nosec G304 -- file is a shard or manifest inside the local backup repository.
+	if err != nil {
+		return err
+	}
+	defer func() { _ = src.Close() }()
+	return writeFileFromReader(full, src)
+}
+
+func (d dirStore) delete(_ context.Context, key string) error {
+	full, err := d.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(full); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (d dirStore) list(_ context.Context, prefix string) ([]string, error) {
+	root, err := d.path(prefix)
+	if err != nil {
+		return nil, err
+	}
+	keys := []string{}
+	err = filepath.WalkDir(root, func(full string, entry os.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			if errors.Is(walkErr, os.ErrNotExist) {
+				return filepath.SkipDir
+			}
+			return walkErr
+		}
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".download-") {
+			return nil
+		}
+		rel, err := filepath.Rel(d.root, full)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, filepath.ToSlash(rel))
+		return nil
+	})
+	return keys, err
+}
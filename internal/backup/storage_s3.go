@@ -0,0 +1,368 @@
+//nolint:err113,wrapcheck,wsl_v5 // Storage errors carry target context for the CLI.
+package backup
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	s3DefaultRegion   = "us-east-1"
+	s3EmptyPayloadSHA = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// s3Client bounds each request; the limit is generous because one request
+// may carry a whole shard.
+var s3Client = &http.Client{Timeout: 10 * time.Minute}
+
+// s3Store talks to S3 and S3-compatible object stores (MinIO, R2, B2, Ceph)
+// with SigV4-signed requests. Credentials come from the standard AWS
+// environment variables so they never land in the backup config.
+type s3Store struct {
+	bucket    string
+	prefix    string
+	endpoint  *url.URL
+	pathStyle bool
+	region    string
+	accessKey string
+	secretKey string
+	session   string
+	client    *http.Client
+	now       func() time.Time
+}
+
+type s3ListResult struct {
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// newS3Store parses s3://bucket/prefix?endpoint=https://host:9000&region=r.
+// Without an endpoint it targets AWS with virtual-hosted bucket addressing;
+// custom endpoints use path-style addressing, which every S3-compatible
+// server accepts.
+func newS3Store(target string) (s3Store, error) {
+	parsed, err := url.Parse(target)
+	if err != nil {
+		return s3Store{}, fmt.Errorf("invalid backup target %q: %w", target, err)
+	}
+	if parsed.Host == "" {
+		return s3Store{}, fmt.Errorf("backup target %q is missing a bucket", target)
+	}
+	query := parsed.Query()
+	store := s3Store{
+		bucket:    parsed.Host,
+		prefix:    strings.Trim(parsed.Path, "/"),
+		region:    firstNonEmpty(query.Get("region"), os.Getenv("AWS_REGION"), os.Getenv("AWS_DEFAULT_REGION"), s3DefaultRegion),
+		accessKey: strings.TrimSpace(os.Getenv("AWS_ACCESS_KEY_ID")),
+		secretKey: strings.TrimSpace(os.Getenv("AWS_SECRET_ACCESS_KEY")),
+		session:   strings.TrimSpace(os.Getenv("AWS_SESSION_TOKEN")),
+		client:    s3Client,
+		now:       time.Now,
+	}
+	if store.accessKey == "" || store.secretKey == "" {
+		return s3Store{}, fmt.Errorf("backup target %q requires AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY", target)
+	}
+	endpoint := firstNonEmpty(query.Get("endpoint"), os.Getenv("AWS_ENDPOINT_URL_S3"), os.Getenv("AWS_ENDPOINT_URL"))
+	if endpoint == "" {
+		store.endpoint = &url.URL{Scheme: "https", Host: fmt.Sprintf("%s.s3.%s.amazonaws.com", store.bucket, store.region)}
+		return store, nil
+	}
+	store.endpoint, err = url.Parse(endpoint)
+	if err != nil || store.endpoint.Host == "" || (store.endpoint.Scheme != "https" && store.endpoint.Scheme != "http") {
+		return s3Store{}, fmt.Errorf("invalid S3 endpoint %q", endpoint)
+	}
+	store.pathStyle = true
+	return store, nil
+}
+
+func (s s3Store) describe() string {
+	return "s3://" + strings.TrimSuffix(s.bucket+"/"+s.prefix, "/")
+}
+
+func (s s3Store) objectKey(key string) (string, error) {
+	clean, err := cleanObjectKey(key)
+	if err != nil {
+		return "", err
+	}
+	if s.prefix == "" {
+		return clean, nil
+	}
+	return s.prefix + "/" + clean, nil
+}
+
+func (s s3Store) objectURL(key string, query url.Values) *url.URL {
+	out := *s.endpoint
+	objectPath := "/" + key
+	if s.pathStyle {
+		objectPath = "/" + s.bucket + objectPath
+		if key == "" {
+			objectPath = "/" + s.bucket
+		}
+	}
+	out.Path = strings.TrimSuffix(s.endpoint.Path, "/") + objectPath
+	out.RawPath = ""
+	out.RawQuery = query.Encode()
+	return &out
+}
+
+func (s s3Store) get(ctx context.Context, key string) (io.ReadCloser, error) {
+	objectKey, err := s.objectKey(key)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.objectURL(objectKey, nil).String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(req, s3EmptyPayloadSHA)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		_ = resp.Body.Close()
+		return nil, os.ErrNotExist
+	}
+	if err := s3ResponseError(resp); err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (s s3Store) put(ctx context.Context, key, file string) error {
+	objectKey, err := s.objectKey(key)
+	if err != nil {
+		return err
+	}
+	payloadHash, size, err := fileSHA256(file)
+	if err != nil {
+		return err
+	}
+	body, err := os.Open(file) // #nosec G304 -- file is a shard or manifest inside the local backup repository.
+	if err != nil {
+		return err
+	}
+	defer func() { _ = body.Close() }()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(objectKey, nil).String(), body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/octet-stream")
+	resp, err := s.do(req, payloadHash)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	return s3ResponseError(resp)
+}
+
+func (s s3Store) delete(ctx context.Context, key string) error {
+	objectKey, err := s.objectKey(key)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.objectURL(objectKey, nil).String(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(req, s3EmptyPayloadSHA)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return s3ResponseError(resp)
+}
+
+func (s s3Store) list(ctx context.Context, prefix string) ([]string, error) {
+	fullPrefix := strings.TrimPrefix(s.prefix+"/"+prefix, "/")
+	keys := []string{}
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {fullPrefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.objectURL("", query).String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := s.do(req, s3EmptyPayloadSHA)
+		if err != nil {
+			return nil, err
+		}
+		if err := s3ResponseError(resp); err != nil {
+			return nil, err
+		}
+		var result s3ListResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("decode S3 list response: %w", err)
+		}
+		for _, object := range result.Contents {
+			key := object.Key
+			if s.prefix != "" {
+				key = strings.TrimPrefix(key, s.prefix+"/")
+			}
+			keys = append(keys, key)
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return keys, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+func (s s3Store) do(req *http.Request, payloadHash string) (*http.Response, error) {
+	signS3Request(req, payloadHash, s.accessKey, s.secretKey, s.session, s.region, s.now().UTC())
+	return s.client.Do(req)
+}
+
+func s3ResponseError(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	defer func() { _ = resp.Body.Close() }()
+	var body struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if xml.Unmarshal(data, &body) == nil && body.Code != "" {
+		return fmt.Errorf("s3 %s %s: %s: %s", resp.Request.Method, resp.Request.URL.Path, body.Code, body.Message)
+	}
+	return fmt.Errorf("s3 %s %s: %s", resp.Request.Method, resp.Request.URL.Path, resp.Status)
+}
+
+// signS3Request adds AWS Signature Version 4 headers. It signs host, range,
+// and every x-amz-* header, which is what S3 requires for object requests.
+func signS3Request(req *http.Request, payloadHash, accessKey, secretKey, session, region string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if session != "" {
+		req.Header.Set("X-Amz-Security-Token", session)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "range" || strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		s3EscapePath(req.URL.Path),
+		s3CanonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := day + "/" + region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+	key := hmacSHA256([]byte("AWS4"+secretKey), day)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", accessKey, scope, signedHeaders, signature))
+}
+
+func s3EscapePath(value string) string {
+	if value == "" {
+		return "/"
+	}
+	parts := strings.Split(value, "/")
+	for i, part := range parts {
+		parts[i] = s3Escape(part)
+	}
+	return strings.Join(parts, "/")
+}
+
+func s3CanonicalQuery(values url.Values) string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	parts := []string{}
+	for _, key := range keys {
+		vals := append([]string(nil), values[key]...)
+		sort.Strings(vals)
+		for _, value := range vals {
+			parts = append(parts, s3Escape(key)+"="+s3Escape(value))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+func s3Escape(value string) string {
+	var b strings.Builder
+	for _, c := range []byte(value) {
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, value string) []byte {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
+
+func fileSHA256(file string) (string, int64, error) {
+	f, err := os.Open(file) // #nosec G304 -- file is a shard or manifest inside the local backup repository.
+	if err != nil {
+		return "", 0, err
+	}
+	defer func() { _ = f.Close() }()
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+	return ""
+}
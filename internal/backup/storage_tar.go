@@ -0,0 +1,216 @@
+//nolint:err113,wrapcheck,wsl_v5 // Storage errors carry target context for the CLI.
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	appconfig "github.com/steipete/gogcli/internal/config"
+)
+
+// tarStorage keeps the latest snapshot in a single uncompressed tarball.
+// Shards are already gzip-compressed and age-encrypted, so the archive is
+// rewritten on publish but never recompressed.
+type tarStorage struct {
+	path string
+}
+
+func (tarStorage) Kind() string { return StorageTar }
+
+func (t tarStorage) Prepare(ctx context.Context, cfg Config) error {
+	return t.Fetch(ctx, cfg)
+}
+
+func (t tarStorage) Fetch(ctx context.Context, cfg Config) error {
+	if err := ensureWorkDir(cfg); err != nil {
+		return err
+	}
+	f, err := os.Open(t.path) // #nosec G304 -- path is the configured backup tarball target.
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	var manifestRaw []byte
+	reader := tar.NewReader(f)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("read backup tarball %s: %w", t.path, err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		switch header.Name {
+		case manifestFile:
+			if manifestRaw, err = io.ReadAll(reader); err != nil {
+				return err
+			}
+			continue
+		case readmeFile:
+			full := filepath.Join(cfg.Repo, readmeFile)
+			if err := rejectSymlinkPath(cfg.Repo, full); err != nil {
+				return err
+			}
+			if err := writeFileFromReader(full, reader); err != nil {
+				return err
+			}
+			continue
+		}
+		full, err := resolveShardPath(cfg.Repo, header.Name)
+		if err != nil {
+			return fmt.Errorf("backup tarball %s: %w", t.path, err)
+		}
+		if err := rejectSymlinkPath(cfg.Repo, full); err != nil {
+			return err
+		}
+		if info, statErr := os.Stat(full); statErr == nil && info.Size() == header.Size {
+			continue
+		}
+		if err := writeFileFromReader(full, reader); err != nil {
+			return err
+		}
+	}
+	if manifestRaw == nil {
+		return fmt.Errorf("backup tarball %s has no %s", t.path, manifestFile)
+	}
+	manifestPath := filepath.Join(cfg.Repo, manifestFile)
+	if err := rejectSymlinkPath(cfg.Repo, manifestPath); err != nil {
+		return err
+	}
+	if err := appconfig.WriteFileAtomic(manifestPath, manifestRaw, 0o600); err != nil {
+		return err
+	}
+	manifest, err := readManifest(cfg.Repo)
+	if err != nil {
+		return err
+	}
+	return removeStaleShards(cfg.Repo, manifest.Shards)
+}
+
+func (t tarStorage) Publish(ctx context.Context, cfg Config, _ string, push bool) (bool, error) {
+	if err := removeTempShardFiles(cfg.Repo); err != nil {
+		return false, err
+	}
+	localRaw, err := os.ReadFile(filepath.Join(cfg.Repo, manifestFile)) // #nosec G304 -- repo is the configured local backup repository.
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	remoteRaw, err := t.readManifest()
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, err
+	}
+	if bytes.Equal(localRaw, remoteRaw) {
+		return false, nil
+	}
+	if !push {
+		return true, nil
+	}
+	manifest, err := readManifest(cfg.Repo)
+	if err != nil {
+		return false, err
+	}
+	if err := os.MkdirAll(filepath.Dir(t.path), 0o700); err != nil {
+		return false, err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(t.path), "."+filepath.Base(t.path)+".*")
+	if err != nil {
+		return false, err
+	}
+	tmpName := tmp.Name()
+	defer func() { _ = os.Remove(tmpName) }()
+	writer := tar.NewWriter(tmp)
+	files := []string{readmeFile}
+	for _, shard := range manifest.Shards {
+		files = append(files, shard.Path)
+	}
+	files = append(files, manifestFile)
+	for _, name := range files {
+		if err := addTarFile(writer, cfg.Repo, name); err != nil {
+			_ = tmp.Close()
+			return false, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		_ = tmp.Close()
+		return false, err
+	}
+	if err := tmp.Close(); err != nil {
+		return false, err
+	}
+	return true, os.Rename(tmpName, t.path)
+}
+
+func (t tarStorage) readManifest() ([]byte, error) {
+	f, err := os.Open(t.path) // #nosec G304 -- path is the configured backup tarball target.
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	reader := tar.NewReader(f)
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, os.ErrNotExist
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Name == manifestFile {
+			return io.ReadAll(reader)
+		}
+	}
+}
+
+func addTarFile(writer *tar.Writer, repo, name string) error {
+	full := filepath.Join(repo, filepath.FromSlash(name))
+	if name != readmeFile && name != manifestFile {
+		var err error
+		if full, err = resolveShardPath(repo, name); err != nil {
+			return err
+		}
+	}
+	f, err := os.Open(full) // #nosec G304 -- paths are manifest shards or fixed files inside the backup repository.
+	if errors.Is(err, os.ErrNotExist) && name == readmeFile {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if err := writer.WriteHeader(&tar.Header{
+		Name:     name,
+		Mode:     0o600,
+		Size:     info.Size(),
+		ModTime:  info.ModTime().UTC().Truncate(time.Second),
+		Typeflag: tar.TypeReg,
+	}); err != nil {
+		return err
+	}
+	_, err = io.Copy(writer, f)
+	return err
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/steipete/gogcli/internal/config"
+	"github.com/steipete/gogcli/internal/googleapi"
+)
+
+// commandAuditLog returns the audit log configured by GOG_AUDIT_LOG or the
+// audit_log config key, or nil when auditing is off. It runs when a Google API
+// client is built, not for every command. An unreadable config is fatal only
+// when GOG_AUDIT_LOG asks for auditing; otherwise it is reported and auditing
+// stays off, so 'gog config' can still repair the file.
+func commandAuditLog(ctx context.Context) (*googleapi.AuditLog, error) {
+	var cfg config.File
+	if store, storeErr := commandConfigStore(ctx); storeErr == nil {
+		var err error
+		if cfg, err = store.Read(); err != nil {
+			if strings.TrimSpace(os.Getenv("GOG_AUDIT_LOG")) != "" {
+				return nil, fmt.Errorf("read config for audit_log: %w", err)
+			}
+			slog.Warn("audit log disabled: config unreadable", "err", err)
+			cfg = config.File{}
+		}
+	}
+	path := strings.TrimSpace(config.GetValue(cfg, config.KeyAuditLog))
+	if path == "" {
+		return nil, nil
+	}
+	expanded, err := config.ExpandPath(path)
+	if err != nil {
+		return nil, fmt.Errorf("expand audit_log: %w", err)
+	}
+	hashBodies := config.GetValue(cfg, config.KeyAuditHashBodies) == boolTrue
+	return googleapi.NewAuditLog(expanded, hashBodies), nil
+}
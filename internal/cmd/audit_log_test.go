@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCommandAuditLogFromEnv(t *testing.T) {
+	t.Setenv("GOG_AUDIT_LOG", "")
+	log, err := commandAuditLog(t.Context())
+	if err != nil || log != nil {
+		t.Fatalf("audit log without config = %v, %v", log, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	t.Setenv("GOG_AUDIT_LOG", path)
+	t.Setenv("GOG_AUDIT_HASH_BODIES", "yes")
+	log, err = commandAuditLog(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if log == nil || log.Path() != path || !log.HashBodies() {
+		t.Fatalf("audit log = %#v", log)
+	}
+}
+
+func TestCommandAuditLogUnreadableConfigFatalOnlyWhenRequested(t *testing.T) {
+	setWatchTestConfigHome(t)
+	t.Setenv("GOG_AUDIT_LOG", "")
+	path := defaultConfigStoreForTest(t).Path()
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("{audit_log:"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ctx := newCmdRuntimeOutputContext(t, io.Discard, io.Discard)
+	if log, err := commandAuditLog(ctx); err != nil || log != nil {
+		t.Fatalf("without GOG_AUDIT_LOG = %v, %v", log, err)
+	}
+	t.Setenv("GOG_AUDIT_LOG", filepath.Join(t.TempDir(), "audit.jsonl"))
+	if log, err := commandAuditLog(ctx); err == nil || log != nil {
+		t.Fatalf("expected config error, got %v, %v", log, err)
+	}
+}
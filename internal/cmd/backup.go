@@ -17,13 +17,17 @@ import (
 )
 
 type BackupCmd struct {
-	Init   BackupInitCmd   `cmd:"" name:"init" help:"Initialize encrypted backup config and repository"`
-	Push   BackupPushCmd   `cmd:"" name:"push" help:"Export services into encrypted backup shards"`
-	Status BackupStatusCmd `cmd:"" name:"status" help:"Inspect backup manifest without decrypting shards"`
-	Verify BackupVerifyCmd `cmd:"" name:"verify" help:"Decrypt and verify all backup shards"`
-	Cat    BackupCatCmd    `cmd:"" name:"cat" help:"Decrypt one backup shard to stdout"`
-	Export BackupExportCmd `cmd:"" name:"export" help:"Write a local plaintext export"`
-	Gmail  BackupGmailCmd  `cmd:"" name:"gmail" help:"Gmail backup operations"`
+	Init    BackupInitCmd    `cmd:"" name:"init" help:"Initialize encrypted backup config and repository"`
+	Push    BackupPushCmd    `cmd:"" name:"push" help:"Export services into encrypted backup shards"`
+	Status  BackupStatusCmd  `cmd:"" name:"status" help:"Inspect backup manifest without decrypting shards"`
+	Verify  BackupVerifyCmd  `cmd:"" name:"verify" help:"Decrypt and verify all backup shards"`
+	Cat     BackupCatCmd     `cmd:"" name:"cat" help:"Decrypt one backup shard to stdout"`
+	Export  BackupExportCmd  `cmd:"" name:"export" help:"Write a local plaintext export"`
+	Restore BackupRestoreCmd `cmd:"" name:"restore" help:"Restore contacts, tasks, calendar, and Gmail from a backup into an account"`
+	Log     BackupLogCmd     `cmd:"" name:"log" help:"List backup snapshots from Git history"`
+	Diff    BackupDiffCmd    `cmd:"" name:"diff" help:"Compare decrypted rows between two backup snapshots"`
+	Prune   BackupPruneCmd   `cmd:"" name:"prune" help:"Apply snapshot retention and delete unreferenced shard objects"`
+	Gmail   BackupGmailCmd   `cmd:"" name:"gmail" help:"Gmail backup operations"`
 }
 
 type BackupGmailCmd struct {
@@ -50,6 +54,7 @@ type backupFlags struct {
 	Config     string   `name:"config" help:"Backup config path" default:""`
 	Repo       string   `name:"repo" help:"Local backup repository path"`
 	Remote     string   `name:"remote" help:"Backup Git remote URL"`
+	Target     string   `name:"target" help:"Storage target: git (default), an absolute directory or .tar path, or s3://bucket/prefix?endpoint=URL&region=R"`
 	Identity   string   `name:"identity" help:"Local age identity path"`
 	Recipients []string `name:"recipient" help:"Public age recipient (repeatable)"`
 	NoPush     bool     `name:"no-push" help:"Commit locally but do not push to the remote"`
@@ -60,6 +65,7 @@ func (f backupFlags) options() backup.Options {
 		ConfigPath: f.Config,
 		Repo:       f.Repo,
 		Remote:     f.Remote,
+		Target:     f.Target,
 		Identity:   f.Identity,
 		Recipients: f.Recipients,
 		Push:       !f.NoPush,
@@ -70,6 +76,7 @@ type backupReadFlags struct {
 	Config   string `name:"config" help:"Backup config path" default:""`
 	Repo     string `name:"repo" help:"Local backup repository path"`
 	Remote   string `name:"remote" help:"Backup Git remote URL"`
+	Target   string `name:"target" help:"Storage target: git (default), an absolute directory or .tar path, or s3://bucket/prefix?endpoint=URL&region=R"`
 	Identity string `name:"identity" help:"Local age identity path"`
 	NoPull   bool   `name:"no-pull" help:"Use local backup repository state without pulling first"`
 }
@@ -84,6 +91,7 @@ func (f backupReadFlags) options() backup.Options {
 		ConfigPath: f.Config,
 		Repo:       f.Repo,
 		Remote:     f.Remote,
+		Target:     f.Target,
 		Identity:   f.Identity,
 		Push:       false,
 		SkipPull:   f.NoPull,
@@ -134,6 +142,7 @@ func (c *BackupInitCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return dryRunExit(ctx, flags, "backup.init", map[string]any{
 			"repo":       cfg.Repo,
 			"remote":     cfg.Remote,
+			"target":     cfg.Target,
 			"identity":   cfg.Identity,
 			"recipients": cfg.Recipients,
 			"push":       opts.Push,
@@ -148,6 +157,7 @@ func (c *BackupInitCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return outfmt.WriteJSON(ctx, stdoutWriter(ctx), map[string]any{
 			"repo":      cfg.Repo,
 			"remote":    cfg.Remote,
+			"target":    cfg.Target,
 			"identity":  cfg.Identity,
 			"recipient": recipient,
 		})
@@ -155,6 +165,9 @@ func (c *BackupInitCmd) Run(ctx context.Context, flags *RootFlags) error {
 	u := ui.FromContext(ctx)
 	u.Out().Linef("repo\t%s", cfg.Repo)
 	u.Out().Linef("remote\t%s", cfg.Remote)
+	if cfg.Target != "" {
+		u.Out().Linef("target\t%s", cfg.Target)
+	}
 	u.Out().Linef("identity\t%s", cfg.Identity)
 	u.Out().Linef("recipient\t%s", recipient)
 	return nil
@@ -395,6 +408,12 @@ func mergeBackupSnapshots(snapshots ...backup.Snapshot) backup.Snapshot {
 		for key, value := range snapshot.Counts {
 			out.Counts[key] += value
 		}
+		for key, value := range snapshot.Cursors {
+			if out.Cursors == nil {
+				out.Cursors = map[string]string{}
+			}
+			out.Cursors[key] = value
+		}
 	}
 	return out
 }
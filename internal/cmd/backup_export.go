@@ -14,6 +14,7 @@ import (
 
 	"github.com/steipete/gogcli/internal/backup"
 	gmailbackup "github.com/steipete/gogcli/internal/backup/gmail"
+	"github.com/steipete/gogcli/internal/mailbox"
 	"github.com/steipete/gogcli/internal/outfmt"
 	"github.com/steipete/gogcli/internal/ui"
 )
@@ -82,7 +83,7 @@ func (c *BackupCatCmd) Run(ctx context.Context, flags *RootFlags) error {
 type BackupExportCmd struct {
 	backupReadFlags
 	Out              string `name:"out" help:"Plaintext export directory" default:"~/Documents/gog-backup-export"`
-	GmailFormat      string `name:"gmail-format" help:"Gmail message export format: eml, markdown, or both" default:"eml" enum:"eml,markdown,both"`
+	GmailFormat      string `name:"gmail-format" help:"Gmail message export format: eml, markdown, both, mbox, or maildir" default:"eml" enum:"eml,markdown,both,mbox,maildir"`
 	GmailAttachments string `name:"gmail-attachments" help:"Gmail attachment export mode for markdown/both: extract or none" default:"extract" enum:"extract,none"`
 }
 
@@ -97,6 +98,10 @@ type backupExportResult struct {
 type backupExportOptions struct {
 	GmailFormat      string
 	GmailAttachments string
+
+	// gmailLabelNames collects label ID to name maps per account from the
+	// labels shards, which sort before message shards, for mbox/maildir.
+	gmailLabelNames map[string]map[string]string
 }
 
 func (c *BackupExportCmd) Run(ctx context.Context, flags *RootFlags) error {
@@ -112,6 +117,7 @@ func (c *BackupExportCmd) Run(ctx context.Context, flags *RootFlags) error {
 	exportOpts := backupExportOptions{
 		GmailFormat:      c.GmailFormat,
 		GmailAttachments: c.GmailAttachments,
+		gmailLabelNames:  map[string]map[string]string{},
 	}
 	if flags != nil && flags.DryRun {
 		cfg, resolveErr := backup.ResolveOptions(backupOpts)
@@ -309,12 +315,24 @@ func resetExportTargets(outDir string, shards []backup.ShardEntry) error {
 		if target == "" {
 			continue
 		}
-		if _, ok := seen[target]; ok {
-			continue
-		}
-		seen[target] = struct{}{}
-		if err := os.RemoveAll(target); err != nil && !os.IsNotExist(err) {
-			return err
+		targets := []string{target}
+		if shard.Service == backupServiceGmail {
+			// mbox and Maildir exports append across message shards.
+			accountDir := filepath.Join(outDir, backupServiceGmail, sanitizeFilePart(shard.Account))
+			targets = append(targets,
+				filepath.Join(accountDir, gmailExportMboxName),
+				filepath.Join(accountDir, gmailExportMboxName+mailbox.StateSuffix),
+				filepath.Join(accountDir, gmailExportMaildirName),
+			)
+		}
+		for _, target := range targets {
+			if _, ok := seen[target]; ok {
+				continue
+			}
+			seen[target] = struct{}{}
+			if err := os.RemoveAll(target); err != nil && !os.IsNotExist(err) {
+				return err
+			}
 		}
 	}
 	return nil
@@ -328,7 +346,10 @@ func writeBackupExportReadme(outDir string) error {
 		"\n" +
 		"Gmail messages are written according to `--gmail-format`: `.eml` by default,\n" +
 		"Markdown notes with extracted attachment files when `--gmail-format markdown`,\n" +
-		"or both when `--gmail-format both`. `gmail/<account>/messages/index.jsonl`\n" +
+		"or both when `--gmail-format both`. `--gmail-format mbox` writes one\n" +
+		"`gmail/<account>/messages.mbox` and `--gmail-format maildir` a\n" +
+		"`gmail/<account>/Maildir` tree with one folder per label; both carry\n" +
+		"an `X-Gmail-Labels` header. `gmail/<account>/messages/index.jsonl`\n" +
 		"maps backup message IDs to exported files. Labels are written as pretty JSON.\n"
 	return os.WriteFile(filepath.Join(outDir, "README.md"), []byte(body), 0o600)
 }
@@ -350,7 +371,7 @@ func exportPlainShard(outDir string, shard backup.PlainShard, opts backupExportO
 	case shard.Service == backupServiceDrive && shard.Kind == "contents":
 		return exportDriveContents(outDir, shard)
 	case shard.Service == backupServiceGmail && shard.Kind == "labels":
-		return exportGmailLabels(outDir, shard)
+		return exportGmailLabels(outDir, shard, opts)
 	case shard.Service == backupServiceGmail && shard.Kind == gmailbackup.MessageShardKind:
 		return exportGmailMessages(outDir, shard, opts)
 	default:
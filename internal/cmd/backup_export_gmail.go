@@ -17,6 +17,12 @@ import (
 
 	"github.com/steipete/gogcli/internal/backup"
 	"github.com/steipete/gogcli/internal/gmailcontent"
+	"github.com/steipete/gogcli/internal/mailbox"
+)
+
+const (
+	gmailExportMboxName    = "messages.mbox"
+	gmailExportMaildirName = "Maildir"
 )
 
 type gmailExportIndexEntry struct {
@@ -33,6 +39,7 @@ type gmailExportIndexEntry struct {
 	Date         string   `json:"date,omitempty"`
 	EML          string   `json:"eml,omitempty"`
 	Markdown     string   `json:"markdown,omitempty"`
+	Mailbox      string   `json:"mailbox,omitempty"`
 	Attachments  []string `json:"attachments,omitempty"`
 }
 
@@ -53,11 +60,18 @@ type backupEmailAttachment struct {
 	Data     []byte
 }
 
-func exportGmailLabels(outDir string, shard backup.PlainShard) (int, int, error) {
+func exportGmailLabels(outDir string, shard backup.PlainShard, opts backupExportOptions) (int, int, error) {
 	var labels []gmailBackupLabel
 	if err := backup.DecodeJSONL(shard.Plaintext, &labels); err != nil {
 		return 0, 0, err
 	}
+	if opts.gmailLabelNames != nil {
+		names := make(map[string]string, len(labels))
+		for _, label := range labels {
+			names[label.ID] = label.Name
+		}
+		opts.gmailLabelNames[shard.Account] = names
+	}
 	path := filepath.Join(outDir, backupServiceGmail, sanitizeFilePart(shard.Account), "labels.json")
 	if err := writeJSONFile(path, labels); err != nil {
 		return 0, 0, err
@@ -90,6 +104,21 @@ func exportGmailMessages(outDir string, shard backup.PlainShard, opts backupExpo
 	defer indexFile.Close()
 	enc := json.NewEncoder(indexFile)
 	enc.SetEscapeHTML(false)
+	var box mailbox.Writer
+	boxRel := ""
+	if gmailFormat == mailbox.FormatMbox || gmailFormat == mailbox.FormatMaildir {
+		boxRel = filepath.ToSlash(filepath.Join(backupServiceGmail, account, gmailExportMboxName))
+		if gmailFormat == mailbox.FormatMaildir {
+			boxRel = filepath.ToSlash(filepath.Join(backupServiceGmail, account, gmailExportMaildirName))
+		}
+		box, err = mailbox.Open(gmailFormat, filepath.Join(outDir, filepath.FromSlash(boxRel)), mailbox.Options{
+			LabelNames: opts.gmailLabelNames[shard.Account],
+		})
+		if err != nil {
+			return 0, 0, err
+		}
+		defer box.Close()
+	}
 	files := 0
 	for _, message := range messages {
 		rawMIME, err := decodeGmailRaw(message.Raw)
@@ -134,6 +163,14 @@ func exportGmailMessages(outDir string, shard backup.PlainShard, opts backupExpo
 			entry.Markdown = rel
 			entry.Attachments = attachmentRels
 		}
+		if box != nil {
+			if !box.Has(message.ID) {
+				if err := box.Write(gmailMailboxMessage(message, rawMIME)); err != nil {
+					return files, 0, fmt.Errorf("write %s message %s: %w", gmailFormat, message.ID, err)
+				}
+			}
+			entry.Mailbox = boxRel
+		}
 		if err := enc.Encode(entry); err != nil {
 			return files, 0, err
 		}
@@ -141,6 +178,19 @@ func exportGmailMessages(outDir string, shard backup.PlainShard, opts backupExpo
 	return files + 1, len(messages), nil
 }
 
+func gmailMailboxMessage(message gmailBackupMessage, rawMIME []byte) mailbox.Message {
+	out := mailbox.Message{
+		ID:       message.ID,
+		ThreadID: message.ThreadID,
+		Labels:   message.LabelIDs,
+		Raw:      rawMIME,
+	}
+	if message.InternalDate > 0 {
+		out.InternalDate = time.UnixMilli(message.InternalDate).UTC()
+	}
+	return out
+}
+
 func decodeGmailRaw(raw string) ([]byte, error) {
 	raw = strings.TrimSpace(raw)
 	if raw == "" {
@@ -68,6 +68,43 @@ func TestExportGmailMessagesWritesReadableEMLAndIndex(t *testing.T) {
 	}
 }
 
+func TestExportGmailMessagesWritesMaildirFoldersFromLabelsShard(t *testing.T) {
+	outDir := t.TempDir()
+	labels, err := backup.NewJSONLShard("gmail", "labels", "acct/hash", "data/gmail/acct/labels.jsonl.gz.age", []gmailBackupLabel{{ID: "Label_7", Name: "Legal/Hold"}})
+	if err != nil {
+		t.Fatalf("NewJSONLShard labels: %v", err)
+	}
+	message := gmailBackupMessage{
+		ID:           "m1",
+		InternalDate: mustUnixMilli(t, "2026-04-02T10:00:00Z"),
+		LabelIDs:     []string{"INBOX", "Label_7", "UNREAD"},
+		Raw:          base64.RawURLEncoding.EncodeToString([]byte("Subject: Hold\r\n\r\nBody")),
+	}
+	shard, err := backup.NewJSONLShard("gmail", "messages", "acct/hash", "data/gmail/acct/messages/2026/04/part-0001.jsonl.gz.age", []gmailBackupMessage{message})
+	if err != nil {
+		t.Fatalf("NewJSONLShard: %v", err)
+	}
+	opts := backupExportOptions{GmailFormat: "maildir", gmailLabelNames: map[string]map[string]string{}}
+	if _, _, err = exportPlainShard(outDir, labels, opts); err != nil {
+		t.Fatalf("export labels: %v", err)
+	}
+	if _, _, err = exportPlainShard(outDir, shard, opts); err != nil {
+		t.Fatalf("export messages: %v", err)
+	}
+	root := filepath.Join(outDir, "gmail", "acct_hash", gmailExportMaildirName)
+	inbox := readText(t, filepath.Join(root, "cur", "1775124000.m1.gog:2,"))
+	if !strings.HasPrefix(inbox, "X-Gmail-Labels: Inbox,Legal/Hold,Unread\nSubject: Hold\n") {
+		t.Fatalf("inbox copy = %q", inbox)
+	}
+	if _, err := os.Stat(filepath.Join(root, ".Legal.Hold", "cur", "1775124000.m1.gog:2,")); err != nil {
+		t.Fatalf("label folder copy: %v", err)
+	}
+	index := readText(t, filepath.Join(outDir, "gmail", "acct_hash", "messages", "index.jsonl"))
+	if !strings.Contains(index, `"mailbox":"gmail/acct_hash/Maildir"`) || strings.Contains(index, `"eml"`) {
+		t.Fatalf("index = %s", index)
+	}
+}
+
 func TestExportGmailMessagesWritesMarkdownAndAttachments(t *testing.T) {
 	outDir := t.TempDir()
 	payload := strings.Join([]string{
@@ -65,16 +65,25 @@ func buildGmailBackupSnapshot(ctx context.Context, flags *RootFlags, opts gmailB
 	}
 	shards = append(shards, labelShard)
 	var messageCount int
-	ids, err := gmailbackup.ListMessageIDs(ctx, source, gmailbackup.ListOptions{
+	cursorKey := backup.CursorKey(backupServiceGmail, accountHash)
+	startHistoryID, err := backup.LocalCursor(opts.BackupOptions, cursorKey)
+	if err != nil {
+		return backup.Snapshot{}, err
+	}
+	sync, err := gmailbackup.SyncMessageIDs(ctx, source, gmailbackup.ListOptions{
 		Selection: gmailBackupSelection(opts),
 		Cache:     opts.Cache,
 		UseCache:  opts.CacheMessages,
 		Refresh:   opts.RefreshCache,
 		Progress:  gmailBackupFetchProgress(ctx),
-	})
+	}, startHistoryID)
 	if err != nil {
 		return backup.Snapshot{}, err
 	}
+	ids := sync.IDs
+	if sync.Incremental {
+		gmailBackupProgressf(ctx, "backup gmail history\tadded=%d\tchanged=%d\tdeleted=%d\tmessages=%d", sync.Added, sync.Changed, sync.Deleted, len(ids))
+	}
 	if opts.CacheMessages {
 		checkpointOpts := gmailBackupCheckpointOptions(ctx, opts)
 		checkpointOpts.RunID = gmailbackup.ResolveCheckpointRunID(ctx, ids, checkpointOpts)
@@ -132,7 +141,7 @@ func buildGmailBackupSnapshot(ctx context.Context, flags *RootFlags, opts gmailB
 		shards = append(shards, messageShards...)
 		messageCount = len(messages)
 	}
-	return backup.Snapshot{
+	snapshot := backup.Snapshot{
 		Services: []string{backupServiceGmail},
 		Accounts: []string{accountHash},
 		Counts: map[string]int{
@@ -140,7 +149,11 @@ func buildGmailBackupSnapshot(ctx context.Context, flags *RootFlags, opts gmailB
 			"gmail.messages": messageCount,
 		},
 		Shards: shards,
-	}, nil
+	}
+	if sync.HistoryID != "" {
+		snapshot.Cursors = map[string]string{cursorKey: sync.HistoryID}
+	}
+	return snapshot, nil
 }
 
 func configureGmailBackupCache(ctx context.Context, opts *gmailBackupOptions) error {
@@ -205,7 +218,7 @@ func gmailBackupFetchProgress(ctx context.Context) func(gmailbackup.Event) {
 		switch event.Phase {
 		case gmailbackup.EventPhaseList:
 			switch event.Resume {
-			case "complete", "partial":
+			case "complete", "partial", "history":
 				gmailBackupProgressf(ctx, "backup gmail list\tresume=%s\tmessages=%d", event.Resume, event.Done)
 			case "start":
 				gmailBackupProgressf(ctx, "backup gmail list\tstart\tmessages=%d", event.Done)
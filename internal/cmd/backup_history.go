@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steipete/gogcli/internal/backup"
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+type BackupLogCmd struct {
+	backupReadFlags
+	Max  int  `name:"max" help:"Max snapshots to list; 0 means all" default:"20"`
+	Stat bool `name:"stat" help:"Decrypt each snapshot and summarize row changes against the previous one"`
+}
+
+type BackupDiffCmd struct {
+	backupReadFlags
+	From     string `arg:"" name:"rev1" help:"Older backup revision (commit, tag, or ref such as HEAD~1)"`
+	To       string `arg:"" name:"rev2" help:"Newer backup revision" default:"HEAD"`
+	Services string `name:"services" help:"Comma-separated services to compare; default compares every service"`
+	Rows     bool   `name:"rows" help:"List the key of every added, removed, or changed row"`
+}
+
+type backupLogEntry struct {
+	backup.Revision
+	Stats []backup.DiffStat `json:"stats,omitempty"`
+}
+
+func (c *BackupLogCmd) Run(ctx context.Context, flags *RootFlags) error {
+	ctx = backupCommandContext(ctx, flags)
+	if c.Max < 0 {
+		return usage("--max must be >= 0")
+	}
+	opts := c.options()
+	if err := bindBackupConfigStore(ctx, &opts); err != nil {
+		return err
+	}
+	if flags != nil && flags.DryRun {
+		cfg, err := backup.ResolveOptions(opts)
+		if err != nil {
+			return err
+		}
+		return dryRunExit(ctx, flags, "backup.log", map[string]any{
+			"repo": cfg.Repo,
+			"pull": !c.NoPull,
+			"max":  c.Max,
+			"stat": c.Stat,
+		})
+	}
+	limit := c.Max
+	if c.Stat && limit > 0 {
+		// The oldest listed snapshot needs its parent to compute a stat.
+		limit++
+	}
+	revisions, repo, err := backup.Log(ctx, opts, limit)
+	if err != nil {
+		return err
+	}
+	entries := make([]backupLogEntry, 0, len(revisions))
+	opts.SkipPull = true
+	for i, revision := range revisions {
+		if c.Max > 0 && i >= c.Max {
+			break
+		}
+		entry := backupLogEntry{Revision: revision}
+		if c.Stat && i+1 < len(revisions) {
+			diff, err := backup.Diff(ctx, opts, revisions[i+1].Commit, revision.Commit, backup.DiffOptions{})
+			if err != nil {
+				return err
+			}
+			entry.Stats = diff.Stats
+		}
+		entries = append(entries, entry)
+	}
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, stdoutWriter(ctx), map[string]any{"repo": repo, "revisions": entries})
+	}
+	u := ui.FromContext(ctx)
+	if len(entries) == 0 {
+		u.Err().Println("No backup snapshots")
+		return nil
+	}
+	for _, entry := range entries {
+		u.Out().Linef("%s\t%s\t%s\tshards=%d\t%s",
+			shortBackupCommit(entry.Commit),
+			entry.Time.Local().Format(time.RFC3339),
+			strings.Join(entry.Services, ","),
+			entry.Shards,
+			entry.Subject,
+		)
+		for _, line := range backupDiffSummary(entry.Stats) {
+			u.Out().Linef("\t%s", line)
+		}
+	}
+	return nil
+}
+
+func (c *BackupDiffCmd) Run(ctx context.Context, flags *RootFlags) error {
+	ctx = backupCommandContext(ctx, flags)
+	services := map[string]bool{}
+	for _, service := range splitCSV(c.Services) {
+		services[strings.ToLower(service)] = true
+	}
+	opts := c.options()
+	if err := bindBackupConfigStore(ctx, &opts); err != nil {
+		return err
+	}
+	if flags != nil && flags.DryRun {
+		cfg, err := backup.ResolveOptions(opts)
+		if err != nil {
+			return err
+		}
+		return dryRunExit(ctx, flags, "backup.diff", map[string]any{
+			"repo":     cfg.Repo,
+			"pull":     !c.NoPull,
+			"from":     c.From,
+			"to":       c.To,
+			"services": splitCSV(c.Services),
+		})
+	}
+	diffOpts := backup.DiffOptions{Rows: c.Rows}
+	if len(services) > 0 {
+		diffOpts.Include = func(entry backup.ShardEntry) bool { return services[entry.Service] }
+	}
+	result, err := backup.Diff(ctx, opts, c.From, c.To, diffOpts)
+	if err != nil {
+		return err
+	}
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, stdoutWriter(ctx), result)
+	}
+	u := ui.FromContext(ctx)
+	u.Out().Linef("repo\t%s", result.Repo)
+	u.Out().Linef("from\t%s", result.From)
+	u.Out().Linef("to\t%s", result.To)
+	for _, line := range backupDiffSummary(result.Stats) {
+		u.Out().Linef("diff\t%s", line)
+	}
+	for _, stat := range result.Stats {
+		for _, row := range stat.Rows {
+			u.Out().Linef("row\t%s\t%s.%s\t%s\t%s", backupDiffMarker(row.Change), stat.Service, stat.Kind, stat.Account, row.Key)
+		}
+	}
+	return nil
+}
+
+// backupDiffSummary folds per-account stats into one "service.kind: +a -r ~c"
+// line per shard kind.
+func backupDiffSummary(stats []backup.DiffStat) []string {
+	totals := map[string]*backup.DiffStat{}
+	keys := []string{}
+	for _, stat := range stats {
+		key := stat.Service + "." + stat.Kind
+		total, ok := totals[key]
+		if !ok {
+			total = &backup.DiffStat{}
+			totals[key] = total
+			keys = append(keys, key)
+		}
+		total.Added += stat.Added
+		total.Removed += stat.Removed
+		total.Changed += stat.Changed
+	}
+	sort.Strings(keys)
+	out := make([]string, 0, len(keys))
+	for _, key := range keys {
+		total := totals[key]
+		out = append(out, fmt.Sprintf("%s: +%d -%d ~%d", key, total.Added, total.Removed, total.Changed))
+	}
+	return out
+}
+
+func backupDiffMarker(change string) string {
+	switch change {
+	case backup.RowAdded:
+		return "+"
+	case backup.RowRemoved:
+		return "-"
+	default:
+		return "~"
+	}
+}
+
+func shortBackupCommit(commit string) string {
+	if len(commit) > 12 {
+		return commit[:12]
+	}
+	return commit
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/steipete/gogcli/internal/backup"
+)
+
+func TestBackupLogAndDiffSummarizeRowChanges(t *testing.T) {
+	repo, config, recipients := newBackupConfigForCmdTest(t)
+	for _, rows := range [][]map[string]string{
+		{{"id": "e1", "summary": "Standup"}, {"id": "e2", "summary": "Review"}},
+		{{"id": "e1", "summary": "Daily standup"}, {"id": "e3", "summary": "Retro"}},
+	} {
+		shard, err := backup.NewJSONLShard("calendar", "events", "acct", "data/calendar/acct/events.jsonl.gz.age", rows)
+		if err != nil {
+			t.Fatalf("NewJSONLShard: %v", err)
+		}
+		if _, err := backup.PushSnapshot(t.Context(), backup.Snapshot{
+			Services: []string{"calendar"},
+			Accounts: []string{"acct"},
+			Shards:   []backup.PlainShard{shard},
+		}, backupOptionsForCmdTest(t, backup.Options{ConfigPath: config, Recipients: recipients})); err != nil {
+			t.Fatalf("PushSnapshot: %v", err)
+		}
+	}
+
+	var logOut bytes.Buffer
+	if err := runKong(t, &BackupLogCmd{}, []string{"--config", config, "--repo", repo, "--no-pull", "--stat"},
+		newCmdOutputContext(t, &logOut, io.Discard), &RootFlags{}); err != nil {
+		t.Fatalf("backup log: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(logOut.String()), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[1], "calendar.events: +1 -1 ~1") {
+		t.Fatalf("unexpected log output:\n%s", logOut.String())
+	}
+
+	var diffOut bytes.Buffer
+	if err := runKong(t, &BackupDiffCmd{}, []string{"HEAD~1", "--config", config, "--repo", repo, "--no-pull", "--rows"},
+		newCmdOutputContext(t, &diffOut, io.Discard), &RootFlags{}); err != nil {
+		t.Fatalf("backup diff: %v", err)
+	}
+	for _, want := range []string{
+		"diff\tcalendar.events: +1 -1 ~1",
+		"row\t~\tcalendar.events\tacct\te1",
+		"row\t-\tcalendar.events\tacct\te2",
+		"row\t+\tcalendar.events\tacct\te3",
+	} {
+		if !strings.Contains(diffOut.String(), want) {
+			t.Fatalf("diff output missing %q:\n%s", want, diffOut.String())
+		}
+	}
+
+	var filtered bytes.Buffer
+	if err := runKong(t, &BackupDiffCmd{}, []string{"HEAD~1", "HEAD", "--services", "contacts", "--config", config, "--repo", repo, "--no-pull"},
+		newCmdOutputContext(t, &filtered, io.Discard), &RootFlags{}); err != nil {
+		t.Fatalf("backup diff --services: %v", err)
+	}
+	if strings.Contains(filtered.String(), "diff\t") {
+		t.Fatalf("filtered diff should be empty:\n%s", filtered.String())
+	}
+}
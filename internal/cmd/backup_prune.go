@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/steipete/gogcli/internal/backup"
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+type BackupPruneCmd struct {
+	backupFlags
+	KeepLast    int `name:"keep-last" help:"Keep the N most recent snapshots"`
+	KeepDaily   int `name:"keep-daily" help:"Keep the newest snapshot for each of the last N days with a snapshot"`
+	KeepWeekly  int `name:"keep-weekly" help:"Keep the newest snapshot for each of the last N ISO weeks with a snapshot"`
+	KeepMonthly int `name:"keep-monthly" help:"Keep the newest snapshot for each of the last N months with a snapshot"`
+}
+
+func (c *BackupPruneCmd) Run(ctx context.Context, flags *RootFlags) error {
+	ctx = backupCommandContext(ctx, flags)
+	if c.KeepLast < 0 || c.KeepDaily < 0 || c.KeepWeekly < 0 || c.KeepMonthly < 0 {
+		return usage("--keep-* values must be >= 0")
+	}
+	opts := c.options()
+	if err := bindBackupConfigStore(ctx, &opts); err != nil {
+		return err
+	}
+	policy := backup.PrunePolicy{
+		KeepLast:    c.KeepLast,
+		KeepDaily:   c.KeepDaily,
+		KeepWeekly:  c.KeepWeekly,
+		KeepMonthly: c.KeepMonthly,
+	}
+	plan, err := backup.Prune(ctx, opts, policy, false)
+	if err != nil {
+		return err
+	}
+	if len(plan.Removed) > 0 || len(plan.Objects) > 0 {
+		action := fmt.Sprintf("rewrite backup history in %s and drop %d snapshot(s)", plan.Repo, len(plan.Removed))
+		if plan.Storage != backup.StorageGit {
+			action = fmt.Sprintf("delete %d unreferenced shard object(s) from the %s backup target", len(plan.Objects), plan.Storage)
+		}
+		if err := dryRunAndConfirmDestructive(ctx, flags, "backup.prune", plan, action); err != nil {
+			return err
+		}
+		opts.SkipPull = true
+		if plan, err = backup.Prune(ctx, opts, policy, true); err != nil {
+			return err
+		}
+	} else if err := dryRunExit(ctx, flags, "backup.prune", plan); err != nil {
+		return err
+	}
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, stdoutWriter(ctx), plan)
+	}
+	u := ui.FromContext(ctx)
+	u.Out().Linef("repo\t%s", plan.Repo)
+	u.Out().Linef("storage\t%s", plan.Storage)
+	if plan.Storage == backup.StorageGit {
+		u.Out().Linef("kept\t%d", len(plan.Kept))
+		u.Out().Linef("removed\t%d", len(plan.Removed))
+	}
+	for _, revision := range plan.Removed {
+		u.Out().Linef("drop\t%s\t%s\t%s", shortBackupCommit(revision.Commit), revision.Time.Local().Format(time.RFC3339), revision.Subject)
+	}
+	for _, key := range plan.Objects {
+		u.Out().Linef("delete\t%s", key)
+	}
+	if len(plan.Removed) == 0 && len(plan.Objects) == 0 {
+		u.Err().Println("Nothing to prune")
+	}
+	return nil
+}
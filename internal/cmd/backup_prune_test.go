@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/steipete/gogcli/internal/backup"
+)
+
+func TestBackupPruneDryRunThenApply(t *testing.T) {
+	repo, config, recipients := newBackupConfigForCmdTest(t)
+	opts := backupOptionsForCmdTest(t, backup.Options{ConfigPath: config, Recipients: recipients})
+	for _, title := range []string{"Inbox", "Errands", "Work"} {
+		shard, err := backup.NewJSONLShard("tasks", "lists", "acct", "data/tasks/acct/lists.jsonl.gz.age", []map[string]string{{"id": "l1", "title": title}})
+		if err != nil {
+			t.Fatalf("NewJSONLShard: %v", err)
+		}
+		if _, err := backup.PushSnapshot(t.Context(), backup.Snapshot{
+			Services: []string{"tasks"},
+			Accounts: []string{"acct"},
+			Shards:   []backup.PlainShard{shard},
+		}, opts); err != nil {
+			t.Fatalf("PushSnapshot: %v", err)
+		}
+	}
+	args := []string{"--config", config, "--repo", repo, "--no-push", "--keep-last", "1"}
+
+	err := runKong(t, &BackupPruneCmd{}, args, newCmdOutputContext(t, io.Discard, io.Discard), &RootFlags{DryRun: true, NoInput: true})
+	if ExitCode(err) != 0 {
+		t.Fatalf("dry-run prune: %v", err)
+	}
+	opts.SkipPull = true
+	if revisions, _, err := backup.Log(t.Context(), opts, 0); err != nil || len(revisions) != 3 {
+		t.Fatalf("dry-run rewrote history: %d revisions, err=%v", len(revisions), err)
+	}
+
+	if err := runKong(t, &BackupPruneCmd{}, args, newCmdOutputContext(t, io.Discard, io.Discard), &RootFlags{NoInput: true}); err == nil {
+		t.Fatal("expected prune without --force to require confirmation")
+	}
+
+	var out bytes.Buffer
+	if err := runKong(t, &BackupPruneCmd{}, args, newCmdOutputContext(t, &out, io.Discard), &RootFlags{Force: true, NoInput: true}); err != nil {
+		t.Fatalf("backup prune: %v", err)
+	}
+	if !strings.Contains(out.String(), "kept\t1") || strings.Count(out.String(), "drop\t") != 2 {
+		t.Fatalf("unexpected prune output:\n%s", out.String())
+	}
+	revisions, _, err := backup.Log(t.Context(), opts, 0)
+	if err != nil || len(revisions) != 1 {
+		t.Fatalf("revisions after prune = %d, err=%v", len(revisions), err)
+	}
+	if _, err := backup.Verify(t.Context(), opts); err != nil {
+		t.Fatalf("Verify after prune: %v", err)
+	}
+}
@@ -0,0 +1,373 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/people/v1"
+	"google.golang.org/api/tasks/v1"
+
+	"github.com/steipete/gogcli/internal/backup"
+	gmailbackup "github.com/steipete/gogcli/internal/backup/gmail"
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+const (
+	backupRestoreServicesHelp = "all, contacts, tasks, calendar, gmail-settings, gmail"
+
+	backupRestoreCreate = "create"
+	backupRestoreUpdate = "update"
+	backupRestoreSkip   = "skip"
+)
+
+type BackupRestoreCmd struct {
+	backupReadFlags
+	Services    string `name:"services" help:"Comma-separated services to restore: all, contacts, tasks, calendar, gmail-settings, gmail (labels and messages)" default:"contacts,tasks,calendar,gmail-settings"`
+	FromAccount string `name:"from-account" help:"Backup account email or account hash to restore from; defaults to the target account"`
+	MaxMessages int64  `name:"max-messages" help:"Max Gmail messages to import when restoring gmail; 0 means all" default:"0"`
+}
+
+type backupRestoreItem struct {
+	Service string `json:"service"`
+	Kind    string `json:"kind"`
+	Action  string `json:"action"`
+	Name    string `json:"name,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+type backupRestoreResult struct {
+	Repo          string              `json:"repo"`
+	SourceAccount string              `json:"sourceAccount"`
+	Services      []string            `json:"services"`
+	Applied       bool                `json:"applied"`
+	Counts        map[string]int      `json:"counts"`
+	Items         []backupRestoreItem `json:"items"`
+}
+
+// backupRestoreSource holds the decrypted rows of every small shard kind that
+// restore needs. Gmail message shards are streamed separately.
+type backupRestoreSource struct {
+	ContactPeople []contactsBackupPerson
+	ContactGroups []*people.ContactGroup
+	TaskLists     []*tasks.TaskList
+	Tasks         []tasksBackupTask
+	Calendars     []*calendar.CalendarListEntry
+	Events        []calendarBackupEvent
+	GmailLabels   []gmailBackupLabel
+	GmailSettings []gmailSettingsBackup
+}
+
+type backupRestorer struct {
+	apply    bool
+	progress func(format string, args ...any)
+	result   backupRestoreResult
+}
+
+func (c *BackupRestoreCmd) Run(ctx context.Context, flags *RootFlags) error {
+	ctx = backupCommandContext(ctx, flags)
+	services, err := parseBackupRestoreServices(c.Services)
+	if err != nil {
+		return err
+	}
+	if c.MaxMessages < 0 {
+		return usage("--max-messages must be >= 0")
+	}
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	sourceAccount := backupRestoreSourceAccount(c.FromAccount, account)
+	opts := c.options()
+	if bindErr := bindBackupConfigStore(ctx, &opts); bindErr != nil {
+		return bindErr
+	}
+
+	source, manifest, repo, err := loadBackupRestoreSource(ctx, opts, sourceAccount, services)
+	if err != nil {
+		return err
+	}
+	if !backupManifestHasAccount(manifest, sourceAccount) {
+		return usagef("backup has no shards for account %s; pass --from-account with the backed-up account email or hash", sourceAccount)
+	}
+
+	restorer := &backupRestorer{
+		apply:    flags == nil || !flags.DryRun,
+		progress: func(format string, args ...any) { gmailBackupProgressf(ctx, format, args...) },
+		result: backupRestoreResult{
+			Repo:          repo,
+			SourceAccount: sourceAccount,
+			Services:      services,
+			Counts:        map[string]int{},
+			Items:         []backupRestoreItem{},
+		},
+	}
+	restorer.result.Applied = restorer.apply
+	messageOpts := opts
+	messageOpts.SkipPull = true
+	if err := restorer.run(ctx, account, source, services, func(visit func(gmailBackupMessage) (bool, error)) error {
+		return walkBackupRestoreMessages(ctx, messageOpts, sourceAccount, visit)
+	}, c.MaxMessages); err != nil {
+		return err
+	}
+	if !restorer.apply {
+		return dryRunExit(ctx, flags, "backup.restore", restorer.result)
+	}
+	return writeBackupRestoreResult(ctx, restorer.result)
+}
+
+func parseBackupRestoreServices(value string) ([]string, error) {
+	seen := map[string]bool{}
+	for _, raw := range splitCSV(value) {
+		service := strings.ToLower(strings.TrimSpace(raw))
+		switch service {
+		case "all":
+			for _, expanded := range []string{backupServiceContacts, backupServiceTasks, backupServiceCalendar, backupServiceGmailSettings, backupServiceGmail} {
+				seen[expanded] = true
+			}
+		case backupServiceContacts, backupServiceTasks, backupServiceCalendar, backupServiceGmailSettings, backupServiceGmail:
+			seen[service] = true
+		case "":
+			continue
+		default:
+			return nil, usagef("unsupported restore service %q (supported: %s)", raw, backupRestoreServicesHelp)
+		}
+	}
+	if len(seen) == 0 {
+		return nil, usage("at least one --services value is required")
+	}
+	out := make([]string, 0, len(seen))
+	for _, service := range []string{backupServiceContacts, backupServiceTasks, backupServiceCalendar, backupServiceGmailSettings, backupServiceGmail} {
+		if seen[service] {
+			out = append(out, service)
+		}
+	}
+	return out, nil
+}
+
+func backupRestoreSourceAccount(fromAccount, account string) string {
+	fromAccount = strings.TrimSpace(fromAccount)
+	if fromAccount == "" {
+		return backupAccountHash(account)
+	}
+	if strings.Contains(fromAccount, "@") {
+		return backupAccountHash(fromAccount)
+	}
+	return strings.ToLower(fromAccount)
+}
+
+func backupManifestHasAccount(manifest backup.Manifest, accountHash string) bool {
+	for _, account := range manifest.Accounts {
+		if account == accountHash {
+			return true
+		}
+	}
+	for _, shard := range manifest.Shards {
+		if shard.Account == accountHash {
+			return true
+		}
+	}
+	return false
+}
+
+func backupRestoreWantsShard(entry backup.ShardEntry, accountHash string, services []string) bool {
+	if entry.Account != accountHash {
+		return false
+	}
+	wants := func(service string) bool {
+		for _, selected := range services {
+			if selected == service {
+				return true
+			}
+		}
+		return false
+	}
+	switch entry.Service {
+	case backupServiceContacts:
+		return wants(backupServiceContacts) && (entry.Kind == "people" || entry.Kind == "groups")
+	case backupServiceTasks:
+		return wants(backupServiceTasks) && (entry.Kind == "lists" || entry.Kind == "tasks")
+	case backupServiceCalendar:
+		return wants(backupServiceCalendar) && (entry.Kind == "calendars" || entry.Kind == "events")
+	case backupServiceGmailSettings:
+		return wants(backupServiceGmailSettings) && entry.Kind == "settings"
+	case backupServiceGmail:
+		return (wants(backupServiceGmail) || wants(backupServiceGmailSettings)) && entry.Kind == "labels"
+	default:
+		return false
+	}
+}
+
+func loadBackupRestoreSource(ctx context.Context, opts backup.Options, accountHash string, services []string) (backupRestoreSource, backup.Manifest, string, error) {
+	var source backupRestoreSource
+	include := func(entry backup.ShardEntry) bool {
+		return backupRestoreWantsShard(entry, accountHash, services)
+	}
+	manifest, repo, err := backup.WalkSelectedShards(ctx, opts, include, func(_ backup.Manifest, _ string, shard backup.PlainShard) error {
+		var decodeErr error
+		switch shard.Service + "." + shard.Kind {
+		case "contacts.people":
+			decodeErr = backup.DecodeJSONL(shard.Plaintext, &source.ContactPeople)
+		case "contacts.groups":
+			decodeErr = backup.DecodeJSONL(shard.Plaintext, &source.ContactGroups)
+		case "tasks.lists":
+			decodeErr = backup.DecodeJSONL(shard.Plaintext, &source.TaskLists)
+		case "tasks.tasks":
+			decodeErr = backup.DecodeJSONL(shard.Plaintext, &source.Tasks)
+		case "calendar.calendars":
+			decodeErr = backup.DecodeJSONL(shard.Plaintext, &source.Calendars)
+		case "calendar.events":
+			decodeErr = backup.DecodeJSONL(shard.Plaintext, &source.Events)
+		case "gmail.labels":
+			decodeErr = backup.DecodeJSONL(shard.Plaintext, &source.GmailLabels)
+		case "gmail-settings.settings":
+			decodeErr = backup.DecodeJSONL(shard.Plaintext, &source.GmailSettings)
+		}
+		if decodeErr != nil {
+			return fmt.Errorf("decode backup shard %s: %w", shard.Path, decodeErr)
+		}
+		return nil
+	})
+	return source, manifest, repo, err
+}
+
+func walkBackupRestoreMessages(ctx context.Context, opts backup.Options, accountHash string, visit func(gmailBackupMessage) (bool, error)) error {
+	stopped := false
+	include := func(entry backup.ShardEntry) bool {
+		return !stopped && entry.Account == accountHash && entry.Service == backupServiceGmail && entry.Kind == gmailbackup.MessageShardKind
+	}
+	_, _, err := backup.WalkSelectedShards(ctx, opts, include, func(_ backup.Manifest, _ string, shard backup.PlainShard) error {
+		var messages []gmailBackupMessage
+		if err := backup.DecodeJSONL(shard.Plaintext, &messages); err != nil {
+			return fmt.Errorf("decode backup shard %s: %w", shard.Path, err)
+		}
+		for _, message := range messages {
+			more, err := visit(message)
+			if err != nil {
+				return err
+			}
+			if !more {
+				stopped = true
+				return nil
+			}
+		}
+		return nil
+	})
+	return err
+}
+
+func (r *backupRestorer) run(
+	ctx context.Context,
+	account string,
+	source backupRestoreSource,
+	services []string,
+	walkMessages func(func(gmailBackupMessage) (bool, error)) error,
+	maxMessages int64,
+) error {
+	selected := map[string]bool{}
+	for _, service := range services {
+		selected[service] = true
+	}
+	var labels backupRestoreLabelMap
+	if selected[backupServiceGmailSettings] || selected[backupServiceGmail] {
+		svc, err := gmailService(ctx, account)
+		if err != nil {
+			return err
+		}
+		labels, err = r.restoreGmailLabels(ctx, svc, source.GmailLabels)
+		if err != nil {
+			return r.stopped("gmail labels", err)
+		}
+		if selected[backupServiceGmailSettings] {
+			if err := r.restoreGmailSettings(ctx, svc, source.GmailSettings, labels); err != nil {
+				return r.stopped("gmail settings", err)
+			}
+		}
+	}
+	if selected[backupServiceContacts] {
+		svc, err := peopleContactsService(ctx, account)
+		if err != nil {
+			return err
+		}
+		if err := r.restoreContacts(ctx, svc, source.ContactGroups, source.ContactPeople); err != nil {
+			return r.stopped("contacts", wrapPeopleAPIError(err))
+		}
+	}
+	if selected[backupServiceTasks] {
+		svc, err := tasksService(ctx, account)
+		if err != nil {
+			return err
+		}
+		if err := r.restoreTasks(ctx, svc, source.TaskLists, source.Tasks); err != nil {
+			return r.stopped("tasks", err)
+		}
+	}
+	if selected[backupServiceCalendar] {
+		svc, err := calendarService(ctx, account)
+		if err != nil {
+			return err
+		}
+		if err := r.restoreCalendar(ctx, svc, source.Calendars, source.Events); err != nil {
+			return r.stopped("calendar", err)
+		}
+	}
+	if selected[backupServiceGmail] {
+		svc, err := gmailService(ctx, account)
+		if err != nil {
+			return err
+		}
+		if err := r.restoreGmailMessages(ctx, svc, labels, walkMessages, maxMessages); err != nil {
+			return r.stopped("gmail messages", err)
+		}
+	}
+	return nil
+}
+
+func (r *backupRestorer) stopped(stage string, err error) error {
+	changes := 0
+	for key, count := range r.result.Counts {
+		if strings.HasSuffix(key, "."+backupRestoreCreate) || strings.HasSuffix(key, "."+backupRestoreUpdate) {
+			changes += count
+		}
+	}
+	return fmt.Errorf("backup restore stopped during %s after %d change(s); rerun to resume: %w", stage, changes, err)
+}
+
+// record adds a plan item. Items are kept for every object except Gmail
+// messages, which are only counted to keep large mailbox plans readable.
+func (r *backupRestorer) record(item backupRestoreItem) {
+	r.count(item.Service, item.Kind, item.Action)
+	r.result.Items = append(r.result.Items, item)
+}
+
+func (r *backupRestorer) count(service, kind, action string) {
+	r.result.Counts[service+"."+kind+"."+action]++
+}
+
+func (r *backupRestorer) progressf(format string, args ...any) {
+	if r.progress != nil {
+		r.progress(format, args...)
+	}
+}
+
+func writeBackupRestoreResult(ctx context.Context, result backupRestoreResult) error {
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, stdoutWriter(ctx), result)
+	}
+	u := ui.FromContext(ctx)
+	u.Out().Linef("repo\t%s", result.Repo)
+	u.Out().Linef("source\t%s", result.SourceAccount)
+	u.Out().Linef("services\t%s", strings.Join(result.Services, ","))
+	keys := make([]string, 0, len(result.Counts))
+	for key := range result.Counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		u.Out().Linef("count.%s\t%d", key, result.Counts[key])
+	}
+	return nil
+}
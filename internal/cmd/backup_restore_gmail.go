@@ -0,0 +1,336 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"google.golang.org/api/gmail/v1"
+)
+
+const backupRestoreForwardAccepted = "accepted"
+
+// backupRestoreLabelMap maps label IDs from the backup to label IDs in the
+// target mailbox. Labels that a dry run would create map to a pending
+// placeholder so plans can still show which filters and messages use them.
+type backupRestoreLabelMap map[string]string
+
+func (m backupRestoreLabelMap) mapIDs(ids []string) ([]string, bool) {
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		mapped, ok := m[id]
+		if !ok {
+			if !isBackupRestoreSystemLabel(id) {
+				return nil, false
+			}
+			mapped = id
+		}
+		out = append(out, mapped)
+	}
+	return out, true
+}
+
+func isBackupRestoreSystemLabel(id string) bool {
+	return id == strings.ToUpper(id) && !strings.HasPrefix(id, "Label_")
+}
+
+func (r *backupRestorer) restoreGmailLabels(ctx context.Context, svc *gmail.Service, labels []gmailBackupLabel) (backupRestoreLabelMap, error) {
+	resp, err := svc.Users.Labels.List("me").Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("list gmail labels: %w", err)
+	}
+	byName := map[string]string{}
+	for _, label := range resp.Labels {
+		if label != nil {
+			byName[strings.ToLower(label.Name)] = label.Id
+		}
+	}
+	out := backupRestoreLabelMap{}
+	for _, label := range labels {
+		if label.ID == "" {
+			continue
+		}
+		if label.Type == "system" {
+			out[label.ID] = label.ID
+			continue
+		}
+		if id, ok := byName[strings.ToLower(label.Name)]; ok {
+			out[label.ID] = id
+			r.record(backupRestoreItem{Service: backupServiceGmail, Kind: "labels", Action: backupRestoreSkip, Name: label.Name, Reason: "exists"})
+			continue
+		}
+		id := gmailPendingLabelPrefix + label.Name
+		if r.apply {
+			created, err := svc.Users.Labels.Create("me", &gmail.Label{
+				Name:                  label.Name,
+				LabelListVisibility:   label.LabelListVisibility,
+				MessageListVisibility: label.MessageListVisibility,
+			}).Context(ctx).Do()
+			if err != nil {
+				return nil, fmt.Errorf("create gmail label %q: %w", label.Name, err)
+			}
+			id = created.Id
+		}
+		out[label.ID] = id
+		byName[strings.ToLower(label.Name)] = id
+		r.record(backupRestoreItem{Service: backupServiceGmail, Kind: "labels", Action: backupRestoreCreate, Name: label.Name})
+	}
+	return out, nil
+}
+
+func (r *backupRestorer) restoreGmailSettings(ctx context.Context, svc *gmail.Service, rows []gmailSettingsBackup, labels backupRestoreLabelMap) error {
+	for _, settings := range rows {
+		if err := r.restoreGmailFilters(ctx, svc, settings.Filters, labels); err != nil {
+			return err
+		}
+		if err := r.restoreGmailSetting(ctx, "vacation", settings.Vacation, func() (any, error) {
+			return svc.Users.Settings.GetVacation("me").Context(ctx).Do()
+		}, func() error {
+			_, err := svc.Users.Settings.UpdateVacation("me", settings.Vacation).Context(ctx).Do()
+			return err
+		}); err != nil {
+			return err
+		}
+		if err := r.restoreGmailSetting(ctx, "imap", settings.IMAP, func() (any, error) {
+			return svc.Users.Settings.GetImap("me").Context(ctx).Do()
+		}, func() error {
+			_, err := svc.Users.Settings.UpdateImap("me", settings.IMAP).Context(ctx).Do()
+			return err
+		}); err != nil {
+			return err
+		}
+		if err := r.restoreGmailSetting(ctx, "pop", settings.POP, func() (any, error) {
+			return svc.Users.Settings.GetPop("me").Context(ctx).Do()
+		}, func() error {
+			_, err := svc.Users.Settings.UpdatePop("me", settings.POP).Context(ctx).Do()
+			return err
+		}); err != nil {
+			return err
+		}
+		if err := r.restoreGmailSetting(ctx, "language", settings.Language, func() (any, error) {
+			return svc.Users.Settings.GetLanguage("me").Context(ctx).Do()
+		}, func() error {
+			_, err := svc.Users.Settings.UpdateLanguage("me", settings.Language).Context(ctx).Do()
+			return err
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreGmailSetting updates a singleton setting only when the backed-up
+// value differs from the current one, so reruns do not issue writes.
+func (r *backupRestorer) restoreGmailSetting(ctx context.Context, kind string, want any, get func() (any, error), update func() error) error {
+	if backupRestoreIsNil(want) {
+		return nil
+	}
+	current, err := get()
+	if err != nil {
+		return fmt.Errorf("get gmail %s settings: %w", kind, err)
+	}
+	if backupRestoreSameJSON(current, want) {
+		r.record(backupRestoreItem{Service: backupServiceGmailSettings, Kind: kind, Action: backupRestoreSkip, Reason: "unchanged"})
+		return nil
+	}
+	if r.apply {
+		if err := update(); err != nil {
+			return fmt.Errorf("update gmail %s settings: %w", kind, err)
+		}
+	}
+	r.record(backupRestoreItem{Service: backupServiceGmailSettings, Kind: kind, Action: backupRestoreUpdate})
+	return ctx.Err()
+}
+
+func (r *backupRestorer) restoreGmailFilters(ctx context.Context, svc *gmail.Service, filters []*gmail.Filter, labels backupRestoreLabelMap) error {
+	if len(filters) == 0 {
+		return nil
+	}
+	existing, err := svc.Users.Settings.Filters.List("me").Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("list gmail filters: %w", err)
+	}
+	known := map[string]bool{}
+	for _, filter := range existing.Filter {
+		if filter != nil {
+			known[backupRestoreFilterSignature(filter.Criteria, filter.Action)] = true
+		}
+	}
+	verified, err := backupRestoreVerifiedForwarding(ctx, svc)
+	if err != nil {
+		return err
+	}
+	for _, filter := range filters {
+		if filter == nil || filter.Criteria == nil || filter.Action == nil {
+			continue
+		}
+		name := backupRestoreFilterName(filter.Criteria)
+		skip := func(reason string) {
+			r.record(backupRestoreItem{Service: backupServiceGmailSettings, Kind: "filters", Action: backupRestoreSkip, Name: name, Reason: reason})
+		}
+		action := *filter.Action
+		var ok bool
+		if action.AddLabelIds, ok = labels.mapIDs(filter.Action.AddLabelIds); !ok {
+			skip("filter references a label missing from the backup")
+			continue
+		}
+		if action.RemoveLabelIds, ok = labels.mapIDs(filter.Action.RemoveLabelIds); !ok {
+			skip("filter references a label missing from the backup")
+			continue
+		}
+		if action.Forward != "" && !verified[strings.ToLower(action.Forward)] {
+			skip("forwarding address is not verified in target account")
+			continue
+		}
+		signature := backupRestoreFilterSignature(filter.Criteria, &action)
+		if known[signature] {
+			skip("exists")
+			continue
+		}
+		if r.apply {
+			if _, err := svc.Users.Settings.Filters.Create("me", &gmail.Filter{Criteria: filter.Criteria, Action: &action}).Context(ctx).Do(); err != nil {
+				return fmt.Errorf("create gmail filter %q: %w", name, err)
+			}
+		}
+		known[signature] = true
+		r.record(backupRestoreItem{Service: backupServiceGmailSettings, Kind: "filters", Action: backupRestoreCreate, Name: name})
+	}
+	return nil
+}
+
+func backupRestoreVerifiedForwarding(ctx context.Context, svc *gmail.Service) (map[string]bool, error) {
+	resp, err := svc.Users.Settings.ForwardingAddresses.List("me").Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("list gmail forwarding addresses: %w", err)
+	}
+	out := map[string]bool{}
+	for _, address := range resp.ForwardingAddresses {
+		if address != nil && address.VerificationStatus == backupRestoreForwardAccepted {
+			out[strings.ToLower(address.ForwardingEmail)] = true
+		}
+	}
+	return out, nil
+}
+
+func backupRestoreFilterSignature(criteria *gmail.FilterCriteria, action *gmail.FilterAction) string {
+	normalized := gmail.FilterAction{}
+	if action != nil {
+		normalized = *action
+		normalized.AddLabelIds = append([]string(nil), action.AddLabelIds...)
+		normalized.RemoveLabelIds = append([]string(nil), action.RemoveLabelIds...)
+		sort.Strings(normalized.AddLabelIds)
+		sort.Strings(normalized.RemoveLabelIds)
+	}
+	data, _ := json.Marshal(struct {
+		Criteria *gmail.FilterCriteria `json:"criteria"`
+		Action   gmail.FilterAction    `json:"action"`
+	}{criteria, normalized})
+	return string(data)
+}
+
+func backupRestoreFilterName(criteria *gmail.FilterCriteria) string {
+	parts := []string{}
+	for _, part := range []struct{ key, value string }{
+		{"from", criteria.From},
+		{"to", criteria.To},
+		{"subject", criteria.Subject},
+		{"query", criteria.Query},
+		{"negated", criteria.NegatedQuery},
+	} {
+		if strings.TrimSpace(part.value) != "" {
+			parts = append(parts, part.key+":"+strings.TrimSpace(part.value))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func backupRestoreSameJSON(left, right any) bool {
+	leftData, leftErr := json.Marshal(left)
+	rightData, rightErr := json.Marshal(right)
+	return leftErr == nil && rightErr == nil && bytes.Equal(leftData, rightData)
+}
+
+func backupRestoreIsNil(value any) bool {
+	switch typed := value.(type) {
+	case nil:
+		return true
+	case *gmail.VacationSettings:
+		return typed == nil
+	case *gmail.ImapSettings:
+		return typed == nil
+	case *gmail.PopSettings:
+		return typed == nil
+	case *gmail.LanguageSettings:
+		return typed == nil
+	default:
+		return false
+	}
+}
+
+// restoreGmailMessages imports raw messages that are not already present,
+// matching on the RFC 822 Message-ID header. Messages are only counted, not
+// listed, so plans for large mailboxes stay readable.
+func (r *backupRestorer) restoreGmailMessages(
+	ctx context.Context,
+	svc *gmail.Service,
+	labels backupRestoreLabelMap,
+	walkMessages func(func(gmailBackupMessage) (bool, error)) error,
+	maxMessages int64,
+) error {
+	var seen int64
+	importer := &gmailRawImporter{svc: svc, dedupe: true, dryRun: !r.apply}
+	err := walkMessages(func(message gmailBackupMessage) (bool, error) {
+		if maxMessages > 0 && seen >= maxMessages {
+			return false, nil
+		}
+		seen++
+		raw, err := decodeGmailRaw(message.Raw)
+		if err != nil {
+			return false, fmt.Errorf("decode backed-up message %s: %w", message.ID, err)
+		}
+		imported, err := importer.importRaw(ctx, raw, backupRestoreMessageLabels(labels, message.LabelIDs))
+		if err != nil {
+			return false, fmt.Errorf("import message %s: %w", message.ID, err)
+		}
+		if imported.Duplicate {
+			r.count(backupServiceGmail, "messages", backupRestoreSkip)
+			return true, nil
+		}
+		r.count(backupServiceGmail, "messages", backupRestoreCreate)
+		if seen%100 == 0 {
+			r.progressf("restore gmail messages\tscanned=%d", seen)
+		}
+		return true, nil
+	})
+	if err != nil {
+		return err
+	}
+	r.progressf("restore gmail messages\tcreate=%d\tskip=%d", r.result.Counts["gmail.messages.create"], r.result.Counts["gmail.messages.skip"])
+	return nil
+}
+
+// backupRestoreMessageLabels drops labels Gmail does not accept on import
+// and labels that no longer map to the target mailbox.
+func backupRestoreMessageLabels(labels backupRestoreLabelMap, ids []string) []string {
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id == "DRAFT" || id == "CHAT" {
+			continue
+		}
+		mapped, ok := labels[id]
+		if !ok {
+			if !isBackupRestoreSystemLabel(id) {
+				continue
+			}
+			mapped = id
+		}
+		if strings.HasPrefix(mapped, gmailPendingLabelPrefix) {
+			continue
+		}
+		out = append(out, mapped)
+	}
+	return out
+}
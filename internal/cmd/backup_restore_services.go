@@ -0,0 +1,428 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/people/v1"
+	"google.golang.org/api/tasks/v1"
+)
+
+const backupRestoreUserContactGroup = "USER_CONTACT_GROUP"
+
+// backupRestorePersonReadOnlyFields are People API fields that CreateContact
+// rejects or ignores; they are dropped before a backed-up person is replayed.
+var backupRestorePersonReadOnlyFields = []string{
+	"resourceName", "etag", "metadata", "photos", "coverPhotos", "ageRange", "ageRanges", "memberships",
+}
+
+func (r *backupRestorer) restoreContacts(ctx context.Context, svc *people.Service, groups []*people.ContactGroup, rows []contactsBackupPerson) error {
+	if err := r.restoreContactGroups(ctx, svc, groups); err != nil {
+		return err
+	}
+	existing, err := contactsDedupeList(ctx, svc, 0)
+	if err != nil {
+		return err
+	}
+	known := map[string]bool{}
+	for _, person := range existing {
+		for _, key := range backupRestoreContactKeys(person) {
+			known[key] = true
+		}
+	}
+	for _, row := range rows {
+		if row.Person == nil {
+			continue
+		}
+		if row.Source != "connections" {
+			r.count(backupServiceContacts, "people", backupRestoreSkip)
+			continue
+		}
+		name := backupRestoreContactName(row.Person)
+		keys := backupRestoreContactKeys(row.Person)
+		if len(keys) == 0 {
+			r.record(backupRestoreItem{Service: backupServiceContacts, Kind: "people", Action: backupRestoreSkip, Name: row.Person.ResourceName, Reason: "no name, email, or phone to match"})
+			continue
+		}
+		if backupRestoreAnyKnown(known, keys) {
+			r.record(backupRestoreItem{Service: backupServiceContacts, Kind: "people", Action: backupRestoreSkip, Name: name, Reason: "exists"})
+			continue
+		}
+		if r.apply {
+			person, err := backupRestorePerson(row.Person)
+			if err != nil {
+				return err
+			}
+			if _, err := svc.People.CreateContact(person).Context(ctx).Do(); err != nil {
+				return fmt.Errorf("create contact %q: %w", name, err)
+			}
+		}
+		for _, key := range keys {
+			known[key] = true
+		}
+		r.record(backupRestoreItem{Service: backupServiceContacts, Kind: "people", Action: backupRestoreCreate, Name: name})
+	}
+	r.progressf("restore contacts\tcreate=%d\tskip=%d", r.result.Counts["contacts.people.create"], r.result.Counts["contacts.people.skip"])
+	return nil
+}
+
+func (r *backupRestorer) restoreContactGroups(ctx context.Context, svc *people.Service, groups []*people.ContactGroup) error {
+	existing, err := fetchBackupContactGroups(ctx, svc)
+	if err != nil {
+		return err
+	}
+	names := map[string]bool{}
+	for _, group := range existing {
+		if group != nil {
+			names[strings.ToLower(strings.TrimSpace(group.Name))] = true
+		}
+	}
+	for _, group := range groups {
+		if group == nil || group.GroupType != backupRestoreUserContactGroup || strings.TrimSpace(group.Name) == "" {
+			continue
+		}
+		key := strings.ToLower(strings.TrimSpace(group.Name))
+		if names[key] {
+			r.record(backupRestoreItem{Service: backupServiceContacts, Kind: "groups", Action: backupRestoreSkip, Name: group.Name, Reason: "exists"})
+			continue
+		}
+		if r.apply {
+			if _, err := svc.ContactGroups.Create(&people.CreateContactGroupRequest{
+				ContactGroup: &people.ContactGroup{Name: strings.TrimSpace(group.Name)},
+			}).Context(ctx).Do(); err != nil {
+				return fmt.Errorf("create contact group %q: %w", group.Name, err)
+			}
+		}
+		names[key] = true
+		r.record(backupRestoreItem{Service: backupServiceContacts, Kind: "groups", Action: backupRestoreCreate, Name: group.Name})
+	}
+	return nil
+}
+
+// backupRestoreContactKeys matches on email and phone first and only falls
+// back to the display name for contacts without either.
+func backupRestoreContactKeys(person *people.Person) []string {
+	keys := contactsDedupeKeys(person, contactsDedupeMatch{Email: true, Phone: true})
+	if len(keys) > 0 {
+		return keys
+	}
+	return contactsDedupeKeys(person, contactsDedupeMatch{Name: true})
+}
+
+func backupRestoreAnyKnown(known map[string]bool, keys []string) bool {
+	for _, key := range keys {
+		if known[key] {
+			return true
+		}
+	}
+	return false
+}
+
+func backupRestoreContactName(person *people.Person) string {
+	if name := primaryName(person); name != "" {
+		return name
+	}
+	for _, email := range person.EmailAddresses {
+		if email != nil && strings.TrimSpace(email.Value) != "" {
+			return strings.TrimSpace(email.Value)
+		}
+	}
+	for _, phone := range person.PhoneNumbers {
+		if phone != nil && strings.TrimSpace(phone.Value) != "" {
+			return strings.TrimSpace(phone.Value)
+		}
+	}
+	return person.ResourceName
+}
+
+func backupRestorePerson(person *people.Person) (*people.Person, error) {
+	data, err := json.Marshal(person)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for _, field := range backupRestorePersonReadOnlyFields {
+		delete(fields, field)
+	}
+	stripBackupRestoreMetadata(fields)
+	data, err = json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	var out people.Person
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func stripBackupRestoreMetadata(value any) {
+	switch typed := value.(type) {
+	case map[string]any:
+		delete(typed, "metadata")
+		for _, nested := range typed {
+			stripBackupRestoreMetadata(nested)
+		}
+	case []any:
+		for _, nested := range typed {
+			stripBackupRestoreMetadata(nested)
+		}
+	}
+}
+
+func (r *backupRestorer) restoreTasks(ctx context.Context, svc *tasks.Service, lists []*tasks.TaskList, rows []tasksBackupTask) error {
+	targetLists, err := fetchBackupTaskLists(ctx, svc)
+	if err != nil {
+		return err
+	}
+	listByTitle := map[string]string{}
+	for _, list := range targetLists {
+		if list != nil {
+			if _, ok := listByTitle[list.Title]; !ok {
+				listByTitle[list.Title] = list.Id
+			}
+		}
+	}
+	listIDs := map[string]string{}
+	for _, list := range lists {
+		if list == nil || strings.TrimSpace(list.Id) == "" {
+			continue
+		}
+		if targetID, ok := listByTitle[list.Title]; ok {
+			listIDs[list.Id] = targetID
+			r.record(backupRestoreItem{Service: backupServiceTasks, Kind: "lists", Action: backupRestoreSkip, Name: list.Title, Reason: "exists"})
+			continue
+		}
+		targetID := ""
+		if r.apply {
+			created, err := svc.Tasklists.Insert(&tasks.TaskList{Title: list.Title}).Context(ctx).Do()
+			if err != nil {
+				return fmt.Errorf("create task list %q: %w", list.Title, err)
+			}
+			targetID = created.Id
+		}
+		listIDs[list.Id] = targetID
+		listByTitle[list.Title] = targetID
+		r.record(backupRestoreItem{Service: backupServiceTasks, Kind: "lists", Action: backupRestoreCreate, Name: list.Title})
+	}
+
+	existingTasks, err := fetchBackupTasks(ctx, svc, targetLists)
+	if err != nil {
+		return err
+	}
+	existing := map[string]string{}
+	for _, row := range existingTasks {
+		if row.Task != nil && !row.Task.Deleted {
+			existing[backupRestoreTaskKey(row.TaskListID, row.Task)] = row.Task.Id
+		}
+	}
+	taskIDs := map[string]string{}
+	for _, row := range orderBackupRestoreTasks(rows) {
+		task := row.Task
+		switch {
+		case task.Deleted:
+			r.count(backupServiceTasks, "tasks", backupRestoreSkip)
+			continue
+		case task.AssignmentInfo != nil:
+			r.record(backupRestoreItem{Service: backupServiceTasks, Kind: "tasks", Action: backupRestoreSkip, Name: task.Title, Reason: "assigned tasks are owned by Docs or Chat"})
+			continue
+		}
+		listID, ok := listIDs[row.TaskListID]
+		if !ok {
+			r.record(backupRestoreItem{Service: backupServiceTasks, Kind: "tasks", Action: backupRestoreSkip, Name: task.Title, Reason: "task list missing from backup"})
+			continue
+		}
+		key := backupRestoreTaskKey(listID, task)
+		if existingID, ok := existing[key]; ok && listID != "" {
+			taskIDs[task.Id] = existingID
+			r.record(backupRestoreItem{Service: backupServiceTasks, Kind: "tasks", Action: backupRestoreSkip, Name: task.Title, Reason: "exists"})
+			continue
+		}
+		if r.apply {
+			call := svc.Tasks.Insert(listID, &tasks.Task{
+				Title:     task.Title,
+				Notes:     task.Notes,
+				Due:       task.Due,
+				Status:    task.Status,
+				Completed: task.Completed,
+			}).Context(ctx)
+			if parentID := taskIDs[task.Parent]; task.Parent != "" && parentID != "" {
+				call = call.Parent(parentID)
+			}
+			created, err := call.Do()
+			if err != nil {
+				return fmt.Errorf("create task %q: %w", task.Title, err)
+			}
+			taskIDs[task.Id] = created.Id
+			existing[key] = created.Id
+		}
+		r.record(backupRestoreItem{Service: backupServiceTasks, Kind: "tasks", Action: backupRestoreCreate, Name: task.Title})
+	}
+	return nil
+}
+
+func backupRestoreTaskKey(listID string, task *tasks.Task) string {
+	return listID + "\x00" + strings.TrimSpace(task.Title) + "\x00" + strings.TrimSpace(task.Due)
+}
+
+// orderBackupRestoreTasks puts parents before subtasks and inserts each level
+// in reverse position order, because Tasks.Insert places new tasks first.
+func orderBackupRestoreTasks(rows []tasksBackupTask) []tasksBackupTask {
+	out := make([]tasksBackupTask, 0, len(rows))
+	for _, row := range rows {
+		if row.Task != nil {
+			out = append(out, row)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		left, right := out[i].Task, out[j].Task
+		if (left.Parent == "") != (right.Parent == "") {
+			return left.Parent == ""
+		}
+		if out[i].TaskListID != out[j].TaskListID {
+			return out[i].TaskListID < out[j].TaskListID
+		}
+		return left.Position > right.Position
+	})
+	return out
+}
+
+func (r *backupRestorer) restoreCalendar(ctx context.Context, svc *calendar.Service, calendars []*calendar.CalendarListEntry, rows []calendarBackupEvent) error {
+	calendarIDs, err := r.restoreCalendars(ctx, svc, calendars)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		event := row.Event
+		if event == nil || event.Status == "cancelled" {
+			r.count(backupServiceCalendar, "events", backupRestoreSkip)
+			continue
+		}
+		name := strings.TrimSpace(event.Summary)
+		if name == "" {
+			name = event.ICalUID
+		}
+		skip := func(reason string) {
+			r.record(backupRestoreItem{Service: backupServiceCalendar, Kind: "events", Action: backupRestoreSkip, Name: name, Reason: reason})
+		}
+		calendarID, ok := calendarIDs[row.CalendarID]
+		switch {
+		case !ok:
+			skip("calendar not available in target account")
+			continue
+		case strings.TrimSpace(event.ICalUID) == "":
+			skip("event has no iCalUID")
+			continue
+		case event.RecurringEventId != "":
+			skip("recurring instance exceptions are restored with their series only")
+			continue
+		case event.EventType != "" && event.EventType != eventTypeDefault:
+			skip(fmt.Sprintf("%s events cannot be imported", event.EventType))
+			continue
+		}
+		if calendarID != "" {
+			found, err := svc.Events.List(calendarID).ICalUID(event.ICalUID).ShowDeleted(false).MaxResults(1).Context(ctx).Do()
+			if err != nil {
+				return fmt.Errorf("look up event %q: %w", name, err)
+			}
+			if len(found.Items) > 0 {
+				skip("exists")
+				continue
+			}
+		}
+		if r.apply {
+			if _, err := svc.Events.Import(calendarID, backupRestoreEvent(event)).
+				ConferenceDataVersion(1).
+				SupportsAttachments(true).
+				Context(ctx).
+				Do(); err != nil {
+				return fmt.Errorf("import event %q: %w", name, err)
+			}
+		}
+		r.record(backupRestoreItem{Service: backupServiceCalendar, Kind: "events", Action: backupRestoreCreate, Name: name})
+	}
+	return nil
+}
+
+// restoreCalendars maps backup calendar IDs to target calendar IDs, creating
+// owned secondary calendars that no longer exist. An empty target ID marks a
+// calendar that would be created by a dry run.
+func (r *backupRestorer) restoreCalendars(ctx context.Context, svc *calendar.Service, calendars []*calendar.CalendarListEntry) (map[string]string, error) {
+	target, err := fetchBackupCalendars(ctx, svc)
+	if err != nil {
+		return nil, err
+	}
+	byID := map[string]bool{}
+	bySummary := map[string]string{}
+	for _, entry := range target {
+		if entry == nil {
+			continue
+		}
+		byID[entry.Id] = true
+		if entry.AccessRole == "owner" {
+			if _, ok := bySummary[entry.Summary]; !ok {
+				bySummary[entry.Summary] = entry.Id
+			}
+		}
+	}
+	out := map[string]string{}
+	for _, entry := range calendars {
+		if entry == nil || strings.TrimSpace(entry.Id) == "" {
+			continue
+		}
+		switch {
+		case entry.Primary:
+			out[entry.Id] = "primary"
+		case byID[entry.Id]:
+			out[entry.Id] = entry.Id
+		case bySummary[entry.Summary] != "":
+			out[entry.Id] = bySummary[entry.Summary]
+		case entry.AccessRole == "owner":
+			targetID := ""
+			if r.apply {
+				created, err := svc.Calendars.Insert(&calendar.Calendar{
+					Summary:     entry.Summary,
+					Description: entry.Description,
+					Location:    entry.Location,
+					TimeZone:    entry.TimeZone,
+				}).Context(ctx).Do()
+				if err != nil {
+					return nil, fmt.Errorf("create calendar %q: %w", entry.Summary, err)
+				}
+				targetID = created.Id
+			}
+			out[entry.Id] = targetID
+			r.record(backupRestoreItem{Service: backupServiceCalendar, Kind: "calendars", Action: backupRestoreCreate, Name: entry.Summary})
+			continue
+		default:
+			r.record(backupRestoreItem{Service: backupServiceCalendar, Kind: "calendars", Action: backupRestoreSkip, Name: entry.Summary, Reason: "not owned and not subscribed in target account"})
+			continue
+		}
+		r.record(backupRestoreItem{Service: backupServiceCalendar, Kind: "calendars", Action: backupRestoreSkip, Name: entry.Summary, Reason: "exists"})
+	}
+	return out, nil
+}
+
+func backupRestoreEvent(event *calendar.Event) *calendar.Event {
+	out := *event
+	out.Id = ""
+	out.Etag = ""
+	out.HtmlLink = ""
+	out.HangoutLink = ""
+	out.Created = ""
+	out.Updated = ""
+	out.Creator = nil
+	if out.ConferenceData != nil {
+		conference := *out.ConferenceData
+		conference.CreateRequest = nil
+		out.ConferenceData = &conference
+	}
+	return &out
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/tasks/v1"
+
+	"github.com/steipete/gogcli/internal/backup"
+)
+
+func TestParseBackupRestoreServices(t *testing.T) {
+	got, err := parseBackupRestoreServices("gmail, tasks,all")
+	if err != nil {
+		t.Fatalf("parseBackupRestoreServices: %v", err)
+	}
+	want := []string{"contacts", "tasks", "calendar", "gmail-settings", "gmail"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("services = %v, want %v", got, want)
+	}
+	if _, err := parseBackupRestoreServices("drive"); err == nil || !strings.Contains(err.Error(), "unsupported restore service") {
+		t.Fatalf("expected unsupported service error, got %v", err)
+	}
+}
+
+func TestBackupRestoreSourceAccount(t *testing.T) {
+	if got, want := backupRestoreSourceAccount("", "A@B.com"), backupAccountHash("a@b.com"); got != want {
+		t.Fatalf("default source = %q, want %q", got, want)
+	}
+	if got, want := backupRestoreSourceAccount("old@b.com", "a@b.com"), backupAccountHash("old@b.com"); got != want {
+		t.Fatalf("email source = %q, want %q", got, want)
+	}
+	if got := backupRestoreSourceAccount("ABCDEF", "a@b.com"); got != "abcdef" {
+		t.Fatalf("hash source = %q", got)
+	}
+}
+
+func TestBackupRestoreTasksCreatesMissingListsAndTasks(t *testing.T) {
+	repo, config := pushBackupRestoreTasksForTest(t, "a@b.com")
+
+	var posts []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/tasks/v1/users/@me/lists" && r.Method == http.MethodGet:
+			_ = json.NewEncoder(w).Encode(map[string]any{"items": []map[string]any{{"id": "l1", "title": "Inbox"}}})
+		case r.URL.Path == "/tasks/v1/lists/l1/tasks" && r.Method == http.MethodGet:
+			_ = json.NewEncoder(w).Encode(map[string]any{"items": []map[string]any{{"id": "t1", "title": "Existing"}}})
+		case r.URL.Path == "/tasks/v1/users/@me/lists" && r.Method == http.MethodPost:
+			posts = append(posts, "list")
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "l2", "title": "Groceries"})
+		case r.URL.Path == "/tasks/v1/lists/l2/tasks" && r.Method == http.MethodPost:
+			var body tasks.Task
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			posts = append(posts, "task:"+body.Title+":"+r.URL.Query().Get("parent"))
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "new-" + body.Title, "title": body.Title})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	var stdout bytes.Buffer
+	ctx := withTasksTestService(newCmdRuntimeJSONOutputContext(t, &stdout, io.Discard), newTasksServiceFromServer(t, srv))
+	err := (&BackupRestoreCmd{
+		backupReadFlags: backupReadFlags{Config: config, Repo: repo, NoPull: true},
+		Services:        "tasks",
+	}).Run(ctx, &RootFlags{Account: "a@b.com"})
+	if err != nil {
+		t.Fatalf("BackupRestoreCmd.Run: %v", err)
+	}
+	if want := []string{"list", "task:Milk:", "task:Oat milk:new-Milk"}; !reflect.DeepEqual(posts, want) {
+		t.Fatalf("posts = %v, want %v", posts, want)
+	}
+	var result backupRestoreResult
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		t.Fatalf("decode result: %v\n%s", err, stdout.String())
+	}
+	wantCounts := map[string]int{
+		"tasks.lists.create": 1,
+		"tasks.lists.skip":   1,
+		"tasks.tasks.create": 2,
+		"tasks.tasks.skip":   2,
+	}
+	if !result.Applied || !reflect.DeepEqual(result.Counts, wantCounts) {
+		t.Fatalf("result = %+v, want counts %v", result, wantCounts)
+	}
+}
+
+func TestBackupRestoreDryRunDoesNotWrite(t *testing.T) {
+	repo, config := pushBackupRestoreTasksForTest(t, "a@b.com")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("dry-run issued %s %s", r.Method, r.URL.Path)
+			http.Error(w, "unexpected write", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"items": []map[string]any{}})
+	}))
+	defer srv.Close()
+
+	var stdout bytes.Buffer
+	ctx := withTasksTestService(newCmdRuntimeJSONOutputContext(t, &stdout, io.Discard), newTasksServiceFromServer(t, srv))
+	err := (&BackupRestoreCmd{
+		backupReadFlags: backupReadFlags{Config: config, Repo: repo, NoPull: true},
+		Services:        "tasks",
+	}).Run(ctx, &RootFlags{Account: "a@b.com", DryRun: true})
+	if ExitCode(err) != 0 {
+		t.Fatalf("dry-run error: %v", err)
+	}
+	for _, want := range []string{`"op": "backup.restore"`, `"tasks.lists.create": 2`, `"tasks.tasks.create": 3`} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Fatalf("dry-run output missing %q:\n%s", want, stdout.String())
+		}
+	}
+}
+
+func TestBackupRestoreRejectsUnknownSourceAccount(t *testing.T) {
+	repo, config := pushBackupRestoreTasksForTest(t, "a@b.com")
+	err := (&BackupRestoreCmd{
+		backupReadFlags: backupReadFlags{Config: config, Repo: repo, NoPull: true},
+		Services:        "tasks",
+	}).Run(newCmdRuntimeJSONOutputContext(t, io.Discard, io.Discard), &RootFlags{Account: "other@b.com"})
+	if err == nil || !strings.Contains(err.Error(), "--from-account") {
+		t.Fatalf("expected --from-account hint, got %v", err)
+	}
+}
+
+func pushBackupRestoreTasksForTest(t *testing.T, account string) (string, string) {
+	t.Helper()
+	repo, config, recipients := newBackupConfigForCmdTest(t)
+	hash := backupAccountHash(account)
+	lists, err := backup.NewJSONLShard(backupServiceTasks, "lists", hash, "data/tasks/"+hash+"/lists.jsonl.gz.age", []*tasks.TaskList{
+		{Id: "old-inbox", Title: "Inbox"},
+		{Id: "old-groceries", Title: "Groceries"},
+	})
+	if err != nil {
+		t.Fatalf("NewJSONLShard lists: %v", err)
+	}
+	rows, err := backup.NewJSONLShard(backupServiceTasks, "tasks", hash, "data/tasks/"+hash+"/tasks.jsonl.gz.age", []tasksBackupTask{
+		{TaskListID: "old-inbox", Task: &tasks.Task{Id: "a", Title: "Existing"}},
+		{TaskListID: "old-groceries", Task: &tasks.Task{Id: "c", Title: "Oat milk", Parent: "b"}},
+		{TaskListID: "old-groceries", Task: &tasks.Task{Id: "b", Title: "Milk"}},
+		{TaskListID: "old-groceries", Task: &tasks.Task{Id: "d", Title: "Gone", Deleted: true}},
+	})
+	if err != nil {
+		t.Fatalf("NewJSONLShard tasks: %v", err)
+	}
+	if _, err := backup.PushSnapshot(t.Context(), backup.Snapshot{
+		Services: []string{backupServiceTasks},
+		Accounts: []string{hash},
+		Counts:   map[string]int{"tasks.lists": 2, "tasks.tasks": 4},
+		Shards:   []backup.PlainShard{lists, rows},
+	}, backupOptionsForCmdTest(t, backup.Options{ConfigPath: config, Recipients: recipients})); err != nil {
+		t.Fatalf("PushSnapshot: %v", err)
+	}
+	return repo, config
+}
@@ -20,7 +20,13 @@ type CalendarCmd struct {
 	ProposeTime     CalendarProposeTimeCmd     `cmd:"" name:"propose-time" help:"Generate URL to propose a new meeting time (browser-only feature)"`
 	Colors          CalendarColorsCmd          `cmd:"" name:"colors" help:"Show calendar colors"`
 	Conflicts       CalendarConflictsCmd       `cmd:"" name:"conflicts" help:"Find busy-time overlaps across calendars"`
+	FindSlot        CalendarFindSlotCmd        `cmd:"" name:"find-slot" aliases:"slot" help:"Find a free meeting slot across attendees (and optionally book it)"`
 	Search          CalendarSearchCmd          `cmd:"" name:"search" aliases:"find,query" help:"Search events"`
+	Export          CalendarExportCmd          `cmd:"" name:"export" help:"Export events as iCalendar (.ics)"`
+	Import          CalendarImportCmd          `cmd:"" name:"import" help:"Import an iCalendar (.ics) file, creating or updating events by iCalUID"`
+	Mirror          CalendarMirrorCmd          `cmd:"" name:"mirror" help:"Mirror busy time between calendars as private placeholder events"`
+	Stats           CalendarStatsCmd           `cmd:"" name:"stats" aliases:"analytics" help:"Summarize accepted time by color, event type, attendee domain, recurrence, and day"`
+	Watch           CalendarWatchCmd           `cmd:"" name:"watch" help:"Watch a calendar for event changes (push channels and sync-token polling)"`
 	Time            CalendarTimeCmd            `cmd:"" name:"time" help:"Show server time"`
 	Users           CalendarUsersCmd           `cmd:"" name:"users" help:"List workspace users (use their email as calendar ID)"`
 	Team            CalendarTeamCmd            `cmd:"" name:"team" help:"Show events for Workspace group members (service account, direct token, or ADC)"`
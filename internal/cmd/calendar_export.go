@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+
+	"github.com/steipete/gogcli/internal/icalendar"
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+type CalendarExportCmd struct {
+	CalendarID string `arg:"" optional:"" name:"calendarId" help:"Calendar ID (default: primary)"`
+	From       string `name:"from" help:"Only events ending after this time (RFC3339, date, or relative; default: everything)"`
+	To         string `name:"to" help:"Only events starting before this time (RFC3339, date, or relative; default: everything)"`
+	Format     string `name:"format" help:"Output format: ics (iCalendar) or json (raw API events)" enum:"ics,json" default:"ics"`
+	ICS        bool   `name:"ics" help:"Shorthand for --format ics"`
+	Out        string `name:"out" short:"o" help:"Output path (.ics), or - for stdout" default:"-"`
+}
+
+func (c *CalendarExportCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	format := c.Format
+	if c.ICS {
+		format = "ics"
+	}
+	outPath := strings.TrimSpace(c.Out)
+	if outPath == "" {
+		outPath = stdoutPath
+	}
+	if dryRunErr := dryRunExit(ctx, flags, "calendar.export", map[string]any{
+		"calendar_id": strings.TrimSpace(c.CalendarID),
+		"from":        strings.TrimSpace(c.From),
+		"to":          strings.TrimSpace(c.To),
+		"format":      format,
+		"out":         outPath,
+	}); dryRunErr != nil {
+		return dryRunErr
+	}
+
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	store, err := commandConfigStore(ctx)
+	if err != nil {
+		return err
+	}
+	svc, err := calendarService(ctx, account)
+	if err != nil {
+		return err
+	}
+	calendarID, err := resolveCalendarSelector(ctx, store, svc, c.CalendarID, true)
+	if err != nil {
+		return err
+	}
+	cal, err := svc.Calendars.Get(calendarID).Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+	loc := time.UTC
+	if cal.TimeZone != "" {
+		if loc, err = loadTimezoneLocation(cal.TimeZone); err != nil {
+			return err
+		}
+	}
+	timeMin, timeMax, err := calendarExportBounds(c.From, c.To, loc)
+	if err != nil {
+		return err
+	}
+
+	// Series come back once with their RRULE; ShowDeleted surfaces cancelled
+	// instances so they can be written as EXDATEs.
+	events, _, err := loadPagedItems("", true, func(pageToken string) ([]*calendar.Event, string, error) {
+		call := svc.Events.List(calendarID).SingleEvents(false).ShowDeleted(true).MaxResults(2500).Context(ctx)
+		if timeMin != "" {
+			call = call.TimeMin(timeMin)
+		}
+		if timeMax != "" {
+			call = call.TimeMax(timeMax)
+		}
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+		resp, listErr := call.Do()
+		if listErr != nil {
+			return nil, "", listErr
+		}
+		return resp.Items, resp.NextPageToken, nil
+	})
+	if err != nil {
+		return err
+	}
+	redactCalendarEventsForOutput(ctx, events)
+
+	var buf bytes.Buffer
+	if format == "json" {
+		if err = outfmt.WriteJSON(ctx, &buf, map[string]any{"calendar": cal, "events": events}); err != nil {
+			return err
+		}
+	} else if err = icalendar.Encode(&buf, icalendar.Calendar{Name: cal.Summary, TimeZone: cal.TimeZone, Events: events}); err != nil {
+		return err
+	}
+	if isStdoutPath(outPath) {
+		_, err = stdoutWriter(ctx).Write(buf.Bytes())
+		return err
+	}
+	if outPath, err = expandUserPath(outPath); err != nil {
+		return err
+	}
+	if err := os.WriteFile(outPath, buf.Bytes(), 0o600); err != nil {
+		return err
+	}
+	exported := 0
+	for _, event := range events {
+		if event.Status != "cancelled" {
+			exported++
+		}
+	}
+	u.Err().Linef("Exported %d event%s to %s", exported, pluralS(exported), outPath)
+	return nil
+}
+
+func calendarExportBounds(from, to string, loc *time.Location) (string, string, error) {
+	now := time.Now().In(loc)
+	var timeMin, timeMax string
+	if from = strings.TrimSpace(from); from != "" {
+		t, err := parseTimeExpr(from, now, loc)
+		if err != nil {
+			return "", "", usagef("invalid --from: %v", err)
+		}
+		timeMin = t.Format(time.RFC3339)
+	}
+	if to = strings.TrimSpace(to); to != "" {
+		t, err := parseTimeExprEndOfDay(to, now, loc)
+		if err != nil {
+			return "", "", usagef("invalid --to: %v", err)
+		}
+		timeMax = t.Format(time.RFC3339)
+	}
+	return timeMin, timeMax, nil
+}
@@ -0,0 +1,325 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/timeparse"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+type CalendarFindSlotCmd struct {
+	Attendees     []string      `name:"attendee" help:"Attendee email (repeatable or comma-separated; you are always included)" required:""`
+	Duration      time.Duration `name:"duration" help:"Meeting length (e.g. 30m, 1h)" default:"30m"`
+	Within        string        `name:"within" help:"Search window: today, tomorrow, this week, next week, next N days, next N workdays" default:"next 5 workdays"`
+	From          string        `name:"from" help:"Window start (RFC3339, date, or relative; overrides --within)"`
+	To            string        `name:"to" help:"Window end (RFC3339, date, or relative; overrides --within)"`
+	WorkingHours  string        `name:"working-hours" help:"Working hours in each attendee's time zone" default:"09:00-17:00"`
+	AttendeeHours []string      `name:"attendee-hours" help:"Working hours for one attendee, email=HH:MM-HH:MM (repeatable)"`
+	AttendeeTZ    []string      `name:"attendee-tz" help:"Time zone for one attendee, email=Zone (repeatable; default: their calendar's zone when shared, else yours)"`
+	Workdays      string        `name:"workdays" help:"Working days (e.g. mon-fri, sun-thu, mon,tue,thu)" default:"mon-fri"`
+	Buffer        time.Duration `name:"buffer" help:"Free time to keep before and after existing meetings (e.g. 10m)" default:"0s"`
+	Step          time.Duration `name:"step" help:"Granularity of candidate start times" default:"15m"`
+	InPerson      bool          `name:"in-person" help:"Only offer slots where every attendee works from the same office"`
+	Max           int           `name:"max" aliases:"limit" help:"Number of candidates to show" default:"5"`
+	Book          bool          `name:"book" help:"Create the event in the chosen slot and invite the attendees"`
+	Choose        int           `name:"choose" help:"Candidate rank to book with --book" default:"1"`
+	Summary       string        `name:"summary" help:"Event title for --book" default:"Meeting"`
+	Description   string        `name:"description" help:"Event description for --book"`
+	WithMeet      bool          `name:"with-meet" help:"Add a Google Meet link when booking"`
+	SendUpdates   string        `name:"send-updates" help:"Notification mode when booking: all, externalOnly, none" default:"all"`
+	Calendar      string        `name:"calendar" aliases:"cal" help:"Calendar to book into (default: primary)"`
+}
+
+func (c *CalendarFindSlotCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	if c.Duration <= 0 || c.Step <= 0 || c.Buffer < 0 {
+		return usage("--duration and --step must be > 0 and --buffer must be >= 0")
+	}
+	if c.Max <= 0 {
+		return usage("max must be > 0")
+	}
+	if c.Book && (c.Choose < 1 || c.Choose > c.Max) {
+		return usagef("--choose must be between 1 and --max (%d)", c.Max)
+	}
+	sendUpdates, err := validateSendUpdates(c.SendUpdates)
+	if err != nil {
+		return err
+	}
+	workday, err := parseWorkdays(c.Workdays)
+	if err != nil {
+		return usagef("invalid --workdays: %v", err)
+	}
+	hoursStart, hoursEnd, err := parseWorkingHours(c.WorkingHours)
+	if err != nil {
+		return usagef("invalid --working-hours: %v", err)
+	}
+	hoursOverrides, err := parseEmailAssignments("--attendee-hours", c.AttendeeHours)
+	if err != nil {
+		return err
+	}
+	tzOverrides, err := parseEmailAssignments("--attendee-tz", c.AttendeeTZ)
+	if err != nil {
+		return err
+	}
+
+	account, svc, err := requireCalendarService(ctx, flags)
+	if err != nil {
+		return err
+	}
+	loc, err := getUserTimezone(ctx, svc)
+	if err != nil {
+		return err
+	}
+	from, to, err := c.window(loc, workday)
+	if err != nil {
+		return err
+	}
+
+	emails := []string{strings.ToLower(strings.TrimSpace(account))}
+	for _, email := range splitCommaValues(c.Attendees) {
+		email = strings.ToLower(email)
+		if !slices.Contains(emails, email) {
+			emails = append(emails, email)
+		}
+	}
+	if len(emails) < 2 {
+		return usage("at least one --attendee other than yourself is required")
+	}
+	for email := range hoursOverrides {
+		if !slices.Contains(emails, email) {
+			return usagef("--attendee-hours: %s is not an attendee", email)
+		}
+	}
+	for email := range tzOverrides {
+		if !slices.Contains(emails, email) {
+			return usagef("--attendee-tz: %s is not an attendee", email)
+		}
+	}
+
+	attendees := make([]*slotAttendee, 0, len(emails))
+	for i, email := range emails {
+		a := &slotAttendee{Email: email, hoursStart: hoursStart, hoursEnd: hoursEnd, WorkingHours: c.WorkingHours, FreeBusy: "ok"}
+		if hours, ok := hoursOverrides[email]; ok {
+			if a.hoursStart, a.hoursEnd, err = parseWorkingHours(hours); err != nil {
+				return usagef("invalid --attendee-hours for %s: %v", email, err)
+			}
+			a.WorkingHours = hours
+		}
+		if err := resolveSlotAttendeeZone(ctx, svc, a, tzOverrides[email], i == 0, loc); err != nil {
+			return err
+		}
+		a.places = fetchSlotPlaces(ctx, svc, email, a.loc, from, to)
+		attendees = append(attendees, a)
+	}
+	if err := fetchSlotBusy(ctx, svc, attendees, from, to); err != nil {
+		return err
+	}
+	for _, a := range attendees {
+		if a.FreeBusy != "ok" {
+			u.Err().Linef("Warning: busy times for %s unavailable (%s); treating as free", a.Email, a.FreeBusy)
+		}
+	}
+
+	candidates := findSlots(slotSearch{
+		from: from, to: to, duration: c.Duration, buffer: c.Buffer, step: c.Step,
+		workday: workday, inPerson: c.InPerson, max: c.Max, loc: loc,
+	}, attendees)
+
+	if !c.Book {
+		if outfmt.IsJSON(ctx) {
+			return outfmt.WriteJSON(ctx, stdoutWriter(ctx), map[string]any{
+				"window":     map[string]string{"from": from.Format(time.RFC3339), "to": to.Format(time.RFC3339)},
+				"duration":   c.Duration.String(),
+				"attendees":  attendees,
+				"candidates": candidates,
+			})
+		}
+		if len(candidates) == 0 {
+			u.Err().Println("No free slot found; try a longer --within, shorter --duration, or smaller --buffer")
+			return nil
+		}
+		return outfmt.WriteTable(ctx, stdoutWriter(ctx), candidates, slotCandidateColumns(attendees))
+	}
+
+	if c.Choose > len(candidates) {
+		return fmt.Errorf("no candidate %d: found %d free slot%s", c.Choose, len(candidates), pluralS(len(candidates)))
+	}
+	chosen := candidates[c.Choose-1]
+	event := &calendar.Event{
+		Summary:     strings.TrimSpace(c.Summary),
+		Description: c.Description,
+		Start:       &calendar.EventDateTime{DateTime: chosen.Start, TimeZone: loc.String()},
+		End:         &calendar.EventDateTime{DateTime: chosen.End, TimeZone: loc.String()},
+	}
+	for _, email := range emails[1:] {
+		event.Attendees = append(event.Attendees, &calendar.EventAttendee{Email: email})
+	}
+	if c.WithMeet {
+		event.ConferenceData = buildMeetConferenceData()
+	}
+	calendarID := strings.TrimSpace(c.Calendar)
+	if calendarID == "" {
+		calendarID = primaryCalendarID
+	}
+	if dryRunErr := dryRunExit(ctx, flags, "calendar.find-slot.book", map[string]any{
+		"calendar_id":  calendarID,
+		"candidate":    chosen,
+		"event":        event,
+		"send_updates": sendUpdates,
+	}); dryRunErr != nil {
+		return dryRunErr
+	}
+	mutation, err := newCalendarMutationContext(ctx, flags, calendarID)
+	if err != nil {
+		return err
+	}
+	created, err := mutation.insertEvent(ctx, event, calendarInsertOptions{
+		sendUpdates:        sendUpdates,
+		conferenceVersion1: c.WithMeet,
+	})
+	if err != nil {
+		return err
+	}
+	u.Err().Linef("Booked candidate %d: %s - %s", chosen.Rank, chosen.Start, chosen.End)
+	return mutation.writeEvent(ctx, created)
+}
+
+func (c *CalendarFindSlotCmd) window(loc *time.Location, workday func(time.Weekday) bool) (time.Time, time.Time, error) {
+	now := time.Now().In(loc)
+	var from, to time.Time
+	var err error
+	if strings.TrimSpace(c.From) != "" || strings.TrimSpace(c.To) != "" {
+		if strings.TrimSpace(c.From) == "" || strings.TrimSpace(c.To) == "" {
+			return time.Time{}, time.Time{}, usage("--from and --to must be used together")
+		}
+		if from, err = parseTimeExpr(c.From, now, loc); err != nil {
+			return time.Time{}, time.Time{}, usagef("invalid --from: %v", err)
+		}
+		if to, err = parseTimeExprEndOfDay(c.To, now, loc); err != nil {
+			return time.Time{}, time.Time{}, usagef("invalid --to: %v", err)
+		}
+	} else if from, to, err = timeparse.ParseWindow(c.Within, now, loc, workday); err != nil {
+		return time.Time{}, time.Time{}, usagef("invalid --within: %v", err)
+	}
+	if from.Before(now) {
+		from = now
+	}
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, usage("search window is empty or in the past")
+	}
+	return from, to, nil
+}
+
+// parseEmailAssignments parses repeated email=value flags.
+func parseEmailAssignments(flag string, values []string) (map[string]string, error) {
+	out := map[string]string{}
+	for _, raw := range values {
+		email, value, ok := strings.Cut(raw, "=")
+		email, value = strings.ToLower(strings.TrimSpace(email)), strings.TrimSpace(value)
+		if !ok || email == "" || value == "" {
+			return nil, usagef("invalid %s %q (expected email=value)", flag, raw)
+		}
+		out[email] = value
+	}
+	return out, nil
+}
+
+// resolveSlotAttendeeZone picks an attendee's time zone: the explicit flag,
+// then their calendar's zone when it is shared with you, then yours.
+func resolveSlotAttendeeZone(ctx context.Context, svc *calendar.Service, a *slotAttendee, override string, self bool, fallback *time.Location) error {
+	if override != "" {
+		loc, err := loadTimezoneLocation(override)
+		if err != nil {
+			return usagef("invalid --attendee-tz for %s: %v", a.Email, err)
+		}
+		a.loc, a.TimeZone, a.TimeZoneSource = loc, loc.String(), "flag"
+		return nil
+	}
+	if self {
+		a.loc, a.TimeZone, a.TimeZoneSource = fallback, fallback.String(), "calendar"
+		return nil
+	}
+	if cal, err := svc.Calendars.Get(a.Email).Context(ctx).Do(); err == nil && cal.TimeZone != "" {
+		if loc, loadErr := loadTimezoneLocation(cal.TimeZone); loadErr == nil {
+			a.loc, a.TimeZone, a.TimeZoneSource = loc, cal.TimeZone, "calendar"
+			return nil
+		}
+	}
+	a.loc, a.TimeZone, a.TimeZoneSource = fallback, fallback.String(), "assumed"
+	return nil
+}
+
+// fetchSlotPlaces reads an attendee's working-location events. Calendars
+// that are not shared with you simply have no known location.
+func fetchSlotPlaces(ctx context.Context, svc *calendar.Service, email string, loc *time.Location, from, to time.Time) []slotPlace {
+	resp, err := svc.Events.List(email).EventTypes(eventTypeWorkingLocation).SingleEvents(true).
+		TimeMin(from.Format(time.RFC3339)).TimeMax(to.Format(time.RFC3339)).MaxResults(250).Context(ctx).Do()
+	if err != nil {
+		return nil
+	}
+	return slotPlacesFromEvents(resp.Items, loc)
+}
+
+func fetchSlotBusy(ctx context.Context, svc *calendar.Service, attendees []*slotAttendee, from, to time.Time) error {
+	items := make([]*calendar.FreeBusyRequestItem, 0, len(attendees))
+	for _, a := range attendees {
+		items = append(items, &calendar.FreeBusyRequestItem{Id: a.Email})
+	}
+	resp, err := svc.Freebusy.Query(&calendar.FreeBusyRequest{
+		TimeMin: from.Format(time.RFC3339),
+		TimeMax: to.Format(time.RFC3339),
+		Items:   items,
+	}).Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+	for _, a := range attendees {
+		data, ok := resp.Calendars[a.Email]
+		if !ok {
+			a.FreeBusy = "missing"
+			continue
+		}
+		if len(data.Errors) > 0 && data.Errors[0] != nil {
+			a.FreeBusy = data.Errors[0].Reason
+			continue
+		}
+		for _, period := range data.Busy {
+			if period == nil {
+				continue
+			}
+			start, err1 := time.Parse(time.RFC3339, period.Start)
+			end, err2 := time.Parse(time.RFC3339, period.End)
+			if err1 == nil && err2 == nil {
+				a.busy = append(a.busy, slotSpan{start: start, end: end})
+			}
+		}
+	}
+	return nil
+}
+
+func slotCandidateColumns(attendees []*slotAttendee) []outfmt.Column[*slotCandidate] {
+	columns := []outfmt.Column[*slotCandidate]{
+		{Header: "RANK", Value: func(c *slotCandidate) string { return fmt.Sprintf("%d", c.Rank) }},
+		{Header: "START", Value: func(c *slotCandidate) string { return c.Start }},
+		{Header: "END", Value: func(c *slotCandidate) string { return c.End }},
+		{Header: "COMFORT", Value: func(c *slotCandidate) string { return fmt.Sprintf("%dm", c.ComfortMinutes) }},
+	}
+	for _, a := range attendees[1:] {
+		email := a.Email
+		columns = append(columns, outfmt.Column[*slotCandidate]{
+			Header: strings.ToUpper(strings.SplitN(email, "@", 2)[0]),
+			Value:  func(c *slotCandidate) string { return c.Local[email] },
+		})
+	}
+	return append(columns, outfmt.Column[*slotCandidate]{
+		Header: "OFFICE",
+		Value:  func(c *slotCandidate) string { return c.SameOffice },
+	})
+}
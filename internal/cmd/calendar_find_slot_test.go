@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+func TestFindSlotsRanksAcrossZones(t *testing.T) {
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	newYork, _ := time.LoadLocation("America/New_York")
+	from := time.Date(2030, 1, 7, 0, 0, 0, 0, time.UTC) // Monday
+	attendees := []*slotAttendee{
+		{Email: "ann@example.com", loc: berlin, hoursStart: 9 * 60, hoursEnd: 18 * 60},
+		{Email: "bob@example.com", loc: newYork, hoursStart: 9 * 60, hoursEnd: 17 * 60, busy: []slotSpan{
+			{start: time.Date(2030, 1, 7, 14, 0, 0, 0, time.UTC), end: time.Date(2030, 1, 7, 15, 0, 0, 0, time.UTC)},
+		}},
+	}
+	got := findSlots(slotSearch{
+		from: from, to: from.AddDate(0, 0, 2), duration: 30 * time.Minute, buffer: 15 * time.Minute, step: 15 * time.Minute,
+		workday: func(d time.Weekday) bool { return d != time.Saturday && d != time.Sunday }, max: 3, loc: time.UTC,
+	}, attendees)
+
+	// Overlap is 14:00-17:00 UTC; Monday loses 14:00-15:15 to Bob's meeting
+	// plus buffer, and nothing inside an hour of Ann's 18:00 end is comfortable.
+	if len(got) != 3 {
+		t.Fatalf("got %d candidates", len(got))
+	}
+	if got[0].Start != "2030-01-07T15:15:00Z" || got[0].ComfortMinutes != 60 {
+		t.Fatalf("first = %+v", got[0])
+	}
+	if got[1].Start != "2030-01-08T15:00:00Z" {
+		t.Fatalf("second should be the next day, got %+v", got[1])
+	}
+	if got[0].Local["bob@example.com"] != "Mon 10:15-10:45 EST" {
+		t.Fatalf("local = %v", got[0].Local)
+	}
+}
+
+func TestCalendarFindSlotBooks(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		inserted *calendar.Event
+		query    string
+	)
+	svc, cleanup := newCalendarServiceForTest(t, withPrimaryCalendar(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/calendars/bob@example.com" && r.Method == http.MethodGet:
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "bob@example.com", "timeZone": "America/New_York"})
+		case strings.HasSuffix(r.URL.Path, "/events") && r.Method == http.MethodGet:
+			if r.URL.Query().Get("eventTypes") != "workingLocation" {
+				t.Errorf("unexpected list query: %s", r.URL.RawQuery)
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"items": []map[string]any{{
+				"id": "wl", "eventType": "workingLocation",
+				"start":                     map[string]any{"date": "2030-01-07"},
+				"end":                       map[string]any{"date": "2030-01-08"},
+				"workingLocationProperties": map[string]any{"type": "officeLocation", "officeLocation": map[string]any{"label": "HQ"}},
+			}}})
+		case r.URL.Path == "/freeBusy" && r.Method == http.MethodPost:
+			_ = json.NewEncoder(w).Encode(map[string]any{"calendars": map[string]any{
+				"a@b.com":         map[string]any{"busy": []any{}},
+				"bob@example.com": map[string]any{"busy": []map[string]any{{"start": "2030-01-07T14:00:00Z", "end": "2030-01-07T15:00:00Z"}}},
+			}})
+		case r.URL.Path == "/calendars/primary/events" && r.Method == http.MethodPost:
+			query = r.URL.RawQuery
+			inserted = &calendar.Event{}
+			_ = json.NewDecoder(r.Body).Decode(inserted)
+			inserted.Id = "ev1"
+			_ = json.NewEncoder(w).Encode(inserted)
+		default:
+			http.NotFound(w, r)
+		}
+	})))
+	defer cleanup()
+
+	args := []string{"--json", "--account", "a@b.com", "calendar", "find-slot", "--attendee", "bob@example.com",
+		"--duration", "45m", "--buffer", "15m", "--from", "2030-01-07", "--to", "2030-01-07", "--max", "2", "--in-person"}
+	result := executeWithCalendarTestService(t, args, svc)
+	if result.err != nil {
+		t.Fatalf("find-slot: %v", result.err)
+	}
+	for _, want := range []string{`"start": "2030-01-07T15:15:00Z"`, `"start": "2030-01-07T16:00:00Z"`, `"same_office": "HQ"`, `"timezone_source": "calendar"`} {
+		if !strings.Contains(result.stdout, want) {
+			t.Fatalf("output missing %s:\n%s", want, result.stdout)
+		}
+	}
+
+	result = executeWithCalendarTestService(t, append(args, "--book", "--choose", "2", "--summary", "Sync"), svc)
+	if result.err != nil {
+		t.Fatalf("book: %v", result.err)
+	}
+	if inserted == nil || inserted.Summary != "Sync" || inserted.Start.DateTime != "2030-01-07T16:00:00Z" ||
+		len(inserted.Attendees) != 1 || inserted.Attendees[0].Email != "bob@example.com" {
+		t.Fatalf("inserted = %+v", inserted)
+	}
+	if !strings.Contains(query, "sendUpdates=all") {
+		t.Fatalf("insert query = %s", query)
+	}
+}
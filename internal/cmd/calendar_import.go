@@ -0,0 +1,308 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+
+	"github.com/steipete/gogcli/internal/icalendar"
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+const (
+	calendarImportCreate    = "create"
+	calendarImportUpdate    = "update"
+	calendarImportUnchanged = "unchanged"
+	calendarImportCancel    = "cancel"
+	calendarImportSkip      = "skip"
+)
+
+type CalendarImportCmd struct {
+	File     string `arg:"" name:"file" help:"iCalendar file (.ics), or - for stdin"`
+	Calendar string `name:"calendar" aliases:"cal" help:"Calendar to import into (default: primary)"`
+}
+
+// calendarImportItem is one VEVENT in the plan: a series or single event,
+// or (with RecurrenceID) one modified instance of a series.
+type calendarImportItem struct {
+	UID          string   `json:"uid"`
+	RecurrenceID string   `json:"recurrence_id,omitempty"`
+	Summary      string   `json:"summary,omitempty"`
+	Action       string   `json:"action"`
+	Changes      []string `json:"changes,omitempty"`
+	EventID      string   `json:"event_id,omitempty"`
+	Reason       string   `json:"reason,omitempty"`
+
+	event    *calendar.Event
+	existing *calendar.Event
+}
+
+func (c *CalendarImportCmd) Run(ctx context.Context, flags *RootFlags) error {
+	data, err := readTextInput(ctx, strings.TrimSpace(c.File))
+	if err != nil {
+		return err
+	}
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	store, err := commandConfigStore(ctx)
+	if err != nil {
+		return err
+	}
+	svc, err := calendarService(ctx, account)
+	if err != nil {
+		return err
+	}
+	calendarID, err := resolveCalendarSelector(ctx, store, svc, c.Calendar, true)
+	if err != nil {
+		return err
+	}
+	timezone, _, err := getCalendarLocation(ctx, svc, calendarID)
+	if err != nil {
+		return err
+	}
+	events, err := icalendar.Decode(bytes.NewReader(data), timezone)
+	if err != nil {
+		return usagef("parse %s: %v", c.File, err)
+	}
+
+	plan, err := planCalendarImport(ctx, svc, calendarID, events)
+	if err != nil {
+		return err
+	}
+	counts := calendarImportCounts(plan)
+	if dryRunErr := dryRunExit(ctx, flags, "calendar.import", map[string]any{
+		"calendar_id": calendarID,
+		"file":        c.File,
+		"counts":      counts,
+		"events":      plan,
+	}); dryRunErr != nil {
+		return dryRunErr
+	}
+	updates, cancels := 0, 0
+	for _, item := range plan {
+		switch {
+		case item.Action == calendarImportUpdate:
+			updates++
+		case item.Action == calendarImportCancel && item.existing != nil:
+			cancels++
+		}
+	}
+	if updates > 0 || cancels > 0 {
+		action := fmt.Sprintf("update %d and cancel %d existing events in %s", updates, cancels, calendarID)
+		if confirmErr := confirmDestructiveChecked(ctx, flags, action); confirmErr != nil {
+			return confirmErr
+		}
+	}
+
+	// Apply every series master before any override so an override that
+	// precedes its master in the file still finds the created series.
+	masterIDs := map[string]string{}
+	for _, masters := range []bool{true, false} {
+		for _, item := range plan {
+			if (item.RecurrenceID == "") != masters {
+				continue
+			}
+			if err := applyCalendarImportItem(ctx, svc, calendarID, item, masterIDs); err != nil {
+				return err
+			}
+		}
+	}
+
+	counts = calendarImportCounts(plan)
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, stdoutWriter(ctx), map[string]any{
+			"calendar_id": calendarID,
+			"counts":      counts,
+			"events":      plan,
+		})
+	}
+	u := ui.FromContext(ctx)
+	for _, item := range plan {
+		if item.Action == calendarImportUnchanged {
+			continue
+		}
+		u.Out().Linef("%s\t%s\t%s\t%s", item.Action, item.EventID, item.UID, item.Summary)
+	}
+	u.Err().Printf("Events: %d created, %d updated, %d cancelled, %d unchanged, %d skipped",
+		counts[calendarImportCreate], counts[calendarImportUpdate], counts[calendarImportCancel], counts[calendarImportUnchanged], counts[calendarImportSkip])
+	return nil
+}
+
+func calendarImportCounts(plan []*calendarImportItem) map[string]int {
+	counts := map[string]int{}
+	for _, action := range []string{calendarImportCreate, calendarImportUpdate, calendarImportUnchanged, calendarImportCancel, calendarImportSkip} {
+		counts[action] = 0
+	}
+	for _, item := range plan {
+		counts[item.Action]++
+	}
+	return counts
+}
+
+// planCalendarImport matches every VEVENT to the calendar by iCalUID (and,
+// for modified instances, by original start time) without changing anything.
+func planCalendarImport(ctx context.Context, svc *calendar.Service, calendarID string, events []*calendar.Event) ([]*calendarImportItem, error) {
+	masters := map[string]*calendar.Event{}
+	for _, event := range events {
+		uid := event.ICalUID
+		if _, seen := masters[uid]; seen {
+			continue
+		}
+		masters[uid] = nil
+		resp, err := svc.Events.List(calendarID).ICalUID(uid).ShowDeleted(true).Context(ctx).Do()
+		if err != nil {
+			return nil, fmt.Errorf("look up %s: %w", uid, err)
+		}
+		for _, item := range resp.Items {
+			if item != nil && item.RecurringEventId == "" {
+				masters[uid] = item
+				break
+			}
+		}
+	}
+
+	plan := make([]*calendarImportItem, 0, len(events))
+	for _, event := range events {
+		item := &calendarImportItem{UID: event.ICalUID, Summary: event.Summary, event: event}
+		plan = append(plan, item)
+		master := masters[event.ICalUID]
+
+		if event.OriginalStartTime != nil {
+			item.RecurrenceID = calendarImportInstant(event.OriginalStartTime)
+			if master == nil || master.Status == "cancelled" {
+				// The series is created by this import; the instance is
+				// matched once it exists.
+				item.Action = calendarImportCreate
+				if event.Status == "cancelled" {
+					item.Action = calendarImportCancel
+				}
+				continue
+			}
+			instance, found, err := findCalendarInstance(ctx, svc, calendarID, master.Id, event.OriginalStartTime)
+			if err != nil {
+				return nil, err
+			}
+			if !found {
+				item.Action, item.Reason = calendarImportSkip, "no instance at recurrence-id"
+				continue
+			}
+			planCalendarImportExisting(item, instance)
+			continue
+		}
+
+		if master == nil || (master.Status == "cancelled" && event.Status != "cancelled") {
+			item.Action = calendarImportCreate
+			if event.Status == "cancelled" {
+				item.Action, item.Reason = calendarImportSkip, "cancelled and not in calendar"
+			}
+			item.existing = master
+			continue
+		}
+		planCalendarImportExisting(item, master)
+	}
+	return plan, nil
+}
+
+func planCalendarImportExisting(item *calendarImportItem, existing *calendar.Event) {
+	item.existing = existing
+	item.EventID = existing.Id
+	if item.event.Status == "cancelled" {
+		item.Action = calendarImportCancel
+		if existing.Status == "cancelled" {
+			item.Action = calendarImportUnchanged
+		}
+		return
+	}
+	item.Changes = icalendar.Changes(existing, item.event)
+	item.Action = calendarImportUnchanged
+	if len(item.Changes) > 0 {
+		item.Action = calendarImportUpdate
+	}
+}
+
+func findCalendarInstance(ctx context.Context, svc *calendar.Service, calendarID, masterID string, originalStart *calendar.EventDateTime) (*calendar.Event, bool, error) {
+	start := calendarImportInstant(originalStart)
+	resp, err := svc.Events.Instances(calendarID, masterID).OriginalStart(start).ShowDeleted(true).Context(ctx).Do()
+	if err != nil {
+		if isGoogleNotFound(err) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("look up instance %s of %s: %w", start, masterID, err)
+	}
+	for _, instance := range resp.Items {
+		if instance != nil {
+			return instance, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+func calendarImportInstant(dt *calendar.EventDateTime) string {
+	if dt.Date != "" {
+		return dt.Date
+	}
+	if t, err := time.Parse(time.RFC3339, dt.DateTime); err == nil {
+		return t.UTC().Format(time.RFC3339)
+	}
+	return dt.DateTime
+}
+
+func applyCalendarImportItem(ctx context.Context, svc *calendar.Service, calendarID string, item *calendarImportItem, masterIDs map[string]string) error {
+	if item.RecurrenceID != "" && item.existing == nil && item.Action != calendarImportSkip {
+		// Instance of a series created earlier in this run.
+		masterID := masterIDs[item.UID]
+		if masterID == "" {
+			item.Action, item.Reason = calendarImportSkip, "series not in calendar"
+			return nil
+		}
+		instance, found, err := findCalendarInstance(ctx, svc, calendarID, masterID, item.event.OriginalStartTime)
+		if err != nil {
+			return err
+		}
+		if !found {
+			item.Action, item.Reason = calendarImportSkip, "no instance at recurrence-id"
+			return nil
+		}
+		planCalendarImportExisting(item, instance)
+	}
+
+	switch item.Action {
+	case calendarImportCreate:
+		event := item.event
+		if item.existing != nil {
+			// A deleted copy still holds the iCalUID; restore it in place.
+			updated, err := svc.Events.Update(calendarID, item.existing.Id, icalendar.Merge(item.existing, event)).
+				SendUpdates(sendUpdatesNone).ConferenceDataVersion(1).Context(ctx).Do()
+			if err != nil {
+				return fmt.Errorf("restore %s: %w", item.UID, err)
+			}
+			item.EventID = updated.Id
+			break
+		}
+		created, err := svc.Events.Import(calendarID, event).ConferenceDataVersion(1).Context(ctx).Do()
+		if err != nil {
+			return fmt.Errorf("import %s: %w", item.UID, err)
+		}
+		item.EventID = created.Id
+	case calendarImportUpdate:
+		if _, err := svc.Events.Update(calendarID, item.existing.Id, icalendar.Merge(item.existing, item.event)).
+			SendUpdates(sendUpdatesNone).ConferenceDataVersion(1).Context(ctx).Do(); err != nil {
+			return fmt.Errorf("update %s: %w", item.UID, err)
+		}
+	case calendarImportCancel:
+		if err := svc.Events.Delete(calendarID, item.existing.Id).SendUpdates(sendUpdatesNone).Context(ctx).Do(); err != nil && !isGoogleNotFound(err) {
+			return fmt.Errorf("cancel %s: %w", item.UID, err)
+		}
+	}
+	if item.RecurrenceID == "" && item.EventID != "" {
+		masterIDs[item.UID] = item.EventID
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+func TestCalendarExportICS(t *testing.T) {
+	svc, cleanup := newCalendarServiceForTest(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/calendars/primary" && r.Method == http.MethodGet:
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "ann@example.com", "summary": "Ann", "timeZone": "Europe/Berlin"})
+		case r.URL.Path == "/calendars/primary/events" && r.Method == http.MethodGet:
+			if q := r.URL.Query(); q.Get("singleEvents") != "false" || q.Get("showDeleted") != "true" || q.Get("timeMin") == "" {
+				t.Errorf("unexpected list query: %s", r.URL.RawQuery)
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"items": []map[string]any{
+				{
+					"id": "s1", "iCalUID": "s1@google.com", "summary": "Weekly sync",
+					"start":      map[string]any{"dateTime": "2026-04-06T10:00:00+02:00", "timeZone": "Europe/Berlin"},
+					"end":        map[string]any{"dateTime": "2026-04-06T10:30:00+02:00", "timeZone": "Europe/Berlin"},
+					"recurrence": []string{"RRULE:FREQ=WEEKLY;BYDAY=MO"},
+					"attendees":  []map[string]any{{"email": "bob@example.com", "responseStatus": "accepted"}},
+				},
+				{
+					"id": "s1_20260413T080000Z", "recurringEventId": "s1", "status": "cancelled",
+					"originalStartTime": map[string]any{"dateTime": "2026-04-13T10:00:00+02:00", "timeZone": "Europe/Berlin"},
+				},
+			}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer cleanup()
+
+	result := executeWithCalendarTestService(t, []string{"--account", "a@b.com", "calendar", "export", "--ics", "--from", "2026-01-01"}, svc)
+	if result.err != nil {
+		t.Fatalf("export: %v", result.err)
+	}
+	out := strings.ReplaceAll(result.stdout, "\r\n ", "")
+	for _, want := range []string{
+		"X-WR-CALNAME:Ann",
+		"BEGIN:VTIMEZONE\r\nTZID:Europe/Berlin",
+		"DTSTART;TZID=Europe/Berlin:20260406T100000",
+		"EXDATE;TZID=Europe/Berlin:20260413T100000",
+		"PARTSTAT=ACCEPTED",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in\n%s", want, out)
+		}
+	}
+	if strings.Count(out, "BEGIN:VEVENT") != 1 {
+		t.Fatalf("cancelled instance exported as an event:\n%s", out)
+	}
+}
+
+func TestCalendarImportPlansAndApplies(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		imported []calendar.Event
+		updated  []string
+	)
+	svc, cleanup := newCalendarServiceForTest(t, withPrimaryCalendar(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/calendars/primary/events" && r.Method == http.MethodGet:
+			var items []map[string]any
+			switch r.URL.Query().Get("iCalUID") {
+			case "same@example.com":
+				items = append(items, map[string]any{
+					"id": "ev-same", "iCalUID": "same@example.com", "summary": "Unchanged", "status": "confirmed",
+					"start": map[string]any{"dateTime": "2026-04-02T10:00:00Z"}, "end": map[string]any{"dateTime": "2026-04-02T11:00:00Z"},
+				})
+			case "changed@example.com":
+				items = append(items, map[string]any{
+					"id": "ev-changed", "iCalUID": "changed@example.com", "summary": "Old title", "colorId": "5",
+					"start": map[string]any{"dateTime": "2026-04-03T10:00:00Z"}, "end": map[string]any{"dateTime": "2026-04-03T11:00:00Z"},
+				})
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"items": items})
+		case r.URL.Path == "/calendars/primary/events/import" && r.Method == http.MethodPost:
+			var event calendar.Event
+			_ = json.NewDecoder(r.Body).Decode(&event)
+			imported = append(imported, event)
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "ev-new", "iCalUID": event.ICalUID})
+		case strings.HasPrefix(r.URL.Path, "/calendars/primary/events/") && r.Method == http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			if r.URL.Query().Get("sendUpdates") != "none" {
+				t.Errorf("sendUpdates = %q", r.URL.Query().Get("sendUpdates"))
+			}
+			updated = append(updated, strings.TrimPrefix(r.URL.Path, "/calendars/primary/events/")+" "+string(body))
+			_, _ = w.Write(body)
+		default:
+			http.NotFound(w, r)
+		}
+	})))
+	defer cleanup()
+
+	path := filepath.Join(t.TempDir(), "team.ics")
+	ics := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n" +
+		"BEGIN:VEVENT\r\nUID:new@example.com\r\nDTSTART:20260401T090000Z\r\nDTEND:20260401T093000Z\r\nSUMMARY:Brand new\r\n" +
+		"CONFERENCE;VALUE=URI;FEATURE=VIDEO:https://meet.google.com/abc-defg-hij\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:same@example.com\r\nDTSTART:20260402T100000Z\r\nDTEND:20260402T110000Z\r\nSUMMARY:Unchanged\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:changed@example.com\r\nDTSTART:20260403T100000Z\r\nDTEND:20260403T110000Z\r\nSUMMARY:New title\r\nEND:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+	if err := os.WriteFile(path, []byte(ics), 0o600); err != nil {
+		t.Fatalf("write ics: %v", err)
+	}
+
+	result := executeWithCalendarTestService(t, []string{"--json", "--dry-run", "--account", "a@b.com", "calendar", "import", path}, svc)
+	if ExitCode(result.err) != 0 {
+		t.Fatalf("dry run: %v", result.err)
+	}
+	for _, want := range []string{`"create": 1`, `"update": 1`, `"unchanged": 1`, `"changes": [`} {
+		if !strings.Contains(result.stdout, want) {
+			t.Fatalf("dry run output missing %s:\n%s", want, result.stdout)
+		}
+	}
+	if len(imported) != 0 || len(updated) != 0 {
+		t.Fatalf("dry run wrote: imported=%d updated=%d", len(imported), len(updated))
+	}
+
+	result = executeWithCalendarTestService(t, []string{"--json", "--force", "--account", "a@b.com", "calendar", "import", path}, svc)
+	if result.err != nil {
+		t.Fatalf("import: %v", result.err)
+	}
+	if len(imported) != 1 || imported[0].ICalUID != "new@example.com" || imported[0].ConferenceData == nil || imported[0].ConferenceData.ConferenceId != "abc-defg-hij" {
+		t.Fatalf("imported = %+v", imported)
+	}
+	if len(updated) != 1 || !strings.HasPrefix(updated[0], "ev-changed ") || !strings.Contains(updated[0], `"summary":"New title"`) || !strings.Contains(updated[0], `"colorId":"5"`) {
+		t.Fatalf("updated = %v", updated)
+	}
+	if !strings.Contains(result.stdout, `"event_id": "ev-new"`) {
+		t.Fatalf("output = %s", result.stdout)
+	}
+}
+
+func TestCalendarImportAppliesOverrideListedBeforeMaster(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		imported []string
+		updated  []string
+	)
+	svc, cleanup := newCalendarServiceForTest(t, withPrimaryCalendar(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/calendars/primary/events" && r.Method == http.MethodGet:
+			_ = json.NewEncoder(w).Encode(map[string]any{"items": []any{}})
+		case r.URL.Path == "/calendars/primary/events/import" && r.Method == http.MethodPost:
+			var event calendar.Event
+			_ = json.NewDecoder(r.Body).Decode(&event)
+			imported = append(imported, event.Summary)
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "ev-series", "iCalUID": event.ICalUID})
+		case r.URL.Path == "/calendars/primary/events/ev-series/instances" && r.Method == http.MethodGet:
+			_ = json.NewEncoder(w).Encode(map[string]any{"items": []map[string]any{{
+				"id": "ev-series_20260408T090000Z", "iCalUID": "series@example.com", "recurringEventId": "ev-series", "summary": "Standup",
+				"start":             map[string]any{"dateTime": "2026-04-08T09:00:00Z"},
+				"end":               map[string]any{"dateTime": "2026-04-08T09:15:00Z"},
+				"originalStartTime": map[string]any{"dateTime": "2026-04-08T09:00:00Z"},
+			}}})
+		case strings.HasPrefix(r.URL.Path, "/calendars/primary/events/") && r.Method == http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			updated = append(updated, strings.TrimPrefix(r.URL.Path, "/calendars/primary/events/"))
+			_, _ = w.Write(body)
+		default:
+			http.NotFound(w, r)
+		}
+	})))
+	defer cleanup()
+
+	path := filepath.Join(t.TempDir(), "series.ics")
+	ics := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n" +
+		"BEGIN:VEVENT\r\nUID:series@example.com\r\nRECURRENCE-ID:20260408T090000Z\r\nDTSTART:20260408T100000Z\r\nDTEND:20260408T101500Z\r\nSUMMARY:Standup (moved)\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:series@example.com\r\nDTSTART:20260406T090000Z\r\nDTEND:20260406T091500Z\r\nRRULE:FREQ=DAILY;COUNT=5\r\nSUMMARY:Standup\r\nEND:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+	if err := os.WriteFile(path, []byte(ics), 0o600); err != nil {
+		t.Fatalf("write ics: %v", err)
+	}
+
+	result := executeWithCalendarTestService(t, []string{"--json", "--force", "--account", "a@b.com", "calendar", "import", path}, svc)
+	if result.err != nil {
+		t.Fatalf("import: %v", result.err)
+	}
+	if len(imported) != 1 || imported[0] != "Standup" {
+		t.Fatalf("imported = %v", imported)
+	}
+	if len(updated) != 1 || updated[0] != "ev-series_20260408T090000Z" {
+		t.Fatalf("updated = %v\n%s", updated, result.stdout)
+	}
+}
@@ -0,0 +1,318 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+const (
+	mirrorSourcePrivateProp = "gog.mirror_source"
+	mirrorEventPrivateProp  = "gog.mirror_event"
+
+	calendarMirrorCreate    = "create"
+	calendarMirrorUpdate    = "update"
+	calendarMirrorDelete    = "delete"
+	calendarMirrorUnchanged = "unchanged"
+)
+
+type CalendarMirrorCmd struct {
+	From             string `name:"from" help:"Source as account:calendar (account email or alias; calendar defaults to primary)" required:""`
+	To               string `name:"to" help:"Target as account:calendar (account email or alias; calendar defaults to primary)" required:""`
+	TwoWay           bool   `name:"two-way" help:"Also mirror the target's events back into the source"`
+	Days             int    `name:"days" help:"Mirror events from today through the next N days" default:"14"`
+	Title            string `name:"title" help:"Title of the placeholder events" default:"Busy"`
+	AllDay           bool   `name:"all-day" help:"Also mirror all-day events (skipped by default; they are usually informational)"`
+	IncludeFree      bool   `name:"include-free" help:"Also mirror events marked as free (transparent)"`
+	IncludeTentative bool   `name:"include-tentative" help:"Also mirror invitations you have not accepted (needsAction/tentative)"`
+}
+
+// calendarMirrorEnd is one side of a mirror: an account and one of its
+// calendars.
+type calendarMirrorEnd struct {
+	Account    string `json:"account"`
+	CalendarID string `json:"calendar_id"`
+
+	svc *calendar.Service
+}
+
+func (e calendarMirrorEnd) key() string {
+	return e.Account + "/" + e.CalendarID
+}
+
+// calendarMirrorAction is one planned change to a placeholder in the target.
+type calendarMirrorAction struct {
+	Action        string `json:"action"`
+	SourceEventID string `json:"source_event_id,omitempty"`
+	EventID       string `json:"event_id,omitempty"`
+	Start         string `json:"start,omitempty"`
+	End           string `json:"end,omitempty"`
+
+	placeholder *calendar.Event
+}
+
+type calendarMirrorDirection struct {
+	From    calendarMirrorEnd       `json:"from"`
+	To      calendarMirrorEnd       `json:"to"`
+	Counts  map[string]int          `json:"counts"`
+	Actions []*calendarMirrorAction `json:"actions"`
+}
+
+func (c *CalendarMirrorCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	if c.Days <= 0 {
+		return usage("--days must be > 0")
+	}
+	title := strings.TrimSpace(c.Title)
+	if title == "" {
+		return usage("--title must not be empty")
+	}
+	source, err := resolveCalendarMirrorEnd(ctx, flags, "--from", c.From)
+	if err != nil {
+		return err
+	}
+	target, err := resolveCalendarMirrorEnd(ctx, flags, "--to", c.To)
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(source.key(), target.key()) {
+		return usage("--from and --to must be different calendars")
+	}
+
+	loc, err := getUserTimezone(ctx, source.svc)
+	if err != nil {
+		return err
+	}
+	now := time.Now().In(loc)
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	to := from.AddDate(0, 0, c.Days)
+
+	directions := []*calendarMirrorDirection{{From: source, To: target}}
+	if c.TwoWay {
+		directions = append(directions, &calendarMirrorDirection{From: target, To: source})
+	}
+	// Plan every direction before writing so a two-way run never mirrors
+	// placeholders created moments earlier.
+	for _, dir := range directions {
+		if err := c.plan(ctx, dir, title, from, to); err != nil {
+			return err
+		}
+	}
+	if dryRunErr := dryRunExit(ctx, flags, "calendar.mirror", map[string]any{
+		"window":     map[string]string{"from": from.Format(time.RFC3339), "to": to.Format(time.RFC3339)},
+		"directions": directions,
+	}); dryRunErr != nil {
+		return dryRunErr
+	}
+
+	for _, dir := range directions {
+		if err := applyCalendarMirror(ctx, dir); err != nil {
+			return err
+		}
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, stdoutWriter(ctx), map[string]any{
+			"window":     map[string]string{"from": from.Format(time.RFC3339), "to": to.Format(time.RFC3339)},
+			"directions": directions,
+		})
+	}
+	for _, dir := range directions {
+		for _, action := range dir.Actions {
+			if action.Action != calendarMirrorUnchanged {
+				u.Out().Linef("%s\t%s\t%s\t%s\t%s", action.Action, dir.To.key(), action.EventID, action.Start, action.End)
+			}
+		}
+		u.Err().Printf("%s -> %s: %d created, %d updated, %d deleted, %d unchanged", dir.From.key(), dir.To.key(),
+			dir.Counts[calendarMirrorCreate], dir.Counts[calendarMirrorUpdate], dir.Counts[calendarMirrorDelete], dir.Counts[calendarMirrorUnchanged])
+	}
+	return nil
+}
+
+// resolveCalendarMirrorEnd parses account:calendar, resolving account
+// aliases the same way --account does.
+func resolveCalendarMirrorEnd(ctx context.Context, flags *RootFlags, flag, value string) (calendarMirrorEnd, error) {
+	accountRaw, calendarID, _ := strings.Cut(strings.TrimSpace(value), ":")
+	calendarID = strings.TrimSpace(calendarID)
+	if calendarID == "" {
+		calendarID = primaryCalendarID
+	}
+	account, ok, err := selectConfiguredAccount(flags, accountRaw)
+	if err == nil && !ok {
+		account, err = requireAccount(flags)
+	}
+	if err != nil {
+		return calendarMirrorEnd{}, fmt.Errorf("%s: %w", flag, err)
+	}
+	svc, err := calendarService(ctx, account)
+	if err != nil {
+		return calendarMirrorEnd{}, err
+	}
+	if calendarID, err = resolveCalendarID(ctx, svc, calendarID); err != nil {
+		return calendarMirrorEnd{}, err
+	}
+	return calendarMirrorEnd{Account: account, CalendarID: calendarID, svc: svc}, nil
+}
+
+func (c *CalendarMirrorCmd) plan(ctx context.Context, dir *calendarMirrorDirection, title string, from, to time.Time) error {
+	sources, err := listCalendarMirrorEvents(ctx, dir.From, from, to, "")
+	if err != nil {
+		return fmt.Errorf("list %s: %w", dir.From.key(), err)
+	}
+	existing, err := listCalendarMirrorEvents(ctx, dir.To, from, to, mirrorSourcePrivateProp+"="+dir.From.key())
+	if err != nil {
+		return fmt.Errorf("list placeholders in %s: %w", dir.To.key(), err)
+	}
+
+	placeholders := map[string]*calendar.Event{}
+	for _, ev := range existing {
+		sourceID := mirrorPrivateProp(ev, mirrorEventPrivateProp)
+		if _, dup := placeholders[sourceID]; dup || sourceID == "" {
+			dir.Actions = append(dir.Actions, &calendarMirrorAction{Action: calendarMirrorDelete, SourceEventID: sourceID, EventID: ev.Id})
+			continue
+		}
+		placeholders[sourceID] = ev
+	}
+
+	for _, ev := range sources {
+		if !c.shouldMirror(ev) {
+			continue
+		}
+		want := newCalendarMirrorPlaceholder(ev, dir.From, title)
+		action := &calendarMirrorAction{
+			SourceEventID: ev.Id,
+			Start:         calendarImportInstant(ev.Start),
+			End:           calendarImportInstant(ev.End),
+			placeholder:   want,
+		}
+		dir.Actions = append(dir.Actions, action)
+		current, ok := placeholders[ev.Id]
+		if !ok {
+			action.Action = calendarMirrorCreate
+			continue
+		}
+		delete(placeholders, ev.Id)
+		action.EventID = current.Id
+		action.Action = calendarMirrorUnchanged
+		if current.Summary != want.Summary || calendarImportInstant(current.Start) != action.Start || calendarImportInstant(current.End) != action.End {
+			action.Action = calendarMirrorUpdate
+		}
+	}
+	// Whatever is left has lost its source event: deleted, declined, or
+	// moved out of the window.
+	for _, sourceID := range slices.Sorted(maps.Keys(placeholders)) {
+		ev := placeholders[sourceID]
+		dir.Actions = append(dir.Actions, &calendarMirrorAction{Action: calendarMirrorDelete, SourceEventID: sourceID, EventID: ev.Id})
+	}
+
+	dir.Counts = map[string]int{calendarMirrorCreate: 0, calendarMirrorUpdate: 0, calendarMirrorDelete: 0, calendarMirrorUnchanged: 0}
+	for _, action := range dir.Actions {
+		dir.Counts[action.Action]++
+	}
+	return nil
+}
+
+// shouldMirror keeps events that make you busy: not cancelled, not free,
+// not declined, and not a placeholder mirrored from somewhere else.
+func (c *CalendarMirrorCmd) shouldMirror(ev *calendar.Event) bool {
+	if ev == nil || ev.Status == "cancelled" || ev.Start == nil || ev.End == nil {
+		return false
+	}
+	if mirrorPrivateProp(ev, mirrorSourcePrivateProp) != "" {
+		return false
+	}
+	switch ev.EventType {
+	case eventTypeWorkingLocation, "birthday", "fromGmail":
+		return false
+	}
+	if ev.Start.Date != "" && !c.AllDay {
+		return false
+	}
+	if ev.Transparency == transparencyTransparent && !c.IncludeFree {
+		return false
+	}
+	for _, attendee := range ev.Attendees {
+		if attendee == nil || !attendee.Self {
+			continue
+		}
+		switch attendee.ResponseStatus {
+		case "declined":
+			return false
+		case "needsAction", "tentative":
+			return c.IncludeTentative
+		}
+	}
+	return true
+}
+
+func newCalendarMirrorPlaceholder(source *calendar.Event, from calendarMirrorEnd, title string) *calendar.Event {
+	return &calendar.Event{
+		Summary:      title,
+		Start:        &calendar.EventDateTime{Date: source.Start.Date, DateTime: source.Start.DateTime, TimeZone: source.Start.TimeZone},
+		End:          &calendar.EventDateTime{Date: source.End.Date, DateTime: source.End.DateTime, TimeZone: source.End.TimeZone},
+		Visibility:   "private",
+		Transparency: "opaque",
+		Reminders:    &calendar.EventReminders{UseDefault: false, ForceSendFields: []string{"UseDefault"}},
+		ExtendedProperties: &calendar.EventExtendedProperties{Private: map[string]string{
+			mirrorSourcePrivateProp: from.key(),
+			mirrorEventPrivateProp:  source.Id,
+		}},
+	}
+}
+
+func listCalendarMirrorEvents(ctx context.Context, end calendarMirrorEnd, from, to time.Time, privateProp string) ([]*calendar.Event, error) {
+	events, _, err := loadPagedItems("", true, func(pageToken string) ([]*calendar.Event, string, error) {
+		call := end.svc.Events.List(end.CalendarID).SingleEvents(true).MaxResults(2500).
+			TimeMin(from.Format(time.RFC3339)).TimeMax(to.Format(time.RFC3339)).Context(ctx)
+		if privateProp != "" {
+			call = call.PrivateExtendedProperty(privateProp)
+		}
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+		resp, err := call.Do()
+		if err != nil {
+			return nil, "", err
+		}
+		return resp.Items, resp.NextPageToken, nil
+	})
+	return events, err
+}
+
+func applyCalendarMirror(ctx context.Context, dir *calendarMirrorDirection) error {
+	svc, calendarID := dir.To.svc, dir.To.CalendarID
+	for _, action := range dir.Actions {
+		switch action.Action {
+		case calendarMirrorCreate:
+			created, err := svc.Events.Insert(calendarID, action.placeholder).SendUpdates(sendUpdatesNone).Context(ctx).Do()
+			if err != nil {
+				return fmt.Errorf("create placeholder for %s in %s: %w", action.SourceEventID, dir.To.key(), err)
+			}
+			action.EventID = created.Id
+		case calendarMirrorUpdate:
+			if _, err := svc.Events.Update(calendarID, action.EventID, action.placeholder).SendUpdates(sendUpdatesNone).Context(ctx).Do(); err != nil {
+				return fmt.Errorf("update placeholder %s in %s: %w", action.EventID, dir.To.key(), err)
+			}
+		case calendarMirrorDelete:
+			if err := svc.Events.Delete(calendarID, action.EventID).SendUpdates(sendUpdatesNone).Context(ctx).Do(); err != nil && !isGoogleNotFound(err) {
+				return fmt.Errorf("delete placeholder %s in %s: %w", action.EventID, dir.To.key(), err)
+			}
+		}
+	}
+	return nil
+}
+
+func mirrorPrivateProp(ev *calendar.Event, key string) string {
+	if ev == nil || ev.ExtendedProperties == nil {
+		return ""
+	}
+	return ev.ExtendedProperties.Private[key]
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+func TestCalendarMirrorSyncsPlaceholders(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		inserted []calendar.Event
+		updated  []string
+		deleted  []string
+	)
+	timed := func(start, end string) map[string]any {
+		return map[string]any{"start": map[string]any{"dateTime": start}, "end": map[string]any{"dateTime": end}}
+	}
+	event := func(id string, fields map[string]any, extra map[string]any) map[string]any {
+		out := map[string]any{"id": id}
+		for k, v := range fields {
+			out[k] = v
+		}
+		for k, v := range extra {
+			out[k] = v
+		}
+		return out
+	}
+
+	personal, cleanupPersonal := newCalendarServiceForTest(t, withPrimaryCalendar(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/calendars/primary/events" || r.Method != http.MethodGet {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"items": []map[string]any{
+			event("moved", timed("2030-01-07T10:00:00Z", "2030-01-07T11:30:00Z"), map[string]any{"summary": "Dentist"}),
+			event("new", timed("2030-01-08T09:00:00Z", "2030-01-08T09:30:00Z"), map[string]any{"summary": "Gym"}),
+			event("declined", timed("2030-01-08T12:00:00Z", "2030-01-08T13:00:00Z"), map[string]any{
+				"attendees": []map[string]any{{"email": "personal@x.com", "self": true, "responseStatus": "declined"}},
+			}),
+			event("free", timed("2030-01-08T14:00:00Z", "2030-01-08T15:00:00Z"), map[string]any{"transparency": "transparent"}),
+			event("allday", map[string]any{"start": map[string]any{"date": "2030-01-09"}, "end": map[string]any{"date": "2030-01-10"}}, nil),
+			event("echo", timed("2030-01-09T10:00:00Z", "2030-01-09T11:00:00Z"), map[string]any{
+				"extendedProperties": map[string]any{"private": map[string]any{mirrorSourcePrivateProp: "work@y.com/primary", mirrorEventPrivateProp: "w1"}},
+			}),
+		}})
+	})))
+	defer cleanupPersonal()
+
+	work, cleanupWork := newCalendarServiceForTest(t, withPrimaryCalendar(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/calendars/primary/events" && r.Method == http.MethodGet:
+			if got := r.URL.Query().Get("privateExtendedProperty"); got != mirrorSourcePrivateProp+"=personal@x.com/primary" {
+				t.Errorf("privateExtendedProperty = %q", got)
+			}
+			props := func(source string) map[string]any {
+				return map[string]any{"summary": "Busy", "extendedProperties": map[string]any{"private": map[string]any{
+					mirrorSourcePrivateProp: "personal@x.com/primary", mirrorEventPrivateProp: source,
+				}}}
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"items": []map[string]any{
+				event("p-moved", timed("2030-01-07T10:00:00Z", "2030-01-07T11:00:00Z"), props("moved")),
+				event("p-gone", timed("2030-01-07T15:00:00Z", "2030-01-07T16:00:00Z"), props("gone")),
+			}})
+		case r.URL.Path == "/calendars/primary/events" && r.Method == http.MethodPost:
+			var ev calendar.Event
+			_ = json.NewDecoder(r.Body).Decode(&ev)
+			inserted = append(inserted, ev)
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "p-new"})
+		case strings.HasPrefix(r.URL.Path, "/calendars/primary/events/") && r.Method == http.MethodPut:
+			updated = append(updated, strings.TrimPrefix(r.URL.Path, "/calendars/primary/events/"))
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "p-moved"})
+		case strings.HasPrefix(r.URL.Path, "/calendars/primary/events/") && r.Method == http.MethodDelete:
+			deleted = append(deleted, strings.TrimPrefix(r.URL.Path, "/calendars/primary/events/"))
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	})))
+	defer cleanupWork()
+
+	factory := func(_ context.Context, account string) (*calendar.Service, error) {
+		if account == "work@y.com" {
+			return work, nil
+		}
+		return personal, nil
+	}
+	args := []string{"--json", "calendar", "mirror", "--from", "personal@x.com", "--to", "work@y.com:primary"}
+
+	result := executeWithCalendarTestServiceFactory(t, append([]string{"--dry-run"}, args...), factory)
+	if ExitCode(result.err) != 0 {
+		t.Fatalf("dry run: %v", result.err)
+	}
+	for _, want := range []string{`"create": 1`, `"update": 1`, `"delete": 1`, `"unchanged": 0`} {
+		if !strings.Contains(result.stdout, want) {
+			t.Fatalf("dry run output missing %s:\n%s", want, result.stdout)
+		}
+	}
+	if len(inserted)+len(updated)+len(deleted) != 0 {
+		t.Fatalf("dry run wrote: %d %d %d", len(inserted), len(updated), len(deleted))
+	}
+
+	result = executeWithCalendarTestServiceFactory(t, args, factory)
+	if result.err != nil {
+		t.Fatalf("mirror: %v", result.err)
+	}
+	if len(inserted) != 1 || inserted[0].Summary != "Busy" || inserted[0].Visibility != "private" ||
+		inserted[0].ExtendedProperties.Private[mirrorEventPrivateProp] != "new" || inserted[0].Description != "" {
+		t.Fatalf("inserted = %+v", inserted)
+	}
+	if strings.Join(updated, ",") != "p-moved" || strings.Join(deleted, ",") != "p-gone" {
+		t.Fatalf("updated = %v, deleted = %v", updated, deleted)
+	}
+}
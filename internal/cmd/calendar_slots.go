@@ -0,0 +1,382 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+
+	"github.com/steipete/gogcli/internal/timeparse"
+)
+
+// slotComfortCap bounds how much distance from the edge of anyone's working
+// day counts toward a slot's score; beyond an hour every slot is equally good.
+const slotComfortCap = 60
+
+type slotSpan struct {
+	start time.Time
+	end   time.Time
+}
+
+// slotPlace is a working-location span: where an attendee works at a time.
+type slotPlace struct {
+	slotSpan
+	allDay bool
+	place  string
+}
+
+type slotAttendee struct {
+	Email          string `json:"email"`
+	TimeZone       string `json:"timezone"`
+	TimeZoneSource string `json:"timezone_source"`
+	WorkingHours   string `json:"working_hours"`
+	FreeBusy       string `json:"freebusy"`
+
+	loc        *time.Location
+	hoursStart int // minutes after local midnight
+	hoursEnd   int
+	busy       []slotSpan
+	places     []slotPlace
+	working    []slotSpan
+}
+
+type slotSearch struct {
+	from     time.Time
+	to       time.Time
+	duration time.Duration
+	buffer   time.Duration
+	step     time.Duration
+	workday  func(time.Weekday) bool
+	inPerson bool
+	max      int
+	loc      *time.Location
+}
+
+type slotCandidate struct {
+	Rank           int               `json:"rank"`
+	Start          string            `json:"start"`
+	End            string            `json:"end"`
+	ComfortMinutes int               `json:"comfort_minutes"`
+	SameOffice     string            `json:"same_office,omitempty"`
+	Local          map[string]string `json:"local"`
+	Locations      map[string]string `json:"locations,omitempty"`
+
+	start time.Time
+	end   time.Time
+}
+
+// findSlots intersects every attendee's free working time and returns the
+// best non-overlapping candidates: furthest from anyone's early morning or
+// late evening first, then shared office days, then soonest. The first
+// pass takes one slot per day so the list offers a spread of days.
+func findSlots(search slotSearch, attendees []*slotAttendee) []*slotCandidate {
+	var common []slotSpan
+	for i, a := range attendees {
+		a.working = slotWorkingSpans(a, search)
+		busy := make([]slotSpan, 0, len(a.busy))
+		for _, b := range a.busy {
+			busy = append(busy, slotSpan{start: b.start.Add(-search.buffer), end: b.end.Add(search.buffer)})
+		}
+		window := []slotSpan{{start: search.from, end: search.to}}
+		free := subtractSlotSpans(intersectSlotSpans(a.working, window), busy)
+		if i == 0 {
+			common = free
+			continue
+		}
+		common = intersectSlotSpans(common, free)
+	}
+
+	var all []*slotCandidate
+	for _, span := range common {
+		for start := slotCeil(span.start, search.step); !start.Add(search.duration).After(span.end); start = start.Add(search.step) {
+			candidate := newSlotCandidate(start, start.Add(search.duration), attendees)
+			if search.inPerson && candidate.SameOffice == "" {
+				continue
+			}
+			all = append(all, candidate)
+		}
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		a, b := all[i], all[j]
+		if a.ComfortMinutes != b.ComfortMinutes {
+			return a.ComfortMinutes > b.ComfortMinutes
+		}
+		if (a.SameOffice != "") != (b.SameOffice != "") {
+			return a.SameOffice != ""
+		}
+		return a.start.Before(b.start)
+	})
+
+	picked := make([]*slotCandidate, 0, search.max)
+	days := map[string]bool{}
+	overlaps := func(c *slotCandidate) bool {
+		for _, p := range picked {
+			if c.start.Before(p.end) && p.start.Before(c.end) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, onePerDay := range []bool{true, false} {
+		for _, c := range all {
+			if len(picked) >= search.max {
+				break
+			}
+			day := c.start.In(search.loc).Format("2006-01-02")
+			if (onePerDay && days[day]) || overlaps(c) {
+				continue
+			}
+			days[day] = true
+			picked = append(picked, c)
+		}
+	}
+	for i, c := range picked {
+		c.Rank = i + 1
+		c.Start = c.start.In(search.loc).Format(time.RFC3339)
+		c.End = c.end.In(search.loc).Format(time.RFC3339)
+	}
+	return picked
+}
+
+func newSlotCandidate(start, end time.Time, attendees []*slotAttendee) *slotCandidate {
+	c := &slotCandidate{
+		start:          start,
+		end:            end,
+		ComfortMinutes: slotComfortCap,
+		Local:          map[string]string{},
+		Locations:      map[string]string{},
+	}
+	office := ""
+	for i, a := range attendees {
+		for _, w := range a.working {
+			if !start.Before(w.start) && !end.After(w.end) {
+				margin := int(min(start.Sub(w.start), w.end.Sub(end)) / time.Minute)
+				c.ComfortMinutes = min(c.ComfortMinutes, margin)
+				break
+			}
+		}
+		localStart, localEnd := start.In(a.loc), end.In(a.loc)
+		c.Local[a.Email] = fmt.Sprintf("%s-%s", localStart.Format("Mon 15:04"), localEnd.Format("15:04 MST"))
+
+		place := slotPlaceAt(a, start)
+		if place != "" {
+			c.Locations[a.Email] = place
+		}
+		switch {
+		case !strings.HasPrefix(place, "office"):
+			office = "-"
+		case i == 0:
+			office = place
+		case place != office:
+			office = "-"
+		}
+	}
+	if office != "-" {
+		c.SameOffice = strings.TrimPrefix(strings.TrimPrefix(office, "office"), ":")
+		if c.SameOffice == "" {
+			c.SameOffice = "office"
+		}
+	}
+	if len(c.Locations) == 0 {
+		c.Locations = nil
+	}
+	return c
+}
+
+// slotWorkingSpans lists an attendee's working hours on workdays, in their
+// own time zone, for every day the search window touches. The spans keep
+// their real edges so slots are scored against the actual working day.
+func slotWorkingSpans(a *slotAttendee, search slotSearch) []slotSpan {
+	var spans []slotSpan
+	first := search.from.In(a.loc)
+	day := time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, a.loc).AddDate(0, 0, -1)
+	for ; day.Before(search.to); day = day.AddDate(0, 0, 1) {
+		if !search.workday(day.Weekday()) {
+			continue
+		}
+		start := time.Date(day.Year(), day.Month(), day.Day(), 0, a.hoursStart, 0, 0, a.loc)
+		end := time.Date(day.Year(), day.Month(), day.Day(), 0, a.hoursEnd, 0, 0, a.loc)
+		if start.Before(search.to) && end.After(search.from) {
+			spans = append(spans, slotSpan{start: start, end: end})
+		}
+	}
+	return spans
+}
+
+func subtractSlotSpans(base, remove []slotSpan) []slotSpan {
+	out := base
+	for _, r := range remove {
+		next := make([]slotSpan, 0, len(out))
+		for _, s := range out {
+			if !r.start.Before(s.end) || !s.start.Before(r.end) {
+				next = append(next, s)
+				continue
+			}
+			if s.start.Before(r.start) {
+				next = append(next, slotSpan{start: s.start, end: r.start})
+			}
+			if r.end.Before(s.end) {
+				next = append(next, slotSpan{start: r.end, end: s.end})
+			}
+		}
+		out = next
+	}
+	return out
+}
+
+func intersectSlotSpans(a, b []slotSpan) []slotSpan {
+	var out []slotSpan
+	for _, x := range a {
+		for _, y := range b {
+			start, end := maxTime(x.start, y.start), minTime(x.end, y.end)
+			if start.Before(end) {
+				out = append(out, slotSpan{start: start, end: end})
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].start.Before(out[j].start) })
+	return out
+}
+
+func slotCeil(t time.Time, step time.Duration) time.Time {
+	truncated := t.Truncate(step)
+	if truncated.Before(t) {
+		return truncated.Add(step)
+	}
+	return truncated
+}
+
+func slotPlaceAt(a *slotAttendee, t time.Time) string {
+	place := ""
+	for _, p := range a.places {
+		if t.Before(p.start) || !t.Before(p.end) {
+			continue
+		}
+		if !p.allDay {
+			return p.place
+		}
+		place = p.place
+	}
+	return place
+}
+
+// slotPlacesFromEvents turns workingLocation events into places:
+// "home", "office[:label]", or "custom[:label]".
+func slotPlacesFromEvents(events []*calendar.Event, loc *time.Location) []slotPlace {
+	var places []slotPlace
+	for _, ev := range events {
+		if ev == nil || ev.WorkingLocationProperties == nil || ev.Start == nil || ev.End == nil {
+			continue
+		}
+		props := ev.WorkingLocationProperties
+		place := ""
+		switch props.Type {
+		case "homeOffice":
+			place = "home"
+		case "officeLocation":
+			place = "office"
+			if props.OfficeLocation != nil {
+				if label := firstNonEmpty(props.OfficeLocation.Label, props.OfficeLocation.BuildingId); label != "" {
+					place += ":" + label
+				}
+			}
+		case "customLocation":
+			place = "custom"
+			if props.CustomLocation != nil && props.CustomLocation.Label != "" {
+				place += ":" + props.CustomLocation.Label
+			}
+		default:
+			continue
+		}
+		span, allDay, ok := slotEventSpan(ev, loc)
+		if ok {
+			places = append(places, slotPlace{slotSpan: span, allDay: allDay, place: place})
+		}
+	}
+	return places
+}
+
+func slotEventSpan(ev *calendar.Event, loc *time.Location) (slotSpan, bool, bool) {
+	if ev.Start.Date != "" {
+		start, err1 := time.ParseInLocation("2006-01-02", ev.Start.Date, loc)
+		end, err2 := time.ParseInLocation("2006-01-02", ev.End.Date, loc)
+		if err1 != nil || err2 != nil {
+			return slotSpan{}, false, false
+		}
+		return slotSpan{start: start, end: end}, true, true
+	}
+	start, err1 := time.Parse(time.RFC3339, ev.Start.DateTime)
+	end, err2 := time.Parse(time.RFC3339, ev.End.DateTime)
+	if err1 != nil || err2 != nil {
+		return slotSpan{}, false, false
+	}
+	return slotSpan{start: start, end: end}, false, true
+}
+
+// parseWorkingHours parses "09:00-17:00" (or "9am-5pm") into minutes after
+// midnight.
+func parseWorkingHours(value string) (int, int, error) {
+	startRaw, endRaw, ok := strings.Cut(strings.TrimSpace(value), "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid working hours %q (expected HH:MM-HH:MM)", value)
+	}
+	sh, sm, _, ok1 := timeparse.ParseClock(startRaw)
+	eh, em, _, ok2 := timeparse.ParseClock(endRaw)
+	if !ok1 || !ok2 {
+		return 0, 0, fmt.Errorf("invalid working hours %q (expected HH:MM-HH:MM)", value)
+	}
+	start, end := sh*60+sm, eh*60+em
+	if end == 0 {
+		end = 24 * 60
+	}
+	if end <= start {
+		return 0, 0, fmt.Errorf("invalid working hours %q: end must be after start", value)
+	}
+	return start, end, nil
+}
+
+// parseWorkdays parses "mon-fri", "sun-thu", or "mon,tue,thu".
+func parseWorkdays(value string) (func(time.Weekday) bool, error) {
+	days := map[time.Weekday]bool{}
+	for _, part := range splitCSV(value) {
+		if from, to, isRange := strings.Cut(part, "-"); isRange {
+			start, ok1 := timeparse.ParseWeekdayName(from)
+			end, ok2 := timeparse.ParseWeekdayName(to)
+			if !ok1 || !ok2 {
+				return nil, fmt.Errorf("invalid workdays %q", value)
+			}
+			for d := start; ; d = (d + 1) % 7 {
+				days[d] = true
+				if d == end {
+					break
+				}
+			}
+			continue
+		}
+		d, ok := timeparse.ParseWeekdayName(part)
+		if !ok {
+			return nil, fmt.Errorf("invalid workdays %q", value)
+		}
+		days[d] = true
+	}
+	if len(days) == 0 {
+		return nil, fmt.Errorf("invalid workdays %q", value)
+	}
+	return func(d time.Weekday) bool { return days[d] }, nil
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
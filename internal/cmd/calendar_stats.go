@@ -0,0 +1,303 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+const (
+	calendarStatsNoAttendees = "(none)"
+	calendarStatsRecurring   = "recurring"
+	calendarStatsOneOff      = "one-off"
+)
+
+type CalendarStatsCmd struct {
+	Cal       []string `name:"cal" help:"Calendar ID, name, or index (can be repeated; default: primary)"`
+	Calendars string   `name:"calendars" help:"Comma-separated calendar IDs, names, or indices from 'calendar calendars'"`
+	All       bool     `name:"all" help:"Include all calendars"`
+	Format    string   `name:"format" help:"Output format: table, json, or csv" enum:"table,json,csv" default:"table"`
+	TimeRangeFlags
+}
+
+// calendarStatsRow is one bucket of one dimension. Minutes count timed
+// events only; all-day events are counted separately so a day of OOO or a
+// working-location marker does not read as 24 hours of meetings.
+type calendarStatsRow struct {
+	Dimension string `json:"-"`
+	Key       string `json:"key"`
+	Label     string `json:"label,omitempty"`
+	Events    int    `json:"events"`
+	Minutes   int    `json:"minutes"`
+	AllDay    int    `json:"all_day_events"`
+}
+
+type calendarStatsReport struct {
+	From         string              `json:"from"`
+	To           string              `json:"to"`
+	Calendars    []string            `json:"calendars"`
+	Totals       calendarStatsRow    `json:"totals"`
+	Skipped      int                 `json:"skipped_not_accepted"`
+	ByColor      []*calendarStatsRow `json:"by_color"`
+	ByEventType  []*calendarStatsRow `json:"by_event_type"`
+	ByDomain     []*calendarStatsRow `json:"by_attendee_domain"`
+	ByRecurrence []*calendarStatsRow `json:"by_recurrence"`
+	MeetingsDay  []*calendarStatsRow `json:"meetings_per_day"`
+}
+
+func (c *CalendarStatsCmd) Run(ctx context.Context, flags *RootFlags) error {
+	_, svc, err := requireCalendarService(ctx, flags)
+	if err != nil {
+		return err
+	}
+	store, err := commandConfigStore(ctx)
+	if err != nil {
+		return err
+	}
+	calendarIDs, err := resolveSelectedCalendarIDs(ctx, store, svc, c.Cal, c.Calendars, c.All, true)
+	if err != nil {
+		return err
+	}
+	// Stats look back: without flags, report the last seven days.
+	tr, err := ResolveTimeRangeWithDefaults(ctx, svc, c.TimeRangeFlags, TimeRangeDefaults{
+		FromOffset:   -7 * 24 * time.Hour,
+		ToOffset:     0,
+		ToFromOffset: 7 * 24 * time.Hour,
+	})
+	if err != nil {
+		return err
+	}
+	from, to := tr.FormatRFC3339()
+
+	colorLabels := map[string]string{}
+	if colors, colorErr := svc.Colors.Get().Context(ctx).Do(); colorErr == nil {
+		for id, def := range colors.Event {
+			colorLabels[id] = def.Background
+		}
+	}
+
+	var events []*calendar.Event
+	for _, calID := range calendarIDs {
+		items, _, listErr := loadPagedItems("", true, func(pageToken string) ([]*calendar.Event, string, error) {
+			resp, err := calendarEventsListCall(ctx, svc, calID, from, to, 2500, "", "", "", "", pageToken).Do()
+			if err != nil {
+				return nil, "", err
+			}
+			return resp.Items, resp.NextPageToken, nil
+		})
+		if listErr != nil {
+			return fmt.Errorf("calendar %s: %w", calID, listErr)
+		}
+		events = append(events, items...)
+	}
+
+	report := buildCalendarStats(events, tr, colorLabels)
+	report.Calendars = calendarIDs
+
+	format := c.Format
+	if outfmt.IsJSON(ctx) {
+		format = "json"
+	}
+	switch format {
+	case "json":
+		return outfmt.WriteJSON(ctx, stdoutWriter(ctx), report)
+	case "csv":
+		return writeCalendarStatsCSV(stdoutWriter(ctx), report)
+	}
+
+	u := ui.FromContext(ctx)
+	u.Out().Linef("%s - %s: %d events, %s accepted (%d all-day, %d not accepted skipped)",
+		report.From, report.To, report.Totals.Events, formatStatsHours(report.Totals.Minutes), report.Totals.AllDay, report.Skipped)
+	return outfmt.WriteTable(ctx, stdoutWriter(ctx), report.rows(), calendarStatsColumns())
+}
+
+// buildCalendarStats aggregates accepted events. An event shared by several
+// selected calendars is counted once.
+func buildCalendarStats(events []*calendar.Event, tr *TimeRange, colorLabels map[string]string) *calendarStatsReport {
+	report := &calendarStatsReport{
+		From:   tr.From.Format(time.RFC3339),
+		To:     tr.To.Format(time.RFC3339),
+		Totals: calendarStatsRow{Key: "total"},
+	}
+	buckets := map[string]map[string]*calendarStatsRow{}
+	add := func(dimension, key, label string, minutes int, allDay bool) {
+		if buckets[dimension] == nil {
+			buckets[dimension] = map[string]*calendarStatsRow{}
+		}
+		row := buckets[dimension][key]
+		if row == nil {
+			row = &calendarStatsRow{Dimension: dimension, Key: key, Label: label}
+			buckets[dimension][key] = row
+		}
+		row.Events++
+		row.Minutes += minutes
+		if allDay {
+			row.AllDay++
+		}
+	}
+	// Every day in the range gets a row, so quiet days show as zero.
+	dayRows := map[string]*calendarStatsRow{}
+	for day := startOfDay(tr.From); day.Before(tr.To); day = day.AddDate(0, 0, 1) {
+		key := day.Format("2006-01-02")
+		row := &calendarStatsRow{Dimension: "day", Key: key, Label: day.Format("Mon")}
+		dayRows[key] = row
+		report.MeetingsDay = append(report.MeetingsDay, row)
+	}
+
+	seen := map[string]bool{}
+	for _, ev := range events {
+		if ev == nil || ev.Status == "cancelled" || ev.Start == nil || ev.End == nil {
+			continue
+		}
+		dedupeKey := firstNonEmpty(ev.ICalUID, ev.Id) + "|" + calendarImportInstant(ev.Start)
+		if seen[dedupeKey] {
+			continue
+		}
+		seen[dedupeKey] = true
+		if !calendarStatsAccepted(ev) {
+			report.Skipped++
+			continue
+		}
+
+		allDay := ev.Start.Date != ""
+		minutes := 0
+		if !allDay {
+			start, end := eventDatePointInstant(ev.Start), eventDatePointInstant(ev.End)
+			start, end = maxTime(start, tr.From), minTime(end, tr.To)
+			if end.After(start) {
+				minutes = int(end.Sub(start) / time.Minute)
+			}
+		}
+		report.Totals.Events++
+		report.Totals.Minutes += minutes
+		if allDay {
+			report.Totals.AllDay++
+		}
+
+		colorID := firstNonEmpty(ev.ColorId, "default")
+		add("color", colorID, colorLabels[ev.ColorId], minutes, allDay)
+		add("event_type", firstNonEmpty(ev.EventType, eventTypeDefault), "", minutes, allDay)
+		recurrence := calendarStatsOneOff
+		if ev.RecurringEventId != "" {
+			recurrence = calendarStatsRecurring
+		}
+		add("recurrence", recurrence, "", minutes, allDay)
+		domains := calendarStatsDomains(ev)
+		if len(domains) == 0 {
+			add("domain", calendarStatsNoAttendees, "", minutes, allDay)
+		}
+		for _, domain := range domains {
+			add("domain", domain, "", minutes, allDay)
+		}
+		if len(domains) > 0 && !allDay {
+			if row := dayRows[eventDatePointInstant(ev.Start).In(tr.Location).Format("2006-01-02")]; row != nil {
+				row.Events++
+				row.Minutes += minutes
+			}
+		}
+	}
+
+	sorted := func(dimension string) []*calendarStatsRow {
+		rows := make([]*calendarStatsRow, 0, len(buckets[dimension]))
+		for _, row := range buckets[dimension] {
+			rows = append(rows, row)
+		}
+		sort.Slice(rows, func(i, j int) bool {
+			if rows[i].Minutes != rows[j].Minutes {
+				return rows[i].Minutes > rows[j].Minutes
+			}
+			if rows[i].Events != rows[j].Events {
+				return rows[i].Events > rows[j].Events
+			}
+			return rows[i].Key < rows[j].Key
+		})
+		return rows
+	}
+	report.ByColor = sorted("color")
+	report.ByEventType = sorted("event_type")
+	report.ByDomain = sorted("domain")
+	report.ByRecurrence = sorted("recurrence")
+	return report
+}
+
+// calendarStatsAccepted reports whether the event is time you committed
+// to: your own events, and invitations you accepted. Events that list you
+// neither as attendee nor as organizer or creator (a shared calendar's
+// entries, say) are not yours.
+func calendarStatsAccepted(ev *calendar.Event) bool {
+	for _, attendee := range ev.Attendees {
+		if attendee != nil && attendee.Self {
+			return attendee.ResponseStatus == "accepted"
+		}
+	}
+	return (ev.Organizer != nil && ev.Organizer.Self) || (ev.Creator != nil && ev.Creator.Self)
+}
+
+// calendarStatsDomains lists the distinct email domains of the other
+// people on an event, skipping rooms and other resources.
+func calendarStatsDomains(ev *calendar.Event) []string {
+	var domains []string
+	for _, attendee := range ev.Attendees {
+		if attendee == nil || attendee.Self || attendee.Resource {
+			continue
+		}
+		_, domain, ok := strings.Cut(strings.ToLower(strings.TrimSpace(attendee.Email)), "@")
+		if !ok || domain == "" || strings.HasSuffix(domain, "calendar.google.com") {
+			continue
+		}
+		if !slices.Contains(domains, domain) {
+			domains = append(domains, domain)
+		}
+	}
+	sort.Strings(domains)
+	return domains
+}
+
+func (r *calendarStatsReport) rows() []*calendarStatsRow {
+	var rows []*calendarStatsRow
+	for _, group := range [][]*calendarStatsRow{r.ByColor, r.ByEventType, r.ByDomain, r.ByRecurrence, r.MeetingsDay} {
+		rows = append(rows, group...)
+	}
+	return rows
+}
+
+func calendarStatsColumns() []outfmt.Column[*calendarStatsRow] {
+	return []outfmt.Column[*calendarStatsRow]{
+		{Header: "DIMENSION", Value: func(row *calendarStatsRow) string { return row.Dimension }},
+		{Header: "KEY", Value: func(row *calendarStatsRow) string { return sanitizeTab(row.Key) }},
+		{Header: "LABEL", Value: func(row *calendarStatsRow) string { return row.Label }},
+		{Header: "EVENTS", Value: func(row *calendarStatsRow) string { return strconv.Itoa(row.Events) }},
+		{Header: "HOURS", Value: func(row *calendarStatsRow) string { return formatStatsHours(row.Minutes) }},
+		{Header: "ALL-DAY", Value: func(row *calendarStatsRow) string { return strconv.Itoa(row.AllDay) }},
+	}
+}
+
+func writeCalendarStatsCSV(w io.Writer, report *calendarStatsReport) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"dimension", "key", "label", "events", "minutes", "all_day_events"}); err != nil {
+		return err
+	}
+	rows := append([]*calendarStatsRow{{Dimension: "total", Key: "total", Events: report.Totals.Events, Minutes: report.Totals.Minutes, AllDay: report.Totals.AllDay}}, report.rows()...)
+	for _, row := range rows {
+		if err := cw.Write([]string{row.Dimension, row.Key, row.Label, strconv.Itoa(row.Events), strconv.Itoa(row.Minutes), strconv.Itoa(row.AllDay)}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatStatsHours(minutes int) string {
+	return strconv.FormatFloat(float64(minutes)/60, 'f', 1, 64) + "h"
+}
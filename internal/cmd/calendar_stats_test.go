@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCalendarStatsAggregatesAcceptedTime(t *testing.T) {
+	svc, cleanup := newCalendarServiceForTest(t, withPrimaryCalendar(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/colors" && r.Method == http.MethodGet:
+			_ = json.NewEncoder(w).Encode(map[string]any{"event": map[string]any{"5": map[string]any{"background": "#fbd75b"}}})
+		case r.URL.Path == "/calendars/primary/events" && r.Method == http.MethodGet:
+			timed := func(id, start, end string, extra map[string]any) map[string]any {
+				out := map[string]any{"id": id, "iCalUID": id, "start": map[string]any{"dateTime": start}, "end": map[string]any{"dateTime": end}}
+				for k, v := range extra {
+					out[k] = v
+				}
+				return out
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"items": []map[string]any{
+				timed("standup", "2030-01-07T09:00:00Z", "2030-01-07T09:30:00Z", map[string]any{
+					"recurringEventId": "standup-series", "colorId": "5",
+					"attendees": []map[string]any{
+						{"email": "me@b.com", "self": true, "responseStatus": "accepted"},
+						{"email": "ann@b.com"}, {"email": "client@acme.com"},
+						{"email": "room@resource.calendar.google.com", "resource": true},
+					},
+				}),
+				timed("declined", "2030-01-07T11:00:00Z", "2030-01-07T12:00:00Z", map[string]any{
+					"attendees": []map[string]any{{"email": "me@b.com", "self": true, "responseStatus": "declined"}, {"email": "ann@b.com"}},
+				}),
+				timed("focus", "2030-01-08T13:00:00Z", "2030-01-08T15:00:00Z", map[string]any{"eventType": "focusTime", "organizer": map[string]any{"self": true}}),
+				timed("shared", "2030-01-08T16:00:00Z", "2030-01-08T17:00:00Z", map[string]any{"organizer": map[string]any{"email": "team@group.calendar.google.com"}}),
+				{"id": "ooo", "iCalUID": "ooo", "eventType": "outOfOffice", "creator": map[string]any{"self": true}, "start": map[string]any{"date": "2030-01-09"}, "end": map[string]any{"date": "2030-01-10"}},
+			}})
+		default:
+			http.NotFound(w, r)
+		}
+	})))
+	defer cleanup()
+
+	result := executeWithCalendarTestService(t, []string{"--json", "--account", "a@b.com", "calendar", "stats", "--from", "2030-01-07", "--to", "2030-01-09"}, svc)
+	if result.err != nil {
+		t.Fatalf("stats: %v", result.err)
+	}
+	var report struct {
+		Totals       calendarStatsRow    `json:"totals"`
+		Skipped      int                 `json:"skipped_not_accepted"`
+		ByColor      []*calendarStatsRow `json:"by_color"`
+		ByEventType  []*calendarStatsRow `json:"by_event_type"`
+		ByDomain     []*calendarStatsRow `json:"by_attendee_domain"`
+		ByRecurrence []*calendarStatsRow `json:"by_recurrence"`
+		MeetingsDay  []*calendarStatsRow `json:"meetings_per_day"`
+	}
+	if err := json.Unmarshal([]byte(result.stdout), &report); err != nil {
+		t.Fatalf("decode: %v\n%s", err, result.stdout)
+	}
+	if report.Totals.Events != 3 || report.Totals.Minutes != 150 || report.Totals.AllDay != 1 || report.Skipped != 2 {
+		t.Fatalf("totals = %+v skipped=%d", report.Totals, report.Skipped)
+	}
+	if report.ByEventType[0].Key != "focusTime" || report.ByEventType[0].Minutes != 120 {
+		t.Fatalf("by event type = %+v", report.ByEventType[0])
+	}
+	var domains []string
+	for _, row := range report.ByDomain {
+		domains = append(domains, row.Key)
+	}
+	if strings.Join(domains, ",") != "(none),acme.com,b.com" {
+		t.Fatalf("domains = %v", domains)
+	}
+	colorFound := false
+	for _, row := range report.ByColor {
+		if row.Key == "5" && row.Label == "#fbd75b" && row.Minutes == 30 {
+			colorFound = true
+		}
+	}
+	if !colorFound {
+		t.Fatalf("by color = %+v", report.ByColor)
+	}
+	if len(report.MeetingsDay) != 3 || report.MeetingsDay[0].Events != 1 || report.MeetingsDay[1].Events != 0 {
+		t.Fatalf("meetings per day = %+v", report.MeetingsDay)
+	}
+
+	result = executeWithCalendarTestService(t, []string{"--account", "a@b.com", "calendar", "stats", "--from", "2030-01-07", "--to", "2030-01-09", "--format", "csv"}, svc)
+	if result.err != nil {
+		t.Fatalf("stats csv: %v", result.err)
+	}
+	if !strings.HasPrefix(result.stdout, "dimension,key,label,events,minutes,all_day_events\ntotal,total,,3,150,1\n") ||
+		!strings.Contains(result.stdout, "recurrence,recurring,,1,30,0\n") {
+		t.Fatalf("csv = %s", result.stdout)
+	}
+}
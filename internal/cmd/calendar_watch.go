@@ -0,0 +1,674 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/googleapi"
+
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+const (
+	calendarWatchStateKind   = "calendar_watch"
+	calendarWatchUpdated     = "updated"
+	calendarWatchCreated     = "created"
+	calendarWatchCancelled   = "cancelled"
+	calendarWatchHookTimeout = 30 * time.Second
+	calendarWatchMaxPages    = 1000
+)
+
+// CalendarWatchCmd groups the change-feed subcommands.
+type CalendarWatchCmd struct {
+	Start CalendarWatchStartCmd `cmd:"" name:"start" aliases:"create" help:"Create an events.watch push channel and seed a sync token"`
+	Stop  CalendarWatchStopCmd  `cmd:"" name:"stop" help:"Stop a push channel"`
+	Poll  CalendarWatchPollCmd  `cmd:"" name:"poll" help:"Poll for event changes with a sync token"`
+	Serve CalendarWatchServeCmd `cmd:"" name:"serve" help:"Receive push notifications and emit event changes"`
+}
+
+type calendarWatchChannelState struct {
+	ID          string `json:"id"`
+	ResourceID  string `json:"resource_id"`
+	ResourceURI string `json:"resource_uri,omitempty"`
+	Expiration  int64  `json:"expiration_ms,omitempty"`
+	WebhookURL  string `json:"webhook_url,omitempty"`
+	TokenHash   string `json:"token_sha256,omitempty"`
+}
+
+// calendarWatchState is shared by start, stop, poll, and serve so a channel
+// created by start can be served and later stopped from the same file.
+type calendarWatchState struct {
+	Version           int                        `json:"version"`
+	Kind              string                     `json:"kind"`
+	CalendarID        string                     `json:"calendar_id"`
+	SyncToken         string                     `json:"sync_token"`
+	SyncedAt          string                     `json:"synced_at,omitempty"`
+	Channel           *calendarWatchChannelState `json:"channel,omitempty"`
+	LastMessageID     string                     `json:"last_message_channel_id,omitempty"`
+	LastMessageNumber uint64                     `json:"last_message_number,omitempty"`
+	UpdatedAt         string                     `json:"updated_at"`
+}
+
+// calendarWatchChange is the normalized shape emitted for each changed event.
+type calendarWatchChange struct {
+	Change           string `json:"change"`
+	EventID          string `json:"eventId"`
+	ICalUID          string `json:"iCalUID,omitempty"`
+	RecurringEventID string `json:"recurringEventId,omitempty"`
+	Status           string `json:"status,omitempty"`
+	Summary          string `json:"summary,omitempty"`
+	Start            string `json:"start,omitempty"`
+	End              string `json:"end,omitempty"`
+	AllDay           bool   `json:"allDay,omitempty"`
+	Updated          string `json:"updated,omitempty"`
+	HTMLLink         string `json:"htmlLink,omitempty"`
+}
+
+type calendarWatchEvent struct {
+	Kind          string                `json:"kind"`
+	Source        string                `json:"source"`
+	CalendarID    string                `json:"calendarId"`
+	ChannelID     string                `json:"channelId,omitempty"`
+	MessageNumber uint64                `json:"messageNumber,omitempty"`
+	SyncToken     string                `json:"syncToken"`
+	NextSyncToken string                `json:"nextSyncToken"`
+	Changes       []calendarWatchChange `json:"changes"`
+}
+
+// calendarWatchOutput holds where change batches go besides stdout.
+type calendarWatchOutput struct {
+	onChange   string
+	hookURL    string
+	hookToken  string
+	hookClient *http.Client
+}
+
+type CalendarWatchStartCmd struct {
+	Calendar     string        `name:"calendar" aliases:"cal" help:"Calendar ID, name, or index (default: primary)"`
+	WebhookURL   string        `name:"webhook-url" required:"" help:"HTTPS webhook URL for Calendar push notifications"`
+	ChannelID    string        `name:"channel-id" help:"Webhook channel ID (default: generated)"`
+	ChannelToken string        `name:"channel-token" help:"Opaque token echoed by Google in webhook notifications"`
+	TTL          time.Duration `name:"ttl" help:"Requested channel lifetime (0: Google's default)" default:"0"`
+	StateFile    string        `name:"state-file" help:"JSON file to record the channel and a fresh sync token for serve/poll/stop"`
+}
+
+func (c *CalendarWatchStartCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	webhookURL := strings.TrimSpace(c.WebhookURL)
+	if webhookURL == "" {
+		return usage("missing --webhook-url")
+	}
+	if err := validateDriveChangesWebhookURL(webhookURL); err != nil {
+		return err
+	}
+	if c.TTL < 0 {
+		return usage("--ttl must be >= 0")
+	}
+	statePath := ""
+	if strings.TrimSpace(c.StateFile) != "" {
+		var err error
+		if statePath, err = expandPollStatePath(c.StateFile); err != nil {
+			return err
+		}
+	}
+	channelID := strings.TrimSpace(c.ChannelID)
+	if channelID == "" {
+		var err error
+		if channelID, err = randomChannelID(); err != nil {
+			return err
+		}
+	}
+	channelToken := strings.TrimSpace(c.ChannelToken)
+
+	if err := dryRunExit(ctx, flags, "calendar.watch.start", map[string]any{
+		"calendar":      firstNonEmpty(strings.TrimSpace(c.Calendar), primaryCalendarID),
+		"webhook_url":   webhookURL,
+		"channel_id":    channelID,
+		"channel_token": channelToken != "",
+		"ttl":           c.TTL.String(),
+		"state_file":    statePath,
+	}); err != nil {
+		return err
+	}
+
+	_, svc, err := requireCalendarService(ctx, flags)
+	if err != nil {
+		return err
+	}
+	calendarID, err := resolveCalendarID(ctx, svc, firstNonEmpty(strings.TrimSpace(c.Calendar), primaryCalendarID))
+	if err != nil {
+		return err
+	}
+
+	// Seed the sync token before creating the channel, so changes made in
+	// between show up in the first sync instead of being lost.
+	var state calendarWatchState
+	if statePath != "" {
+		var existing calendarWatchState
+		exists, readErr := readCalendarWatchState(statePath, &existing)
+		if readErr != nil {
+			return readErr
+		}
+		switch {
+		case exists && existing.Channel != nil:
+			return usagef("state file already records channel %s; run 'calendar watch stop --state-file' first", existing.Channel.ID)
+		case exists && existing.CalendarID != calendarID:
+			return usagef("watch state calendar_id %q does not match --calendar %q", existing.CalendarID, calendarID)
+		case exists && existing.SyncToken != "":
+			// Renewing a channel keeps the sync token, so nothing changed
+			// while no channel existed is lost.
+			state = existing
+			state.LastMessageID = ""
+			state.LastMessageNumber = 0
+		default:
+			syncToken, seedErr := seedCalendarSyncToken(ctx, svc, calendarID)
+			if seedErr != nil {
+				return seedErr
+			}
+			now := time.Now().UTC().Format(time.RFC3339Nano)
+			state = calendarWatchState{
+				Version:    pollStateVersion,
+				Kind:       calendarWatchStateKind,
+				CalendarID: calendarID,
+				SyncToken:  syncToken,
+				SyncedAt:   now,
+			}
+		}
+		state.UpdatedAt = time.Now().UTC().Format(time.RFC3339Nano)
+	}
+
+	channel := &calendar.Channel{
+		Id:      channelID,
+		Type:    "web_hook",
+		Address: webhookURL,
+		Token:   channelToken,
+	}
+	if c.TTL > 0 {
+		channel.Expiration = time.Now().Add(c.TTL).UnixMilli()
+	}
+	resp, err := svc.Events.Watch(calendarID, channel).Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+
+	if statePath != "" {
+		state.Channel = &calendarWatchChannelState{
+			ID:          resp.Id,
+			ResourceID:  resp.ResourceId,
+			ResourceURI: resp.ResourceUri,
+			Expiration:  resp.Expiration,
+			WebhookURL:  webhookURL,
+		}
+		if channelToken != "" {
+			state.Channel.TokenHash = channelTokenHash(channelToken)
+		}
+		if err := writePollState(statePath, state); err != nil {
+			return err
+		}
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, stdoutWriter(ctx), map[string]any{
+			"calendarId": calendarID,
+			"channel":    resp,
+			"stateFile":  statePath,
+		})
+	}
+	u.Out().Linef("calendarId\t%s", calendarID)
+	u.Out().Linef("id\t%s", resp.Id)
+	u.Out().Linef("resourceId\t%s", resp.ResourceId)
+	u.Out().Linef("resourceUri\t%s", resp.ResourceUri)
+	u.Out().Linef("expiration\t%d", resp.Expiration)
+	if statePath != "" {
+		u.Out().Linef("stateFile\t%s", statePath)
+	}
+	return nil
+}
+
+type CalendarWatchStopCmd struct {
+	ChannelID  string `arg:"" optional:"" name:"channelId" help:"Webhook channel ID (default: from --state-file)"`
+	ResourceID string `arg:"" optional:"" name:"resourceId" help:"Webhook resource ID returned by start (default: from --state-file)"`
+	StateFile  string `name:"state-file" help:"State file written by 'calendar watch start'; the channel is cleared from it"`
+}
+
+func (c *CalendarWatchStopCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+	channelID := strings.TrimSpace(c.ChannelID)
+	resourceID := strings.TrimSpace(c.ResourceID)
+
+	statePath := ""
+	var state calendarWatchState
+	if strings.TrimSpace(c.StateFile) != "" {
+		var err error
+		if statePath, err = expandPollStatePath(c.StateFile); err != nil {
+			return err
+		}
+		exists, readErr := readCalendarWatchState(statePath, &state)
+		if readErr != nil {
+			return readErr
+		}
+		if exists && state.Channel != nil {
+			if channelID == "" && resourceID == "" {
+				channelID, resourceID = state.Channel.ID, state.Channel.ResourceID
+			}
+		}
+	}
+	if channelID == "" || resourceID == "" {
+		return usage("required: channelId resourceId (or --state-file with a recorded channel)")
+	}
+
+	if err := dryRunExit(ctx, flags, "calendar.watch.stop", map[string]any{
+		"channel_id":  channelID,
+		"resource_id": resourceID,
+		"state_file":  statePath,
+	}); err != nil {
+		return err
+	}
+
+	_, svc, err := requireCalendarService(ctx, flags)
+	if err != nil {
+		return err
+	}
+	if err := svc.Channels.Stop(&calendar.Channel{Id: channelID, ResourceId: resourceID}).Context(ctx).Do(); err != nil {
+		return err
+	}
+	if statePath != "" && state.Channel != nil && state.Channel.ID == channelID {
+		state.Channel = nil
+		state.LastMessageID = ""
+		state.LastMessageNumber = 0
+		state.UpdatedAt = time.Now().UTC().Format(time.RFC3339Nano)
+		if err := writePollState(statePath, state); err != nil {
+			return err
+		}
+	}
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, stdoutWriter(ctx), map[string]any{"stopped": true, "channelId": channelID, "resourceId": resourceID})
+	}
+	u.Out().Linef("stopped\ttrue")
+	return nil
+}
+
+type CalendarWatchPollCmd struct {
+	Calendar      string        `name:"calendar" aliases:"cal" help:"Calendar ID, name, or index for a new state file (default: primary)"`
+	StateFile     string        `name:"state-file" required:"" help:"JSON file that stores the calendar sync token"`
+	Interval      time.Duration `name:"interval" help:"Delay between polls" default:"60s"`
+	OnChange      string        `name:"on-change" help:"Trusted local shell command run for each non-empty batch; batch JSON is provided on stdin"`
+	HookURL       string        `name:"hook-url" help:"URL that receives each non-empty batch as a JSON POST"`
+	HookToken     string        `name:"hook-token" help:"Bearer token sent with --hook-url requests"`
+	MaxIterations int           `name:"max-iterations" help:"Stop after N polls; 0 runs until interrupted" default:"0"`
+}
+
+func (c *CalendarWatchPollCmd) Run(ctx context.Context, flags *RootFlags) error {
+	pollCtx, stop := pollSignalContext(ctx)
+	defer stop()
+	return c.run(pollCtx, flags, defaultPollRuntime())
+}
+
+func (c *CalendarWatchPollCmd) run(ctx context.Context, flags *RootFlags, runtime pollRuntime) error {
+	runtime = runtime.withDefaults()
+	statePath, err := expandPollStatePath(c.StateFile)
+	if err != nil {
+		return err
+	}
+	if c.Interval <= 0 {
+		return usage("--interval must be greater than zero")
+	}
+	if c.MaxIterations < 0 {
+		return usage("--max-iterations must be >= 0")
+	}
+	output, err := newCalendarWatchOutput(c.OnChange, c.HookURL, c.HookToken)
+	if err != nil {
+		return err
+	}
+
+	if dryRunErr := dryRunExit(ctx, flags, "calendar.watch.poll", map[string]any{
+		"calendar":        firstNonEmpty(strings.TrimSpace(c.Calendar), primaryCalendarID),
+		"state_file":      statePath,
+		"interval":        c.Interval.String(),
+		"max_iterations":  c.MaxIterations,
+		"hook_configured": output.onChange != "",
+		"hook_url_set":    output.hookURL != "",
+	}); dryRunErr != nil {
+		return dryRunErr
+	}
+
+	_, svc, err := requireCalendarService(ctx, flags)
+	if err != nil {
+		return err
+	}
+	state, err := initializeCalendarWatchState(ctx, svc, statePath, c.Calendar, runtime.now().UTC())
+	if err != nil {
+		return err
+	}
+
+	for iteration := 1; ; iteration++ {
+		next, event, syncErr := syncCalendarWatch(ctx, svc, state, runtime.now().UTC())
+		if syncErr != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return syncErr
+		}
+		if event != nil {
+			event.Source = "poll"
+			if err := output.deliver(ctx, runtime, *event); err != nil {
+				return err
+			}
+		}
+		if err := writePollState(statePath, next); err != nil {
+			return err
+		}
+		state = next
+
+		if c.MaxIterations > 0 && iteration >= c.MaxIterations {
+			return nil
+		}
+		if err := runtime.wait(ctx, c.Interval); err != nil {
+			return err
+		}
+	}
+}
+
+func readCalendarWatchState(path string, state *calendarWatchState) (bool, error) {
+	exists, err := readPollState(path, state)
+	if err != nil || !exists {
+		return exists, err
+	}
+	if state.Version != pollStateVersion {
+		return false, fmt.Errorf("unsupported calendar watch state version %d", state.Version)
+	}
+	if state.Kind != calendarWatchStateKind {
+		return false, fmt.Errorf("state file kind %q is not a calendar watch state; use a separate --state-file", state.Kind)
+	}
+	state.CalendarID = strings.TrimSpace(state.CalendarID)
+	state.SyncToken = strings.TrimSpace(state.SyncToken)
+	if state.CalendarID == "" {
+		return false, errors.New("calendar watch state has empty calendar_id")
+	}
+	return true, nil
+}
+
+// initializeCalendarWatchState loads the state file, or seeds a new one from
+// a full sync of the calendar when none exists yet.
+func initializeCalendarWatchState(ctx context.Context, svc *calendar.Service, statePath string, calendarFlag string, now time.Time) (calendarWatchState, error) {
+	var state calendarWatchState
+	exists, err := readCalendarWatchState(statePath, &state)
+	if err != nil {
+		return calendarWatchState{}, err
+	}
+	if exists {
+		if calendarFlag = strings.TrimSpace(calendarFlag); calendarFlag != "" {
+			calendarID, resolveErr := resolveCalendarID(ctx, svc, calendarFlag)
+			if resolveErr != nil {
+				return calendarWatchState{}, resolveErr
+			}
+			if calendarID != state.CalendarID {
+				return calendarWatchState{}, usagef("watch state calendar_id %q does not match --calendar %q", state.CalendarID, calendarID)
+			}
+		}
+		if state.SyncToken != "" {
+			return state, nil
+		}
+	} else {
+		calendarID, resolveErr := resolveCalendarID(ctx, svc, firstNonEmpty(strings.TrimSpace(calendarFlag), primaryCalendarID))
+		if resolveErr != nil {
+			return calendarWatchState{}, resolveErr
+		}
+		state = calendarWatchState{Version: pollStateVersion, Kind: calendarWatchStateKind, CalendarID: calendarID}
+	}
+	syncToken, err := seedCalendarSyncToken(ctx, svc, state.CalendarID)
+	if err != nil {
+		return calendarWatchState{}, err
+	}
+	state.SyncToken = syncToken
+	state.SyncedAt = now.Format(time.RFC3339Nano)
+	state.UpdatedAt = state.SyncedAt
+	if err := writePollState(statePath, state); err != nil {
+		return calendarWatchState{}, err
+	}
+	return state, nil
+}
+
+// seedCalendarSyncToken pages through a full sync and keeps only the final
+// sync token; existing events are the baseline, not changes.
+func seedCalendarSyncToken(ctx context.Context, svc *calendar.Service, calendarID string) (string, error) {
+	pageToken := ""
+	for range calendarWatchMaxPages {
+		call := svc.Events.List(calendarID).
+			MaxResults(2500).
+			Fields("nextPageToken", "nextSyncToken").
+			Context(ctx)
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+		resp, err := call.Do()
+		if err != nil {
+			return "", err
+		}
+		if resp.NextPageToken != "" {
+			pageToken = resp.NextPageToken
+			continue
+		}
+		if resp.NextSyncToken == "" {
+			return "", fmt.Errorf("calendar %s: full sync ended without nextSyncToken", calendarID)
+		}
+		return resp.NextSyncToken, nil
+	}
+	return "", fmt.Errorf("pagination exceeded max pages")
+}
+
+// loadCalendarChanges returns every event changed since syncToken.
+func loadCalendarChanges(ctx context.Context, svc *calendar.Service, calendarID string, syncToken string) ([]*calendar.Event, string, error) {
+	var events []*calendar.Event
+	pageToken := ""
+	for range calendarWatchMaxPages {
+		call := svc.Events.List(calendarID).
+			SyncToken(syncToken).
+			ShowDeleted(true).
+			MaxResults(2500).
+			Context(ctx)
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+		resp, err := call.Do()
+		if err != nil {
+			return nil, "", err
+		}
+		events = append(events, resp.Items...)
+		if resp.NextPageToken != "" {
+			pageToken = resp.NextPageToken
+			continue
+		}
+		if resp.NextSyncToken == "" {
+			return nil, "", fmt.Errorf("calendar %s: incremental sync ended without nextSyncToken", calendarID)
+		}
+		return events, resp.NextSyncToken, nil
+	}
+	return nil, "", fmt.Errorf("pagination exceeded max pages")
+}
+
+// syncCalendarWatch runs one incremental sync. It returns the next state and
+// a change batch, or a nil batch when nothing changed. An expired sync token
+// (410 Gone) re-seeds from a full sync without emitting changes.
+func syncCalendarWatch(ctx context.Context, svc *calendar.Service, state calendarWatchState, now time.Time) (calendarWatchState, *calendarWatchEvent, error) {
+	next := state
+	next.UpdatedAt = now.Format(time.RFC3339Nano)
+	events, nextSyncToken, err := loadCalendarChanges(ctx, svc, state.CalendarID, state.SyncToken)
+	if isGoneAPIError(err) {
+		ui.FromContext(ctx).Err().Linef("calendar watch: sync token expired for %s; re-seeding from a full sync", state.CalendarID)
+		if nextSyncToken, err = seedCalendarSyncToken(ctx, svc, state.CalendarID); err != nil {
+			return state, nil, err
+		}
+		next.SyncToken = nextSyncToken
+		next.SyncedAt = next.UpdatedAt
+		return next, nil, nil
+	}
+	if err != nil {
+		return state, nil, err
+	}
+	next.SyncToken = nextSyncToken
+	next.SyncedAt = next.UpdatedAt
+	if len(events) == 0 {
+		return next, nil, nil
+	}
+	var since time.Time
+	if state.SyncedAt != "" {
+		since, _ = time.Parse(time.RFC3339Nano, state.SyncedAt)
+	}
+	event := &calendarWatchEvent{
+		Kind:          "calendar_changes",
+		CalendarID:    state.CalendarID,
+		SyncToken:     state.SyncToken,
+		NextSyncToken: nextSyncToken,
+		Changes:       normalizeCalendarChanges(events, since),
+	}
+	return next, event, nil
+}
+
+func normalizeCalendarChanges(events []*calendar.Event, since time.Time) []calendarWatchChange {
+	changes := make([]calendarWatchChange, 0, len(events))
+	for _, ev := range events {
+		if ev == nil {
+			continue
+		}
+		change := calendarWatchChange{
+			Change:           classifyCalendarChange(ev, since),
+			EventID:          ev.Id,
+			ICalUID:          ev.ICalUID,
+			RecurringEventID: ev.RecurringEventId,
+			Status:           ev.Status,
+			Summary:          ev.Summary,
+			Updated:          ev.Updated,
+			HTMLLink:         ev.HtmlLink,
+		}
+		if ev.Start != nil {
+			change.Start = calendarImportInstant(ev.Start)
+			change.AllDay = ev.Start.Date != ""
+		}
+		if ev.End != nil {
+			change.End = calendarImportInstant(ev.End)
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// classifyCalendarChange labels an event relative to the previous sync: an
+// event created since then is new to the consumer even if it was edited
+// again before this sync ran.
+func classifyCalendarChange(ev *calendar.Event, since time.Time) string {
+	if ev.Status == calendarWatchCancelled {
+		return calendarWatchCancelled
+	}
+	created, createdErr := time.Parse(time.RFC3339Nano, ev.Created)
+	if createdErr != nil {
+		return calendarWatchUpdated
+	}
+	if !since.IsZero() {
+		if !created.Before(since) {
+			return calendarWatchCreated
+		}
+		return calendarWatchUpdated
+	}
+	if updated, err := time.Parse(time.RFC3339Nano, ev.Updated); err == nil && updated.Sub(created) < 2*time.Second {
+		return calendarWatchCreated
+	}
+	return calendarWatchUpdated
+}
+
+func isGoneAPIError(err error) bool {
+	var gerr *googleapi.Error
+	if errors.As(err, &gerr) {
+		return gerr.Code == http.StatusGone
+	}
+	return false
+}
+
+func newCalendarWatchOutput(onChange string, hookURL string, hookToken string) (calendarWatchOutput, error) {
+	output := calendarWatchOutput{
+		onChange:  strings.TrimSpace(onChange),
+		hookURL:   strings.TrimSpace(hookURL),
+		hookToken: strings.TrimSpace(hookToken),
+	}
+	if output.hookURL == "" {
+		if output.hookToken != "" {
+			return calendarWatchOutput{}, usage("--hook-token requires --hook-url")
+		}
+		return output, nil
+	}
+	parsed, err := url.Parse(output.hookURL)
+	if err != nil || (parsed.Scheme != driveChangesWebhookSchemeHTTPS && parsed.Scheme != driveChangesServerSchemeHTTP) || parsed.Host == "" {
+		return calendarWatchOutput{}, usage("--hook-url must be an absolute HTTP or HTTPS URL")
+	}
+	output.hookClient = &http.Client{Timeout: calendarWatchHookTimeout}
+	return output, nil
+}
+
+// deliver writes the batch to stdout, then runs the shell hook and posts to
+// the hook URL. A hook failure stops the caller before the sync token is
+// persisted, so the batch is retried on the next sync.
+func (o calendarWatchOutput) deliver(ctx context.Context, runtime pollRuntime, event calendarWatchEvent) error {
+	if err := writeCalendarWatchEvent(ctx, event); err != nil {
+		return err
+	}
+	if err := runtime.runHook(ctx, o.onChange, event); err != nil {
+		return err
+	}
+	return o.post(ctx, event)
+}
+
+func (o calendarWatchOutput) post(ctx context.Context, event calendarWatchEvent) error {
+	if o.hookURL == "" {
+		return nil
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("encode hook payload: %w", err)
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, o.hookURL, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("create hook request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if o.hookToken != "" {
+		request.Header.Set("Authorization", "Bearer "+o.hookToken)
+	}
+	response, err := o.hookClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("hook request failed: %w", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("hook status %d", response.StatusCode)
+	}
+	return nil
+}
+
+func writeCalendarWatchEvent(ctx context.Context, event calendarWatchEvent) error {
+	if outfmt.IsJSON(ctx) {
+		return writePollJSON(ctx, event)
+	}
+	for _, change := range event.Changes {
+		if _, err := fmt.Fprintf(
+			stdoutWriter(ctx),
+			"%s\t%s\t%s\t%s\t%s\n",
+			change.Change,
+			change.EventID,
+			change.Start,
+			change.End,
+			sanitizeTab(change.Summary),
+		); err != nil {
+			return fmt.Errorf("write poll output: %w", err)
+		}
+	}
+	return nil
+}
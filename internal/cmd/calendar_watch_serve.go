@@ -0,0 +1,284 @@
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+
+	"github.com/steipete/gogcli/internal/config"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+type CalendarWatchServeCmd struct {
+	Listen              string        `name:"listen" help:"Listen address" default:"127.0.0.1:8443"`
+	Path                string        `name:"path" help:"Notification handler path" default:"/calendar-changes"`
+	Cert                string        `name:"cert" help:"TLS certificate path; pair with --key (omit behind an HTTPS reverse proxy)"`
+	Key                 string        `name:"key" help:"TLS private key path; pair with --cert"`
+	ChannelToken        string        `name:"channel-token" help:"Expected X-Goog-Channel-Token value"`
+	ChannelTokenFile    string        `name:"channel-token-file" type:"path" help:"Read the expected channel token from a file"`
+	Calendar            string        `name:"calendar" aliases:"cal" help:"Calendar ID, name, or index for a new state file (default: primary)"`
+	StateFile           string        `name:"state-file" required:"" help:"JSON file that stores the sync token and channel (shared with 'calendar watch start')"`
+	OnChange            string        `name:"on-change" help:"Trusted local shell command run for each non-empty batch; batch JSON is provided on stdin"`
+	HookURL             string        `name:"hook-url" help:"URL that receives each non-empty batch as a JSON POST"`
+	HookToken           string        `name:"hook-token" help:"Bearer token sent with --hook-url requests"`
+	NotificationTimeout time.Duration `name:"notification-timeout" help:"Maximum time for one callback, including Calendar reads and hooks" default:"5m"`
+}
+
+type calendarWatchServer struct {
+	mu                  sync.Mutex
+	statePath           string
+	state               calendarWatchState
+	service             *calendar.Service
+	path                string
+	channelToken        string
+	output              calendarWatchOutput
+	notificationTimeout time.Duration
+	runtime             pollRuntime
+	warnf               func(string, ...any)
+}
+
+func (c *CalendarWatchServeCmd) Run(ctx context.Context, flags *RootFlags) error {
+	serveCtx, stop := pollSignalContext(ctx)
+	defer stop()
+	u := ui.FromContext(serveCtx)
+	statePath, err := expandPollStatePath(c.StateFile)
+	if err != nil {
+		return err
+	}
+	channelToken, err := c.resolveChannelToken()
+	if err != nil {
+		return err
+	}
+	if validationErr := c.validate(channelToken); validationErr != nil {
+		return validationErr
+	}
+	output, err := newCalendarWatchOutput(c.OnChange, c.HookURL, c.HookToken)
+	if err != nil {
+		return err
+	}
+
+	if dryRunErr := dryRunExit(serveCtx, flags, "calendar.watch.serve", map[string]any{
+		"listen":               strings.TrimSpace(c.Listen),
+		"path":                 strings.TrimSpace(c.Path),
+		"tls":                  strings.TrimSpace(c.Cert) != "",
+		"calendar":             firstNonEmpty(strings.TrimSpace(c.Calendar), primaryCalendarID),
+		"state_file":           statePath,
+		"hook_configured":      output.onChange != "",
+		"hook_url_set":         output.hookURL != "",
+		"notification_timeout": c.NotificationTimeout.String(),
+	}); dryRunErr != nil {
+		return dryRunErr
+	}
+
+	var tlsConfig *tls.Config
+	if strings.TrimSpace(c.Cert) != "" {
+		certPath, keyPath, pathErr := expandDriveChangesTLSPaths(c.Cert, c.Key)
+		if pathErr != nil {
+			return pathErr
+		}
+		certificate, certErr := tls.LoadX509KeyPair(certPath, keyPath)
+		if certErr != nil {
+			return fmt.Errorf("load TLS certificate: %w", certErr)
+		}
+		tlsConfig = &tls.Config{
+			MinVersion:   tls.VersionTLS12,
+			Certificates: []tls.Certificate{certificate},
+		}
+	}
+
+	_, svc, err := requireCalendarService(serveCtx, flags)
+	if err != nil {
+		return err
+	}
+	runtime := defaultPollRuntime()
+	state, err := initializeCalendarWatchState(serveCtx, svc, statePath, c.Calendar, runtime.now().UTC())
+	if err != nil {
+		return err
+	}
+	if state.Channel != nil && state.Channel.TokenHash != "" && state.Channel.TokenHash != channelTokenHash(channelToken) {
+		return usage("channel token does not match the token recorded by 'calendar watch start'")
+	}
+
+	var listenConfig net.ListenConfig
+	listener, err := listenConfig.Listen(serveCtx, "tcp", strings.TrimSpace(c.Listen))
+	if err != nil {
+		return fmt.Errorf("listen on %s: %w", c.Listen, err)
+	}
+	if tlsConfig != nil {
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+
+	server := &calendarWatchServer{
+		statePath:           statePath,
+		state:               state,
+		service:             svc,
+		path:                strings.TrimSpace(c.Path),
+		channelToken:        channelToken,
+		output:              output,
+		notificationTimeout: c.NotificationTimeout,
+		runtime:             runtime,
+		warnf:               u.Err().Linef,
+	}
+	httpServer := &http.Server{
+		Handler:           server,
+		ReadTimeout:       defaultDriveChangesReadTimeout,
+		ReadHeaderTimeout: 5 * time.Second,
+		IdleTimeout:       30 * time.Second,
+		MaxHeaderBytes:    64 << 10,
+		BaseContext:       func(net.Listener) context.Context { return serveCtx },
+	}
+	serveErrors := make(chan error, 1)
+	go func() {
+		serveErrors <- httpServer.Serve(listener)
+	}()
+
+	scheme := driveChangesServerSchemeHTTP
+	if tlsConfig != nil {
+		scheme = driveChangesWebhookSchemeHTTPS
+	}
+	u.Err().Linef("calendar watch serve: listening on %s://%s%s for %s", scheme, listener.Addr(), server.path, state.CalendarID)
+
+	select {
+	case serveErr := <-serveErrors:
+		if errors.Is(serveErr, http.ErrServerClosed) {
+			return nil
+		}
+		return serveErr
+	case <-serveCtx.Done():
+		if shutdownErr := shutdownHTTPServer(serveCtx, httpServer); shutdownErr != nil {
+			return shutdownErr
+		}
+		return serveCtx.Err()
+	}
+}
+
+func (c *CalendarWatchServeCmd) resolveChannelToken() (string, error) {
+	direct := strings.TrimSpace(c.ChannelToken)
+	tokenFile := strings.TrimSpace(c.ChannelTokenFile)
+	if direct != "" && tokenFile != "" {
+		return "", usage("provide only one of --channel-token or --channel-token-file")
+	}
+	if tokenFile != "" {
+		path, err := config.ExpandPath(tokenFile)
+		if err != nil {
+			return "", fmt.Errorf("expand --channel-token-file: %w", err)
+		}
+		raw, err := os.ReadFile(path) //nolint:gosec // explicit operator-provided secret file.
+		if err != nil {
+			return "", fmt.Errorf("read --channel-token-file: %w", err)
+		}
+		direct = strings.TrimSpace(string(raw))
+	}
+	if direct == "" {
+		direct = strings.TrimSpace(os.Getenv("GOG_CALENDAR_CHANNEL_TOKEN"))
+	}
+	if direct == "" {
+		return "", usage("provide --channel-token, --channel-token-file, or GOG_CALENDAR_CHANNEL_TOKEN")
+	}
+	return direct, nil
+}
+
+func (c *CalendarWatchServeCmd) validate(channelToken string) error {
+	if strings.TrimSpace(c.Listen) == "" {
+		return usage("missing --listen")
+	}
+	if path := strings.TrimSpace(c.Path); path == "" || !strings.HasPrefix(path, "/") {
+		return usage("--path must start with '/'")
+	}
+	if (strings.TrimSpace(c.Cert) == "") != (strings.TrimSpace(c.Key) == "") {
+		return usage("--cert and --key must be provided together")
+	}
+	if len(channelToken) > 256 {
+		return usage("--channel-token must be at most 256 bytes")
+	}
+	if c.NotificationTimeout <= 0 {
+		return usage("--notification-timeout must be greater than zero")
+	}
+	return nil
+}
+
+func (s *calendarWatchServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != s.path {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if !driveChangesChannelTokenMatches(r.Header.Get("X-Goog-Channel-Token"), s.channelToken) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	notification, err := parseDriveChangesNotification(r)
+	if err != nil {
+		s.warnf("calendar watch serve: invalid notification: %v", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), s.notificationTimeout)
+	defer cancel()
+	if err := s.handleNotification(ctx, notification); err != nil {
+		if errors.Is(err, errDriveChangesUntrackedNotification) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		s.warnf("calendar watch serve: notification failed: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleNotification processes one push. Notifications are serialized, and
+// a failed delivery leaves the sync token unchanged so Google's retry (or
+// the next notification) replays the batch.
+func (s *calendarWatchServer) handleNotification(ctx context.Context, notification driveChangesNotification) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if channel := s.state.Channel; channel != nil &&
+		(channel.ID != notification.ChannelID || channel.ResourceID != notification.ResourceID) {
+		s.warnf("calendar watch serve: rejecting untracked channel=%s resource=%s", notification.ChannelID, notification.ResourceID)
+		return errDriveChangesUntrackedNotification
+	}
+	// Message numbers only increase within one channel.
+	if s.state.LastMessageID == notification.ChannelID && s.state.LastMessageNumber >= notification.MessageNumber {
+		return nil
+	}
+
+	next := s.state
+	if notification.ResourceState != "sync" {
+		synced, event, err := syncCalendarWatch(ctx, s.service, s.state, s.runtime.now().UTC())
+		if err != nil {
+			return err
+		}
+		if event != nil {
+			event.Source = "notification"
+			event.ChannelID = notification.ChannelID
+			event.MessageNumber = notification.MessageNumber
+			if err := s.output.deliver(ctx, s.runtime, *event); err != nil {
+				return err
+			}
+		}
+		next = synced
+	}
+	next.LastMessageID = notification.ChannelID
+	next.LastMessageNumber = notification.MessageNumber
+	next.UpdatedAt = s.runtime.now().UTC().Format(time.RFC3339Nano)
+	if err := writePollState(s.statePath, next); err != nil {
+		return err
+	}
+	s.state = next
+	return nil
+}
@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func calendarWatchTestHandler(t *testing.T) http.Handler {
+	t.Helper()
+	return withPrimaryCalendar(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/calendars/primary/events" || r.Method != http.MethodGet {
+			http.NotFound(w, r)
+			return
+		}
+		switch r.URL.Query().Get("syncToken") {
+		case "":
+			_ = json.NewEncoder(w).Encode(map[string]any{"nextSyncToken": "fresh"})
+		case "stale":
+			w.WriteHeader(http.StatusGone)
+			_ = json.NewEncoder(w).Encode(map[string]any{"error": map[string]any{"code": 410, "message": "Sync token is no longer valid"}})
+		case "tok1":
+			if r.URL.Query().Get("showDeleted") != "true" {
+				t.Errorf("incremental sync should show deleted events: %s", r.URL.RawQuery)
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"nextSyncToken": "tok2", "items": []map[string]any{
+				{"id": "new", "status": "confirmed", "summary": "Kickoff", "created": "2030-01-02T09:00:00Z", "updated": "2030-01-02T09:05:00Z",
+					"start": map[string]any{"dateTime": "2030-01-03T10:00:00+01:00"}, "end": map[string]any{"dateTime": "2030-01-03T11:00:00+01:00"}},
+				{"id": "moved", "status": "confirmed", "summary": "Review", "created": "2029-12-01T09:00:00Z", "updated": "2030-01-02T10:00:00Z",
+					"start": map[string]any{"date": "2030-01-04"}, "end": map[string]any{"date": "2030-01-05"}},
+				{"id": "gone", "status": "cancelled"},
+			}})
+		default:
+			_ = json.NewEncoder(w).Encode(map[string]any{"nextSyncToken": r.URL.Query().Get("syncToken")})
+		}
+	}))
+}
+
+func writeCalendarWatchTestState(t *testing.T, path string, state calendarWatchState) {
+	t.Helper()
+	state.Version = pollStateVersion
+	state.Kind = calendarWatchStateKind
+	state.CalendarID = "primary"
+	state.SyncedAt = "2030-01-01T00:00:00Z"
+	if err := writePollState(path, state); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readCalendarWatchTestState(t *testing.T, path string) calendarWatchState {
+	t.Helper()
+	var state calendarWatchState
+	if _, err := readCalendarWatchState(path, &state); err != nil {
+		t.Fatal(err)
+	}
+	return state
+}
+
+func TestCalendarWatchPollEmitsNormalizedChanges(t *testing.T) {
+	svc, cleanup := newCalendarServiceForTest(t, calendarWatchTestHandler(t))
+	defer cleanup()
+
+	var (
+		mu     sync.Mutex
+		posted []calendarWatchEvent
+		auth   string
+	)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		auth = r.Header.Get("Authorization")
+		var event calendarWatchEvent
+		_ = json.NewDecoder(r.Body).Decode(&event)
+		posted = append(posted, event)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer hook.Close()
+
+	statePath := filepath.Join(t.TempDir(), "watch.json")
+	writeCalendarWatchTestState(t, statePath, calendarWatchState{SyncToken: "tok1"})
+
+	result := executeWithCalendarTestService(t, []string{"--json", "--account", "a@b.com", "calendar", "watch", "poll",
+		"--state-file", statePath, "--max-iterations", "1", "--hook-url", hook.URL, "--hook-token", "secret"}, svc)
+	if result.err != nil {
+		t.Fatalf("poll: %v", result.err)
+	}
+	var event calendarWatchEvent
+	if err := json.Unmarshal([]byte(result.stdout), &event); err != nil {
+		t.Fatalf("decode: %v\n%s", err, result.stdout)
+	}
+	if event.Kind != "calendar_changes" || event.Source != "poll" || event.SyncToken != "tok1" || event.NextSyncToken != "tok2" {
+		t.Fatalf("event = %+v", event)
+	}
+	var got []string
+	for _, change := range event.Changes {
+		got = append(got, change.Change+":"+change.EventID)
+	}
+	if strings.Join(got, ",") != "created:new,updated:moved,cancelled:gone" {
+		t.Fatalf("changes = %v", got)
+	}
+	if event.Changes[0].Start != "2030-01-03T09:00:00Z" || !event.Changes[1].AllDay {
+		t.Fatalf("normalized times = %+v", event.Changes[:2])
+	}
+	if len(posted) != 1 || len(posted[0].Changes) != 3 || auth != "Bearer secret" {
+		t.Fatalf("hook posted %d batches, auth %q", len(posted), auth)
+	}
+	if state := readCalendarWatchTestState(t, statePath); state.SyncToken != "tok2" {
+		t.Fatalf("state sync token = %q", state.SyncToken)
+	}
+
+	// An expired sync token re-seeds from a full sync without emitting.
+	writeCalendarWatchTestState(t, statePath, calendarWatchState{SyncToken: "stale"})
+	result = executeWithCalendarTestService(t, []string{"--json", "--account", "a@b.com", "calendar", "watch", "poll",
+		"--state-file", statePath, "--max-iterations", "1"}, svc)
+	if result.err != nil {
+		t.Fatalf("poll after 410: %v", result.err)
+	}
+	if strings.TrimSpace(result.stdout) != "" {
+		t.Fatalf("re-seed should not emit changes: %s", result.stdout)
+	}
+	if state := readCalendarWatchTestState(t, statePath); state.SyncToken != "fresh" {
+		t.Fatalf("state sync token after 410 = %q", state.SyncToken)
+	}
+}
+
+func TestCalendarWatchServerHandlesNotifications(t *testing.T) {
+	svc, cleanup := newCalendarServiceForTest(t, calendarWatchTestHandler(t))
+	defer cleanup()
+	ctx, output := newCalendarTestJSONContext(t, svc)
+
+	statePath := filepath.Join(t.TempDir(), "watch.json")
+	writeCalendarWatchTestState(t, statePath, calendarWatchState{
+		SyncToken: "tok1",
+		Channel:   &calendarWatchChannelState{ID: "chan-1", ResourceID: "res-1"},
+	})
+	var hooked []any
+	server := &calendarWatchServer{
+		statePath:           statePath,
+		state:               readCalendarWatchTestState(t, statePath),
+		service:             svc,
+		path:                "/calendar-changes",
+		channelToken:        "tok",
+		output:              calendarWatchOutput{onChange: "hook"},
+		notificationTimeout: time.Minute,
+		runtime: pollRuntime{
+			now: func() time.Time { return time.Date(2030, 1, 2, 12, 0, 0, 0, time.UTC) },
+			runHook: func(_ context.Context, _ string, payload any) error {
+				hooked = append(hooked, payload)
+				return nil
+			},
+		},
+		warnf: func(string, ...any) {},
+	}
+
+	notify := func(channelID, state string, message int) int {
+		req := httptest.NewRequest(http.MethodPost, "/calendar-changes", nil).WithContext(ctx)
+		req.Header.Set("X-Goog-Channel-Token", "tok")
+		req.Header.Set("X-Goog-Channel-ID", channelID)
+		req.Header.Set("X-Goog-Resource-ID", "res-1")
+		req.Header.Set("X-Goog-Resource-State", state)
+		req.Header.Set("X-Goog-Resource-URI", "https://www.googleapis.com/calendar/v3/calendars/primary/events")
+		req.Header.Set("X-Goog-Message-Number", strconv.Itoa(message))
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := notify("chan-1", "sync", 1); code != http.StatusNoContent || len(hooked) != 0 {
+		t.Fatalf("sync: code=%d hooked=%d", code, len(hooked))
+	}
+	if code := notify("other", "exists", 2); code != http.StatusUnauthorized {
+		t.Fatalf("untracked channel code = %d", code)
+	}
+	if code := notify("chan-1", "exists", 2); code != http.StatusNoContent || len(hooked) != 1 {
+		t.Fatalf("exists: code=%d hooked=%d", code, len(hooked))
+	}
+	event := hooked[0].(calendarWatchEvent)
+	if event.Source != "notification" || event.ChannelID != "chan-1" || event.MessageNumber != 2 || len(event.Changes) != 3 {
+		t.Fatalf("event = %+v", event)
+	}
+	if !strings.Contains(output.String(), `"change":"created"`) {
+		t.Fatalf("stdout = %s", output.String())
+	}
+	if code := notify("chan-1", "exists", 2); code != http.StatusNoContent || len(hooked) != 1 {
+		t.Fatalf("duplicate: code=%d hooked=%d", code, len(hooked))
+	}
+	state := readCalendarWatchTestState(t, statePath)
+	if state.SyncToken != "tok2" || state.LastMessageNumber != 2 || state.Channel == nil {
+		t.Fatalf("state = %+v", state)
+	}
+}
@@ -155,9 +155,12 @@ func (c *ConfigListCmd) Run(ctx context.Context) error {
 		return err
 	}
 
+	// A broken file must not hide the list: it is how users find the path to
+	// fix, and environment overrides still apply.
 	cfg, err := store.Read()
 	if err != nil {
-		return err
+		fmt.Fprintf(stderrWriter(ctx), "gog: %v; showing defaults\n", err)
+		cfg = config.File{}
 	}
 
 	path := store.Path()
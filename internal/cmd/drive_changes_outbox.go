@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/steipete/gogcli/internal/filelock"
+	"github.com/steipete/gogcli/internal/outbox"
+)
+
+const (
+	driveChangesOutboxKind     = "drive_changes"
+	driveChangesOutboxInterval = 5 * time.Second
+)
+
+// driveChangesOutboxDir keeps queued hook events next to the state file they
+// were read against.
+func driveChangesOutboxDir(statePath string) string {
+	return statePath + ".outbox"
+}
+
+// deliverChangeEvent runs the on-change hook, or queues the event when the
+// hook fails or earlier events are still waiting. A queued event lets the page
+// token advance; the outbox worker reruns the hook later.
+func (s *driveChangesServer) deliverChangeEvent(ctx context.Context, event driveChangesServeEvent) error {
+	if s.outbox == nil {
+		return s.runtime.runHook(ctx, s.onChange, event)
+	}
+	waiting, err := s.outbox.HasPending()
+	if err != nil {
+		return err
+	}
+	var cause error
+	if !waiting {
+		cause = s.runtime.runHook(ctx, s.onChange, event)
+		if cause == nil {
+			return nil
+		}
+	}
+	entry, err := s.outbox.Enqueue(driveChangesOutboxKind, event, cause)
+	if err != nil {
+		if cause != nil {
+			return cause
+		}
+		return err
+	}
+	if cause != nil {
+		s.warnf("drive changes serve: hook failed, queued %s for retry: %v", entry.ID, cause)
+	}
+	return nil
+}
+
+func (s *driveChangesServer) runOutbox(ctx context.Context) {
+	ticker := time.NewTicker(driveChangesOutboxInterval)
+	defer ticker.Stop()
+
+	for {
+		result, err := s.outbox.Flush(ctx, func(ctx context.Context, entry outbox.Entry) error {
+			return s.runtime.runHook(ctx, s.onChange, json.RawMessage(entry.Payload))
+		})
+		switch {
+		case err != nil && !errors.Is(err, filelock.ErrTimeout):
+			s.warnf("drive changes serve: outbox flush failed: %v", err)
+		case result.DeadLettered > 0:
+			s.warnf("drive changes serve: moved %d events to %s", result.DeadLettered, s.outbox.Dir())
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"google.golang.org/api/drive/v3"
 
 	"github.com/steipete/gogcli/internal/config"
+	"github.com/steipete/gogcli/internal/outbox"
 	"github.com/steipete/gogcli/internal/ui"
 )
 
@@ -49,6 +50,8 @@ type DriveChangesServeCmd struct {
 	ChannelTTL          time.Duration `name:"channel-ttl" help:"Requested channel lifetime" default:"24h"`
 	RenewBefore         time.Duration `name:"renew-before" help:"Renew this long before channel expiration" default:"10m"`
 	NotificationTimeout time.Duration `name:"notification-timeout" help:"Maximum time for one callback, including Drive reads and the hook" default:"5m"`
+	Outbox              bool          `name:"outbox" help:"Queue failed --on-change runs next to the state file and retry them with backoff"`
+	OutboxMaxAttempts   int           `name:"outbox-max-attempts" help:"Hook attempts before a queued event moves to the dead-letter directory" default:"8"`
 }
 
 type driveChangesServeChannelState struct {
@@ -126,6 +129,7 @@ type driveChangesServer struct {
 	channelTTL          time.Duration
 	renewBefore         time.Duration
 	notificationTimeout time.Duration
+	outbox              *outbox.Outbox
 	runtime             driveChangesServeRuntime
 	logf                func(string, ...any)
 	warnf               func(string, ...any)
@@ -170,6 +174,7 @@ func (c *DriveChangesServeCmd) run(ctx context.Context, flags *RootFlags, runtim
 		"channel_ttl":          c.ChannelTTL.String(),
 		"renew_before":         c.RenewBefore.String(),
 		"notification_timeout": c.NotificationTimeout.String(),
+		"outbox":               c.Outbox,
 	}); dryRunErr != nil {
 		return dryRunErr
 	}
@@ -233,6 +238,10 @@ func (c *DriveChangesServeCmd) run(ctx context.Context, flags *RootFlags, runtim
 		logf:                u.Err().Linef,
 		warnf:               u.Err().Linef,
 	}
+	if c.Outbox {
+		server.outbox = outbox.New(driveChangesOutboxDir(statePath), outbox.Options{MaxAttempts: c.OutboxMaxAttempts})
+		go server.runOutbox(ctx)
+	}
 	httpServer := &http.Server{
 		Handler:           server,
 		ReadTimeout:       defaultDriveChangesReadTimeout,
@@ -335,6 +344,12 @@ func (c *DriveChangesServeCmd) validate(channelToken string) error {
 	if c.NotificationTimeout <= 0 {
 		return usage("--notification-timeout must be greater than zero")
 	}
+	if c.Outbox && strings.TrimSpace(c.OnChange) == "" {
+		return usage("--outbox requires --on-change")
+	}
+	if c.Outbox && c.OutboxMaxAttempts <= 0 {
+		return usage("--outbox-max-attempts must be greater than zero")
+	}
 	webhookURL := strings.TrimSpace(c.WebhookURL)
 	if c.AutoRenew {
 		if webhookURL == "" {
@@ -17,6 +17,8 @@ import (
 	"time"
 
 	"google.golang.org/api/drive/v3"
+
+	"github.com/steipete/gogcli/internal/outbox"
 )
 
 const (
@@ -481,6 +483,62 @@ func TestDriveChangesServeHookFailureRetainsStateForRetry(t *testing.T) {
 	}
 }
 
+func TestDriveChangesServeOutboxQueuesFailedHookAndAdvancesState(t *testing.T) {
+	svc, closeDrive := newDriveTestService(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"newStartPageToken": "next-token",
+			"changes":           []map[string]any{{"fileId": "file-1"}},
+		})
+	}))
+	defer closeDrive()
+
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	state := driveChangesServeState{
+		Version:   pollStateVersion,
+		PageToken: pollTestStartToken,
+	}
+	if err := writePollState(statePath, state); err != nil {
+		t.Fatalf("write state: %v", err)
+	}
+	server := newDriveChangesTestReceiver(t, svc, state)
+	server.statePath = statePath
+	server.onChange = "./handle-change"
+	server.outbox = outbox.New(driveChangesOutboxDir(statePath), outbox.Options{})
+	hookCalls := 0
+	server.runtime.runHook = func(context.Context, string, any) error {
+		hookCalls++
+		return errors.New("hook failed")
+	}
+
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, newDriveChangesNotificationRequest(t, driveChangesTestChannelToken, "change", 4))
+	if response.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want 204", response.Code)
+	}
+	persisted, _, err := readDriveChangesServeState(statePath)
+	if err != nil {
+		t.Fatalf("read state: %v", err)
+	}
+	if persisted.PageToken != "next-token" {
+		t.Fatalf("unexpected state: %#v", persisted)
+	}
+	pending, err := server.outbox.Pending()
+	if err != nil || len(pending) != 1 || pending[0].Kind != driveChangesOutboxKind || pending[0].Attempts != 1 {
+		t.Fatalf("pending = %#v err=%v", pending, err)
+	}
+
+	// Later events queue behind the waiting one without running the hook.
+	if err := server.deliverChangeEvent(context.Background(), driveChangesServeEvent{MessageNumber: 5}); err != nil {
+		t.Fatalf("deliverChangeEvent: %v", err)
+	}
+	if hookCalls != 1 {
+		t.Fatalf("hook calls = %d, want 1", hookCalls)
+	}
+	if pending, _ = server.outbox.Pending(); len(pending) != 2 {
+		t.Fatalf("pending = %#v", pending)
+	}
+}
+
 func TestDriveChangesServeHookDoesNotBlockStateMutex(t *testing.T) {
 	svc, closeDrive := newDriveTestService(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
 		_ = json.NewEncoder(w).Encode(map[string]any{
@@ -241,7 +241,7 @@ func (s *driveChangesServer) handleNotification(ctx context.Context, notificatio
 			NextPageToken:     nextPageToken,
 			Changes:           filtered,
 		}
-		if err := s.runtime.runHook(ctx, s.onChange, event); err != nil {
+		if err := s.deliverChangeEvent(ctx, event); err != nil {
 			return err
 		}
 	}
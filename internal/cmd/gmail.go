@@ -17,13 +17,20 @@ type GmailCmd struct {
 	Unread  GmailUnreadCmd   `cmd:"" name:"unread" aliases:"mark-unread" group:"Organize" help:"Mark messages as unread"`
 	Trash   GmailTrashMsgCmd `cmd:"" name:"trash" group:"Organize" help:"Move messages to trash"`
 
+	Export GmailExportCmd `cmd:"" name:"export" group:"Read" help:"Export matching messages to an mbox file or Maildir (resumable)"`
+	Import GmailImportCmd `cmd:"" name:"import" group:"Organize" help:"Import an mbox, Maildir, or .eml files into Gmail (resumable, deduped)"`
+
+	Subscriptions GmailSubscriptionsCmd `cmd:"" name:"subscriptions" aliases:"subs,newsletters" group:"Organize" help:"Find newsletter senders and unsubscribe"`
+
 	Send      GmailSendCmd      `cmd:"" name:"send" group:"Write" help:"Send an email"`
 	Reply     GmailReplyCmd     `cmd:"" name:"reply" group:"Write" help:"Reply to a message"`
 	ReplyAll  GmailReplyAllCmd  `cmd:"" name:"reply-all" aliases:"replyall" group:"Write" help:"Reply to all message participants"`
 	Forward   GmailForwardCmd   `cmd:"" name:"forward" aliases:"fwd" group:"Write" help:"Forward a message to new recipients"`
 	AutoReply GmailAutoReplyCmd `cmd:"" name:"autoreply" group:"Write" help:"Reply once to matching messages"`
+	Merge     GmailMergeCmd     `cmd:"" name:"merge" aliases:"mail-merge" group:"Write" help:"Send or draft personalized messages from CSV or Sheets rows"`
 	Track     GmailTrackCmd     `cmd:"" name:"track" group:"Write" help:"Email open tracking"`
 	Drafts    GmailDraftsCmd    `cmd:"" name:"drafts" aliases:"draft" group:"Write" help:"Draft operations"`
+	Scheduled GmailScheduledCmd `cmd:"" name:"scheduled" aliases:"schedule" group:"Write" help:"Scheduled sends (gmail send --at)"`
 
 	Settings GmailSettingsCmd `cmd:"" name:"settings" group:"Admin" help:"Settings and admin"`
 
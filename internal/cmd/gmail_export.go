@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	gmailbackup "github.com/steipete/gogcli/internal/backup/gmail"
+	"github.com/steipete/gogcli/internal/mailbox"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+const gmailExportProgressEvery = 100
+
+type GmailExportCmd struct {
+	Query            string `name:"query" short:"q" help:"Gmail search query selecting the messages to export (default: all mail)"`
+	Format           string `name:"format" help:"Archive format: mbox (one file) or maildir (one folder per label)" enum:"mbox,maildir" default:"mbox"`
+	Out              string `name:"out" short:"o" required:"" help:"mbox file or Maildir directory to write"`
+	Max              int64  `name:"max" aliases:"limit" help:"Max messages to export (0 = all matches)" default:"0"`
+	IncludeSpamTrash bool   `name:"include-spam-trash" help:"Include spam and trash"`
+	Restart          bool   `name:"restart" help:"Start over instead of resuming an earlier export to --out"`
+}
+
+func (c *GmailExportCmd) Run(ctx context.Context, flags *RootFlags) error {
+	if c.Max < 0 {
+		return usage("--max must be >= 0")
+	}
+	outPath, err := expandUserPath(c.Out)
+	if err != nil {
+		return err
+	}
+	_, svc, err := requireGmailService(ctx, flags)
+	if err != nil {
+		return err
+	}
+	source, err := gmailbackup.NewServiceSource(svc)
+	if err != nil {
+		return err
+	}
+	query := strings.TrimSpace(c.Query)
+	ids, err := listGmailExportIDs(ctx, source, gmailbackup.ListRequest{Query: query, IncludeSpamTrash: c.IncludeSpamTrash}, c.Max)
+	if err != nil {
+		return err
+	}
+	if dryRunErr := dryRunExit(ctx, flags, "gmail.export", map[string]any{
+		"query":    query,
+		"format":   c.Format,
+		"out":      outPath,
+		"messages": len(ids),
+		"restart":  c.Restart,
+	}); dryRunErr != nil {
+		return dryRunErr
+	}
+
+	labels, err := source.Labels(ctx)
+	if err != nil {
+		return err
+	}
+	names := make(map[string]string, len(labels))
+	for _, label := range labels {
+		names[label.ID] = label.Name
+	}
+	box, err := mailbox.Open(c.Format, outPath, mailbox.Options{LabelNames: names, Restart: c.Restart})
+	if err != nil {
+		return err
+	}
+	defer box.Close()
+
+	u := ui.FromContext(ctx)
+	exported, skipped, missing := 0, 0, 0
+	for i, id := range ids {
+		if u != nil && i > 0 && i%gmailExportProgressEvery == 0 {
+			u.Err().Linef("export\t%d/%d", i, len(ids))
+		}
+		if box.Has(id) {
+			skipped++
+			continue
+		}
+		message, fetchErr := source.RawMessage(ctx, id)
+		if fetchErr != nil {
+			if isGoogleNotFound(fetchErr) {
+				missing++
+				continue
+			}
+			return fetchErr
+		}
+		rawMIME, decodeErr := decodeGmailRaw(message.Raw)
+		if decodeErr != nil {
+			return fmt.Errorf("decode Gmail raw %s: %w", id, decodeErr)
+		}
+		if writeErr := box.Write(gmailMailboxMessage(message, rawMIME)); writeErr != nil {
+			return fmt.Errorf("write %s message %s: %w", c.Format, id, writeErr)
+		}
+		exported++
+	}
+	if closeErr := box.Close(); closeErr != nil {
+		return closeErr
+	}
+	return writeResult(ctx, u,
+		kv("out", outPath),
+		kv("format", c.Format),
+		kv("matched", len(ids)),
+		kv("exported", exported),
+		kv("skipped", skipped),
+		kv("missing", missing),
+	)
+}
+
+func listGmailExportIDs(ctx context.Context, source *gmailbackup.ServiceSource, req gmailbackup.ListRequest, limit int64) ([]string, error) {
+	var ids []string
+	for {
+		req.MaxResults = 500
+		if limit > 0 && limit-int64(len(ids)) < req.MaxResults {
+			req.MaxResults = limit - int64(len(ids))
+		}
+		page, err := source.ListMessageIDs(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, page.IDs...)
+		if page.NextPageToken == "" || (limit > 0 && int64(len(ids)) >= limit) {
+			return ids, nil
+		}
+		req.PageToken = page.NextPageToken
+	}
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/steipete/gogcli/internal/mailbox"
+)
+
+func TestGmailExportMboxResumes(t *testing.T) {
+	var gets atomic.Int32
+	svc, cleanup := newGmailServiceForTest(t, func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/gmail/v1")
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case path == "/users/me/labels":
+			_ = json.NewEncoder(w).Encode(map[string]any{"labels": []map[string]any{{"id": "Label_1", "name": "Legal Hold"}}})
+		case path == "/users/me/messages":
+			if r.URL.Query().Get("q") != "label:legal-hold" {
+				t.Errorf("q = %q", r.URL.Query().Get("q"))
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"messages": []map[string]any{{"id": "m1"}, {"id": "m2"}, {"id": "gone"}}})
+		case strings.HasPrefix(path, "/users/me/messages/"):
+			id := strings.TrimPrefix(path, "/users/me/messages/")
+			gets.Add(1)
+			if id == "gone" {
+				http.Error(w, `{"error":{"code":404,"message":"Not Found"}}`, http.StatusNotFound)
+				return
+			}
+			raw := "From: a@example.com\r\nSubject: " + id + "\r\n\r\nFrom the desk of " + id + "\r\n"
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"id":           id,
+				"threadId":     "abc",
+				"internalDate": "1775124000000",
+				"labelIds":     []string{"INBOX", "Label_1"},
+				"raw":          base64.RawURLEncoding.EncodeToString([]byte(raw)),
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	defer cleanup()
+
+	out := filepath.Join(t.TempDir(), "hold.mbox")
+	args := []string{"--query", "label:legal-hold", "--out", out}
+	var stdout bytes.Buffer
+	ctx := withGmailTestService(newCmdRuntimeJSONOutputContext(t, &stdout, io.Discard), svc)
+	if err := runKong(t, &GmailExportCmd{}, args, ctx, &RootFlags{Account: "a@b.com"}); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	var result struct {
+		Exported int `json:"exported"`
+		Skipped  int `json:"skipped"`
+		Missing  int `json:"missing"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		t.Fatalf("decode: %v\n%s", err, stdout.String())
+	}
+	if result.Exported != 2 || result.Missing != 1 || result.Skipped != 0 {
+		t.Fatalf("result = %+v", result)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("read mbox: %v", err)
+	}
+	text := string(data)
+	if strings.Count(text, "\nFrom a@example.com ") != 1 || !strings.HasPrefix(text, "From a@example.com ") ||
+		!strings.Contains(text, "X-Gmail-Labels: Inbox,Legal Hold\n") || !strings.Contains(text, "\n>From the desk of m2\n") {
+		t.Fatalf("mbox:\n%s", text)
+	}
+	if _, err := os.Stat(out + mailbox.StateSuffix); err != nil {
+		t.Fatalf("state file: %v", err)
+	}
+
+	gets.Store(0)
+	stdout.Reset()
+	if err := runKong(t, &GmailExportCmd{}, args, ctx, &RootFlags{Account: "a@b.com"}); err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		t.Fatalf("decode resume: %v", err)
+	}
+	if result.Exported != 0 || result.Skipped != 2 || gets.Load() != 1 {
+		t.Fatalf("resume result = %+v gets=%d", result, gets.Load())
+	}
+	if again, _ := os.ReadFile(out); !bytes.Equal(again, data) {
+		t.Fatalf("resume changed mbox")
+	}
+}
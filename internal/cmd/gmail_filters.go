@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/steipete/gogcli/internal/config"
+	"github.com/steipete/gogcli/internal/gmailfilters"
 	"github.com/steipete/gogcli/internal/outfmt"
 	"github.com/steipete/gogcli/internal/ui"
 )
@@ -17,6 +18,7 @@ type GmailFiltersCmd struct {
 	Create GmailFiltersCreateCmd `cmd:"" name:"create" aliases:"add,new" help:"Create a new email filter"`
 	Delete GmailFiltersDeleteCmd `cmd:"" name:"delete" aliases:"rm,del,remove" help:"Delete a filter"`
 	Export GmailFiltersExportCmd `cmd:"" name:"export" help:"Export filters as Gmail WebUI-compatible XML"`
+	Apply  GmailFiltersApplyCmd  `cmd:"" name:"apply" aliases:"sync,import" help:"Sync filters to a YAML or Gmail XML file (create, keep, optionally delete)"`
 }
 
 type GmailFiltersListCmd struct{}
@@ -143,7 +145,7 @@ func (c *GmailFiltersDeleteCmd) Run(ctx context.Context, flags *RootFlags) error
 
 type GmailFiltersExportCmd struct {
 	Out    string `name:"out" short:"o" help:"Write export to this file (defaults to stdout)"`
-	Format string `name:"format" help:"Export format: xml or json (default: xml; --json without --out uses json for compatibility)"`
+	Format string `name:"format" help:"Export format: xml, yaml, or json (default: xml; --json without --out uses json for compatibility)"`
 }
 
 func (c *GmailFiltersExportCmd) Run(ctx context.Context, flags *RootFlags) error {
@@ -155,8 +157,11 @@ func (c *GmailFiltersExportCmd) Run(ctx context.Context, flags *RootFlags) error
 			format = "json"
 		}
 	}
-	if format != "xml" && format != "json" {
-		return usage("--format must be xml or json")
+	if format == "yml" {
+		format = "yaml"
+	}
+	if format != "xml" && format != "json" && format != "yaml" {
+		return usage("--format must be xml, yaml, or json")
 	}
 	if outPath != "" {
 		var err error
@@ -208,6 +213,23 @@ func (c *GmailFiltersExportCmd) Run(ctx context.Context, flags *RootFlags) error
 			_, err = stdoutWriter(ctx).Write(data)
 			return err
 		}
+	case "yaml":
+		labelNames, labelErr := fetchLabelIDToName(svc)
+		if labelErr != nil {
+			return labelErr
+		}
+		set := &gmailfilters.Set{Filters: make([]gmailfilters.Filter, 0, len(filters))}
+		for _, filter := range filters {
+			set.Filters = append(set.Filters, gmailfilters.FromAPI(filter, labelNames))
+		}
+		data, err = gmailfilters.MarshalYAML(set)
+		if err != nil {
+			return err
+		}
+		if outPath == "" {
+			_, err = stdoutWriter(ctx).Write(data)
+			return err
+		}
 	}
 
 	if outPath == "" {
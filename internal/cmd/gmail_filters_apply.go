@@ -0,0 +1,252 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/api/gmail/v1"
+
+	"github.com/steipete/gogcli/internal/gmailfilters"
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+type GmailFiltersApplyCmd struct {
+	File   string `arg:"" name:"file" help:"Filter file: gog YAML or Gmail mailFilters XML (- for stdin)"`
+	Format string `name:"format" help:"File format: auto, yaml, or xml" enum:"auto,yaml,xml" default:"auto"`
+	Prune  bool   `name:"prune" aliases:"delete-unmanaged" help:"Delete existing filters that are not in the file"`
+}
+
+type gmailFilterPlanItem struct {
+	ID      string `json:"id,omitempty"`
+	Summary string `json:"summary"`
+}
+
+// gmailFilterPlan is the diff between a filter file and the mailbox. Gmail
+// filters cannot be edited in place, so a changed filter shows up as one
+// create plus one delete (or unmanaged entry without --prune).
+type gmailFilterPlan struct {
+	CreateLabels []string              `json:"create_labels"`
+	Create       []gmailFilterPlanItem `json:"create"`
+	Keep         []gmailFilterPlanItem `json:"keep"`
+	Delete       []gmailFilterPlanItem `json:"delete"`
+	Unmanaged    []gmailFilterPlanItem `json:"unmanaged"`
+
+	creates []gmailfilters.Filter
+}
+
+func (c *GmailFiltersApplyCmd) Run(ctx context.Context, flags *RootFlags) error {
+	data, err := readTextInput(ctx, strings.TrimSpace(c.File))
+	if err != nil {
+		return err
+	}
+	set, err := gmailfilters.Parse(data, c.Format)
+	if err != nil {
+		return usage(err.Error())
+	}
+	for _, filter := range set.Filters {
+		if filter.Forward != "" {
+			if validateErr := validateGmailSettingsEmail("forward", filter.Forward); validateErr != nil {
+				return newUsageError(validateErr)
+			}
+		}
+	}
+
+	svc, err := loadGmailSettingsService(ctx, flags)
+	if err != nil {
+		return err
+	}
+	labels, err := svc.Users.Labels.List("me").Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+	existing, err := svc.Users.Settings.Filters.List("me").Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+	nameToID, idToName := gmailFilterLabelMaps(labels.Labels)
+	plan := planGmailFilters(set, existing.Filter, nameToID, idToName, c.Prune)
+
+	if dryRunErr := dryRunExit(ctx, flags, "gmail.filters.apply", plan); dryRunErr != nil {
+		return dryRunErr
+	}
+	if action := plan.confirmAction(); action != "" {
+		if confirmErr := confirmDestructiveChecked(ctx, flags, action); confirmErr != nil {
+			return confirmErr
+		}
+	}
+
+	for _, name := range plan.CreateLabels {
+		label, createErr := createLabel(ctx, svc, name)
+		if createErr != nil {
+			return mapLabelCreateError(createErr, name)
+		}
+		nameToID[strings.ToLower(name)] = label.Id
+	}
+	lookup := gmailFilterLabelLookup(nameToID)
+	created := make([]gmailFilterPlanItem, 0, len(plan.creates))
+	for _, filter := range plan.creates {
+		apiFilter, missing := filter.ToAPI(lookup)
+		if len(missing) > 0 {
+			return fmt.Errorf("filter %q: labels still missing after creation: %s", filter.Summary(), strings.Join(missing, ", "))
+		}
+		result, createErr := createGmailFilterWithRetry(ctx, svc, apiFilter)
+		if createErr != nil {
+			return fmt.Errorf("create filter %q: %w", filter.Summary(), createErr)
+		}
+		created = append(created, gmailFilterPlanItem{ID: result.Id, Summary: filter.Summary()})
+	}
+	for _, item := range plan.Delete {
+		if deleteErr := svc.Users.Settings.Filters.Delete("me", item.ID).Context(ctx).Do(); deleteErr != nil && !isGoogleNotFound(deleteErr) {
+			return fmt.Errorf("delete filter %s: %w", item.ID, deleteErr)
+		}
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, stdoutWriter(ctx), map[string]any{
+			"labels_created": plan.CreateLabels,
+			"created":        created,
+			"deleted":        plan.Delete,
+			"kept":           len(plan.Keep),
+			"unmanaged":      plan.Unmanaged,
+		})
+	}
+	u := ui.FromContext(ctx)
+	for _, name := range plan.CreateLabels {
+		u.Out().Linef("label\t%s", name)
+	}
+	for _, item := range created {
+		u.Out().Linef("created\t%s\t%s", item.ID, item.Summary)
+	}
+	for _, item := range plan.Delete {
+		u.Out().Linef("deleted\t%s\t%s", item.ID, item.Summary)
+	}
+	u.Err().Printf("Filters: %d created, %d deleted, %d unchanged, %d unmanaged", len(created), len(plan.Delete), len(plan.Keep), len(plan.Unmanaged))
+	if len(plan.Unmanaged) > 0 {
+		u.Err().Println("Unmanaged filters were left in place; pass --prune to delete them")
+	}
+	return nil
+}
+
+func gmailFilterLabelMaps(labels []*gmail.Label) (map[string]string, map[string]string) {
+	nameToID := make(map[string]string, len(labels)*2)
+	idToName := make(map[string]string, len(labels))
+	for _, label := range labels {
+		if label == nil || label.Id == "" {
+			continue
+		}
+		nameToID[strings.ToLower(label.Id)] = label.Id
+		if label.Name != "" {
+			nameToID[strings.ToLower(label.Name)] = label.Id
+			idToName[label.Id] = label.Name
+		}
+	}
+	return nameToID, idToName
+}
+
+func gmailFilterLabelLookup(nameToID map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		id, ok := nameToID[strings.ToLower(strings.TrimSpace(name))]
+		return id, ok
+	}
+}
+
+func planGmailFilters(set *gmailfilters.Set, existing []*gmail.Filter, nameToID, idToName map[string]string, prune bool) *gmailFilterPlan {
+	plan := &gmailFilterPlan{
+		CreateLabels: []string{},
+		Create:       []gmailFilterPlanItem{},
+		Keep:         []gmailFilterPlanItem{},
+		Delete:       []gmailFilterPlanItem{},
+		Unmanaged:    []gmailFilterPlanItem{},
+	}
+	lookup := gmailFilterLabelLookup(nameToID)
+
+	pendingLabels := map[string]bool{}
+	addLabel := func(name string) {
+		key := strings.ToLower(name)
+		if _, ok := lookup(name); ok || pendingLabels[key] {
+			return
+		}
+		pendingLabels[key] = true
+		plan.CreateLabels = append(plan.CreateLabels, name)
+	}
+	for _, name := range set.Labels {
+		addLabel(name)
+	}
+
+	// Labels that do not exist yet get placeholder IDs so duplicate filters
+	// in the file are still detected.
+	planLookup := func(name string) (string, bool) {
+		if id, ok := lookup(name); ok {
+			return id, true
+		}
+		return "new:" + strings.ToLower(strings.TrimSpace(name)), true
+	}
+	claimed := make([]bool, len(existing))
+	var planned []*gmail.Filter
+	for _, filter := range set.Filters {
+		_, missing := filter.ToAPI(lookup)
+		for _, name := range missing {
+			addLabel(name)
+		}
+		apiFilter, _ := filter.ToAPI(planLookup)
+		if findGmailFilterIndex(planned, nil, apiFilter) >= 0 {
+			continue
+		}
+		planned = append(planned, apiFilter)
+		if len(missing) == 0 {
+			if idx := findGmailFilterIndex(existing, claimed, apiFilter); idx >= 0 {
+				claimed[idx] = true
+				plan.Keep = append(plan.Keep, gmailFilterPlanItem{ID: existing[idx].Id, Summary: filter.Summary()})
+				continue
+			}
+		}
+		plan.creates = append(plan.creates, filter)
+		plan.Create = append(plan.Create, gmailFilterPlanItem{Summary: filter.Summary()})
+	}
+
+	for i, filter := range existing {
+		if claimed[i] || filter == nil {
+			continue
+		}
+		item := gmailFilterPlanItem{ID: filter.Id, Summary: gmailfilters.FromAPI(filter, idToName).Summary()}
+		if prune {
+			plan.Delete = append(plan.Delete, item)
+		} else {
+			plan.Unmanaged = append(plan.Unmanaged, item)
+		}
+	}
+	return plan
+}
+
+func findGmailFilterIndex(filters []*gmail.Filter, claimed []bool, want *gmail.Filter) int {
+	for i, filter := range filters {
+		if claimed != nil && claimed[i] {
+			continue
+		}
+		if gmailFiltersEqual(filter, want) {
+			return i
+		}
+	}
+	return -1
+}
+
+// confirmAction describes the parts of the plan that need confirmation:
+// deletes, and new filters that forward mail elsewhere.
+func (plan *gmailFilterPlan) confirmAction() string {
+	var parts []string
+	if len(plan.Delete) > 0 {
+		parts = append(parts, fmt.Sprintf("delete %d gmail filters", len(plan.Delete)))
+	}
+	forwards := 0
+	for _, filter := range plan.creates {
+		if filter.Forward != "" {
+			forwards++
+		}
+	}
+	if forwards > 0 {
+		parts = append(parts, fmt.Sprintf("create %d forwarding filters", forwards))
+	}
+	return strings.Join(parts, " and ")
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/api/gmail/v1"
+)
+
+type gmailFiltersApplyFake struct {
+	mu      sync.Mutex
+	labels  []*gmail.Label
+	filters []*gmail.Filter
+	created []*gmail.Filter
+	deleted []string
+}
+
+func newGmailFiltersApplyTestService(t *testing.T, fake *gmailFiltersApplyFake) *gmail.Service {
+	t.Helper()
+	svc, cleanup := newGmailServiceForTest(t, func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/gmail/v1")
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && path == "/users/me/labels":
+			_ = json.NewEncoder(w).Encode(map[string]any{"labels": fake.labels})
+		case r.Method == http.MethodPost && path == "/users/me/labels":
+			var label gmail.Label
+			_ = json.NewDecoder(r.Body).Decode(&label)
+			label.Id = "Label_new_" + label.Name
+			fake.labels = append(fake.labels, &label)
+			_ = json.NewEncoder(w).Encode(label)
+		case r.Method == http.MethodGet && path == "/users/me/settings/filters":
+			_ = json.NewEncoder(w).Encode(map[string]any{"filter": fake.filters})
+		case r.Method == http.MethodPost && path == "/users/me/settings/filters":
+			var filter gmail.Filter
+			_ = json.NewDecoder(r.Body).Decode(&filter)
+			filter.Id = "created" + string(rune('0'+len(fake.created)))
+			fake.created = append(fake.created, &filter)
+			_ = json.NewEncoder(w).Encode(filter)
+		case r.Method == http.MethodDelete && strings.HasPrefix(path, "/users/me/settings/filters/"):
+			fake.deleted = append(fake.deleted, strings.TrimPrefix(path, "/users/me/settings/filters/"))
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	t.Cleanup(cleanup)
+	return svc
+}
+
+func TestGmailFiltersApply(t *testing.T) {
+	fake := &gmailFiltersApplyFake{
+		labels: []*gmail.Label{{Id: "INBOX", Name: "INBOX"}, {Id: "Label_1", Name: "Newsletters"}},
+		filters: []*gmail.Filter{
+			{Id: "keep1", Criteria: &gmail.FilterCriteria{From: "news@example.com"}, Action: &gmail.FilterAction{AddLabelIds: []string{"Label_1"}, RemoveLabelIds: []string{"INBOX"}}},
+			{Id: "old1", Criteria: &gmail.FilterCriteria{Subject: "old"}, Action: &gmail.FilterAction{AddLabelIds: []string{"STARRED"}}},
+		},
+	}
+	path := filepath.Join(t.TempDir(), "filters.yaml")
+	spec := `filters:
+  - match: {from: news@example.com}
+    actions: {add_labels: [newsletters], archive: true}
+  - match: {from: news@example.com}
+    actions: {add_labels: [Newsletters], archive: true}
+  - match: {query: invoice}
+    actions: {add_labels: [Receipts], mark_read: true}
+`
+	if err := os.WriteFile(path, []byte(spec), 0o600); err != nil {
+		t.Fatalf("write spec: %v", err)
+	}
+	var stdout bytes.Buffer
+	ctx := withGmailTestService(newCmdRuntimeJSONOutputContext(t, &stdout, io.Discard), newGmailFiltersApplyTestService(t, fake))
+
+	err := runKong(t, &GmailFiltersApplyCmd{}, []string{path, "--prune"}, ctx, &RootFlags{Account: "a@b.com", DryRun: true})
+	if ExitCode(err) != 0 {
+		t.Fatalf("dry run exit = %d: %v", ExitCode(err), err)
+	}
+	var dry struct {
+		Request gmailFilterPlan `json:"request"`
+	}
+	if decodeErr := json.Unmarshal(stdout.Bytes(), &dry); decodeErr != nil {
+		t.Fatalf("decode: %v\n%s", decodeErr, stdout.String())
+	}
+	plan := dry.Request
+	if len(plan.CreateLabels) != 1 || plan.CreateLabels[0] != "Receipts" || len(plan.Keep) != 1 || plan.Keep[0].ID != "keep1" ||
+		len(plan.Create) != 1 || plan.Create[0].Summary != "invoice -> label Receipts, mark read" ||
+		len(plan.Delete) != 1 || plan.Delete[0].Summary != "subject:old -> star" {
+		t.Fatalf("plan = %#v", plan)
+	}
+	fake.mu.Lock()
+	if len(fake.created) != 0 || len(fake.deleted) != 0 {
+		t.Fatalf("dry run changed filters: %#v %#v", fake.created, fake.deleted)
+	}
+	fake.mu.Unlock()
+
+	err = runKong(t, &GmailFiltersApplyCmd{}, []string{path, "--prune"}, ctx, &RootFlags{Account: "a@b.com", NoInput: true})
+	if err == nil || !strings.Contains(err.Error(), "delete 1 gmail filters") {
+		t.Fatalf("expected confirmation refusal, got %v", err)
+	}
+
+	stdout.Reset()
+	if err = runKong(t, &GmailFiltersApplyCmd{}, []string{path, "--prune"}, ctx, &RootFlags{Account: "a@b.com", Force: true}); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if len(fake.created) != 1 || fake.created[0].Action.AddLabelIds[0] != "Label_new_Receipts" || fake.created[0].Criteria.Query != "invoice" {
+		t.Fatalf("created = %#v", fake.created)
+	}
+	if len(fake.deleted) != 1 || fake.deleted[0] != "old1" {
+		t.Fatalf("deleted = %v", fake.deleted)
+	}
+	if !strings.Contains(stdout.String(), `"kept": 1`) {
+		t.Fatalf("output = %s", stdout.String())
+	}
+}
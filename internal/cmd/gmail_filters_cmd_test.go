@@ -16,6 +16,7 @@ import (
 	"google.golang.org/api/gmail/v1"
 
 	"github.com/steipete/gogcli/internal/app"
+	"github.com/steipete/gogcli/internal/gmailfilters"
 )
 
 func TestGmailFiltersCreate_Validation(t *testing.T) {
@@ -349,6 +350,32 @@ func TestGmailFiltersExport(t *testing.T) {
 		}
 	})
 
+	t.Run("yaml and xml exports parse back", func(t *testing.T) {
+		yamlResult := executeWithGmailTestService(t, []string{
+			"--plain", "--account", "a@b.com", "gmail", "filters", "export", "--format", "yaml",
+		}, svc)
+		xmlResult := executeWithGmailTestService(t, []string{
+			"--plain", "--account", "a@b.com", "gmail", "filters", "export",
+		}, svc)
+		if yamlResult.err != nil || xmlResult.err != nil {
+			t.Fatalf("export: yaml=%v xml=%v", yamlResult.err, xmlResult.err)
+		}
+		fromYAML, err := gmailfilters.ParseYAML([]byte(yamlResult.stdout))
+		if err != nil {
+			t.Fatalf("parse yaml: %v\n%s", err, yamlResult.stdout)
+		}
+		fromXML, err := gmailfilters.ParseXML([]byte(xmlResult.stdout))
+		if err != nil {
+			t.Fatalf("parse xml: %v", err)
+		}
+		if len(fromYAML.Filters) != 1 || len(fromXML.Filters) != 1 || fromYAML.Filters[0].Summary() != fromXML.Filters[0].Summary() {
+			t.Fatalf("yaml=%#v xml=%#v", fromYAML.Filters, fromXML.Filters)
+		}
+		if !strings.Contains(yamlResult.stdout, "Notifications & Alerts") || !strings.Contains(yamlResult.stdout, "category: social") {
+			t.Fatalf("unexpected yaml:\n%s", yamlResult.stdout)
+		}
+	})
+
 	t.Run("stdout json compatibility", func(t *testing.T) {
 		result := executeWithGmailTestService(t, []string{
 			"--plain", "--account", "a@b.com", "gmail", "filters", "export", "--format", "json",
@@ -0,0 +1,260 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"google.golang.org/api/gmail/v1"
+
+	"github.com/steipete/gogcli/internal/mailbox"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+const (
+	gmailImportProgressSuffix = ".gog-import.jsonl"
+	gmailImportProgressEvery  = 100
+)
+
+type GmailImportCmd struct {
+	Source       string   `arg:"" name:"source" type:"path" help:"mbox file, .eml file, Maildir, or directory of .eml files"`
+	Label        []string `name:"label" short:"l" help:"Label to add to every imported message (name or ID; repeatable, e.g. --label INBOX --label Migrated)"`
+	SourceLabels bool     `name:"source-labels" help:"Apply labels from X-Gmail-Labels headers and Maildir folders/flags" default:"true" negatable:""`
+	Dedupe       bool     `name:"dedupe" help:"Skip messages whose Message-ID is already in the mailbox (adds missing labels instead)" default:"true" negatable:""`
+	Progress     string   `name:"progress" type:"path" help:"Resume file recording handled messages (default: <source>.gog-import.jsonl)"`
+	Restart      bool     `name:"restart" help:"Ignore the resume file and start over"`
+}
+
+type gmailImportProgressEntry struct {
+	Key       string `json:"key"`
+	MessageID string `json:"message_id,omitempty"`
+	ID        string `json:"id"`
+	Duplicate bool   `json:"duplicate,omitempty"`
+}
+
+type gmailImportResult struct {
+	Source        string   `json:"source"`
+	Progress      string   `json:"progress"`
+	Imported      int      `json:"imported"`
+	Duplicates    int      `json:"duplicates"`
+	Resumed       int      `json:"resumed"`
+	LabelsCreated []string `json:"labels_created"`
+}
+
+func (c *GmailImportCmd) Run(ctx context.Context, flags *RootFlags) error {
+	source, err := expandUserPath(c.Source)
+	if err != nil {
+		return err
+	}
+	if _, statErr := os.Stat(source); statErr != nil {
+		return usagef("import source: %v", statErr)
+	}
+	progressPath := strings.TrimSpace(c.Progress)
+	if progressPath == "" {
+		progressPath = filepath.Clean(source) + gmailImportProgressSuffix
+	}
+	if progressPath, err = expandUserPath(progressPath); err != nil {
+		return err
+	}
+	dryRun := flags != nil && flags.DryRun
+	done, byMessageID := map[string]bool{}, map[string]string{}
+	switch {
+	case !c.Restart:
+		if done, byMessageID, err = loadGmailImportProgress(progressPath); err != nil {
+			return err
+		}
+	case !dryRun:
+		if removeErr := os.Remove(progressPath); removeErr != nil && !os.IsNotExist(removeErr) {
+			return removeErr
+		}
+	}
+
+	_, svc, err := requireGmailService(ctx, flags)
+	if err != nil {
+		return err
+	}
+	labelList, err := svc.Users.Labels.List("me").Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+	nameToID, _ := gmailFilterLabelMaps(labelList.Labels)
+	labels := &gmailImportLabels{svc: svc, nameToID: nameToID, create: !dryRun}
+
+	result := gmailImportResult{Source: source, Progress: progressPath, LabelsCreated: []string{}}
+	if dryRun {
+		pending, resumed := 0, 0
+		walkErr := mailbox.Walk(source, func(entry mailbox.Entry) error {
+			if done[entry.Key] {
+				resumed++
+				return nil
+			}
+			pending++
+			_, labelErr := labels.resolve(ctx, c.entryLabels(entry))
+			return labelErr
+		})
+		if walkErr != nil {
+			return walkErr
+		}
+		return dryRunExit(ctx, flags, "gmail.import", map[string]any{
+			"source":         source,
+			"progress":       progressPath,
+			"messages":       pending,
+			"resumed":        resumed,
+			"labels_created": labels.created,
+			"dedupe":         c.Dedupe,
+		})
+	}
+
+	progress, err := os.OpenFile(progressPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600) // #nosec G304 -- user-selected resume file.
+	if err != nil {
+		return err
+	}
+	defer progress.Close()
+	record := func(entry gmailImportProgressEntry) error {
+		data, marshalErr := json.Marshal(entry)
+		if marshalErr != nil {
+			return marshalErr
+		}
+		_, writeErr := progress.Write(append(data, '\n'))
+		return writeErr
+	}
+
+	importer := &gmailRawImporter{svc: svc, dedupe: c.Dedupe, known: byMessageID}
+	u := ui.FromContext(ctx)
+	handled := 0
+	err = mailbox.Walk(source, func(entry mailbox.Entry) error {
+		if done[entry.Key] {
+			result.Resumed++
+			return nil
+		}
+		handled++
+		if u != nil && handled%gmailImportProgressEvery == 0 {
+			u.Err().Linef("import\t%d\timported=%d\tduplicates=%d", handled, result.Imported, result.Duplicates)
+		}
+		labelIDs, labelErr := labels.resolve(ctx, c.entryLabels(entry))
+		if labelErr != nil {
+			return labelErr
+		}
+		imported, importErr := importer.importRaw(ctx, entry.Raw, labelIDs)
+		if importErr != nil {
+			return fmt.Errorf("import %s: %w", entry.Key, importErr)
+		}
+		progressEntry := gmailImportProgressEntry{Key: entry.Key, MessageID: imported.MessageID, ID: imported.ID, Duplicate: imported.Duplicate}
+		if imported.Duplicate {
+			if len(labelIDs) > 0 {
+				if _, modifyErr := svc.Users.Messages.Modify("me", imported.ID, &gmail.ModifyMessageRequest{AddLabelIds: labelIDs}).Context(ctx).Do(); modifyErr != nil {
+					return fmt.Errorf("label duplicate %s: %w", imported.MessageID, modifyErr)
+				}
+			}
+			result.Duplicates++
+		} else {
+			result.Imported++
+		}
+		done[entry.Key] = true
+		return record(progressEntry)
+	})
+	result.LabelsCreated = append(result.LabelsCreated, labels.created...)
+	if err != nil {
+		if u != nil && (result.Imported > 0 || result.Duplicates > 0) {
+			u.Err().Printf("Stopped after %d imported, %d duplicates; rerun the same command to resume", result.Imported, result.Duplicates)
+		}
+		return err
+	}
+	return writeResult(ctx, u,
+		kv("source", result.Source),
+		kv("progress", result.Progress),
+		kv("imported", result.Imported),
+		kv("duplicates", result.Duplicates),
+		kv("resumed", result.Resumed),
+		kv("labels_created", result.LabelsCreated),
+	)
+}
+
+func (c *GmailImportCmd) entryLabels(entry mailbox.Entry) []string {
+	names := append([]string(nil), c.Label...)
+	if c.SourceLabels {
+		names = append(names, entry.Labels...)
+	}
+	return names
+}
+
+func loadGmailImportProgress(path string) (map[string]bool, map[string]string, error) {
+	done := map[string]bool{}
+	byMessageID := map[string]string{}
+	data, err := os.ReadFile(path) // #nosec G304 -- user-selected resume file.
+	if os.IsNotExist(err) {
+		return done, byMessageID, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		// Drop a torn last line from an interrupted run so new entries start
+		// on a fresh line; that message is deduped by Message-ID next time.
+		data = data[:bytes.LastIndexByte(data, '\n')+1]
+		if truncErr := os.Truncate(path, int64(len(data))); truncErr != nil {
+			return nil, nil, truncErr
+		}
+	}
+	for _, line := range bytes.Split(data, []byte{'\n'}) {
+		var entry gmailImportProgressEntry
+		if json.Unmarshal(line, &entry) != nil || entry.Key == "" {
+			continue
+		}
+		done[entry.Key] = true
+		if entry.MessageID != "" && entry.ID != "" {
+			byMessageID[entry.MessageID] = entry.ID
+		}
+	}
+	return done, byMessageID, nil
+}
+
+// gmailImportLabels resolves label names to IDs, creating missing user
+// labels on first use (or, in a dry run, recording them as pending).
+type gmailImportLabels struct {
+	svc      *gmail.Service
+	nameToID map[string]string
+	create   bool
+	created  []string
+}
+
+func (l *gmailImportLabels) resolve(ctx context.Context, names []string) ([]string, error) {
+	ids := make([]string, 0, len(names))
+	add := func(id string) {
+		if id != "" && !strings.HasPrefix(id, gmailPendingLabelPrefix) && !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if id, ok := mailbox.SystemLabelID(name); ok {
+			add(id)
+			continue
+		}
+		key := strings.ToLower(name)
+		if id, ok := l.nameToID[key]; ok {
+			add(id)
+			continue
+		}
+		id := gmailPendingLabelPrefix + name
+		if l.create {
+			label, err := createLabel(ctx, l.svc, name)
+			if err != nil {
+				return nil, mapLabelCreateError(err, name)
+			}
+			id = label.Id
+		}
+		l.nameToID[key] = id
+		l.created = append(l.created, name)
+		add(id)
+	}
+	return ids, nil
+}
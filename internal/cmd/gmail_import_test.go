@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/api/gmail/v1"
+)
+
+func TestGmailImportMboxDedupesAndResumes(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		imported []string
+		modified []string
+		created  []string
+	)
+	svc, cleanup := newGmailServiceForTest(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		path := r.URL.Path
+		switch {
+		case strings.HasSuffix(path, "/users/me/labels") && r.Method == http.MethodGet:
+			_ = json.NewEncoder(w).Encode(map[string]any{"labels": []map[string]any{{"id": "INBOX", "name": "INBOX"}, {"id": "Label_1", "name": "Projects"}}})
+		case strings.HasSuffix(path, "/users/me/labels") && r.Method == http.MethodPost:
+			var label gmail.Label
+			_ = json.NewDecoder(r.Body).Decode(&label)
+			created = append(created, label.Name)
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "Label_new", "name": label.Name})
+		case strings.HasSuffix(path, "/users/me/messages") && r.Method == http.MethodGet:
+			var messages []map[string]any
+			if r.URL.Query().Get("q") == "rfc822msgid:old@example.com" {
+				messages = append(messages, map[string]any{"id": "existing1"})
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"messages": messages})
+		case strings.HasSuffix(path, "/users/me/messages/import"):
+			if r.URL.Query().Get("internalDateSource") != "dateHeader" {
+				t.Errorf("internalDateSource = %q", r.URL.Query().Get("internalDateSource"))
+			}
+			body, _ := io.ReadAll(r.Body)
+			imported = append(imported, string(body))
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "new1"})
+		case strings.HasSuffix(path, "/modify"):
+			var req gmail.ModifyMessageRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			modified = append(modified, strings.TrimSuffix(strings.TrimPrefix(path, "/gmail/v1/users/me/messages/"), "/modify")+"="+strings.Join(req.AddLabelIds, ","))
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "x"})
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	defer cleanup()
+
+	source := filepath.Join(t.TempDir(), "legacy.mbox")
+	mbox := "From a@example.com Thu Apr  2 10:00:00 2026\n" +
+		"Message-ID: <new@example.com>\nX-Gmail-Labels: Inbox,Projects,Opened\nSubject: first\n\nbody\n>From the start\n\n" +
+		"From b@example.com Thu Apr  2 11:00:00 2026\n" +
+		"Message-ID: <old@example.com>\nSubject: already there\n\nbody\n\n" +
+		"From a@example.com Thu Apr  2 10:00:00 2026\n" +
+		"Message-ID: <new@example.com>\nSubject: first (copy)\n\nbody\n"
+	if err := os.WriteFile(source, []byte(mbox), 0o600); err != nil {
+		t.Fatalf("write mbox: %v", err)
+	}
+	var stdout bytes.Buffer
+	ctx := withGmailTestService(newCmdRuntimeJSONOutputContext(t, &stdout, io.Discard), svc)
+	args := []string{source, "--label", "Migrated"}
+
+	err := runKong(t, &GmailImportCmd{}, args, ctx, &RootFlags{Account: "a@b.com", DryRun: true})
+	if ExitCode(err) != 0 {
+		t.Fatalf("dry run: %v", err)
+	}
+	if !strings.Contains(stdout.String(), `"messages": 3`) || !strings.Contains(stdout.String(), `"Migrated"`) || len(created) != 0 || len(imported) != 0 {
+		t.Fatalf("dry run output = %s created=%v imported=%d", stdout.String(), created, len(imported))
+	}
+
+	stdout.Reset()
+	if err = runKong(t, &GmailImportCmd{}, args, ctx, &RootFlags{Account: "a@b.com"}); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	var result gmailImportResult
+	if err = json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		t.Fatalf("decode: %v\n%s", err, stdout.String())
+	}
+	if result.Imported != 1 || result.Duplicates != 2 || strings.Join(result.LabelsCreated, ",") != "Migrated" {
+		t.Fatalf("result = %+v", result)
+	}
+	if len(imported) != 1 || !strings.Contains(imported[0], `"labelIds":["Label_new","INBOX","Label_1"]`) ||
+		!strings.Contains(imported[0], "\nFrom the start\n") || strings.Contains(imported[0], "Thu Apr") {
+		t.Fatalf("import body = %q", imported)
+	}
+	if strings.Join(modified, " ") != "existing1=Label_new new1=Label_new" {
+		t.Fatalf("modified = %v", modified)
+	}
+
+	stdout.Reset()
+	if err = runKong(t, &GmailImportCmd{}, args, ctx, &RootFlags{Account: "a@b.com"}); err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	if err = json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		t.Fatalf("decode resume: %v", err)
+	}
+	if result.Resumed != 3 || result.Imported != 0 || len(imported) != 1 {
+		t.Fatalf("resume result = %+v imports=%d", result, len(imported))
+	}
+}
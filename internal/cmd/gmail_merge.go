@@ -0,0 +1,480 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"google.golang.org/api/gmail/v1"
+
+	"github.com/steipete/gogcli/internal/mailmime"
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/tracking"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+type GmailMergeCmd struct {
+	CSV              string        `name:"csv" type:"path" help:"CSV file with a header row ('-' for stdin)"`
+	Sheet            string        `name:"sheet" help:"Spreadsheet ID to read rows from (use with --range)"`
+	Range            string        `name:"range" help:"A1 range including the header row (e.g. Contacts!A1:F)"`
+	ToColumn         string        `name:"to-column" help:"Column with recipient addresses (comma-separated for several)" default:"email"`
+	Cc               string        `name:"cc" help:"CC recipients for every message (comma-separated)"`
+	Bcc              string        `name:"bcc" help:"BCC recipients for every message (comma-separated)"`
+	Subject          string        `name:"subject" help:"Subject template (Go template; columns as {{.Name}} or {{index . \"Column Name\"}})"`
+	Body             string        `name:"body" help:"Plain text body template"`
+	BodyFile         string        `name:"body-file" help:"Plain text body template file ('-' for stdin)"`
+	BodyHTML         string        `name:"body-html" help:"HTML body template (values are HTML-escaped)"`
+	BodyHTMLFile     string        `name:"body-html-file" help:"HTML body template file ('-' for stdin)"`
+	BodyMarkdown     string        `name:"body-markdown" help:"Markdown body template (rendered to HTML; the source is the plain text part)"`
+	BodyMarkdownFile string        `name:"body-markdown-file" help:"Markdown body template file ('-' for stdin)"`
+	Attach           []string      `name:"attach" help:"Attachment for every message (repeatable)"`
+	AttachColumn     string        `name:"attach-column" help:"Column with per-row attachment paths (separated by ';')"`
+	From             string        `name:"from" help:"Send from this email address (must be a verified send-as alias)"`
+	ReplyTo          string        `name:"reply-to" help:"Reply-To header address"`
+	Draft            bool          `name:"draft" aliases:"drafts" help:"Create drafts instead of sending"`
+	Throttle         time.Duration `name:"throttle" help:"Wait between messages" default:"1s"`
+	StartRow         int           `name:"start-row" help:"Skip rows before this source row number (header is the first row)"`
+	Resume           bool          `name:"resume" help:"Skip rows whose status column already says sent or drafted"`
+	StatusColumn     string        `name:"status-column" help:"Column for per-row status; written back with --sheet (empty to disable)" default:"status"`
+	Limit            int           `name:"limit" aliases:"max" help:"Process at most this many rows (0 = all)"`
+	ContinueOnError  bool          `name:"continue-on-error" help:"Keep going after a failed row instead of stopping"`
+	Track            bool          `name:"track" help:"Add an open-tracking pixel per row (requires an HTML or Markdown body and one recipient per row)"`
+}
+
+type gmailMergeResult struct {
+	Row        int    `json:"row"`
+	To         string `json:"to,omitempty"`
+	Subject    string `json:"subject,omitempty"`
+	Status     string `json:"status"`
+	MessageID  string `json:"message_id,omitempty"`
+	ThreadID   string `json:"thread_id,omitempty"`
+	DraftID    string `json:"draft_id,omitempty"`
+	TrackingID string `json:"tracking_id,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+const (
+	gmailMergeStatusSent    = "sent"
+	gmailMergeStatusDrafted = "drafted"
+	gmailMergeStatusFailed  = "failed"
+	gmailMergeStatusSkipped = "skipped"
+)
+
+type gmailMergeRun struct {
+	svc         *gmail.Service
+	from        string
+	templates   *gmailMergeTemplates
+	toColumn    string
+	attachCol   string
+	cc          []string
+	bcc         []string
+	replyTo     string
+	attachments []string
+	draft       bool
+	trackingCfg *tracking.Config
+}
+
+func (c *GmailMergeCmd) Run(ctx context.Context, flags *RootFlags) error {
+	u := ui.FromContext(ctx)
+
+	csvPath := strings.TrimSpace(c.CSV)
+	spreadsheetID := normalizeGoogleID(strings.TrimSpace(c.Sheet))
+	rangeSpec := cleanRange(c.Range)
+	if (csvPath == "") == (spreadsheetID == "") {
+		return usage("provide exactly one of --csv or --sheet")
+	}
+	if spreadsheetID != "" && strings.TrimSpace(rangeSpec) == "" {
+		return usage("--sheet requires --range")
+	}
+	if spreadsheetID != "" {
+		if _, err := parseGmailMergeRange(rangeSpec); err != nil {
+			return err
+		}
+	}
+	if strings.TrimSpace(c.Subject) == "" {
+		return usage("required: --subject")
+	}
+	if strings.TrimSpace(c.ToColumn) == "" {
+		return usage("--to-column must not be empty")
+	}
+	if c.Throttle < 0 {
+		return usage("--throttle must not be negative")
+	}
+	if c.StartRow < 0 || c.Limit < 0 {
+		return usage("--start-row and --limit must not be negative")
+	}
+	stdinUses := 0
+	for _, path := range []string{csvPath, c.BodyFile, c.BodyHTMLFile, c.BodyMarkdownFile} {
+		if strings.TrimSpace(path) == "-" {
+			stdinUses++
+		}
+	}
+	if stdinUses > 1 {
+		return usage("use stdin for only one of --csv, --body-file, --body-html-file, or --body-markdown-file")
+	}
+
+	body, htmlBody, err := resolveComposeBodyInputs(ctx, c.Body, c.BodyFile, c.BodyHTML, c.BodyHTMLFile)
+	if err != nil {
+		return err
+	}
+	markdownBody, err := resolveBodyFileInput(ctx, c.BodyMarkdown, c.BodyMarkdownFile, "--body-markdown", "--body-markdown-file")
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(markdownBody) != "" && strings.TrimSpace(htmlBody) != "" {
+		return usage("use only one of --body-html or --body-markdown")
+	}
+	if strings.TrimSpace(body) == "" && strings.TrimSpace(htmlBody) == "" && strings.TrimSpace(markdownBody) == "" {
+		return usage("required: --body, --body-html, or --body-markdown (or a matching --*-file)")
+	}
+	templates, err := parseGmailMergeTemplates(c.Subject, body, htmlBody, markdownBody)
+	if err != nil {
+		return err
+	}
+	if c.Track && !templates.hasHTML() {
+		return usage("--track requires --body-html or --body-markdown (pixel must be in HTML)")
+	}
+	if headerErr := validateComposeHeaderInputs("", c.Cc, c.Bcc, c.ReplyTo, "", c.From); headerErr != nil {
+		return headerErr
+	}
+	attachPaths, err := expandComposeAttachmentPaths(c.Attach)
+	if err != nil {
+		return err
+	}
+
+	var source *gmailMergeSource
+	if csvPath != "" {
+		if source, err = readGmailMergeCSV(ctx, csvPath); err != nil {
+			return err
+		}
+		if err = c.checkColumns(source); err != nil {
+			return err
+		}
+	}
+
+	if dryRunErr := dryRunExit(ctx, flags, "gmail.merge", c.dryRunRequest(csvPath, spreadsheetID, rangeSpec, attachPaths, source, templates)); dryRunErr != nil {
+		return dryRunErr
+	}
+
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	if !c.Draft {
+		// merge is not in the static send paths because --draft never sends.
+		if err = gmailSendBlocked(ctx, flags, account); err != nil {
+			return err
+		}
+	}
+	svc, err := gmailService(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	var sheet *gmailMergeSheet
+	if spreadsheetID != "" {
+		sheetsSvc, sheetsErr := sheetsService(ctx, account)
+		if sheetsErr != nil {
+			return sheetsErr
+		}
+		if source, sheet, err = readGmailMergeSheet(ctx, sheetsSvc, spreadsheetID, rangeSpec, c.StatusColumn); err != nil {
+			return err
+		}
+		if err = c.checkColumns(source); err != nil {
+			return err
+		}
+	}
+
+	from, err := resolveComposeSender(ctx, svc, account, c.From)
+	if err != nil {
+		return err
+	}
+
+	run := &gmailMergeRun{
+		svc:         svc,
+		from:        from.header,
+		templates:   templates,
+		toColumn:    source.column(c.ToColumn),
+		attachCol:   source.column(c.AttachColumn),
+		cc:          splitCSV(c.Cc),
+		bcc:         splitCSV(c.Bcc),
+		replyTo:     c.ReplyTo,
+		attachments: attachPaths,
+		draft:       c.Draft,
+	}
+	if c.Track {
+		cfg, _, _, cfgErr := loadTrackingConfig(ctx, account, true)
+		if cfgErr != nil {
+			return fmt.Errorf("load tracking config: %w", cfgErr)
+		}
+		if !cfg.IsConfigured() {
+			return trackingConfigError("tracking not configured; run 'gog gmail track setup' first")
+		}
+		run.trackingCfg = cfg
+	}
+
+	statusCol := source.column(c.StatusColumn)
+	results := make([]gmailMergeResult, 0, len(source.Rows))
+	var failure error
+	processed := 0
+	for _, row := range source.Rows {
+		if row.Number < c.StartRow {
+			continue
+		}
+		if c.Resume && statusCol != "" && gmailMergeRowDone(row.Values[statusCol]) {
+			results = append(results, gmailMergeResult{Row: row.Number, To: row.Values[run.toColumn], Status: gmailMergeStatusSkipped})
+			continue
+		}
+		if len(splitCSV(row.Values[run.toColumn])) == 0 {
+			results = append(results, gmailMergeResult{Row: row.Number, Status: gmailMergeStatusSkipped, Error: "no recipient"})
+			continue
+		}
+		if c.Limit > 0 && processed >= c.Limit {
+			break
+		}
+		if processed > 0 && c.Throttle > 0 {
+			if waitErr := waitForPollInterval(ctx, c.Throttle); waitErr != nil {
+				failure = fmt.Errorf("interrupted before row %d: %w; resume with --start-row %d", row.Number, waitErr, row.Number)
+				break
+			}
+		}
+		processed++
+
+		result, rowErr := run.sendRow(ctx, row)
+		results = append(results, result)
+		if statusErr := sheet.writeStatus(ctx, row.Number, gmailMergeStatusCell(result)); statusErr != nil {
+			u.Err().Linef("Warning: %v", statusErr)
+		}
+		if rowErr != nil && !c.ContinueOnError {
+			failure = fmt.Errorf("row %d: %w; resume with --start-row %d", row.Number, rowErr, row.Number)
+			break
+		}
+	}
+
+	if err := writeGmailMergeResults(ctx, u, results); err != nil {
+		return err
+	}
+	if failure != nil {
+		return failure
+	}
+	if failed := countGmailMergeStatus(results, gmailMergeStatusFailed); failed > 0 {
+		return fmt.Errorf("%d of %d merge rows failed", failed, processed)
+	}
+	return nil
+}
+
+func (c *GmailMergeCmd) checkColumns(source *gmailMergeSource) error {
+	if source.column(c.ToColumn) == "" {
+		return usagef("merge source has no %q column (set --to-column)", c.ToColumn)
+	}
+	if strings.TrimSpace(c.AttachColumn) != "" && source.column(c.AttachColumn) == "" {
+		return usagef("merge source has no %q column", c.AttachColumn)
+	}
+	return nil
+}
+
+func (c *GmailMergeCmd) dryRunRequest(csvPath, spreadsheetID, rangeSpec string, attachPaths []string, source *gmailMergeSource, templates *gmailMergeTemplates) map[string]any {
+	request := map[string]any{
+		"csv":               csvPath,
+		"spreadsheet_id":    spreadsheetID,
+		"range":             rangeSpec,
+		"to_column":         strings.TrimSpace(c.ToColumn),
+		"cc":                splitCSV(c.Cc),
+		"bcc":               splitCSV(c.Bcc),
+		"from":              strings.TrimSpace(c.From),
+		"attachments":       attachPaths,
+		"attach_column":     strings.TrimSpace(c.AttachColumn),
+		"draft":             c.Draft,
+		"throttle":          c.Throttle.String(),
+		"start_row":         c.StartRow,
+		"resume":            c.Resume,
+		"status_column":     strings.TrimSpace(c.StatusColumn),
+		"limit":             c.Limit,
+		"continue_on_error": c.ContinueOnError,
+		"track":             c.Track,
+	}
+	if source == nil {
+		return request
+	}
+
+	// CSV rows are local, so the dry run renders them to surface template errors.
+	toColumn := source.column(c.ToColumn)
+	statusCol := source.column(c.StatusColumn)
+	preview := make([]gmailMergeResult, 0, len(source.Rows))
+	for _, row := range source.Rows {
+		if row.Number < c.StartRow {
+			continue
+		}
+		item := gmailMergeResult{Row: row.Number, To: row.Values[toColumn], Status: "pending"}
+		switch {
+		case c.Resume && statusCol != "" && gmailMergeRowDone(row.Values[statusCol]):
+			item.Status = gmailMergeStatusSkipped
+		case len(splitCSV(item.To)) == 0:
+			item.Status = gmailMergeStatusSkipped
+			item.Error = "no recipient"
+		default:
+			msg, err := templates.render(row.Values)
+			if err != nil {
+				item.Status = gmailMergeStatusFailed
+				item.Error = err.Error()
+			}
+			item.Subject = msg.Subject
+		}
+		preview = append(preview, item)
+	}
+	request["rows"] = preview
+	return request
+}
+
+func (r *gmailMergeRun) sendRow(ctx context.Context, row gmailMergeRow) (gmailMergeResult, error) {
+	to := splitCSV(row.Values[r.toColumn])
+	result := gmailMergeResult{Row: row.Number, To: strings.Join(to, ", "), Status: gmailMergeStatusFailed}
+	fail := func(err error) (gmailMergeResult, error) {
+		result.Error = err.Error()
+		return result, err
+	}
+
+	msg, err := r.templates.render(row.Values)
+	if err != nil {
+		return fail(err)
+	}
+	result.Subject = msg.Subject
+	if msg.Subject == "" {
+		return fail(errGmailMergeEmptySubject)
+	}
+
+	paths := append([]string{}, r.attachments...)
+	if r.attachCol != "" {
+		rowPaths, pathErr := expandComposeAttachmentPaths(splitGmailMergeAttachments(row.Values[r.attachCol]))
+		if pathErr != nil {
+			return fail(pathErr)
+		}
+		paths = append(paths, rowPaths...)
+	}
+	atts, _, err := mailmime.PrepareAttachments(attachmentsFromPaths(paths), os.ReadFile)
+	if err != nil {
+		return fail(err)
+	}
+
+	htmlBody := msg.BodyHTML
+	if r.trackingCfg != nil {
+		if len(to)+len(r.cc)+len(r.bcc) != 1 {
+			return fail(errGmailMergeTrackRecipients)
+		}
+		if htmlBody, result.TrackingID, err = addTrackingPixel(r.trackingCfg, to[0], msg.Subject, htmlBody); err != nil {
+			return fail(err)
+		}
+	}
+
+	message, err := buildGmailMessage(ctx, sendMessageOptions{
+		FromAddr:    r.from,
+		ReplyTo:     r.replyTo,
+		Subject:     msg.Subject,
+		Body:        msg.Body,
+		BodyHTML:    htmlBody,
+		Attachments: atts,
+	}, sendBatch{To: to, Cc: r.cc, Bcc: r.bcc}, false)
+	if err != nil {
+		return fail(err)
+	}
+
+	if r.draft {
+		draft, draftErr := r.svc.Users.Drafts.Create("me", &gmail.Draft{Message: message}).Context(ctx).Do()
+		if draftErr != nil {
+			return fail(draftErr)
+		}
+		result.Status = gmailMergeStatusDrafted
+		result.DraftID = draft.Id
+		if draft.Message != nil {
+			result.MessageID = draft.Message.Id
+			result.ThreadID = draft.Message.ThreadId
+		}
+		return result, nil
+	}
+
+	sent, err := r.svc.Users.Messages.Send("me", message).Context(ctx).Do()
+	if err != nil {
+		return fail(err)
+	}
+	result.Status = gmailMergeStatusSent
+	result.MessageID = sent.Id
+	result.ThreadID = sent.ThreadId
+	return result, nil
+}
+
+var (
+	errGmailMergeEmptySubject    = errors.New("rendered subject is empty")
+	errGmailMergeTrackRecipients = errors.New("--track requires exactly one recipient per row (no --cc/--bcc)")
+)
+
+func splitGmailMergeAttachments(value string) []string {
+	var paths []string
+	for _, part := range strings.Split(value, ";") {
+		if part = strings.TrimSpace(part); part != "" {
+			paths = append(paths, part)
+		}
+	}
+	return paths
+}
+
+// gmailMergeRowDone reports whether a status cell records a finished row.
+func gmailMergeRowDone(status string) bool {
+	status = strings.ToLower(strings.TrimSpace(status))
+	return strings.HasPrefix(status, gmailMergeStatusSent) || strings.HasPrefix(status, gmailMergeStatusDrafted)
+}
+
+func gmailMergeStatusCell(result gmailMergeResult) string {
+	stamp := time.Now().UTC().Format(time.RFC3339)
+	switch result.Status {
+	case gmailMergeStatusSent:
+		return fmt.Sprintf("sent %s %s", stamp, result.MessageID)
+	case gmailMergeStatusDrafted:
+		return fmt.Sprintf("drafted %s %s", stamp, result.DraftID)
+	default:
+		return fmt.Sprintf("error %s: %s", stamp, result.Error)
+	}
+}
+
+func countGmailMergeStatus(results []gmailMergeResult, status string) int {
+	n := 0
+	for _, r := range results {
+		if r.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+func writeGmailMergeResults(ctx context.Context, u *ui.UI, results []gmailMergeResult) error {
+	summary := map[string]int{
+		gmailMergeStatusSent:    countGmailMergeStatus(results, gmailMergeStatusSent),
+		gmailMergeStatusDrafted: countGmailMergeStatus(results, gmailMergeStatusDrafted),
+		gmailMergeStatusFailed:  countGmailMergeStatus(results, gmailMergeStatusFailed),
+		gmailMergeStatusSkipped: countGmailMergeStatus(results, gmailMergeStatusSkipped),
+	}
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, stdoutWriter(ctx), map[string]any{
+			"rows":    results,
+			"summary": summary,
+		})
+	}
+
+	if len(results) > 0 {
+		w, flush := tableWriter(ctx)
+		_, _ = fmt.Fprintln(w, "ROW\tSTATUS\tTO\tID\tERROR")
+		for _, r := range results {
+			id := r.MessageID
+			if r.DraftID != "" {
+				id = r.DraftID
+			}
+			_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", r.Row, r.Status, r.To, id, sanitizeTab(r.Error))
+		}
+		flush()
+	}
+	u.Err().Linef("Sent %d, drafted %d, failed %d, skipped %d",
+		summary[gmailMergeStatusSent], summary[gmailMergeStatusDrafted], summary[gmailMergeStatusFailed], summary[gmailMergeStatusSkipped])
+	return nil
+}
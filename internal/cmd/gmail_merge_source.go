@@ -0,0 +1,301 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/yuin/goldmark"
+	"google.golang.org/api/sheets/v4"
+
+	"github.com/steipete/gogcli/internal/config"
+	"github.com/steipete/gogcli/internal/sheetsa1"
+)
+
+type gmailMergeRow struct {
+	// Number is the row number in the source: the sheet row for Sheets, the
+	// CSV record number (header = 1) for CSV files.
+	Number int
+	Values map[string]string
+}
+
+type gmailMergeSource struct {
+	Header []string
+	Rows   []gmailMergeRow
+}
+
+// column returns the header spelled as in the source for a case-insensitive
+// column name, or "" when the column is missing.
+func (s *gmailMergeSource) column(name string) string {
+	name = strings.TrimSpace(name)
+	for _, h := range s.Header {
+		if strings.EqualFold(h, name) {
+			return h
+		}
+	}
+	return ""
+}
+
+func (s *gmailMergeSource) columnIndex(name string) int {
+	name = strings.TrimSpace(name)
+	for i, h := range s.Header {
+		if strings.EqualFold(h, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+func newGmailMergeSource(records [][]string, firstRow int) (*gmailMergeSource, error) {
+	if len(records) == 0 {
+		return nil, usage("merge source is empty; the first row must be a header")
+	}
+	header := make([]string, len(records[0]))
+	seen := map[string]bool{}
+	for i, name := range records[0] {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		if name == "" {
+			continue
+		}
+		key := strings.ToLower(name)
+		if seen[key] {
+			return nil, usagef("duplicate merge column %q", name)
+		}
+		seen[key] = true
+		header[i] = name
+	}
+
+	source := &gmailMergeSource{Header: header}
+	for i, record := range records[1:] {
+		values := make(map[string]string, len(header))
+		for col, name := range header {
+			if name == "" {
+				continue
+			}
+			if col < len(record) {
+				values[name] = strings.TrimSpace(record[col])
+			} else {
+				values[name] = ""
+			}
+		}
+		source.Rows = append(source.Rows, gmailMergeRow{Number: firstRow + 1 + i, Values: values})
+	}
+	return source, nil
+}
+
+func readGmailMergeCSV(ctx context.Context, path string) (*gmailMergeSource, error) {
+	var r io.Reader
+	if strings.TrimSpace(path) == "-" {
+		r = stdinReader(ctx)
+	} else {
+		expanded, err := config.ExpandPath(path)
+		if err != nil {
+			return nil, err
+		}
+		f, err := os.Open(expanded) //nolint:gosec // user-provided path
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, usagef("read --csv: %v", err)
+	}
+	return newGmailMergeSource(records, 1)
+}
+
+// gmailMergeSheet reads merge rows from a Sheets range and writes per-row
+// status back into the status column.
+type gmailMergeSheet struct {
+	svc           *sheets.Service
+	spreadsheetID string
+	sheetName     string
+	headerRow     int
+	statusCol     int
+	writeHeader   string
+}
+
+func parseGmailMergeRange(rangeSpec string) (sheetsa1.Range, error) {
+	rng, err := sheetsa1.Parse(rangeSpec)
+	if err != nil {
+		return sheetsa1.Range{}, usagef("--range must be an A1 range with a header row (e.g. Contacts!A1:F): %v", err)
+	}
+	if rng.StartRow == 0 {
+		rng.StartRow = 1
+	}
+	if rng.StartCol == 0 {
+		rng.StartCol = 1
+	}
+	return rng, nil
+}
+
+func readGmailMergeSheet(ctx context.Context, svc *sheets.Service, spreadsheetID, rangeSpec, statusColumn string) (*gmailMergeSource, *gmailMergeSheet, error) {
+	rng, err := parseGmailMergeRange(rangeSpec)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp, err := svc.Spreadsheets.Values.Get(spreadsheetID, rangeSpec).Context(ctx).Do()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	records := make([][]string, 0, len(resp.Values))
+	for _, row := range resp.Values {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = fmt.Sprint(cell)
+		}
+		records = append(records, cells)
+	}
+	source, err := newGmailMergeSource(records, rng.StartRow)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sheet := &gmailMergeSheet{
+		svc:           svc,
+		spreadsheetID: spreadsheetID,
+		sheetName:     rng.SheetName,
+		headerRow:     rng.StartRow,
+	}
+	if statusColumn = strings.TrimSpace(statusColumn); statusColumn != "" {
+		if idx := source.columnIndex(statusColumn); idx >= 0 {
+			sheet.statusCol = rng.StartCol + idx
+		} else {
+			sheet.statusCol = rng.StartCol + len(source.Header)
+			sheet.writeHeader = statusColumn
+		}
+	}
+	return source, sheet, nil
+}
+
+// writeStatus records a row's merge status. The status header is written on
+// first use when the range did not already have one.
+func (s *gmailMergeSheet) writeStatus(ctx context.Context, row int, status string) error {
+	if s == nil || s.statusCol == 0 {
+		return nil
+	}
+	if s.writeHeader != "" {
+		if err := s.writeCell(ctx, s.headerRow, s.writeHeader); err != nil {
+			return err
+		}
+		s.writeHeader = ""
+	}
+	return s.writeCell(ctx, row, status)
+}
+
+func (s *gmailMergeSheet) writeCell(ctx context.Context, row int, value string) error {
+	cell := sheetsa1.FormatCell(s.sheetName, row, s.statusCol)
+	_, err := s.svc.Spreadsheets.Values.Update(s.spreadsheetID, cell, &sheets.ValueRange{
+		Values: [][]interface{}{{value}},
+	}).ValueInputOption("RAW").Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("write merge status to %s: %w", cell, err)
+	}
+	return nil
+}
+
+// gmailMergeTemplates renders the per-row subject and bodies. Missing columns
+// are errors so a typo fails the row instead of sending a blank.
+type gmailMergeTemplates struct {
+	subject  *texttemplate.Template
+	text     *texttemplate.Template
+	html     *htmltemplate.Template
+	markdown *texttemplate.Template
+}
+
+type gmailMergeMessage struct {
+	Subject  string
+	Body     string
+	BodyHTML string
+}
+
+func parseGmailMergeTemplates(subject, text, html, markdown string) (*gmailMergeTemplates, error) {
+	t := &gmailMergeTemplates{}
+	var err error
+	if t.subject, err = texttemplate.New("subject").Option("missingkey=error").Parse(subject); err != nil {
+		return nil, usagef("parse --subject template: %v", err)
+	}
+	if strings.TrimSpace(text) != "" {
+		if t.text, err = texttemplate.New("body").Option("missingkey=error").Parse(text); err != nil {
+			return nil, usagef("parse body template: %v", err)
+		}
+	}
+	if strings.TrimSpace(html) != "" {
+		if t.html, err = htmltemplate.New("html").Option("missingkey=error").Parse(html); err != nil {
+			return nil, usagef("parse HTML body template: %v", err)
+		}
+	}
+	if strings.TrimSpace(markdown) != "" {
+		if t.markdown, err = texttemplate.New("markdown").Option("missingkey=error").Parse(markdown); err != nil {
+			return nil, usagef("parse Markdown body template: %v", err)
+		}
+	}
+	return t, nil
+}
+
+func (t *gmailMergeTemplates) hasHTML() bool {
+	return t.html != nil || t.markdown != nil
+}
+
+func (t *gmailMergeTemplates) render(values map[string]string) (gmailMergeMessage, error) {
+	var msg gmailMergeMessage
+	subject, err := executeGmailMergeTemplate(t.subject, values)
+	if err != nil {
+		return msg, err
+	}
+	msg.Subject = strings.TrimSpace(subject)
+	if strings.ContainsAny(msg.Subject, "\r\n") {
+		return msg, errGmailMergeSubjectNewline
+	}
+
+	if t.text != nil {
+		if msg.Body, err = executeGmailMergeTemplate(t.text, values); err != nil {
+			return msg, err
+		}
+	}
+	if t.html != nil {
+		var buf bytes.Buffer
+		if err = t.html.Execute(&buf, values); err != nil {
+			return msg, err
+		}
+		msg.BodyHTML = buf.String()
+	}
+	if t.markdown != nil {
+		source, mdErr := executeGmailMergeTemplate(t.markdown, values)
+		if mdErr != nil {
+			return msg, mdErr
+		}
+		var buf bytes.Buffer
+		if err = goldmark.Convert([]byte(source), &buf); err != nil {
+			return msg, fmt.Errorf("render markdown: %w", err)
+		}
+		msg.BodyHTML = buf.String()
+		if msg.Body == "" {
+			msg.Body = source
+		}
+	}
+	return msg, nil
+}
+
+var errGmailMergeSubjectNewline = errors.New("rendered subject contains a newline")
+
+func executeGmailMergeTemplate(tmpl *texttemplate.Template, values map[string]string) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, values); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
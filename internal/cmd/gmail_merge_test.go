@@ -0,0 +1,245 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/api/gmail/v1"
+	"google.golang.org/api/sheets/v4"
+
+	"github.com/steipete/gogcli/internal/config"
+)
+
+type gmailMergeRecorder struct {
+	mu     sync.Mutex
+	sent   []string
+	drafts []string
+	failTo string
+}
+
+func newGmailMergeTestService(t *testing.T, rec *gmailMergeRecorder) *gmail.Service {
+	t.Helper()
+	svc, cleanup := newGmailServiceForTest(t, func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/gmail/v1")
+		if r.Method != http.MethodPost {
+			http.NotFound(w, r)
+			return
+		}
+		var raw string
+		switch path {
+		case "/users/me/messages/send":
+			var msg gmail.Message
+			_ = json.NewDecoder(r.Body).Decode(&msg)
+			raw = msg.Raw
+		case "/users/me/drafts":
+			var draft gmail.Draft
+			_ = json.NewDecoder(r.Body).Decode(&draft)
+			raw = draft.Message.Raw
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		decoded, err := base64.RawURLEncoding.DecodeString(raw)
+		if err != nil {
+			t.Fatalf("decode raw: %v", err)
+		}
+		rec.mu.Lock()
+		defer rec.mu.Unlock()
+		if rec.failTo != "" && strings.Contains(string(decoded), "To: "+rec.failTo) {
+			http.Error(w, `{"error":{"code":400,"message":"bad recipient"}}`, http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if path == "/users/me/drafts" {
+			rec.drafts = append(rec.drafts, string(decoded))
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "d" + strconv.Itoa(len(rec.drafts)), "message": map[string]any{"id": "m", "threadId": "t"}})
+			return
+		}
+		rec.sent = append(rec.sent, string(decoded))
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "m" + strconv.Itoa(len(rec.sent)), "threadId": "t"})
+	})
+	t.Cleanup(cleanup)
+	return svc
+}
+
+func TestGmailMergeCmd_CSVMarkdownWithAttachments(t *testing.T) {
+	setWatchTestConfigHome(t)
+	dir := t.TempDir()
+	attachment := filepath.Join(dir, "invoice.txt")
+	if err := os.WriteFile(attachment, []byte("invoice-bytes"), 0o600); err != nil {
+		t.Fatalf("write attachment: %v", err)
+	}
+	csvPath := filepath.Join(dir, "people.csv")
+	csvBody := "Email,Name,Files\n" +
+		"ada@example.com,Ada <3," + attachment + "\n" +
+		",Nobody,\n" +
+		"bob@example.com,Bob,\n"
+	if err := os.WriteFile(csvPath, []byte(csvBody), 0o600); err != nil {
+		t.Fatalf("write csv: %v", err)
+	}
+
+	rec := &gmailMergeRecorder{}
+	var stdout bytes.Buffer
+	ctx := withGmailTestService(newCmdRuntimeJSONOutputContext(t, &stdout, io.Discard), newGmailMergeTestService(t, rec))
+	err := runKong(t, &GmailMergeCmd{}, []string{
+		"--csv", csvPath,
+		"--subject", "Hi {{.Name}}",
+		"--body-markdown", "Hello **{{.Name}}**",
+		"--attach-column", "files",
+		"--throttle", "0s",
+	}, ctx, &RootFlags{Account: "a@b.com"})
+	if err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if len(rec.sent) != 2 {
+		t.Fatalf("sent = %d messages", len(rec.sent))
+	}
+	first := rec.sent[0]
+	for _, want := range []string{"To: ada@example.com", "Subject: Hi Ada <3", "<strong>Ada &lt;3</strong>", "Hello **Ada <3**", base64.StdEncoding.EncodeToString([]byte("invoice-bytes"))} {
+		if !strings.Contains(first, want) {
+			t.Fatalf("first message missing %q:\n%s", want, first)
+		}
+	}
+	if strings.Contains(rec.sent[1], "invoice.txt") {
+		t.Fatalf("second row got first row's attachment")
+	}
+
+	var got struct {
+		Rows    []gmailMergeResult `json:"rows"`
+		Summary map[string]int     `json:"summary"`
+	}
+	if decodeErr := json.Unmarshal(stdout.Bytes(), &got); decodeErr != nil {
+		t.Fatalf("decode: %v\n%s", decodeErr, stdout.String())
+	}
+	if got.Summary["sent"] != 2 || got.Summary["skipped"] != 1 || len(got.Rows) != 3 {
+		t.Fatalf("output = %#v", got)
+	}
+	if got.Rows[0].Row != 2 || got.Rows[0].MessageID != "m1" || got.Rows[1].Row != 3 || got.Rows[1].Status != "skipped" {
+		t.Fatalf("rows = %#v", got.Rows)
+	}
+}
+
+func TestGmailMergeCmd_SheetResumeAndStatusWriteback(t *testing.T) {
+	setWatchTestConfigHome(t)
+
+	var mu sync.Mutex
+	updates := map[string]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"range": "Contacts!B1:D4",
+				"values": [][]any{
+					{"email", "name", "Status"},
+					{"done@example.com", "Done", "sent 2026-01-01T00:00:00Z m0"},
+					{"bad@example.com", "Bad"},
+					{"later@example.com", "Later"},
+				},
+			})
+		case http.MethodPut:
+			var vr sheets.ValueRange
+			_ = json.NewDecoder(r.Body).Decode(&vr)
+			if r.URL.Query().Get("valueInputOption") != "RAW" {
+				t.Errorf("valueInputOption = %q", r.URL.Query().Get("valueInputOption"))
+			}
+			cell := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+			mu.Lock()
+			updates[cell] = vr.Values[0][0].(string)
+			mu.Unlock()
+			_ = json.NewEncoder(w).Encode(map[string]any{"updatedRange": cell})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	sheetsSvc := newSheetsServiceFromServer(t, srv)
+
+	rec := &gmailMergeRecorder{failTo: "bad@example.com"}
+	ctx := withSheetsTestService(withGmailTestService(newCmdRuntimeJSONOutputContext(t, io.Discard, io.Discard), newGmailMergeTestService(t, rec)), sheetsSvc)
+	args := []string{"--sheet", "sheet1", "--range", "Contacts!B1:D", "--subject", "Hi {{.name}}", "--body", "Hello", "--throttle", "0s", "--resume", "--draft"}
+	err := runKong(t, &GmailMergeCmd{}, args, ctx, &RootFlags{Account: "a@b.com"})
+	if err == nil || !strings.Contains(err.Error(), "resume with --start-row 3") {
+		t.Fatalf("expected row 3 failure with resume hint, got %v", err)
+	}
+
+	mu.Lock()
+	if _, ok := updates["Contacts!D1"]; ok || !strings.HasPrefix(updates["Contacts!D3"], "error ") {
+		t.Fatalf("updates after failure = %#v", updates)
+	}
+	mu.Unlock()
+	rec.mu.Lock()
+	if len(rec.drafts) != 0 {
+		t.Fatalf("drafts after failure = %d", len(rec.drafts))
+	}
+	rec.failTo = ""
+	rec.mu.Unlock()
+
+	err = runKong(t, &GmailMergeCmd{}, append(args, "--start-row", "3"), ctx, &RootFlags{Account: "a@b.com"})
+	if err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !strings.HasPrefix(updates["Contacts!D3"], "drafted ") || !strings.HasSuffix(updates["Contacts!D4"], " d2") {
+		t.Fatalf("updates after resume = %#v", updates)
+	}
+	if _, ok := updates["Contacts!D2"]; ok {
+		t.Fatalf("row 2 was already sent but got rewritten")
+	}
+}
+
+func TestGmailMergeCmd_DryRunRendersCSV(t *testing.T) {
+	setWatchTestConfigHome(t)
+	csvPath := filepath.Join(t.TempDir(), "people.csv")
+	if err := os.WriteFile(csvPath, []byte("email,name\na@example.com,Ada\nb@example.com,\n"), 0o600); err != nil {
+		t.Fatalf("write csv: %v", err)
+	}
+
+	var stdout bytes.Buffer
+	ctx := newCmdRuntimeJSONOutputContext(t, &stdout, io.Discard)
+	err := runKong(t, &GmailMergeCmd{}, []string{"--csv", csvPath, "--subject", "Hi {{.name}} {{.nickname}}", "--body", "x"}, ctx, &RootFlags{Account: "a@b.com", DryRun: true})
+	if ExitCode(err) != 0 {
+		t.Fatalf("exit = %d: %v", ExitCode(err), err)
+	}
+	var got struct {
+		Request struct {
+			Rows []gmailMergeResult `json:"rows"`
+		} `json:"request"`
+	}
+	if decodeErr := json.Unmarshal(stdout.Bytes(), &got); decodeErr != nil {
+		t.Fatalf("decode: %v\n%s", decodeErr, stdout.String())
+	}
+	if len(got.Request.Rows) != 2 || got.Request.Rows[0].Status != "failed" || !strings.Contains(got.Request.Rows[0].Error, "nickname") {
+		t.Fatalf("rows = %#v", got.Request.Rows)
+	}
+
+	err = runKong(t, &GmailMergeCmd{}, []string{"--csv", csvPath, "--to-column", "mail", "--subject", "s", "--body", "x"}, ctx, &RootFlags{Account: "a@b.com", DryRun: true})
+	if ExitCode(err) != 2 {
+		t.Fatalf("missing column exit = %d: %v", ExitCode(err), err)
+	}
+	err = runKong(t, &GmailMergeCmd{}, []string{"--csv", csvPath, "--subject", "s", "--body", "x"}, ctx, &RootFlags{Account: "a@b.com", GmailNoSend: true})
+	if err == nil || !strings.Contains(err.Error(), "--gmail-no-send") {
+		t.Fatalf("expected no-send error, got %v", err)
+	}
+	if err = defaultConfigStoreForTest(t).Write(config.File{NoSendAccounts: map[string]bool{"a@b.com": true}}); err != nil {
+		t.Fatalf("WriteConfig: %v", err)
+	}
+	err = runKong(t, &GmailMergeCmd{}, []string{"--csv", csvPath, "--subject", "s", "--body", "x"}, ctx, &RootFlags{Account: "a@b.com"})
+	if err == nil || !strings.Contains(err.Error(), "config no-send") {
+		t.Fatalf("expected per-account no-send error, got %v", err)
+	}
+}
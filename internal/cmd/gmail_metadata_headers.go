@@ -11,6 +11,8 @@ var (
 	}
 
 	gmailMessageSummaryMetadataHeaders = []string{"From", "Subject", "Date"}
+
+	gmailSubscriptionMetadataHeaders = []string{"From", "List-Id", "List-Unsubscribe", "List-Unsubscribe-Post"}
 )
 
 func defaultGmailGetMetadataHeaders() []string {
@@ -10,15 +10,16 @@ import (
 )
 
 var gmailSendCommandPaths = map[string]struct{}{
-	"send":              {},
-	"gmail.send":        {},
-	"gmail.reply":       {},
-	"gmail.reply-all":   {},
-	"gmail.replyall":    {},
-	"gmail.autoreply":   {},
-	"gmail.forward":     {},
-	"gmail.fwd":         {},
-	"gmail.drafts.send": {},
+	"send":                {},
+	"gmail.send":          {},
+	"gmail.reply":         {},
+	"gmail.reply-all":     {},
+	"gmail.replyall":      {},
+	"gmail.autoreply":     {},
+	"gmail.forward":       {},
+	"gmail.fwd":           {},
+	"gmail.drafts.send":   {},
+	"gmail.scheduled.run": {},
 }
 
 func enforceGmailNoSend(kctx *kong.Context, flags *RootFlags, runtime *app.Runtime) error {
@@ -65,3 +66,20 @@ func isGmailSendPath(path []string) bool {
 	_, ok := gmailSendCommandPaths[strings.Join(path, ".")]
 	return ok
 }
+
+// gmailSendBlocked returns the reason sending is blocked for account, or nil.
+// Commands that only sometimes send (and so are not in gmailSendCommandPaths)
+// call it before the sending step.
+func gmailSendBlocked(ctx context.Context, flags *RootFlags, account string) error {
+	if flags != nil && flags.GmailNoSend {
+		return usage("Gmail sending is blocked by --gmail-no-send")
+	}
+	cfg, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if cfg.GmailNoSend {
+		return usage("Gmail sending is blocked by config gmail_no_send")
+	}
+	return checkAccountNoSend(ctx, account)
+}
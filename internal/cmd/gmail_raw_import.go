@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"google.golang.org/api/gmail/v1"
+	"google.golang.org/api/googleapi"
+)
+
+// gmailPendingLabelPrefix marks label IDs that a dry run would create, so
+// plans can still show which messages use them.
+const gmailPendingLabelPrefix = "pending:"
+
+// gmailRawImporter imports raw RFC 822 messages into a mailbox, shared by
+// 'gmail import' and 'backup restore'. With dedupe set, a message whose
+// Message-ID is already in the mailbox is reported as a duplicate instead.
+type gmailRawImporter struct {
+	svc    *gmail.Service
+	dedupe bool
+	dryRun bool
+	// known maps Message-IDs handled earlier (this run or a resumed one) to
+	// their Gmail IDs, saving a search per repeated message.
+	known map[string]string
+}
+
+// gmailRawImport describes one handled message. ID is empty for a message a
+// dry run would import.
+type gmailRawImport struct {
+	MessageID string
+	ID        string
+	Duplicate bool
+}
+
+func (g *gmailRawImporter) importRaw(ctx context.Context, raw []byte, labelIDs []string) (gmailRawImport, error) {
+	result := gmailRawImport{MessageID: gmailRawMessageID(raw)}
+	if result.MessageID != "" && g.dedupe {
+		existing, err := g.lookup(ctx, result.MessageID)
+		if err != nil {
+			return gmailRawImport{}, err
+		}
+		if existing != "" {
+			result.ID, result.Duplicate = existing, true
+			g.remember(result)
+			return result, nil
+		}
+	}
+	if g.dryRun {
+		return result, nil
+	}
+	imported, err := g.svc.Users.Messages.Import("me", &gmail.Message{LabelIds: labelIDs}).
+		InternalDateSource("dateHeader").
+		NeverMarkSpam(true).
+		Media(bytes.NewReader(raw), googleapi.ContentType("message/rfc822")).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return gmailRawImport{}, err
+	}
+	result.ID = imported.Id
+	g.remember(result)
+	return result, nil
+}
+
+func (g *gmailRawImporter) lookup(ctx context.Context, messageID string) (string, error) {
+	if id := g.known[messageID]; id != "" {
+		return id, nil
+	}
+	found, err := g.svc.Users.Messages.List("me").Q("rfc822msgid:" + messageID).IncludeSpamTrash(true).MaxResults(1).Context(ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("look up message %s: %w", messageID, err)
+	}
+	if len(found.Messages) > 0 {
+		return found.Messages[0].Id, nil
+	}
+	return "", nil
+}
+
+func (g *gmailRawImporter) remember(result gmailRawImport) {
+	if g.known != nil && result.MessageID != "" && result.ID != "" {
+		g.known[result.MessageID] = result.ID
+	}
+}
+
+func gmailRawMessageID(raw []byte) string {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return ""
+	}
+	return strings.Trim(strings.TrimSpace(msg.Header.Get("Message-ID")), "<>")
+}
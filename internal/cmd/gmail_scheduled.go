@@ -0,0 +1,357 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"google.golang.org/api/gmail/v1"
+
+	"github.com/steipete/gogcli/internal/config"
+	"github.com/steipete/gogcli/internal/filelock"
+	"github.com/steipete/gogcli/internal/gmailschedule"
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/timeparse"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+func openGmailSchedule(ctx context.Context, account string) (*gmailschedule.Repository, error) {
+	layout, err := commandLayout(ctx, config.PathKindState)
+	if err != nil {
+		return nil, err
+	}
+	repo := gmailschedule.New(filepath.Join(layout.GmailScheduledDir(), sanitizeAccountForPath(account)), gmailschedule.Options{})
+	if err := repo.Ensure(); err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+// parseGmailSendAt resolves --at: a duration from now ("90m", "+2h") or any
+// expression ParseRangeExpr accepts ("tomorrow 9am", "2026-03-02 14:30").
+func parseGmailSendAt(value string, now time.Time, loc *time.Location) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if d, err := time.ParseDuration(strings.TrimPrefix(value, "+")); err == nil {
+		if d <= 0 {
+			return time.Time{}, usage("--at duration must be positive")
+		}
+		return now.Add(d), nil
+	}
+	at, err := timeparse.ParseRangeExpr(value, now, loc)
+	if err != nil {
+		return time.Time{}, usagef("--at: %v", err)
+	}
+	if !at.After(now) {
+		return time.Time{}, usagef("--at %s is in the past", at.Format(time.RFC3339))
+	}
+	return at, nil
+}
+
+// scheduleGmailSend stores the composed message as a draft and records a
+// schedule entry that gmail scheduled run sends once sendAt has passed.
+func scheduleGmailSend(ctx context.Context, svc *gmail.Service, account string, opts sendMessageOptions, batch sendBatch, sendAt time.Time) error {
+	trackingID := ""
+	if opts.Track {
+		var err error
+		opts.BodyHTML, trackingID, err = addTrackingPixel(opts.TrackingCfg, batch.TrackingRecipient, opts.Subject, opts.BodyHTML)
+		if err != nil {
+			return err
+		}
+	}
+	msg, err := buildGmailMessage(ctx, opts, batch, false)
+	if err != nil {
+		return err
+	}
+	repo, err := openGmailSchedule(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	draft, err := svc.Users.Drafts.Create("me", &gmail.Draft{Message: msg}).Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+	threadID := msg.ThreadId
+	if draft.Message != nil && draft.Message.ThreadId != "" {
+		threadID = draft.Message.ThreadId
+	}
+	entry, err := repo.Add(gmailschedule.Entry{
+		Account:  account,
+		DraftID:  draft.Id,
+		ThreadID: threadID,
+		To:       append(append(append([]string{}, batch.To...), batch.Cc...), batch.Bcc...),
+		Subject:  opts.Subject,
+		SendAt:   sendAt,
+	})
+	if err != nil {
+		return fmt.Errorf("draft %s was created but not scheduled: %w", draft.Id, err)
+	}
+
+	kvs := []resultKV{
+		kv("id", entry.ID),
+		kv("draft_id", entry.DraftID),
+		kv("send_at", entry.SendAt.Format(time.RFC3339)),
+	}
+	if trackingID != "" {
+		kvs = append(kvs, kv("tracking_id", trackingID))
+	}
+	return writeResult(ctx, ui.FromContext(ctx), kvs...)
+}
+
+type GmailScheduledCmd struct {
+	List   GmailScheduledListCmd   `cmd:"" name:"list" aliases:"ls" help:"List scheduled sends"`
+	Cancel GmailScheduledCancelCmd `cmd:"" name:"cancel" aliases:"rm,delete" help:"Cancel scheduled sends and delete their drafts"`
+	Run    GmailScheduledRunCmd    `cmd:"" name:"run" help:"Send scheduled drafts that are due (run from cron, or with --loop)"`
+}
+
+type gmailScheduledItem struct {
+	ID        string   `json:"id"`
+	State     string   `json:"state"`
+	SendAt    string   `json:"send_at"`
+	DraftID   string   `json:"draft_id"`
+	ThreadID  string   `json:"thread_id,omitempty"`
+	To        []string `json:"to,omitempty"`
+	Subject   string   `json:"subject,omitempty"`
+	Attempts  int      `json:"attempts,omitempty"`
+	LastError string   `json:"last_error,omitempty"`
+	SentAt    string   `json:"sent_at,omitempty"`
+	MessageID string   `json:"message_id,omitempty"`
+}
+
+func gmailScheduledItemFromEntry(entry gmailschedule.Entry) gmailScheduledItem {
+	return gmailScheduledItem{
+		ID:        entry.ID,
+		State:     entry.State,
+		SendAt:    formatOutboxTime(entry.SendAt),
+		DraftID:   entry.DraftID,
+		ThreadID:  entry.ThreadID,
+		To:        entry.To,
+		Subject:   entry.Subject,
+		Attempts:  entry.Attempts,
+		LastError: entry.LastError,
+		SentAt:    formatOutboxTime(entry.SentAt),
+		MessageID: entry.MessageID,
+	}
+}
+
+func gmailScheduledItems(entries []gmailschedule.Entry) []gmailScheduledItem {
+	items := make([]gmailScheduledItem, 0, len(entries))
+	for _, entry := range entries {
+		items = append(items, gmailScheduledItemFromEntry(entry))
+	}
+	return items
+}
+
+type GmailScheduledListCmd struct {
+	State string `name:"state" help:"Entries to list: all, pending, sent, or failed" enum:"all,pending,sent,failed" default:"all"`
+}
+
+func (c *GmailScheduledListCmd) Run(ctx context.Context, flags *RootFlags) error {
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	repo, err := openGmailSchedule(ctx, account)
+	if err != nil {
+		return err
+	}
+	entries, err := repo.List()
+	if err != nil {
+		return err
+	}
+
+	items := make([]gmailScheduledItem, 0, len(entries))
+	for _, entry := range entries {
+		if c.State != "all" && entry.State != c.State {
+			continue
+		}
+		items = append(items, gmailScheduledItemFromEntry(entry))
+	}
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, stdoutWriter(ctx), map[string]any{"scheduled": items})
+	}
+	if len(items) == 0 {
+		ui.FromContext(ctx).Err().Println("No scheduled sends")
+		return nil
+	}
+	w, flush := tableWriter(ctx)
+	defer flush()
+	_, _ = fmt.Fprintln(w, "ID\tSTATE\tSEND_AT\tTO\tSUBJECT\tDRAFT\tLAST_ERROR")
+	for _, item := range items {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			item.ID, item.State, item.SendAt, strings.Join(item.To, ","), sanitizeTab(item.Subject), item.DraftID, sanitizeTab(item.LastError))
+	}
+	return nil
+}
+
+type GmailScheduledCancelCmd struct {
+	IDs       []string `arg:"" name:"id" help:"Scheduled send IDs"`
+	KeepDraft bool     `name:"keep-draft" help:"Keep the Gmail draft instead of deleting it"`
+}
+
+func (c *GmailScheduledCancelCmd) Run(ctx context.Context, flags *RootFlags) error {
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	for _, id := range c.IDs {
+		if validateErr := gmailschedule.ValidateID(id); validateErr != nil {
+			return usage(validateErr.Error())
+		}
+	}
+	action := fmt.Sprintf("cancel %d scheduled sends", len(c.IDs))
+	if !c.KeepDraft {
+		action += " and delete their drafts"
+	}
+	if confirmErr := dryRunAndConfirmDestructive(ctx, flags, "gmail.scheduled.cancel", map[string]any{
+		"account":    account,
+		"ids":        c.IDs,
+		"keep_draft": c.KeepDraft,
+	}, action); confirmErr != nil {
+		return confirmErr
+	}
+
+	repo, err := openGmailSchedule(ctx, account)
+	if err != nil {
+		return err
+	}
+	var svc *gmail.Service
+	if !c.KeepDraft {
+		if _, svc, err = requireGmailService(ctx, flags); err != nil {
+			return err
+		}
+	}
+
+	cancelled := make([]string, 0, len(c.IDs))
+	for _, id := range c.IDs {
+		_, cancelErr := repo.Cancel(id, func(entry gmailschedule.Entry) error {
+			if c.KeepDraft {
+				return nil
+			}
+			deleteErr := svc.Users.Drafts.Delete("me", entry.DraftID).Context(ctx).Do()
+			if deleteErr != nil && !isGoogleNotFound(deleteErr) {
+				return fmt.Errorf("delete draft %s: %w", entry.DraftID, deleteErr)
+			}
+			return nil
+		})
+		if cancelErr != nil {
+			if errors.Is(cancelErr, gmailschedule.ErrNotFound) || errors.Is(cancelErr, gmailschedule.ErrNotActive) {
+				return usage(cancelErr.Error())
+			}
+			return cancelErr
+		}
+		cancelled = append(cancelled, id)
+	}
+	return writeResult(ctx, ui.FromContext(ctx), kv("cancelled", cancelled))
+}
+
+type GmailScheduledRunCmd struct {
+	Loop      bool          `name:"loop" aliases:"daemon" help:"Keep running and send drafts as they come due"`
+	Interval  time.Duration `name:"interval" help:"How often --loop checks for due sends" default:"1m"`
+	Limit     int           `name:"limit" aliases:"max" help:"Send at most this many drafts per pass (0 = all)"`
+	Retention time.Duration `name:"retention" help:"Forget sent entries after this long" default:"720h"`
+}
+
+func (c *GmailScheduledRunCmd) Run(ctx context.Context, flags *RootFlags) error {
+	if c.Loop && c.Interval <= 0 {
+		return usage("--interval must be positive")
+	}
+	if c.Limit < 0 {
+		return usage("--limit must not be negative")
+	}
+	account, svc, err := requireGmailSendService(ctx, flags)
+	if err != nil {
+		return err
+	}
+	repo, err := openGmailSchedule(ctx, account)
+	if err != nil {
+		return err
+	}
+	send := func(ctx context.Context, entry gmailschedule.Entry) (string, error) {
+		sent, sendErr := svc.Users.Drafts.Send("me", &gmail.Draft{Id: entry.DraftID}).Context(ctx).Do()
+		if sendErr != nil {
+			if isGoogleNotFound(sendErr) {
+				return "", &gmailschedule.PermanentError{Err: fmt.Errorf("draft %s no longer exists", entry.DraftID)}
+			}
+			return "", sendErr
+		}
+		return sent.Id, nil
+	}
+
+	if !c.Loop {
+		result, runErr := repo.RunDue(ctx, c.Limit, send)
+		if runErr != nil {
+			return runErr
+		}
+		c.prune(ctx, repo)
+		if writeErr := writeGmailScheduledRun(ctx, result); writeErr != nil {
+			return writeErr
+		}
+		if len(result.Failed) > 0 {
+			return fmt.Errorf("%d scheduled sends failed; see gog gmail scheduled list --state failed", len(result.Failed))
+		}
+		return nil
+	}
+
+	ctx, stop := pollSignalContext(ctx)
+	defer stop()
+	u := ui.FromContext(ctx)
+	for {
+		result, runErr := repo.RunDue(ctx, c.Limit, send)
+		switch {
+		case runErr != nil && ctx.Err() != nil:
+			return nil
+		case runErr != nil && errors.Is(runErr, filelock.ErrTimeout):
+			u.Err().Linef("scheduled: another run holds the lock; retrying")
+		case runErr != nil:
+			u.Err().Linef("scheduled: %v", runErr)
+		default:
+			logGmailScheduledRun(u, result)
+		}
+		c.prune(ctx, repo)
+		if waitErr := waitForPollInterval(ctx, c.Interval); waitErr != nil {
+			return nil
+		}
+	}
+}
+
+func (c *GmailScheduledRunCmd) prune(ctx context.Context, repo *gmailschedule.Repository) {
+	if c.Retention <= 0 {
+		return
+	}
+	if _, err := repo.PruneSent(c.Retention); err != nil && !errors.Is(err, filelock.ErrTimeout) {
+		ui.FromContext(ctx).Err().Linef("Warning: %v", err)
+	}
+}
+
+func writeGmailScheduledRun(ctx context.Context, result gmailschedule.RunResult) error {
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, stdoutWriter(ctx), map[string]any{
+			"sent":    gmailScheduledItems(result.Sent),
+			"failed":  gmailScheduledItems(result.Failed),
+			"retried": gmailScheduledItems(result.Retried),
+		})
+	}
+	u := ui.FromContext(ctx)
+	if len(result.Sent)+len(result.Failed)+len(result.Retried) == 0 {
+		u.Err().Println("No scheduled sends due")
+		return nil
+	}
+	logGmailScheduledRun(u, result)
+	return nil
+}
+
+func logGmailScheduledRun(u *ui.UI, result gmailschedule.RunResult) {
+	for _, entry := range result.Sent {
+		u.Out().Linef("sent\t%s\t%s", entry.ID, entry.MessageID)
+	}
+	for _, entry := range result.Retried {
+		u.Err().Linef("retry\t%s\t%s", entry.ID, entry.LastError)
+	}
+	for _, entry := range result.Failed {
+		u.Err().Linef("failed\t%s\t%s", entry.ID, entry.LastError)
+	}
+}
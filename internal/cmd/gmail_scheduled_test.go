@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/api/gmail/v1"
+
+	"github.com/steipete/gogcli/internal/gmailschedule"
+)
+
+type gmailScheduledRecorder struct {
+	mu      sync.Mutex
+	drafts  []string
+	sent    []string
+	deleted []string
+}
+
+func newGmailScheduledTestService(t *testing.T, rec *gmailScheduledRecorder) *gmail.Service {
+	t.Helper()
+	svc, cleanup := newGmailServiceForTest(t, func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/gmail/v1")
+		rec.mu.Lock()
+		defer rec.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && path == "/users/me/drafts":
+			var draft gmail.Draft
+			_ = json.NewDecoder(r.Body).Decode(&draft)
+			raw, err := base64.RawURLEncoding.DecodeString(draft.Message.Raw)
+			if err != nil {
+				t.Fatalf("decode raw: %v", err)
+			}
+			rec.drafts = append(rec.drafts, string(raw))
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "d1", "message": map[string]any{"id": "m-draft", "threadId": "t1"}})
+		case r.Method == http.MethodPost && path == "/users/me/drafts/send":
+			var draft gmail.Draft
+			_ = json.NewDecoder(r.Body).Decode(&draft)
+			if draft.Id == "gone" {
+				http.Error(w, `{"error":{"code":404,"message":"Requested entity was not found."}}`, http.StatusNotFound)
+				return
+			}
+			rec.sent = append(rec.sent, draft.Id)
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "m-" + draft.Id, "threadId": "t1"})
+		case r.Method == http.MethodDelete && strings.HasPrefix(path, "/users/me/drafts/"):
+			rec.deleted = append(rec.deleted, strings.TrimPrefix(path, "/users/me/drafts/"))
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	t.Cleanup(cleanup)
+	return svc
+}
+
+func TestGmailSendAtSchedulesDraft(t *testing.T) {
+	setWatchTestConfigHome(t)
+	rec := &gmailScheduledRecorder{}
+	var stdout bytes.Buffer
+	ctx := withGmailTestService(newCmdRuntimeJSONOutputContext(t, &stdout, io.Discard), newGmailScheduledTestService(t, rec))
+	flags := &RootFlags{Account: "a@b.com"}
+
+	err := runKong(t, &GmailSendCmd{}, []string{"--to", "x@example.com", "--subject", "Later", "--body", "Hi", "--at", "2h"}, ctx, flags)
+	if err != nil {
+		t.Fatalf("send --at: %v", err)
+	}
+	var scheduled struct {
+		ID      string `json:"id"`
+		DraftID string `json:"draft_id"`
+		SendAt  string `json:"send_at"`
+	}
+	if decodeErr := json.Unmarshal(stdout.Bytes(), &scheduled); decodeErr != nil {
+		t.Fatalf("decode: %v\n%s", decodeErr, stdout.String())
+	}
+	sendAt, _ := time.Parse(time.RFC3339, scheduled.SendAt)
+	if scheduled.DraftID != "d1" || time.Until(sendAt) < 110*time.Minute {
+		t.Fatalf("scheduled = %#v", scheduled)
+	}
+	rec.mu.Lock()
+	if len(rec.drafts) != 1 || !strings.Contains(rec.drafts[0], "Subject: Later") || len(rec.sent) != 0 {
+		t.Fatalf("drafts=%d sent=%v", len(rec.drafts), rec.sent)
+	}
+	rec.mu.Unlock()
+
+	stdout.Reset()
+	if err = runKong(t, &GmailScheduledRunCmd{}, nil, ctx, flags); err != nil {
+		t.Fatalf("run before due: %v", err)
+	}
+	if !strings.Contains(stdout.String(), `"sent": []`) {
+		t.Fatalf("run before due output = %s", stdout.String())
+	}
+
+	stdout.Reset()
+	if err = runKong(t, &GmailScheduledListCmd{}, []string{"--state", "pending"}, ctx, flags); err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	var listed struct {
+		Scheduled []gmailScheduledItem `json:"scheduled"`
+	}
+	if decodeErr := json.Unmarshal(stdout.Bytes(), &listed); decodeErr != nil {
+		t.Fatalf("decode list: %v\n%s", decodeErr, stdout.String())
+	}
+	if len(listed.Scheduled) != 1 || listed.Scheduled[0].ID != scheduled.ID || listed.Scheduled[0].To[0] != "x@example.com" {
+		t.Fatalf("list = %#v", listed.Scheduled)
+	}
+
+	stdout.Reset()
+	if err = runKong(t, &GmailScheduledCancelCmd{}, []string{scheduled.ID}, ctx, &RootFlags{Account: "a@b.com", Force: true}); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if len(rec.deleted) != 1 || rec.deleted[0] != "d1" {
+		t.Fatalf("deleted = %v", rec.deleted)
+	}
+}
+
+func TestGmailScheduledRunSendsDueDrafts(t *testing.T) {
+	setWatchTestConfigHome(t)
+	rec := &gmailScheduledRecorder{}
+	var stdout bytes.Buffer
+	ctx := withGmailTestService(newCmdRuntimeJSONOutputContext(t, &stdout, io.Discard), newGmailScheduledTestService(t, rec))
+
+	repo, err := openGmailSchedule(ctx, "a@b.com")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	past := time.Now().Add(-time.Minute)
+	for _, draftID := range []string{"ok", "gone"} {
+		if _, addErr := repo.Add(gmailschedule.Entry{Account: "a@b.com", DraftID: draftID, SendAt: past}); addErr != nil {
+			t.Fatalf("add: %v", addErr)
+		}
+	}
+	if _, err = repo.Add(gmailschedule.Entry{Account: "a@b.com", DraftID: "future", SendAt: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+
+	err = runKong(t, &GmailScheduledRunCmd{}, nil, ctx, &RootFlags{Account: "a@b.com"})
+	if err == nil || !strings.Contains(err.Error(), "1 scheduled sends failed") {
+		t.Fatalf("expected failure summary, got %v", err)
+	}
+	var result struct {
+		Sent   []gmailScheduledItem `json:"sent"`
+		Failed []gmailScheduledItem `json:"failed"`
+	}
+	if decodeErr := json.Unmarshal(stdout.Bytes(), &result); decodeErr != nil {
+		t.Fatalf("decode: %v\n%s", decodeErr, stdout.String())
+	}
+	if len(result.Sent) != 1 || result.Sent[0].MessageID != "m-ok" || len(result.Failed) != 1 || !strings.Contains(result.Failed[0].LastError, "no longer exists") {
+		t.Fatalf("result = %#v", result)
+	}
+	rec.mu.Lock()
+	if len(rec.sent) != 1 || rec.sent[0] != "ok" {
+		t.Fatalf("sent = %v", rec.sent)
+	}
+	rec.mu.Unlock()
+
+	entries, err := repo.List()
+	if err != nil || len(entries) != 3 || entries[2].State != gmailschedule.StatePending {
+		t.Fatalf("entries = %#v err=%v", entries, err)
+	}
+}
+
+func TestParseGmailSendAt(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	got, err := parseGmailSendAt("+90m", now, time.UTC)
+	if err != nil || !got.Equal(now.Add(90*time.Minute)) {
+		t.Fatalf("duration = %v err=%v", got, err)
+	}
+	got, err = parseGmailSendAt("tomorrow 9am", now, time.UTC)
+	if err != nil || !got.Equal(time.Date(2026, 3, 3, 9, 0, 0, 0, time.UTC)) {
+		t.Fatalf("tomorrow 9am = %v err=%v", got, err)
+	}
+	for _, value := range []string{"yesterday", "-5m", "not a time"} {
+		if _, err := parseGmailSendAt(value, now, time.UTC); ExitCode(err) != 2 {
+			t.Fatalf("%q exit = %d: %v", value, ExitCode(err), err)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"google.golang.org/api/gmail/v1"
 
@@ -34,6 +35,7 @@ type GmailSendCmd struct {
 	Track            bool     `name:"track" help:"Enable open tracking (requires tracking setup)"`
 	TrackSplit       bool     `name:"track-split" help:"Send tracked messages separately per recipient"`
 	Quote            bool     `name:"quote" help:"Include quoted original message in reply (requires --reply-to-message-id or --thread-id)"`
+	At               string   `name:"at" help:"Schedule the send: save a draft and send it with 'gmail scheduled run' at this time (e.g. 'tomorrow 9am', '2026-03-02 14:30', '90m')"`
 }
 
 type sendBatch struct {
@@ -117,6 +119,24 @@ func (c *GmailSendCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return err
 	}
 
+	var sendAt time.Time
+	if strings.TrimSpace(c.At) != "" {
+		if c.TrackSplit {
+			return usage("--at cannot be combined with --track-split")
+		}
+		loc, locErr := mailDateLocation(ctx, stderrWriter(ctx))
+		if locErr != nil {
+			return locErr
+		}
+		if sendAt, err = parseGmailSendAt(c.At, time.Now(), loc); err != nil {
+			return err
+		}
+	}
+	sendAtValue := ""
+	if !sendAt.IsZero() {
+		sendAtValue = sendAt.Format(time.RFC3339)
+	}
+
 	if dryRunErr := dryRunExit(ctx, flags, "gmail.send", map[string]any{
 		"to":                  splitCSV(c.To),
 		"cc":                  splitCSV(c.Cc),
@@ -135,6 +155,7 @@ func (c *GmailSendCmd) Run(ctx context.Context, flags *RootFlags) error {
 		"signature_file":      strings.TrimSpace(c.SignatureFile),
 		"track":               c.Track,
 		"track_split":         c.TrackSplit,
+		"at":                  sendAtValue,
 	}); dryRunErr != nil {
 		return dryRunErr
 	}
@@ -204,7 +225,7 @@ func (c *GmailSendCmd) Run(ctx context.Context, flags *RootFlags) error {
 	}
 
 	batches := buildSendBatches(toRecipients, ccRecipients, bccRecipients, c.Track, c.TrackSplit)
-	results, err := sendGmailBatches(ctx, svc, sendMessageOptions{
+	opts := sendMessageOptions{
 		FromAddr:    from.header,
 		ReplyTo:     c.ReplyTo,
 		Subject:     subject,
@@ -214,7 +235,11 @@ func (c *GmailSendCmd) Run(ctx context.Context, flags *RootFlags) error {
 		Attachments: atts,
 		Track:       c.Track,
 		TrackingCfg: trackingCfg,
-	}, batches)
+	}
+	if !sendAt.IsZero() {
+		return scheduleGmailSend(ctx, svc, account, opts, batches[0], sendAt)
+	}
+	results, err := sendGmailBatches(ctx, svc, opts, batches)
 	if err != nil {
 		return err
 	}
@@ -330,15 +355,11 @@ func sendGmailBatches(ctx context.Context, svc *gmail.Service, opts sendMessageO
 			if recipient == "" {
 				recipient = strings.TrimSpace(firstRecipient(batch.To, batch.Cc, batch.Bcc))
 			}
-			pixelURL, blob, pixelErr := tracking.GeneratePixelURL(opts.TrackingCfg, recipient, opts.Subject)
+			var pixelErr error
+			htmlBody, trackingID, pixelErr = addTrackingPixel(opts.TrackingCfg, recipient, opts.Subject, htmlBody)
 			if pixelErr != nil {
-				return nil, fmt.Errorf("generate tracking pixel: %w", pixelErr)
+				return nil, pixelErr
 			}
-			trackingID = blob
-
-			// Inject pixel into HTML body (prefer before </body> / </html>)
-			pixelHTML := tracking.GeneratePixelHTML(pixelURL)
-			htmlBody = injectTrackingPixelHTML(htmlBody, pixelHTML)
 		}
 
 		messageOpts := opts
@@ -396,6 +417,18 @@ func firstRecipient(toRecipients, ccRecipients, bccRecipients []string) string {
 	return ""
 }
 
+// addTrackingPixel returns htmlBody with a per-recipient open-tracking pixel
+// and the tracking ID encoded in it.
+func addTrackingPixel(cfg *tracking.Config, recipient, subject, htmlBody string) (string, string, error) {
+	pixelURL, blob, err := tracking.GeneratePixelURL(cfg, recipient, subject)
+	if err != nil {
+		return "", "", fmt.Errorf("generate tracking pixel: %w", err)
+	}
+
+	// Inject pixel into HTML body (prefer before </body> / </html>)
+	return injectTrackingPixelHTML(htmlBody, tracking.GeneratePixelHTML(pixelURL)), blob, nil
+}
+
 func injectTrackingPixelHTML(htmlBody, pixelHTML string) string {
 	lower := strings.ToLower(htmlBody)
 	if i := strings.LastIndex(lower, "</body>"); i != -1 {
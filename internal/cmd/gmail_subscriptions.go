@@ -0,0 +1,397 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/api/gmail/v1"
+
+	"github.com/steipete/gogcli/internal/googleapi"
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+const (
+	gmailUnsubscribeStatusUnsubscribed = "unsubscribed"
+	gmailUnsubscribeStatusRequested    = "requested"
+	gmailUnsubscribeStatusManual       = "manual"
+	gmailUnsubscribeStatusFailed       = "failed"
+
+	gmailUnsubscribeLookupMax = 25
+)
+
+type GmailSubscriptionsCmd struct {
+	List        GmailSubscriptionsListCmd        `cmd:"" name:"list" aliases:"ls,scan" default:"withargs" help:"Group newsletter senders by volume and last read time"`
+	Unsubscribe GmailSubscriptionsUnsubscribeCmd `cmd:"" name:"unsubscribe" aliases:"unsub" help:"Unsubscribe from senders via one-click POST, mailto, or URL"`
+}
+
+type gmailSubscription struct {
+	Sender       string                  `json:"sender"`
+	Name         string                  `json:"name,omitempty"`
+	ListID       string                  `json:"list_id,omitempty"`
+	Messages     int                     `json:"messages"`
+	Unread       int                     `json:"unread"`
+	LastReceived string                  `json:"last_received,omitempty"`
+	LastRead     string                  `json:"last_read,omitempty"`
+	Method       string                  `json:"method"`
+	Unsubscribe  gmailUnsubscribeOptions `json:"unsubscribe"`
+
+	lastReceived time.Time
+	lastRead     time.Time
+}
+
+type GmailSubscriptionsListCmd struct {
+	Query string `name:"query" short:"q" help:"Gmail search query selecting the messages to scan" default:"newer_than:90d"`
+	Max   int64  `name:"max" aliases:"limit" help:"Max messages to scan" default:"500"`
+	Min   int    `name:"min" aliases:"min-messages" help:"Only show senders with at least this many messages" default:"1"`
+}
+
+func (c *GmailSubscriptionsListCmd) Run(ctx context.Context, flags *RootFlags) error {
+	if err := validateGmailMaxResults(c.Max); err != nil {
+		return err
+	}
+	_, svc, err := requireGmailService(ctx, flags)
+	if err != nil {
+		return err
+	}
+	ids, err := searchMessageIDs(ctx, svc, strings.TrimSpace(c.Query), c.Max)
+	if err != nil {
+		return err
+	}
+	messages, err := fetchGmailSubscriptionMetadata(ctx, svc, ids)
+	if err != nil {
+		return err
+	}
+
+	subs := groupGmailSubscriptions(messages)
+	filtered := subs[:0]
+	for _, sub := range subs {
+		if sub.Messages >= c.Min {
+			filtered = append(filtered, sub)
+		}
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, stdoutWriter(ctx), map[string]any{
+			"subscriptions": filtered,
+			"scanned":       len(messages),
+		})
+	}
+	if len(filtered) == 0 {
+		ui.FromContext(ctx).Err().Printf("No subscriptions found in %d messages", len(messages))
+		return nil
+	}
+	w, flush := tableWriter(ctx)
+	defer flush()
+	_, _ = fmt.Fprintln(w, "SENDER\tNAME\tMESSAGES\tUNREAD\tLAST_RECEIVED\tLAST_READ\tMETHOD")
+	for _, sub := range filtered {
+		lastRead := sub.LastRead
+		if lastRead == "" {
+			lastRead = "never"
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%s\t%s\t%s\n",
+			sub.Sender, sanitizeTab(sub.Name), sub.Messages, sub.Unread, sub.LastReceived, lastRead, sub.Method)
+	}
+	return nil
+}
+
+// fetchGmailSubscriptionMetadata loads the list headers for each message,
+// ten at a time, keeping the search order.
+func fetchGmailSubscriptionMetadata(ctx context.Context, svc *gmail.Service, ids []string) ([]*gmail.Message, error) {
+	const maxConcurrency = 10
+	sem := make(chan struct{}, maxConcurrency)
+	messages := make([]*gmail.Message, len(ids))
+	errs := make([]error, len(ids))
+
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		go func(idx int, messageID string) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				errs[idx] = ctx.Err()
+				return
+			}
+			msg, err := getGmailSubscriptionMetadata(ctx, svc, messageID)
+			if err != nil {
+				errs[idx] = fmt.Errorf("message %s: %w", messageID, err)
+				return
+			}
+			messages[idx] = msg
+		}(i, id)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return messages, nil
+}
+
+func getGmailSubscriptionMetadata(ctx context.Context, svc *gmail.Service, messageID string) (*gmail.Message, error) {
+	return svc.Users.Messages.Get("me", messageID).
+		Format("metadata").
+		MetadataHeaders(gmailSubscriptionMetadataHeaders...).
+		Fields("id,labelIds,internalDate,payload(headers)").
+		Context(ctx).
+		Do()
+}
+
+// groupGmailSubscriptions groups messages that carry List-Unsubscribe by
+// sender address, busiest senders first.
+func groupGmailSubscriptions(messages []*gmail.Message) []*gmailSubscription {
+	bySender := map[string]*gmailSubscription{}
+	for _, msg := range messages {
+		if msg == nil || msg.Payload == nil {
+			continue
+		}
+		header := headerValue(msg.Payload, "List-Unsubscribe")
+		if strings.TrimSpace(header) == "" {
+			continue
+		}
+		from := headerValue(msg.Payload, "From")
+		sender := canonicalEmail(from)
+		if sender == "" {
+			continue
+		}
+		sub := bySender[sender]
+		if sub == nil {
+			sub = &gmailSubscription{Sender: sender}
+			bySender[sender] = sub
+		}
+
+		received := time.UnixMilli(msg.InternalDate)
+		sub.Messages++
+		if slices.Contains(msg.LabelIds, "UNREAD") {
+			sub.Unread++
+		} else if received.After(sub.lastRead) {
+			sub.lastRead = received
+		}
+		if sub.Messages == 1 || received.After(sub.lastReceived) {
+			sub.lastReceived = received
+			sub.Unsubscribe = parseGmailListUnsubscribe(header, headerValue(msg.Payload, "List-Unsubscribe-Post"))
+			sub.ListID = strings.TrimSpace(headerValue(msg.Payload, "List-Id"))
+			if addr, err := mail.ParseAddress(from); err == nil {
+				sub.Name = addr.Name
+			}
+		}
+	}
+
+	subs := make([]*gmailSubscription, 0, len(bySender))
+	for _, sub := range bySender {
+		sub.Method = sub.Unsubscribe.method(true)
+		sub.LastReceived = formatOutboxTime(sub.lastReceived)
+		sub.LastRead = formatOutboxTime(sub.lastRead)
+		subs = append(subs, sub)
+	}
+	sort.Slice(subs, func(i, j int) bool {
+		if subs[i].Messages != subs[j].Messages {
+			return subs[i].Messages > subs[j].Messages
+		}
+		if !subs[i].lastReceived.Equal(subs[j].lastReceived) {
+			return subs[i].lastReceived.After(subs[j].lastReceived)
+		}
+		return subs[i].Sender < subs[j].Sender
+	})
+	return subs
+}
+
+type GmailSubscriptionsUnsubscribeCmd struct {
+	Senders []string `arg:"" name:"sender" help:"Sender email addresses (see gmail subscriptions list)"`
+	Method  string   `name:"method" help:"auto (one-click, then mailto, then URL), one-click, mailto, or url" enum:"auto,one-click,mailto,url" default:"auto"`
+	Filter  bool     `name:"filter" aliases:"archive" help:"Also create a filter that archives future mail from each sender"`
+}
+
+type gmailUnsubscribeResult struct {
+	Sender    string `json:"sender"`
+	Method    string `json:"method,omitempty"`
+	Status    string `json:"status"`
+	URL       string `json:"url,omitempty"`
+	To        string `json:"to,omitempty"`
+	MessageID string `json:"message_id,omitempty"`
+	FilterID  string `json:"filter_id,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+func (c *GmailSubscriptionsUnsubscribeCmd) Run(ctx context.Context, flags *RootFlags) error {
+	senders := make([]string, 0, len(c.Senders))
+	for _, raw := range c.Senders {
+		sender := canonicalEmail(raw)
+		if !strings.Contains(sender, "@") {
+			return usagef("invalid sender %q; pass an email address", raw)
+		}
+		senders = append(senders, sender)
+	}
+	// The one-click POST goes to the sender's server, not through the Google
+	// transport, so --readonly has to be checked here.
+	if googleapi.ReadOnly(ctx) && (flags == nil || !flags.DryRun) && c.Method != gmailUnsubscribeMethodURL {
+		return fmt.Errorf("%w: unsubscribe posts one-click requests or sends mail", googleapi.ErrReadOnly)
+	}
+	action := fmt.Sprintf("unsubscribe from %d senders", len(senders))
+	if c.Filter {
+		action += " and archive their future mail"
+	}
+	if err := dryRunAndConfirmDestructive(ctx, flags, "gmail.subscriptions.unsubscribe", map[string]any{
+		"senders": senders,
+		"method":  c.Method,
+		"filter":  c.Filter,
+	}, action); err != nil {
+		return err
+	}
+
+	account, svc, err := requireGmailService(ctx, flags)
+	if err != nil {
+		return err
+	}
+	sendBlocked := gmailSendBlocked(ctx, flags, account)
+
+	results := make([]gmailUnsubscribeResult, 0, len(senders))
+	failed := 0
+	for _, sender := range senders {
+		result := c.unsubscribe(ctx, svc, account, sender, sendBlocked)
+		if result.Status == gmailUnsubscribeStatusFailed {
+			failed++
+		}
+		results = append(results, result)
+	}
+
+	if outfmt.IsJSON(ctx) {
+		if err := outfmt.WriteJSON(ctx, stdoutWriter(ctx), map[string]any{"results": results}); err != nil {
+			return err
+		}
+	} else {
+		w, flush := tableWriter(ctx)
+		_, _ = fmt.Fprintln(w, "SENDER\tMETHOD\tSTATUS\tDETAIL\tFILTER")
+		for _, r := range results {
+			detail := r.URL
+			switch {
+			case r.Error != "":
+				detail = r.Error
+			case r.MessageID != "":
+				detail = "sent " + r.MessageID + " to " + r.To
+			}
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", r.Sender, r.Method, r.Status, sanitizeTab(detail), r.FilterID)
+		}
+		flush()
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d unsubscribes failed", failed, len(results))
+	}
+	return nil
+}
+
+func (c *GmailSubscriptionsUnsubscribeCmd) unsubscribe(ctx context.Context, svc *gmail.Service, account, sender string, sendBlocked error) gmailUnsubscribeResult {
+	result := gmailUnsubscribeResult{Sender: sender, Status: gmailUnsubscribeStatusFailed}
+	fail := func(err error) gmailUnsubscribeResult {
+		result.Error = err.Error()
+		return result
+	}
+
+	opts, err := lookupGmailUnsubscribe(ctx, svc, sender)
+	if err != nil {
+		return fail(err)
+	}
+	result.Method = c.Method
+	if result.Method == "auto" {
+		result.Method = opts.method(sendBlocked == nil)
+	}
+
+	switch result.Method {
+	case gmailUnsubscribeMethodOneClick:
+		if !opts.OneClick {
+			return fail(fmt.Errorf("%s does not support one-click unsubscribe", sender))
+		}
+		result.URL = opts.oneClickURL()
+		if err := postGmailOneClickUnsubscribe(ctx, result.URL); err != nil {
+			return fail(err)
+		}
+		result.Status = gmailUnsubscribeStatusUnsubscribed
+	case gmailUnsubscribeMethodMailto:
+		if len(opts.Mailtos) == 0 {
+			return fail(fmt.Errorf("%s has no mailto unsubscribe address", sender))
+		}
+		if sendBlocked != nil {
+			return fail(sendBlocked)
+		}
+		sent, to, err := sendGmailUnsubscribeMailto(ctx, svc, account, opts.Mailtos[0])
+		if err != nil {
+			return fail(err)
+		}
+		result.To = to
+		result.MessageID = sent
+		result.Status = gmailUnsubscribeStatusRequested
+	case gmailUnsubscribeMethodURL:
+		if len(opts.URLs) == 0 {
+			return fail(fmt.Errorf("%s has no unsubscribe URL", sender))
+		}
+		result.URL = opts.URLs[0]
+		result.Status = gmailUnsubscribeStatusManual
+	default:
+		return fail(fmt.Errorf("%s: %w", sender, errGmailUnsubscribeNoMethod))
+	}
+
+	if c.Filter {
+		filter, err := createGmailFilterWithRetry(ctx, svc, &gmail.Filter{
+			Criteria: &gmail.FilterCriteria{From: sender},
+			Action:   &gmail.FilterAction{RemoveLabelIds: []string{"INBOX"}},
+		})
+		if err != nil {
+			result.Status = gmailUnsubscribeStatusFailed
+			return fail(fmt.Errorf("%s %s, but creating the archive filter failed: %w", result.Method, sender, err))
+		}
+		result.FilterID = filter.Id
+	}
+	return result
+}
+
+// lookupGmailUnsubscribe reads the List-Unsubscribe headers from the newest
+// recent message from sender that has them.
+func lookupGmailUnsubscribe(ctx context.Context, svc *gmail.Service, sender string) (gmailUnsubscribeOptions, error) {
+	ids, err := searchMessageIDs(ctx, svc, "from:"+sender, gmailUnsubscribeLookupMax)
+	if err != nil {
+		return gmailUnsubscribeOptions{}, err
+	}
+	for _, id := range ids {
+		msg, err := getGmailSubscriptionMetadata(ctx, svc, id)
+		if err != nil {
+			return gmailUnsubscribeOptions{}, fmt.Errorf("message %s: %w", id, err)
+		}
+		header := headerValue(msg.Payload, "List-Unsubscribe")
+		if strings.TrimSpace(header) == "" {
+			continue
+		}
+		return parseGmailListUnsubscribe(header, headerValue(msg.Payload, "List-Unsubscribe-Post")), nil
+	}
+	return gmailUnsubscribeOptions{}, fmt.Errorf("%s: %w", sender, errGmailUnsubscribeNoMethod)
+}
+
+func sendGmailUnsubscribeMailto(ctx context.Context, svc *gmail.Service, account, target string) (string, string, error) {
+	mailto, err := parseGmailUnsubscribeMailto(target)
+	if err != nil {
+		return "", "", err
+	}
+	msg, err := buildGmailMessage(ctx, sendMessageOptions{
+		FromAddr: account,
+		Subject:  mailto.Subject,
+		Body:     mailto.Body,
+	}, sendBatch{To: mailto.To}, false)
+	if err != nil {
+		return "", "", err
+	}
+	sent, err := svc.Users.Messages.Send("me", msg).Context(ctx).Do()
+	if err != nil {
+		return "", "", err
+	}
+	return sent.Id, strings.Join(mailto.To, ", "), nil
+}
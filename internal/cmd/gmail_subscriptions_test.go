@@ -0,0 +1,223 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/api/gmail/v1"
+
+	"github.com/steipete/gogcli/internal/googleapi"
+)
+
+type gmailSubscriptionsFake struct {
+	mu       sync.Mutex
+	messages map[string]*gmail.Message
+	queries  []string
+	sent     []string
+	filters  []*gmail.Filter
+}
+
+func newGmailSubscriptionsFakeMessage(id, from, unsubscribe, post string, internalDate int64, labels ...string) *gmail.Message {
+	headers := []*gmail.MessagePartHeader{{Name: "From", Value: from}}
+	if unsubscribe != "" {
+		headers = append(headers, &gmail.MessagePartHeader{Name: "List-Unsubscribe", Value: unsubscribe})
+	}
+	if post != "" {
+		headers = append(headers, &gmail.MessagePartHeader{Name: "List-Unsubscribe-Post", Value: post})
+	}
+	return &gmail.Message{Id: id, LabelIds: labels, InternalDate: internalDate, Payload: &gmail.MessagePart{Headers: headers}}
+}
+
+func newGmailSubscriptionsTestService(t *testing.T, fake *gmailSubscriptionsFake, order []string) *gmail.Service {
+	t.Helper()
+	svc, cleanup := newGmailServiceForTest(t, func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/gmail/v1")
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && path == "/users/me/messages":
+			q := r.URL.Query().Get("q")
+			fake.queries = append(fake.queries, q)
+			var list []map[string]string
+			for _, id := range order {
+				msg := fake.messages[id]
+				if strings.HasPrefix(q, "from:") && !strings.Contains(strings.ToLower(headerValue(msg.Payload, "From")), strings.TrimPrefix(q, "from:")) {
+					continue
+				}
+				list = append(list, map[string]string{"id": id})
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"messages": list})
+		case r.Method == http.MethodGet && strings.HasPrefix(path, "/users/me/messages/"):
+			msg := fake.messages[strings.TrimPrefix(path, "/users/me/messages/")]
+			if msg == nil {
+				http.NotFound(w, r)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(msg)
+		case r.Method == http.MethodPost && path == "/users/me/messages/send":
+			var msg gmail.Message
+			_ = json.NewDecoder(r.Body).Decode(&msg)
+			raw, _ := base64.RawURLEncoding.DecodeString(msg.Raw)
+			fake.sent = append(fake.sent, string(raw))
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "sent1"})
+		case r.Method == http.MethodPost && path == "/users/me/settings/filters":
+			var filter gmail.Filter
+			_ = json.NewDecoder(r.Body).Decode(&filter)
+			filter.Id = "f1"
+			fake.filters = append(fake.filters, &filter)
+			_ = json.NewEncoder(w).Encode(filter)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	t.Cleanup(cleanup)
+	return svc
+}
+
+func TestParseGmailListUnsubscribe(t *testing.T) {
+	opts := parseGmailListUnsubscribe(
+		"<mailto:leave@news.example?subject=Remove%20me>, <https://news.example/u/1>",
+		"List-Unsubscribe=One-Click",
+	)
+	if !opts.OneClick || opts.oneClickURL() != "https://news.example/u/1" || len(opts.Mailtos) != 1 {
+		t.Fatalf("opts = %#v", opts)
+	}
+	if got := opts.method(false); got != gmailUnsubscribeMethodOneClick {
+		t.Fatalf("method = %q", got)
+	}
+
+	httpOnly := parseGmailListUnsubscribe("<http://news.example/u>, <mailto:leave@news.example>", "List-Unsubscribe=One-Click")
+	if httpOnly.OneClick {
+		t.Fatalf("one-click over plain http must be refused: %#v", httpOnly)
+	}
+	if httpOnly.method(true) != gmailUnsubscribeMethodMailto || httpOnly.method(false) != gmailUnsubscribeMethodURL {
+		t.Fatalf("methods = %q / %q", httpOnly.method(true), httpOnly.method(false))
+	}
+
+	mailto, err := parseGmailUnsubscribeMailto("mailto:leave@news.example?subject=Remove%20me&body=stop")
+	if err != nil || len(mailto.To) != 1 || mailto.To[0] != "leave@news.example" || mailto.Subject != "Remove me" || mailto.Body != "stop" {
+		t.Fatalf("mailto = %#v err=%v", mailto, err)
+	}
+	if _, err := parseGmailUnsubscribeMailto("mailto:?subject=x"); err == nil {
+		t.Fatalf("expected error for mailto without recipient")
+	}
+}
+
+func TestGmailSubscriptionsListGroupsSenders(t *testing.T) {
+	fake := &gmailSubscriptionsFake{messages: map[string]*gmail.Message{
+		"m1": newGmailSubscriptionsFakeMessage("m1", "News <news@example.com>", "<https://example.com/u>", "List-Unsubscribe=One-Click", 3000, "UNREAD"),
+		"m2": newGmailSubscriptionsFakeMessage("m2", "news@example.com", "<https://example.com/u>", "", 2000),
+		"m3": newGmailSubscriptionsFakeMessage("m3", "Deals <deals@shop.example>", "<mailto:stop@shop.example>", "", 2500, "UNREAD"),
+		"m4": newGmailSubscriptionsFakeMessage("m4", "friend@example.org", "", "", 4000),
+	}}
+	var stdout bytes.Buffer
+	ctx := withGmailTestService(newCmdRuntimeJSONOutputContext(t, &stdout, io.Discard), newGmailSubscriptionsTestService(t, fake, []string{"m4", "m1", "m3", "m2"}))
+
+	if err := runKong(t, &GmailSubscriptionsListCmd{}, nil, ctx, &RootFlags{Account: "a@b.com"}); err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	var got struct {
+		Subscriptions []gmailSubscription `json:"subscriptions"`
+		Scanned       int                 `json:"scanned"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatalf("decode: %v\n%s", err, stdout.String())
+	}
+	if got.Scanned != 4 || len(got.Subscriptions) != 2 {
+		t.Fatalf("got = %#v", got)
+	}
+	news := got.Subscriptions[0]
+	if news.Sender != "news@example.com" || news.Name != "News" || news.Messages != 2 || news.Unread != 1 ||
+		news.LastReceived != "1970-01-01T00:00:03Z" || news.LastRead != "1970-01-01T00:00:02Z" || news.Method != gmailUnsubscribeMethodOneClick {
+		t.Fatalf("news = %#v", news)
+	}
+	if deals := got.Subscriptions[1]; deals.Sender != "deals@shop.example" || deals.LastRead != "" || deals.Method != gmailUnsubscribeMethodMailto {
+		t.Fatalf("deals = %#v", deals)
+	}
+	if fake.queries[0] != "newer_than:90d" {
+		t.Fatalf("query = %q", fake.queries[0])
+	}
+}
+
+func TestGmailSubscriptionsUnsubscribe(t *testing.T) {
+	setWatchTestConfigHome(t)
+	var posts []string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		posts = append(posts, r.Method+" "+r.URL.Path+" "+string(body))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	origClient := gmailUnsubscribeClient
+	gmailUnsubscribeClient = srv.Client()
+	t.Cleanup(func() { gmailUnsubscribeClient = origClient })
+
+	fake := &gmailSubscriptionsFake{messages: map[string]*gmail.Message{
+		"m1": newGmailSubscriptionsFakeMessage("m1", "news@example.com", "<"+srv.URL+"/u/1>, <mailto:x@example.com>", "List-Unsubscribe=One-Click", 1),
+		"m2": newGmailSubscriptionsFakeMessage("m2", "deals@shop.example", "<mailto:stop@shop.example?subject=stop>, <https://shop.example/u>", "", 1),
+		"m3": newGmailSubscriptionsFakeMessage("m3", "quiet@example.net", "", "", 1),
+	}}
+	var stdout bytes.Buffer
+	ctx := withGmailTestService(newCmdRuntimeJSONOutputContext(t, &stdout, io.Discard), newGmailSubscriptionsTestService(t, fake, []string{"m1", "m2", "m3"}))
+
+	err := runKong(t, &GmailSubscriptionsUnsubscribeCmd{}, []string{"news@example.com", "Deals <deals@shop.example>", "--filter"}, ctx, &RootFlags{Account: "a@b.com", Force: true})
+	if err != nil {
+		t.Fatalf("unsubscribe: %v", err)
+	}
+	var got struct {
+		Results []gmailUnsubscribeResult `json:"results"`
+	}
+	if decodeErr := json.Unmarshal(stdout.Bytes(), &got); decodeErr != nil {
+		t.Fatalf("decode: %v\n%s", decodeErr, stdout.String())
+	}
+	if len(got.Results) != 2 || got.Results[0].Status != gmailUnsubscribeStatusUnsubscribed || got.Results[0].FilterID != "f1" ||
+		got.Results[1].Method != gmailUnsubscribeMethodMailto || got.Results[1].Status != gmailUnsubscribeStatusRequested {
+		t.Fatalf("results = %#v", got.Results)
+	}
+	if len(posts) != 1 || posts[0] != "POST /u/1 List-Unsubscribe=One-Click" {
+		t.Fatalf("posts = %v", posts)
+	}
+	fake.mu.Lock()
+	if len(fake.sent) != 1 || !strings.Contains(fake.sent[0], "To: stop@shop.example") || !strings.Contains(fake.sent[0], "Subject: stop") {
+		t.Fatalf("sent = %v", fake.sent)
+	}
+	if len(fake.filters) != 2 || fake.filters[1].Criteria.From != "deals@shop.example" || fake.filters[1].Action.RemoveLabelIds[0] != "INBOX" {
+		t.Fatalf("filters = %#v", fake.filters)
+	}
+	fake.mu.Unlock()
+
+	stdout.Reset()
+	err = runKong(t, &GmailSubscriptionsUnsubscribeCmd{}, []string{"deals@shop.example", "quiet@example.net"}, ctx, &RootFlags{Account: "a@b.com", Force: true, GmailNoSend: true})
+	if err == nil || !strings.Contains(err.Error(), "1 of 2 unsubscribes failed") {
+		t.Fatalf("expected one failure, got %v", err)
+	}
+	if decodeErr := json.Unmarshal(stdout.Bytes(), &got); decodeErr != nil {
+		t.Fatalf("decode: %v\n%s", decodeErr, stdout.String())
+	}
+	if got.Results[0].Status != gmailUnsubscribeStatusManual || got.Results[0].URL != "https://shop.example/u" || !strings.Contains(got.Results[1].Error, "no List-Unsubscribe") {
+		t.Fatalf("no-send results = %#v", got.Results)
+	}
+
+	// --readonly must stop the one-click POST, which bypasses the Google transport.
+	readOnlyCtx := googleapi.WithReadOnly(ctx, true)
+	err = runKong(t, &GmailSubscriptionsUnsubscribeCmd{}, []string{"news@example.com"}, readOnlyCtx, &RootFlags{Account: "a@b.com", Force: true})
+	if !errors.Is(err, googleapi.ErrReadOnly) {
+		t.Fatalf("expected read-only error, got %v", err)
+	}
+	err = runKong(t, &GmailSubscriptionsUnsubscribeCmd{}, []string{"news@example.com"}, readOnlyCtx, &RootFlags{Account: "a@b.com", DryRun: true})
+	if ExitCode(err) != 0 {
+		t.Fatalf("read-only dry run: %v", err)
+	}
+	if len(posts) != 1 {
+		t.Fatalf("read-only posts = %v", posts)
+	}
+}
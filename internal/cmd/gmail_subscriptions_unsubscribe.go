@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	gmailUnsubscribeMethodOneClick = "one-click"
+	gmailUnsubscribeMethodMailto   = "mailto"
+	gmailUnsubscribeMethodURL      = "url"
+	gmailUnsubscribeMethodNone     = "none"
+)
+
+// gmailUnsubscribeClient posts RFC 8058 one-click requests. Redirects are not
+// followed: a redirected POST turns into a GET, which is not an unsubscribe.
+var gmailUnsubscribeClient = &http.Client{
+	Timeout: 30 * time.Second,
+	CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+var errGmailUnsubscribeNoMethod = errors.New("sender has no List-Unsubscribe header")
+
+// gmailUnsubscribeOptions is what a List-Unsubscribe header (RFC 2369) offers,
+// plus whether List-Unsubscribe-Post (RFC 8058) allows a one-click POST.
+type gmailUnsubscribeOptions struct {
+	URLs     []string `json:"urls,omitempty"`
+	Mailtos  []string `json:"mailtos,omitempty"`
+	OneClick bool     `json:"one_click,omitempty"`
+}
+
+func parseGmailListUnsubscribe(header, post string) gmailUnsubscribeOptions {
+	var opts gmailUnsubscribeOptions
+	for _, part := range strings.Split(header, ",") {
+		part = strings.TrimSpace(part)
+		start := strings.Index(part, "<")
+		end := strings.LastIndex(part, ">")
+		if start < 0 || end <= start {
+			continue
+		}
+		target := strings.TrimSpace(part[start+1 : end])
+		lower := strings.ToLower(target)
+		switch {
+		case strings.HasPrefix(lower, "mailto:"):
+			opts.Mailtos = append(opts.Mailtos, target)
+		case strings.HasPrefix(lower, "https://"), strings.HasPrefix(lower, "http://"):
+			opts.URLs = append(opts.URLs, target)
+		}
+	}
+	opts.OneClick = strings.EqualFold(strings.Join(strings.Fields(post), ""), "List-Unsubscribe=One-Click") &&
+		opts.oneClickURL() != ""
+	return opts
+}
+
+// oneClickURL returns the first HTTPS URL; RFC 8058 forbids one-click over
+// plain HTTP.
+func (o gmailUnsubscribeOptions) oneClickURL() string {
+	for _, u := range o.URLs {
+		if strings.HasPrefix(strings.ToLower(u), "https://") {
+			return u
+		}
+	}
+	return ""
+}
+
+// method picks the best available method: one-click, then mailto (when
+// sending is allowed), then a URL to open by hand.
+func (o gmailUnsubscribeOptions) method(canSend bool) string {
+	switch {
+	case o.OneClick:
+		return gmailUnsubscribeMethodOneClick
+	case len(o.Mailtos) > 0 && canSend:
+		return gmailUnsubscribeMethodMailto
+	case len(o.URLs) > 0:
+		return gmailUnsubscribeMethodURL
+	case len(o.Mailtos) > 0:
+		return gmailUnsubscribeMethodMailto
+	default:
+		return gmailUnsubscribeMethodNone
+	}
+}
+
+func postGmailOneClickUnsubscribe(ctx context.Context, target string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, strings.NewReader("List-Unsubscribe=One-Click"))
+	if err != nil {
+		return fmt.Errorf("one-click unsubscribe: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := gmailUnsubscribeClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("one-click unsubscribe: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("one-click unsubscribe: %s returned %s", target, resp.Status)
+	}
+	return nil
+}
+
+type gmailUnsubscribeMailto struct {
+	To      []string
+	Subject string
+	Body    string
+}
+
+// parseGmailUnsubscribeMailto splits a mailto: URI (RFC 6068) into the
+// recipients, subject, and body of the unsubscribe message.
+func parseGmailUnsubscribeMailto(raw string) (gmailUnsubscribeMailto, error) {
+	parsed, err := url.Parse(raw)
+	if err != nil || !strings.EqualFold(parsed.Scheme, "mailto") {
+		return gmailUnsubscribeMailto{}, fmt.Errorf("invalid mailto unsubscribe %q", raw)
+	}
+	to, err := url.PathUnescape(parsed.Opaque)
+	if err != nil {
+		return gmailUnsubscribeMailto{}, fmt.Errorf("invalid mailto unsubscribe %q: %w", raw, err)
+	}
+	query := parsed.Query()
+	msg := gmailUnsubscribeMailto{Subject: "unsubscribe", Body: "unsubscribe"}
+	for _, value := range append([]string{to}, query["to"]...) {
+		for _, addr := range strings.Split(value, ",") {
+			if addr = strings.TrimSpace(addr); addr == "" {
+				continue
+			}
+			if _, addrErr := mail.ParseAddress(addr); addrErr != nil {
+				return gmailUnsubscribeMailto{}, fmt.Errorf("invalid mailto unsubscribe address %q", addr)
+			}
+			msg.To = append(msg.To, addr)
+		}
+	}
+	if len(msg.To) == 0 {
+		return gmailUnsubscribeMailto{}, fmt.Errorf("mailto unsubscribe %q has no recipient", raw)
+	}
+	for key, values := range query {
+		if len(values) == 0 || strings.TrimSpace(values[0]) == "" {
+			continue
+		}
+		switch strings.ToLower(key) {
+		case "subject":
+			msg.Subject = strings.Join(strings.Fields(values[0]), " ")
+		case "body":
+			msg.Body = values[0]
+		}
+	}
+	return msg, nil
+}
@@ -25,12 +25,15 @@ var (
 )
 
 type GmailWatchCmd struct {
-	Start  GmailWatchStartCmd  `cmd:"" name:"start" aliases:"begin" help:"Start Gmail watch for Pub/Sub"`
-	Status GmailWatchStatusCmd `cmd:"" name:"status" aliases:"ls" help:"Show stored watch state"`
-	Renew  GmailWatchRenewCmd  `cmd:"" name:"renew" aliases:"update" help:"Renew Gmail watch using stored config"`
-	Stop   GmailWatchStopCmd   `cmd:"" name:"stop" aliases:"rm,delete" help:"Stop Gmail watch and clear stored state"`
-	Serve  GmailWatchServeCmd  `cmd:"" name:"serve" help:"Run Pub/Sub push handler"`
-	Pull   GmailWatchPullCmd   `cmd:"" name:"pull" help:"Run Pub/Sub pull consumer"`
+	Start     GmailWatchStartCmd     `cmd:"" name:"start" aliases:"begin" help:"Start Gmail watch for Pub/Sub"`
+	Status    GmailWatchStatusCmd    `cmd:"" name:"status" aliases:"ls" help:"Show stored watch state"`
+	Renew     GmailWatchRenewCmd     `cmd:"" name:"renew" aliases:"update" help:"Renew Gmail watch using stored config"`
+	Stop      GmailWatchStopCmd      `cmd:"" name:"stop" aliases:"rm,delete" help:"Stop Gmail watch and clear stored state"`
+	Serve     GmailWatchServeCmd     `cmd:"" name:"serve" help:"Run Pub/Sub push handler"`
+	Pull      GmailWatchPullCmd      `cmd:"" name:"pull" help:"Run Pub/Sub pull consumer"`
+	Supervise GmailWatchSuperviseCmd `cmd:"" name:"supervise" aliases:"daemon" help:"Serve or pull notifications for many accounts, renew their watches, and report health"`
+	Outbox    GmailWatchOutboxCmd    `cmd:"" name:"outbox" help:"Inspect, replay, or drop queued hook deliveries"`
+	Secret    GmailWatchSecretCmd    `cmd:"" name:"secret" help:"Manage HMAC secrets for signing hook deliveries"`
 }
 
 type GmailWatchStartCmd struct {
@@ -41,6 +44,7 @@ type GmailWatchStartCmd struct {
 	HookToken   string   `name:"hook-token" help:"Webhook bearer token"`
 	IncludeBody bool     `name:"include-body" help:"Include text/plain body in hook payload"`
 	MaxBytes    int      `name:"max-bytes" help:"Max bytes of body to include" default:"20000"`
+	SignHook    bool     `name:"sign-hook" help:"Sign hook requests with the account's HMAC secret (see gmail watch secret)"`
 }
 
 func (c *GmailWatchStartCmd) Run(ctx context.Context, kctx *kong.Context, flags *RootFlags) error {
@@ -60,6 +64,12 @@ func (c *GmailWatchStartCmd) Run(ctx context.Context, kctx *kong.Context, flags
 			return err
 		}
 	}
+	if c.SignHook {
+		if hook == nil {
+			return usage("--sign-hook requires --hook-url")
+		}
+		hook.Sign = true
+	}
 
 	if dryRunErr := dryRunExit(ctx, flags, "gmail.watch.start", map[string]any{
 		"topic":   strings.TrimSpace(c.Topic),
@@ -235,6 +245,10 @@ type GmailWatchServeCmd struct {
 	HistoryTypes  []string `name:"history-types" help:"History types to include (repeatable, comma-separated: messageAdded,messageDeleted,labelAdded,labelRemoved). Default: messageAdded"`
 	ExcludeLabels string   `name:"exclude-labels" help:"List of Gmail label IDs to exclude from hook payload (e.g. SPAM,TRASH,Label_123). Set to empty string to disable." default:"SPAM,TRASH"`
 	SaveHook      bool     `name:"save-hook" help:"Persist hook settings to watch state"`
+	SignHook      bool     `name:"sign-hook" help:"Sign hook requests with the account's HMAC secret (see gmail watch secret)"`
+	Rules         string   `name:"rules" help:"YAML rules file evaluated against each new message (labels, archive, mark read, forward, reply, command)"`
+	Outbox        bool     `name:"outbox" help:"Queue failed hook deliveries on disk and retry them with backoff instead of asking Pub/Sub to redeliver"`
+	OutboxMax     int      `name:"outbox-max-attempts" help:"Delivery attempts before a queued payload moves to the dead-letter queue" default:"8"`
 }
 
 func (c *GmailWatchServeCmd) Run(ctx context.Context, kctx *kong.Context, flags *RootFlags) error {
@@ -275,6 +289,10 @@ func (c *GmailWatchServeCmd) Run(ctx context.Context, kctx *kong.Context, flags
 	if fetchDelay < 0 {
 		return usage("--fetch-delay must be >= 0")
 	}
+	rules, err := loadGmailWatchRules(c.Rules)
+	if err != nil {
+		return err
+	}
 
 	if flags != nil && flags.DryRun {
 		dryRunState, stateFound, stateErr := readGmailWatchStateOptional(ctx, account)
@@ -286,6 +304,7 @@ func (c *GmailWatchServeCmd) Run(ctx context.Context, kctx *kong.Context, flags
 			Token:       c.HookToken,
 			IncludeBody: c.IncludeBody,
 			MaxBytes:    c.MaxBytes,
+			Sign:        c.SignHook,
 		}, true)
 		if hookErr != nil {
 			if !errors.Is(hookErr, errNoHookConfigured) {
@@ -302,9 +321,11 @@ func (c *GmailWatchServeCmd) Run(ctx context.Context, kctx *kong.Context, flags
 		maxBodyBytes := defaultHookMaxBytes
 		includeBody := c.IncludeBody
 		hookTokenSet := c.HookToken != ""
+		signHook := c.SignHook
 		if dryRunHook != nil {
 			includeBody = dryRunHook.IncludeBody
 			hookTokenSet = dryRunHook.Token != ""
+			signHook = dryRunHook.Sign
 			if dryRunHook.MaxBytes > 0 {
 				maxBodyBytes = dryRunHook.MaxBytes
 			}
@@ -326,13 +347,22 @@ func (c *GmailWatchServeCmd) Run(ctx context.Context, kctx *kong.Context, flags
 				"include_body": includeBody,
 				"max_bytes":    maxBodyBytes,
 				"save":         c.SaveHook,
+				"sign":         signHook,
 			},
 			"fetch_delay_seconds": fetchDelay.Seconds(),
 			"timezone":            loc.String(),
 			"history_types":       historyTypes,
 			"exclude_labels":      splitCommaList(c.ExcludeLabels),
+			"rules":               gmailWatchRulesSummary(c.Rules, rules),
+			"outbox":              gmailWatchOutboxSummary(c.Outbox, c.OutboxMax),
 		})
 	}
+	if err := checkGmailWatchRulesCanSend(ctx, flags, account, rules); err != nil {
+		return err
+	}
+	if c.Outbox && c.OutboxMax <= 0 {
+		return usage("--outbox-max-attempts must be > 0")
+	}
 
 	store, err := loadGmailWatchStore(ctx, account)
 	if err != nil {
@@ -345,6 +375,7 @@ func (c *GmailWatchServeCmd) Run(ctx context.Context, kctx *kong.Context, flags
 		Token:       c.HookToken,
 		IncludeBody: c.IncludeBody,
 		MaxBytes:    c.MaxBytes,
+		Sign:        c.SignHook,
 	}, true)
 	if err != nil {
 		if !errors.Is(err, errNoHookConfigured) {
@@ -386,12 +417,17 @@ func (c *GmailWatchServeCmd) Run(ctx context.Context, kctx *kong.Context, flags
 		DateLocation:  loc,
 		ExcludeLabels: splitCommaList(c.ExcludeLabels),
 		VerboseOutput: flags.Verbose,
+		Rules:         rules,
 	}
 	if hook != nil {
 		cfg.HookURL = hook.URL
 		cfg.HookToken = hook.Token
 		cfg.IncludeBody = hook.IncludeBody
 		cfg.MaxBodyBytes = hook.MaxBytes
+		cfg.HookSigner, err = loadGmailWatchHookSigner(ctx, account, hook)
+		if err != nil {
+			return err
+		}
 	}
 
 	if cfg.MaxBodyBytes <= 0 {
@@ -422,8 +458,19 @@ func (c *GmailWatchServeCmd) Run(ctx context.Context, kctx *kong.Context, flags
 		warnf:           u.Err().Linef,
 	}
 
+	if c.Outbox && cfg.HookURL != "" {
+		server.outbox, err = openGmailWatchOutbox(ctx, account, c.OutboxMax)
+		if err != nil {
+			return err
+		}
+		go server.runOutbox(ctx)
+	}
+
 	addr := net.JoinHostPort(c.Bind, strconv.Itoa(c.Port))
 	u.Err().Linef("watch: listening on %s%s", addr, c.Path)
+	if rules != nil {
+		u.Err().Linef("watch: %d rules loaded from %s", len(rules.Rules), c.Rules)
+	}
 
 	httpServer := &http.Server{
 		Addr:              addr,
@@ -471,6 +518,9 @@ func writeWatchState(ctx context.Context, state gmailWatchState, showSecrets boo
 		if state.Hook.MaxBytes > 0 {
 			u.Out().Linef("hook_max_bytes\t%d", state.Hook.MaxBytes)
 		}
+		if state.Hook.Sign {
+			u.Out().Linef("hook_sign\ttrue")
+		}
 		if state.Hook.Token != "" {
 			switch {
 			case showSecrets:
@@ -563,6 +613,7 @@ type watchHookFlagValues struct {
 	Token       string
 	IncludeBody bool
 	MaxBytes    int
+	Sign        bool
 }
 
 func resolveWatchHookFromFlags(kctx *kong.Context, state gmailWatchState, values watchHookFlagValues, allowNoHook bool) (*gmailWatchHook, error) {
@@ -570,6 +621,7 @@ func resolveWatchHookFromFlags(kctx *kong.Context, state gmailWatchState, values
 	hookToken := values.Token
 	includeBody := values.IncludeBody
 	maxBytes := values.MaxBytes
+	sign := values.Sign
 
 	if hookURL == "" && state.Hook != nil {
 		hookURL = state.Hook.URL
@@ -582,12 +634,19 @@ func resolveWatchHookFromFlags(kctx *kong.Context, state gmailWatchState, values
 		if !flagProvided(kctx, "max-bytes") && state.Hook.MaxBytes > 0 {
 			maxBytes = state.Hook.MaxBytes
 		}
+		if !flagProvided(kctx, "sign-hook") {
+			sign = state.Hook.Sign
+		}
 	}
 
 	hook, err := hookFromFlags(hookURL, hookToken, includeBody, maxBytes, flagProvided(kctx, "max-bytes"), allowNoHook)
 	if err == nil {
+		hook.Sign = sign
 		return hook, nil
 	}
+	if sign && errors.Is(err, errNoHookConfigured) {
+		return nil, usage("--sign-hook requires --hook-url or a stored hook")
+	}
 	return nil, err
 }
 
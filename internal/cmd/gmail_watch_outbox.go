@@ -0,0 +1,332 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/steipete/gogcli/internal/config"
+	"github.com/steipete/gogcli/internal/filelock"
+	"github.com/steipete/gogcli/internal/gmailwatch"
+	"github.com/steipete/gogcli/internal/outbox"
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+const (
+	gmailWatchOutboxKind     = "gmail_watch"
+	gmailWatchOutboxInterval = 5 * time.Second
+)
+
+func gmailWatchOutboxDir(layout config.Layout, account string) string {
+	return filepath.Join(layout.GmailWatchDir(), "outbox", sanitizeAccountForPath(account))
+}
+
+func openGmailWatchOutbox(ctx context.Context, account string, maxAttempts int) (*outbox.Outbox, error) {
+	layout, err := commandLayout(ctx, config.PathKindConfig, config.PathKindState)
+	if err != nil {
+		return nil, err
+	}
+
+	return outbox.New(gmailWatchOutboxDir(layout, account), outbox.Options{MaxAttempts: maxAttempts}), nil
+}
+
+// deliverHookWithOutbox sends a payload, or queues it on disk when the hook
+// fails or older payloads are still waiting. A queued payload counts as
+// delivered for the watch cursor; the outbox worker retries it.
+func (s *gmailWatchServer) deliverHookWithOutbox(ctx context.Context, payload *gmailHookPayload) gmailwatch.DeliveryResult {
+	waiting, err := s.outbox.HasPending()
+	if err != nil {
+		s.warn("watch: outbox unavailable: %v", err)
+		return s.deliverHook(ctx, payload)
+	}
+
+	var cause error
+	if !waiting {
+		result := s.deliverHook(ctx, payload)
+		if result.Err == nil {
+			return result
+		}
+		cause = result.Err
+	}
+
+	entry, err := s.outbox.Enqueue(gmailWatchOutboxKind, payload, cause)
+	if err != nil {
+		s.warn("watch: outbox enqueue failed: %v", err)
+		if cause == nil {
+			return s.deliverHook(ctx, payload)
+		}
+		return gmailwatch.DeliveryResult{Status: gmailwatch.DeliveryStatusError, Note: cause.Error(), Err: cause, Record: true}
+	}
+
+	note := "queued " + entry.ID
+	if cause != nil {
+		note += ": " + cause.Error()
+		s.warn("watch: hook failed, queued %s for retry: %v", entry.ID, cause)
+	}
+	return gmailwatch.DeliveryResult{Status: gmailwatch.DeliveryStatusQueued, Note: note, Record: true}
+}
+
+func (s *gmailWatchServer) runOutbox(ctx context.Context) {
+	ticker := time.NewTicker(gmailWatchOutboxInterval)
+	defer ticker.Stop()
+
+	for {
+		s.flushOutbox(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *gmailWatchServer) flushOutbox(ctx context.Context) {
+	result, err := s.outbox.Flush(ctx, s.deliverOutboxEntry)
+	if err != nil {
+		if !errors.Is(err, filelock.ErrTimeout) {
+			s.warn("watch: outbox flush failed: %v", err)
+		}
+		return
+	}
+	if result.Delivered > 0 {
+		s.log("watch: outbox delivered %d queued payloads", result.Delivered)
+	}
+	if result.DeadLettered > 0 {
+		s.warn("watch: outbox moved %d payloads to the dead-letter queue; see gog gmail watch outbox list", result.DeadLettered)
+	}
+}
+
+func (s *gmailWatchServer) deliverOutboxEntry(ctx context.Context, entry outbox.Entry) error {
+	var payload gmailHookPayload
+	if err := json.Unmarshal(entry.Payload, &payload); err != nil {
+		return fmt.Errorf("decode queued payload: %w", err)
+	}
+	result := s.deliverHook(ctx, &payload)
+	if result.Record && s.store != nil {
+		_ = s.store.RecordDelivery(result.Status, result.Note, s.currentTime())
+	}
+	return result.Err
+}
+
+func gmailWatchOutboxSummary(enabled bool, maxAttempts int) map[string]any {
+	return map[string]any{"enabled": enabled, "max_attempts": maxAttempts}
+}
+
+type GmailWatchOutboxCmd struct {
+	List   GmailWatchOutboxListCmd   `cmd:"" name:"list" aliases:"ls" help:"List queued and dead-lettered hook deliveries"`
+	Replay GmailWatchOutboxReplayCmd `cmd:"" name:"replay" aliases:"retry" help:"Requeue dead-lettered deliveries and send them to the stored hook"`
+	Drop   GmailWatchOutboxDropCmd   `cmd:"" name:"drop" aliases:"rm,delete" help:"Delete queued or dead-lettered deliveries"`
+}
+
+type gmailWatchOutboxItem struct {
+	ID            string `json:"id"`
+	State         string `json:"state"`
+	CreatedAt     string `json:"created_at"`
+	Attempts      int    `json:"attempts"`
+	NextAttemptAt string `json:"next_attempt_at,omitempty"`
+	DeadAt        string `json:"dead_at,omitempty"`
+	LastError     string `json:"last_error,omitempty"`
+	HistoryID     string `json:"history_id,omitempty"`
+	Messages      int    `json:"messages"`
+}
+
+func gmailWatchOutboxItemFromEntry(entry outbox.Entry) gmailWatchOutboxItem {
+	item := gmailWatchOutboxItem{
+		ID:        entry.ID,
+		State:     entry.State,
+		CreatedAt: formatOutboxTime(entry.CreatedAt),
+		Attempts:  entry.Attempts,
+		LastError: entry.LastError,
+		DeadAt:    formatOutboxTime(entry.DeadAt),
+	}
+	if entry.State == outbox.StatePending {
+		item.NextAttemptAt = formatOutboxTime(entry.NextAttemptAt)
+	}
+	var payload gmailHookPayload
+	if json.Unmarshal(entry.Payload, &payload) == nil {
+		item.HistoryID = payload.HistoryID
+		item.Messages = len(payload.Messages)
+	}
+	return item
+}
+
+func formatOutboxTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+type GmailWatchOutboxListCmd struct {
+	State string `name:"state" help:"Entries to list: all, pending, or dead" enum:"all,pending,dead" default:"all"`
+}
+
+func (c *GmailWatchOutboxListCmd) Run(ctx context.Context, flags *RootFlags) error {
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	box, err := openGmailWatchOutbox(ctx, account, 0)
+	if err != nil {
+		return err
+	}
+
+	var entries []outbox.Entry
+	if c.State != outbox.StateDead {
+		pending, pendingErr := box.Pending()
+		if pendingErr != nil {
+			return pendingErr
+		}
+		entries = append(entries, pending...)
+	}
+	if c.State != outbox.StatePending {
+		dead, deadErr := box.Dead()
+		if deadErr != nil {
+			return deadErr
+		}
+		entries = append(entries, dead...)
+	}
+
+	items := make([]gmailWatchOutboxItem, 0, len(entries))
+	for _, entry := range entries {
+		items = append(items, gmailWatchOutboxItemFromEntry(entry))
+	}
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, stdoutWriter(ctx), map[string]any{"entries": items})
+	}
+	if len(items) == 0 {
+		ui.FromContext(ctx).Err().Println("No outbox entries")
+		return nil
+	}
+	w, flush := tableWriter(ctx)
+	defer flush()
+	_, _ = fmt.Fprintln(w, "ID\tSTATE\tATTEMPTS\tHISTORY\tMESSAGES\tNEXT/DEAD\tLAST_ERROR")
+	for _, item := range items {
+		when := item.NextAttemptAt
+		if item.State == outbox.StateDead {
+			when = item.DeadAt
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%d\t%s\t%s\n",
+			item.ID, item.State, item.Attempts, item.HistoryID, item.Messages, when, sanitizeTab(item.LastError))
+	}
+	return nil
+}
+
+type GmailWatchOutboxReplayCmd struct {
+	IDs []string `arg:"" name:"id" optional:"" help:"Dead-lettered entry IDs"`
+	All bool     `name:"all" help:"Replay every dead-lettered entry"`
+}
+
+func (c *GmailWatchOutboxReplayCmd) Run(ctx context.Context, flags *RootFlags) error {
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	if len(c.IDs) == 0 && !c.All {
+		return usage("provide entry IDs or --all")
+	}
+	if len(c.IDs) > 0 && c.All {
+		return usage("provide entry IDs or --all, not both")
+	}
+	if dryRunErr := dryRunExit(ctx, flags, "gmail.watch.outbox.replay", map[string]any{
+		"account": account,
+		"ids":     c.IDs,
+		"all":     c.All,
+	}); dryRunErr != nil {
+		return dryRunErr
+	}
+
+	box, err := openGmailWatchOutbox(ctx, account, 0)
+	if err != nil {
+		return err
+	}
+	replayed, err := box.Replay(c.IDs...)
+	if err != nil {
+		return mapOutboxError(err)
+	}
+
+	delivered := 0
+	state, found, err := readGmailWatchStateOptional(ctx, account)
+	if err != nil {
+		return err
+	}
+	hookSet := found && state.Hook != nil && strings.TrimSpace(state.Hook.URL) != ""
+	if hookSet && len(replayed) > 0 {
+		signer, signerErr := loadGmailWatchHookSigner(ctx, account, state.Hook)
+		if signerErr != nil {
+			return signerErr
+		}
+		server := &gmailWatchServer{
+			cfg:        gmailWatchServeConfig{Account: account, HookURL: state.Hook.URL, HookToken: state.Hook.Token, HookSigner: signer},
+			hookClient: &http.Client{Timeout: defaultHookRequestTimeoutSec * time.Second},
+			outbox:     box,
+		}
+		result, flushErr := box.Flush(ctx, server.deliverOutboxEntry)
+		if flushErr != nil {
+			return flushErr
+		}
+		delivered = result.Delivered
+	}
+
+	u := ui.FromContext(ctx)
+	if !hookSet && len(replayed) > 0 {
+		u.Err().Println("No stored hook; entries wait for gmail watch serve/pull --outbox")
+	}
+	return writeResult(ctx, u,
+		kv("replayed", len(replayed)),
+		kv("delivered", delivered),
+		kv("pending", len(replayed)-delivered),
+	)
+}
+
+type GmailWatchOutboxDropCmd struct {
+	IDs     []string `arg:"" name:"id" optional:"" help:"Entry IDs (pending or dead)"`
+	AllDead bool     `name:"all-dead" help:"Drop every dead-lettered entry"`
+}
+
+func (c *GmailWatchOutboxDropCmd) Run(ctx context.Context, flags *RootFlags) error {
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	if len(c.IDs) == 0 && !c.AllDead {
+		return usage("provide entry IDs or --all-dead")
+	}
+	if len(c.IDs) > 0 && c.AllDead {
+		return usage("provide entry IDs or --all-dead, not both")
+	}
+	action := fmt.Sprintf("drop %d outbox entries", len(c.IDs))
+	if c.AllDead {
+		action = "drop all dead-lettered outbox entries"
+	}
+	if confirmErr := dryRunAndConfirmDestructive(ctx, flags, "gmail.watch.outbox.drop", map[string]any{
+		"account":  account,
+		"ids":      c.IDs,
+		"all_dead": c.AllDead,
+	}, action); confirmErr != nil {
+		return confirmErr
+	}
+
+	box, err := openGmailWatchOutbox(ctx, account, 0)
+	if err != nil {
+		return err
+	}
+	dropped, err := box.Drop(c.IDs...)
+	if err != nil {
+		return mapOutboxError(err)
+	}
+	return writeResult(ctx, ui.FromContext(ctx), kv("dropped", len(dropped)))
+}
+
+func mapOutboxError(err error) error {
+	if errors.Is(err, outbox.ErrNotFound) {
+		return usage(err.Error())
+	}
+	return err
+}
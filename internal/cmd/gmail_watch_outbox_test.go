@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/steipete/gogcli/internal/gmailwatch"
+	"github.com/steipete/gogcli/internal/outbox"
+)
+
+func TestGmailWatchServer_OutboxQueuesFailedHook(t *testing.T) {
+	setWatchTestConfigHome(t)
+
+	var failing atomic.Bool
+	failing.Store(true)
+	var hookCalls atomic.Int32
+	hookSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hookCalls.Add(1)
+		if failing.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer hookSrv.Close()
+
+	s := newWatchRulesTestServer(t, newWatchRulesGmailServer(t, &watchRulesGmail{}), hookSrv.URL, "")
+	now := time.Unix(1000, 0)
+	layout := gmailWatchTestLayout(t)
+	s.outbox = outbox.New(gmailWatchOutboxDir(layout, "a@b.com"), outbox.Options{Now: func() time.Time { return now }})
+
+	_ = serveWatchRulesPush(t, s)
+
+	state := s.store.Get()
+	if state.HistoryID != "200" {
+		t.Fatalf("history not advanced: %#v", state)
+	}
+	if state.LastDeliveryStatus != gmailwatch.DeliveryStatusQueued {
+		t.Fatalf("delivery status = %q", state.LastDeliveryStatus)
+	}
+	pending, err := s.outbox.Pending()
+	if err != nil || len(pending) != 1 || pending[0].Attempts != 1 {
+		t.Fatalf("pending = %#v err=%v", pending, err)
+	}
+
+	// Not due yet: the worker leaves the entry alone.
+	s.flushOutbox(context.Background())
+	if hookCalls.Load() != 1 {
+		t.Fatalf("hook calls = %d, want 1", hookCalls.Load())
+	}
+
+	failing.Store(false)
+	now = now.Add(time.Hour)
+	s.flushOutbox(context.Background())
+	if hookCalls.Load() != 2 {
+		t.Fatalf("hook calls = %d, want 2", hookCalls.Load())
+	}
+	if has, _ := s.outbox.HasPending(); has {
+		t.Fatal("outbox still has pending entries")
+	}
+	if got := s.store.Get().LastDeliveryStatus; got != gmailwatch.DeliveryStatusOK {
+		t.Fatalf("delivery status after flush = %q", got)
+	}
+}
+
+func TestGmailWatchOutboxCmds(t *testing.T) {
+	setWatchTestConfigHome(t)
+
+	box := outbox.New(gmailWatchOutboxDir(gmailWatchTestLayout(t), "a@b.com"), outbox.Options{MaxAttempts: 1})
+	payload := &gmailHookPayload{Account: "a@b.com", HistoryID: "200", Messages: []gmailwatch.Message{{ID: "m1"}}}
+	if _, err := box.Enqueue(gmailWatchOutboxKind, payload, nil); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	if _, err := box.Flush(context.Background(), func(context.Context, outbox.Entry) error { return errors.New("down") }); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	flags := &RootFlags{Account: "a@b.com", NoInput: true, Force: true}
+
+	var stdout bytes.Buffer
+	ctx := newCmdRuntimeJSONOutputContext(t, &stdout, io.Discard)
+	if err := runKong(t, &GmailWatchOutboxListCmd{}, []string{"--state", "dead"}, ctx, flags); err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	var listed struct {
+		Entries []gmailWatchOutboxItem `json:"entries"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &listed); err != nil {
+		t.Fatalf("decode list: %v\n%s", err, stdout.String())
+	}
+	if len(listed.Entries) != 1 || listed.Entries[0].HistoryID != "200" || listed.Entries[0].Messages != 1 ||
+		listed.Entries[0].LastError != "down" || listed.Entries[0].DeadAt == "" {
+		t.Fatalf("entries = %#v", listed.Entries)
+	}
+	id := listed.Entries[0].ID
+
+	if err := runKong(t, &GmailWatchOutboxReplayCmd{}, nil, ctx, flags); err == nil || !strings.Contains(err.Error(), "--all") {
+		t.Fatalf("expected usage error, got %v", err)
+	}
+	if err := runKong(t, &GmailWatchOutboxReplayCmd{}, []string{"missing"}, ctx, flags); ExitCode(err) != 2 {
+		t.Fatalf("replay missing exit = %d: %v", ExitCode(err), err)
+	}
+
+	// Without a stored hook, replayed entries wait in the pending queue.
+	stdout.Reset()
+	if err := runKong(t, &GmailWatchOutboxReplayCmd{}, []string{id}, ctx, flags); err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	var replayed map[string]int
+	if err := json.Unmarshal(stdout.Bytes(), &replayed); err != nil {
+		t.Fatalf("decode replay: %v\n%s", err, stdout.String())
+	}
+	if replayed["replayed"] != 1 || replayed["delivered"] != 0 || replayed["pending"] != 1 {
+		t.Fatalf("replay = %#v", replayed)
+	}
+
+	stdout.Reset()
+	if err := runKong(t, &GmailWatchOutboxDropCmd{}, []string{id}, ctx, flags); err != nil {
+		t.Fatalf("drop: %v", err)
+	}
+	if pending, _ := box.Pending(); len(pending) != 0 {
+		t.Fatalf("pending after drop = %#v", pending)
+	}
+}
@@ -34,6 +34,12 @@ func (s *gmailWatchServer) watchProcessor() *gmailwatch.Processor {
 	}
 	if s.cfg.HookURL != "" {
 		processor.Deliver = s.deliverHook
+		if s.outbox != nil {
+			processor.Deliver = s.deliverHookWithOutbox
+		}
+	}
+	if s.cfg.Rules != nil {
+		processor.Deliver = s.deliverWithRules(processor.Deliver)
 	}
 
 	return processor
@@ -31,6 +31,9 @@ type GmailWatchPullCmd struct {
 	HistoryTypes  []string `name:"history-types" help:"History types to include (repeatable, comma-separated: messageAdded,messageDeleted,labelAdded,labelRemoved). Default: messageAdded"`
 	ExcludeLabels string   `name:"exclude-labels" help:"List of Gmail label IDs to exclude from hook payload (e.g. SPAM,TRASH,Label_123). Set to empty string to disable." default:"SPAM,TRASH"`
 	SaveHook      bool     `name:"save-hook" help:"Persist hook settings to watch state"`
+	SignHook      bool     `name:"sign-hook" help:"Sign hook requests with the account's HMAC secret (see gmail watch secret)"`
+	Outbox        bool     `name:"outbox" help:"Queue failed hook deliveries on disk and retry them with backoff instead of nacking the Pub/Sub message"`
+	OutboxMax     int      `name:"outbox-max-attempts" help:"Delivery attempts before a queued payload moves to the dead-letter queue" default:"8"`
 }
 
 func (c *GmailWatchPullCmd) Run(ctx context.Context, kctx *kong.Context, flags *RootFlags) error {
@@ -66,6 +69,9 @@ func (c *GmailWatchPullCmd) Run(ctx context.Context, kctx *kong.Context, flags *
 	if fetchDelay < 0 {
 		return usage("--fetch-delay must be >= 0")
 	}
+	if c.Outbox && c.OutboxMax <= 0 {
+		return usage("--outbox-max-attempts must be > 0")
+	}
 	if dryRunErr := dryRunExit(ctx, flags, "gmail.watch.pull", map[string]any{
 		"account":             account,
 		"subscription":        subscription,
@@ -77,6 +83,8 @@ func (c *GmailWatchPullCmd) Run(ctx context.Context, kctx *kong.Context, flags *
 		"hook_url_set":        strings.TrimSpace(c.HookURL) != "",
 		"hook_token_set":      c.HookToken != "",
 		"save_hook":           c.SaveHook,
+		"sign_hook":           c.SignHook,
+		"outbox":              gmailWatchOutboxSummary(c.Outbox, c.OutboxMax),
 	}); dryRunErr != nil {
 		return dryRunErr
 	}
@@ -91,6 +99,7 @@ func (c *GmailWatchPullCmd) Run(ctx context.Context, kctx *kong.Context, flags *
 		Token:       c.HookToken,
 		IncludeBody: c.IncludeBody,
 		MaxBytes:    c.MaxBytes,
+		Sign:        c.SignHook,
 	}, false)
 	if err != nil {
 		if errors.Is(err, errNoHookConfigured) {
@@ -122,6 +131,10 @@ func (c *GmailWatchPullCmd) Run(ctx context.Context, kctx *kong.Context, flags *
 	if cfg.MaxBodyBytes <= 0 {
 		cfg.MaxBodyBytes = defaultHookMaxBytes
 	}
+	cfg.HookSigner, err = loadGmailWatchHookSigner(ctx, account, hook)
+	if err != nil {
+		return err
+	}
 
 	selectedClient := strings.TrimSpace(flags.Client)
 	gmailFactory, err := gmailServiceFactory(ctx)
@@ -156,6 +169,13 @@ func (c *GmailWatchPullCmd) Run(ctx context.Context, kctx *kong.Context, flags *
 		logf:            u.Err().Linef,
 		warnf:           u.Err().Linef,
 	}
+	if c.Outbox {
+		processor.outbox, err = openGmailWatchOutbox(ctx, account, c.OutboxMax)
+		if err != nil {
+			return err
+		}
+		go processor.runOutbox(ctx)
+	}
 	u.Err().Linef("watch: pulling from %s", subscription)
 
 	err = receiver.Receive(ctx, processor.handlePullMessage)
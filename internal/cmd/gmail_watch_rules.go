@@ -0,0 +1,252 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"google.golang.org/api/gmail/v1"
+
+	"github.com/steipete/gogcli/internal/config"
+	"github.com/steipete/gogcli/internal/gmailcontent"
+	"github.com/steipete/gogcli/internal/gmailwatch"
+)
+
+var errWatchRuleSkipped = errors.New("skipped")
+
+func loadGmailWatchRules(path string) (*gmailwatch.Rules, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return nil, nil
+	}
+	expanded, err := config.ExpandPath(path)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := gmailwatch.LoadRules(expanded)
+	if err != nil {
+		return nil, usage(err.Error())
+	}
+	return rules, nil
+}
+
+func gmailWatchRulesSummary(path string, rules *gmailwatch.Rules) map[string]any {
+	if rules == nil {
+		return map[string]any{"file": ""}
+	}
+	names := make([]string, 0, len(rules.Rules))
+	for _, rule := range rules.Rules {
+		names = append(names, rule.Name)
+	}
+	return map[string]any{
+		"file":  strings.TrimSpace(path),
+		"names": names,
+		"sends": rules.Sends(),
+	}
+}
+
+// checkGmailWatchRulesCanSend applies the send guards that gmail send, reply,
+// and forward enforce, since rules send mail outside those command paths.
+func checkGmailWatchRulesCanSend(ctx context.Context, flags *RootFlags, account string, rules *gmailwatch.Rules) error {
+	if rules == nil || !rules.Sends() {
+		return nil
+	}
+	if flags != nil && flags.GmailNoSend {
+		return usage("--rules forwards or replies, but Gmail sending is blocked by --gmail-no-send")
+	}
+	cfg, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if cfg.GmailNoSend {
+		return usage("--rules forwards or replies, but Gmail sending is blocked by config gmail_no_send")
+	}
+	return checkAccountNoSend(ctx, account)
+}
+
+// deliverWithRules runs the rules after next succeeds. A failed hook leaves
+// the history position for a retry, so rules wait for that retry rather than
+// acting twice.
+func (s *gmailWatchServer) deliverWithRules(next func(context.Context, *gmailHookPayload) gmailwatch.DeliveryResult) func(context.Context, *gmailHookPayload) gmailwatch.DeliveryResult {
+	return func(ctx context.Context, payload *gmailHookPayload) gmailwatch.DeliveryResult {
+		var result gmailwatch.DeliveryResult
+		if next != nil {
+			result = next(ctx, payload)
+			if result.Err != nil {
+				return result
+			}
+		}
+		s.applyRules(ctx, payload)
+		return result
+	}
+}
+
+func (s *gmailWatchServer) applyRules(ctx context.Context, payload *gmailHookPayload) {
+	if s.cfg.Rules == nil || payload == nil || len(payload.Messages) == 0 {
+		return
+	}
+	svc, err := s.newService(ctx, s.cfg.Account)
+	if err != nil {
+		s.warn("watch: rules skipped: %v", err)
+		return
+	}
+	handler := &gmailWatchRuleHandler{svc: svc, account: s.cfg.Account}
+	for _, result := range s.cfg.Rules.Apply(ctx, payload, handler) {
+		switch {
+		case result.Err == nil:
+			s.log("watch: rule %q %s message %s", result.Rule, result.Action, result.MessageID)
+		case errors.Is(result.Err, errWatchRuleSkipped):
+			s.log("watch: rule %q %s message %s: %v", result.Rule, result.Action, result.MessageID, result.Err)
+		default:
+			s.warn("watch: rule %q %s message %s failed: %v", result.Rule, result.Action, result.MessageID, result.Err)
+		}
+	}
+}
+
+func (s *gmailWatchServer) log(format string, args ...any) {
+	if s.logf != nil {
+		s.logf(format, args...)
+	}
+}
+
+func (s *gmailWatchServer) warn(format string, args ...any) {
+	if s.warnf != nil {
+		s.warnf(format, args...)
+	}
+}
+
+type gmailWatchRuleHandler struct {
+	svc     *gmail.Service
+	account string
+
+	sender    *composeFromResult
+	senderErr error
+}
+
+func (h *gmailWatchRuleHandler) LabelNames(context.Context) (map[string]string, error) {
+	return fetchLabelIDToName(h.svc)
+}
+
+func (h *gmailWatchRuleHandler) ModifyLabels(ctx context.Context, msg gmailwatch.Message, add, remove []string) error {
+	addIDs, removeIDs, err := resolveModifyLabelIDs(h.svc, add, remove)
+	if err != nil {
+		return err
+	}
+	_, err = h.svc.Users.Messages.Modify("me", msg.ID, &gmail.ModifyMessageRequest{
+		AddLabelIds:    addIDs,
+		RemoveLabelIds: removeIDs,
+	}).Context(ctx).Do()
+	return err
+}
+
+func (h *gmailWatchRuleHandler) from(ctx context.Context) (composeFromResult, error) {
+	if h.sender == nil && h.senderErr == nil {
+		from, err := resolveComposeSender(ctx, h.svc, h.account, "")
+		h.sender, h.senderErr = &from, err
+	}
+	if h.senderErr != nil {
+		return composeFromResult{}, h.senderErr
+	}
+	return *h.sender, nil
+}
+
+// skipRuleSend keeps rules from answering mail the account sent itself or
+// that another automated system generated, which would risk mail loops.
+func skipRuleSend(msg *gmail.Message) error {
+	if hasMessageLabel(msg, "SENT") {
+		return fmt.Errorf("%w: sent by this account", errWatchRuleSkipped)
+	}
+	autoSubmitted := strings.ToLower(strings.TrimSpace(headerValue(msg.Payload, "Auto-Submitted")))
+	if autoSubmitted != "" && autoSubmitted != "no" {
+		return fmt.Errorf("%w: auto-submitted", errWatchRuleSkipped)
+	}
+	return nil
+}
+
+func (h *gmailWatchRuleHandler) Forward(ctx context.Context, msg gmailwatch.Message, to []string) error {
+	orig, err := h.svc.Users.Messages.Get("me", msg.ID).Format(gmailFormatFull).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("fetch message: %w", err)
+	}
+	if err := skipRuleSend(orig); err != nil {
+		return err
+	}
+	from, err := h.from(ctx)
+	if err != nil {
+		return err
+	}
+
+	origFrom := headerValue(orig.Payload, "From")
+	origTo := headerValue(orig.Payload, "To")
+	origCc := headerValue(orig.Payload, "Cc")
+	origDate := headerValue(orig.Payload, "Date")
+	origSubject := headerValue(orig.Payload, "Subject")
+	origHTML := gmailcontent.FindPartBody(orig.Payload, "text/html")
+	plain := formatForwardedMessage("", origFrom, origDate, origSubject, origTo, origCc, gmailcontent.FindPartBody(orig.Payload, "text/plain"))
+	var html string
+	if origHTML != "" {
+		html = formatForwardedMessageHTML("", origFrom, origDate, origSubject, origTo, origCc, origHTML)
+	}
+	attachments, err := preserveForwardMessageParts(ctx, h.svc, msg.ID, orig.Payload, origHTML, true)
+	if err != nil {
+		return fmt.Errorf("preserve forwarded message parts: %w", err)
+	}
+
+	raw, err := buildGmailMessage(ctx, sendMessageOptions{
+		FromAddr:    from.header,
+		Subject:     buildForwardSubject(origSubject),
+		Body:        plain,
+		BodyHTML:    html,
+		Attachments: attachments,
+		Headers:     map[string]string{"Auto-Submitted": "auto-generated"},
+	}, sendBatch{To: to}, false)
+	if err != nil {
+		return fmt.Errorf("build message: %w", err)
+	}
+	_, err = h.svc.Users.Messages.Send("me", raw).Context(ctx).Do()
+	return err
+}
+
+func (h *gmailWatchRuleHandler) Reply(ctx context.Context, msg gmailwatch.Message, body string) error {
+	orig, err := fetchMessageForAutoReply(ctx, h.svc, msg.ID)
+	if err != nil {
+		return fmt.Errorf("fetch message: %w", err)
+	}
+	if err := skipRuleSend(orig); err != nil {
+		return err
+	}
+	if skip, reason := shouldSkipAutoReplyMessage(orig); skip {
+		return fmt.Errorf("%w: %s", errWatchRuleSkipped, reason)
+	}
+	from, err := h.from(ctx)
+	if err != nil {
+		return err
+	}
+
+	self := []string{h.account}
+	if from.sendingEmail != "" && !strings.EqualFold(from.sendingEmail, h.account) {
+		self = append(self, from.sendingEmail)
+	}
+	replyMeta := replyInfoFromMessage(orig, false)
+	recipients := autoReplyRecipients(replyMeta, self)
+	if len(recipients) == 0 {
+		return fmt.Errorf("%w: no reply recipient", errWatchRuleSkipped)
+	}
+
+	_, err = sendGmailBatches(ctx, h.svc, sendMessageOptions{
+		FromAddr:  from.header,
+		Subject:   autoReplySubject("", headerValue(orig.Payload, "Subject")),
+		Body:      body,
+		ReplyInfo: replyMeta,
+		Headers: map[string]string{
+			"Auto-Submitted":           "auto-replied",
+			"X-Auto-Response-Suppress": "All",
+		},
+	}, []sendBatch{{To: recipients}})
+	return err
+}
+
+func (h *gmailWatchRuleHandler) RunCommand(ctx context.Context, rule string, msg gmailwatch.Message, argv []string) error {
+	return gmailwatch.RunRuleCommand(ctx, h.account, rule, msg, argv, gmailwatch.DefaultRuleCommandTimeout)
+}
@@ -0,0 +1,245 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/api/gmail/v1"
+	"google.golang.org/api/option"
+)
+
+func writeWatchRulesFile(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatalf("write rules: %v", err)
+	}
+	return path
+}
+
+type watchRulesGmail struct {
+	mu              sync.Mutex
+	metadataHeaders []string
+	modify          []gmail.ModifyMessageRequest
+}
+
+func newWatchRulesGmailServer(t *testing.T, recorder *watchRulesGmail) *gmail.Service {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.Contains(r.URL.Path, "/users/me/history"):
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"historyId": "200",
+				"history": []map[string]any{
+					{"messagesAdded": []map[string]any{{"message": map[string]any{"id": "m1"}}}},
+				},
+			})
+		case strings.Contains(r.URL.Path, "/users/me/labels"):
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"labels": []map[string]any{
+					{"id": "INBOX", "name": "INBOX", "type": "system"},
+					{"id": "Label_9", "name": "Lists", "type": "user"},
+				},
+			})
+		case strings.HasSuffix(r.URL.Path, "/users/me/messages/m1/modify"):
+			var req gmail.ModifyMessageRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			recorder.mu.Lock()
+			recorder.modify = append(recorder.modify, req)
+			recorder.mu.Unlock()
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "m1"})
+		case strings.HasSuffix(r.URL.Path, "/users/me/messages/m1"):
+			recorder.mu.Lock()
+			recorder.metadataHeaders = r.URL.Query()["metadataHeaders"]
+			recorder.mu.Unlock()
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"id":       "m1",
+				"threadId": "t1",
+				"labelIds": []string{"INBOX", "UNREAD"},
+				"payload": map[string]any{
+					"headers": []map[string]any{
+						{"name": "From", "value": "digest@lists.example.com"},
+						{"name": "Subject", "value": "Weekly digest"},
+						{"name": "List-Id", "value": "<weekly.lists.example.com>"},
+					},
+				},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	svc, err := gmail.NewService(context.Background(),
+		option.WithoutAuthentication(),
+		option.WithHTTPClient(srv.Client()),
+		option.WithEndpoint(srv.URL+"/"),
+	)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	return svc
+}
+
+func newWatchRulesTestServer(t *testing.T, svc *gmail.Service, hookURL string, rulesPath string) *gmailWatchServer {
+	t.Helper()
+	store := newGmailWatchTestStore(t, "a@b.com")
+	if err := store.Update(func(s *gmailWatchState) error {
+		s.Account = "a@b.com"
+		s.HistoryID = "100"
+		return nil
+	}); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	rules, err := loadGmailWatchRules(rulesPath)
+	if err != nil {
+		t.Fatalf("load rules: %v", err)
+	}
+	return &gmailWatchServer{
+		cfg: gmailWatchServeConfig{
+			Account:     "a@b.com",
+			Path:        "/gmail-pubsub",
+			SharedToken: "tok",
+			HookURL:     hookURL,
+			AllowNoHook: hookURL == "",
+			HistoryMax:  100,
+			ResyncMax:   10,
+			Rules:       rules,
+		},
+		store:      store,
+		newService: func(context.Context, string) (*gmail.Service, error) { return svc, nil },
+		hookClient: http.DefaultClient,
+		logf:       func(string, ...any) {},
+		warnf:      func(string, ...any) {},
+	}
+}
+
+func serveWatchRulesPush(t *testing.T, s *gmailWatchServer) *httptest.ResponseRecorder {
+	t.Helper()
+	push := pubsubPushEnvelope{}
+	push.Message.Data = base64.StdEncoding.EncodeToString([]byte(`{"emailAddress":"a@b.com","historyId":"200"}`))
+	body, _ := json.Marshal(push)
+	req := httptest.NewRequestWithContext(context.Background(), http.MethodPost, "/gmail-pubsub?token=tok", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+	s.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestGmailWatchServer_RulesModifyLabelsWithoutHook(t *testing.T) {
+	setWatchTestConfigHome(t)
+
+	rulesPath := writeWatchRulesFile(t, `
+rules:
+  - name: digests
+    match:
+      labels: [inbox]
+      headers:
+        List-Id: "lists\\.example\\.com"
+    actions:
+      add_labels: [Lists]
+      archive: true
+      mark_read: true
+  - name: other
+    match: {subject: invoice}
+    actions: {archive: true}
+`)
+	recorder := &watchRulesGmail{}
+	s := newWatchRulesTestServer(t, newWatchRulesGmailServer(t, recorder), "", rulesPath)
+
+	rr := serveWatchRulesPush(t, s)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status: %d body=%q", rr.Code, rr.Body.String())
+	}
+	if bytes.Contains(rr.Body.Bytes(), []byte("List-Id")) {
+		t.Fatalf("rule-only headers leaked into payload: %s", rr.Body.String())
+	}
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	if !hasHeaderName(recorder.metadataHeaders, "List-Id") {
+		t.Fatalf("metadata headers = %v, want List-Id", recorder.metadataHeaders)
+	}
+	if len(recorder.modify) != 1 {
+		t.Fatalf("modify calls = %#v", recorder.modify)
+	}
+	got := recorder.modify[0]
+	if strings.Join(got.AddLabelIds, ",") != "Label_9" || strings.Join(got.RemoveLabelIds, ",") != "INBOX,UNREAD" {
+		t.Fatalf("modify = %#v", got)
+	}
+}
+
+func TestGmailWatchServer_RulesWaitForHookSuccess(t *testing.T) {
+	setWatchTestConfigHome(t)
+
+	hookSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer hookSrv.Close()
+
+	rulesPath := writeWatchRulesFile(t, "rules:\n  - match: {from: digest}\n    actions: {archive: true}\n")
+	recorder := &watchRulesGmail{}
+	s := newWatchRulesTestServer(t, newWatchRulesGmailServer(t, recorder), hookSrv.URL, rulesPath)
+
+	_ = serveWatchRulesPush(t, s)
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	if len(recorder.modify) != 0 {
+		t.Fatalf("rules ran after failed hook: %#v", recorder.modify)
+	}
+}
+
+func TestGmailWatchServeCmd_DryRunReportsRules(t *testing.T) {
+	setWatchTestConfigHome(t)
+	rulesPath := writeWatchRulesFile(t, "rules:\n  - name: fwd\n    match: {from: boss}\n    actions: {forward: [me@example.com]}\n")
+
+	var stdout bytes.Buffer
+	ctx := newCmdRuntimeJSONOutputContext(t, &stdout, io.Discard)
+	err := runKong(t, &GmailWatchServeCmd{}, []string{"--rules", rulesPath}, ctx, &RootFlags{Account: "a@b.com", DryRun: true, NoInput: true})
+	if ExitCode(err) != 0 {
+		t.Fatalf("exit code = %d: %v", ExitCode(err), err)
+	}
+	var got struct {
+		Request struct {
+			Rules struct {
+				File  string   `json:"file"`
+				Names []string `json:"names"`
+				Sends bool     `json:"sends"`
+			} `json:"rules"`
+		} `json:"request"`
+	}
+	if decodeErr := json.Unmarshal(stdout.Bytes(), &got); decodeErr != nil {
+		t.Fatalf("decode: %v\n%s", decodeErr, stdout.String())
+	}
+	if got.Request.Rules.File != rulesPath || strings.Join(got.Request.Rules.Names, ",") != "fwd" || !got.Request.Rules.Sends {
+		t.Fatalf("rules = %#v", got.Request.Rules)
+	}
+
+	bad := writeWatchRulesFile(t, "rules:\n  - match: {from: boss}\n")
+	err = runKong(t, &GmailWatchServeCmd{}, []string{"--rules", bad}, ctx, &RootFlags{Account: "a@b.com", DryRun: true, NoInput: true})
+	if err == nil || !strings.Contains(err.Error(), "no actions") {
+		t.Fatalf("expected invalid rules error, got %v", err)
+	}
+}
+
+func TestGmailWatchServeCmd_RulesRespectNoSend(t *testing.T) {
+	setWatchTestConfigHome(t)
+	rulesPath := writeWatchRulesFile(t, "rules:\n  - match: {from: boss}\n    actions: {reply: thanks}\n")
+
+	ctx := newCmdRuntimeJSONOutputContext(t, io.Discard, io.Discard)
+	err := runKong(t, &GmailWatchServeCmd{}, []string{"--rules", rulesPath}, ctx, &RootFlags{Account: "a@b.com", GmailNoSend: true, NoInput: true})
+	if err == nil || !strings.Contains(err.Error(), "--gmail-no-send") {
+		t.Fatalf("expected no-send error, got %v", err)
+	}
+}
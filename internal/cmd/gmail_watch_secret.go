@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/steipete/gogcli/internal/app"
+	"github.com/steipete/gogcli/internal/gmailwatch"
+	"github.com/steipete/gogcli/internal/outfmt"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+var errWatchSecretStoreRequired = errors.New("hook secret store is required")
+
+func newGmailWatchSecretStore(ctx context.Context) (*gmailwatch.HookSecretStore, error) {
+	runtime, ok := app.FromContext(ctx)
+	if !ok || runtime.Auth.OpenSecretStore == nil {
+		return nil, errWatchSecretStoreRequired
+	}
+	store, err := runtime.Auth.OpenSecretStore()
+	if err != nil {
+		return nil, fmt.Errorf("open hook secret store: %w", err)
+	}
+	return gmailwatch.NewHookSecretStore(store)
+}
+
+// loadGmailWatchHookSigner returns the signer for a hook that asks for
+// signatures, or nil when the hook is unsigned.
+func loadGmailWatchHookSigner(ctx context.Context, account string, hook *gmailWatchHook) (*gmailwatch.HookSigner, error) {
+	if hook == nil || !hook.Sign {
+		return nil, nil
+	}
+	store, err := newGmailWatchSecretStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := store.Load(account)
+	if err != nil {
+		return nil, fmt.Errorf("load hook secrets: %w", err)
+	}
+	signer, err := keys.Signer()
+	if errors.Is(err, gmailwatch.ErrNoHookSecret) {
+		return nil, &ExitError{Code: exitCodeConfig, Err: fmt.Errorf("no hook signing secret for %s; run 'gog gmail watch secret rotate' first", account)}
+	}
+	return signer, err
+}
+
+type GmailWatchSecretCmd struct {
+	Rotate GmailWatchSecretRotateCmd `cmd:"" name:"rotate" aliases:"new" help:"Generate a new signing secret version and make it current"`
+	List   GmailWatchSecretListCmd   `cmd:"" name:"list" aliases:"ls" help:"List stored signing secret versions"`
+	Show   GmailWatchSecretShowCmd   `cmd:"" name:"show" aliases:"get" help:"Print a signing secret"`
+	Delete GmailWatchSecretDeleteCmd `cmd:"" name:"delete" aliases:"rm" help:"Delete a retired signing secret version"`
+}
+
+type GmailWatchSecretRotateCmd struct{}
+
+func (c *GmailWatchSecretRotateCmd) Run(ctx context.Context, flags *RootFlags) error {
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	if dryRunErr := dryRunExit(ctx, flags, "gmail.watch.secret.rotate", map[string]any{
+		"account": account,
+	}); dryRunErr != nil {
+		return dryRunErr
+	}
+
+	store, err := newGmailWatchSecretStore(ctx)
+	if err != nil {
+		return err
+	}
+	version, secret, err := store.Rotate(account)
+	if err != nil {
+		return err
+	}
+	keys, err := store.Load(account)
+	if err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, stdoutWriter(ctx), map[string]any{
+			"account":  account,
+			"version":  version,
+			"secret":   secret,
+			"versions": keys.Versions(),
+		})
+	}
+	u := ui.FromContext(ctx)
+	u.Out().Linef("version\t%d", version)
+	u.Out().Linef("secret\t%s", secret)
+	u.Out().Linef("versions\t%s", formatTrackingKeyVersions(keys.Versions()))
+	u.Err().Println("Add the new secret to hook receivers, then restart gmail watch serve/pull to sign with it")
+	return nil
+}
+
+type GmailWatchSecretListCmd struct{}
+
+func (c *GmailWatchSecretListCmd) Run(ctx context.Context, flags *RootFlags) error {
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	store, err := newGmailWatchSecretStore(ctx)
+	if err != nil {
+		return err
+	}
+	keys, err := store.Load(account)
+	if err != nil {
+		return err
+	}
+
+	if outfmt.IsJSON(ctx) {
+		return outfmt.WriteJSON(ctx, stdoutWriter(ctx), map[string]any{
+			"account":  account,
+			"current":  keys.Current,
+			"versions": keys.Versions(),
+		})
+	}
+	if len(keys.Keys) == 0 {
+		ui.FromContext(ctx).Err().Println("No hook signing secrets; run gog gmail watch secret rotate")
+		return nil
+	}
+	w, flush := tableWriter(ctx)
+	defer flush()
+	_, _ = fmt.Fprintln(w, "VERSION\tCURRENT")
+	for _, version := range keys.Versions() {
+		_, _ = fmt.Fprintf(w, "%d\t%t\n", version, version == keys.Current)
+	}
+	return nil
+}
+
+type GmailWatchSecretShowCmd struct {
+	Version int `arg:"" name:"version" optional:"" help:"Secret version (default: current)"`
+}
+
+func (c *GmailWatchSecretShowCmd) Run(ctx context.Context, flags *RootFlags) error {
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	if c.Version < 0 {
+		return usage("version must be > 0")
+	}
+	store, err := newGmailWatchSecretStore(ctx)
+	if err != nil {
+		return err
+	}
+	keys, err := store.Load(account)
+	if err != nil {
+		return err
+	}
+	version := c.Version
+	if version == 0 {
+		version = keys.Current
+	}
+	secret, ok := keys.Keys[version]
+	if !ok {
+		if version == 0 {
+			return &ExitError{Code: exitCodeConfig, Err: fmt.Errorf("no hook signing secret for %s; run 'gog gmail watch secret rotate' first", account)}
+		}
+		return usagef("no hook signing secret version %d", version)
+	}
+
+	return writeResult(ctx, ui.FromContext(ctx),
+		kv("version", version),
+		kv("secret", secret),
+	)
+}
+
+type GmailWatchSecretDeleteCmd struct {
+	Version int `arg:"" name:"version" help:"Secret version to delete"`
+}
+
+func (c *GmailWatchSecretDeleteCmd) Run(ctx context.Context, flags *RootFlags) error {
+	account, err := requireAccount(flags)
+	if err != nil {
+		return err
+	}
+	if c.Version <= 0 {
+		return usage("version must be > 0")
+	}
+	if confirmErr := dryRunAndConfirmDestructive(ctx, flags, "gmail.watch.secret.delete", map[string]any{
+		"account": account,
+		"version": c.Version,
+	}, fmt.Sprintf("delete hook signing secret v%d", c.Version)); confirmErr != nil {
+		return confirmErr
+	}
+
+	store, err := newGmailWatchSecretStore(ctx)
+	if err != nil {
+		return err
+	}
+	if err := store.Delete(account, c.Version); err != nil {
+		if errors.Is(err, gmailwatch.ErrHookSecretVersion) || errors.Is(err, gmailwatch.ErrHookSecretCurrent) {
+			return usage(err.Error())
+		}
+		return err
+	}
+	return writeResult(ctx, ui.FromContext(ctx),
+		kv("deleted", true),
+		kv("version", c.Version),
+	)
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steipete/gogcli/internal/app"
+	"github.com/steipete/gogcli/internal/gmailwatch"
+	"github.com/steipete/gogcli/internal/secrets"
+)
+
+func withWatchSecretStore(ctx context.Context, store secrets.SecretStore) context.Context {
+	return withTestRuntime(ctx, func(runtime *app.Runtime) {
+		runtime.Auth.OpenSecretStore = func() (secrets.SecretStore, error) { return store, nil }
+	})
+}
+
+func TestGmailWatchSecretCmds(t *testing.T) {
+	setWatchTestConfigHome(t)
+
+	backend := newMemSecretsStore()
+	var stdout bytes.Buffer
+	ctx := withWatchSecretStore(newCmdRuntimeJSONOutputContext(t, &stdout, io.Discard), backend)
+	flags := &RootFlags{Account: "a@b.com", NoInput: true, Force: true}
+
+	var rotated struct {
+		Version  int    `json:"version"`
+		Secret   string `json:"secret"`
+		Versions []int  `json:"versions"`
+	}
+	for range 2 {
+		stdout.Reset()
+		if err := runKong(t, &GmailWatchSecretRotateCmd{}, nil, ctx, flags); err != nil {
+			t.Fatalf("rotate: %v", err)
+		}
+		if err := json.Unmarshal(stdout.Bytes(), &rotated); err != nil {
+			t.Fatalf("decode rotate: %v\n%s", err, stdout.String())
+		}
+	}
+	if rotated.Version != 2 || rotated.Secret == "" || len(rotated.Versions) != 2 {
+		t.Fatalf("rotate = %#v", rotated)
+	}
+	if string(backend.secrets["gmail-watch/a@b.com/hook_secret_v2"]) != rotated.Secret {
+		t.Fatalf("secret not stored in keyring: %#v", backend.secrets)
+	}
+
+	if err := runKong(t, &GmailWatchSecretDeleteCmd{}, []string{"2"}, ctx, flags); ExitCode(err) != 2 {
+		t.Fatalf("delete current exit = %d: %v", ExitCode(err), err)
+	}
+	stdout.Reset()
+	if err := runKong(t, &GmailWatchSecretDeleteCmd{}, []string{"1"}, ctx, flags); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	stdout.Reset()
+	if err := runKong(t, &GmailWatchSecretListCmd{}, nil, ctx, flags); err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	var listed struct {
+		Current  int   `json:"current"`
+		Versions []int `json:"versions"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &listed); err != nil {
+		t.Fatalf("decode list: %v\n%s", err, stdout.String())
+	}
+	if listed.Current != 2 || len(listed.Versions) != 1 || listed.Versions[0] != 2 {
+		t.Fatalf("list = %#v", listed)
+	}
+
+	stdout.Reset()
+	if err := runKong(t, &GmailWatchSecretShowCmd{}, nil, ctx, flags); err != nil {
+		t.Fatalf("show: %v", err)
+	}
+	if !strings.Contains(stdout.String(), rotated.Secret) {
+		t.Fatalf("show = %s", stdout.String())
+	}
+}
+
+func TestGmailWatchServer_SignsHookRequests(t *testing.T) {
+	setWatchTestConfigHome(t)
+
+	backend := newMemSecretsStore()
+	store, err := gmailwatch.NewHookSecretStore(backend)
+	if err != nil {
+		t.Fatalf("NewHookSecretStore: %v", err)
+	}
+	version, secret, err := store.Rotate("a@b.com")
+	if err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+
+	ctx := withWatchSecretStore(context.Background(), backend)
+	if signer, signerErr := loadGmailWatchHookSigner(ctx, "a@b.com", &gmailWatchHook{URL: "http://x"}); signerErr != nil || signer != nil {
+		t.Fatalf("unsigned hook signer = %#v err=%v", signer, signerErr)
+	}
+	signer, err := loadGmailWatchHookSigner(ctx, "a@b.com", &gmailWatchHook{URL: "http://x", Sign: true})
+	if err != nil || signer.KeyVersion != version {
+		t.Fatalf("signer = %#v err=%v", signer, err)
+	}
+	if _, err := loadGmailWatchHookSigner(ctx, "other@b.com", &gmailWatchHook{URL: "http://x", Sign: true}); ExitCode(err) != exitCodeConfig {
+		t.Fatalf("missing secret exit = %d: %v", ExitCode(err), err)
+	}
+
+	verified := make(chan error, 1)
+	hookSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		verified <- gmailwatch.VerifySignature(r.Header, body, map[int][]byte{version: []byte(secret)}, time.Now(), 0)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer hookSrv.Close()
+
+	s := newWatchRulesTestServer(t, newWatchRulesGmailServer(t, &watchRulesGmail{}), hookSrv.URL, "")
+	s.cfg.HookSigner = signer
+	if rr := serveWatchRulesPush(t, s); rr.Code != http.StatusOK {
+		t.Fatalf("status: %d body=%q", rr.Code, rr.Body.String())
+	}
+	if err := <-verified; err != nil {
+		t.Fatalf("receiver rejected signature: %v", err)
+	}
+}
+
+func TestGmailWatchServeCmd_DryRunReportsSignHook(t *testing.T) {
+	setWatchTestConfigHome(t)
+
+	var stdout bytes.Buffer
+	ctx := newCmdRuntimeJSONOutputContext(t, &stdout, io.Discard)
+	err := runKong(t, &GmailWatchServeCmd{}, []string{"--hook-url", "http://127.0.0.1:1/hook", "--sign-hook"}, ctx,
+		&RootFlags{Account: "a@b.com", DryRun: true, NoInput: true})
+	if ExitCode(err) != 0 {
+		t.Fatalf("exit code = %d: %v", ExitCode(err), err)
+	}
+	if !strings.Contains(stdout.String(), `"sign": true`) {
+		t.Fatalf("dry run = %s", stdout.String())
+	}
+
+	err = runKong(t, &GmailWatchServeCmd{}, []string{"--sign-hook"}, ctx, &RootFlags{Account: "a@b.com", DryRun: true, NoInput: true})
+	if err == nil || !strings.Contains(err.Error(), "--sign-hook requires") {
+		t.Fatalf("expected sign-hook usage error, got %v", err)
+	}
+}
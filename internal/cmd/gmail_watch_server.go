@@ -14,6 +14,7 @@ import (
 	"google.golang.org/api/idtoken"
 
 	"github.com/steipete/gogcli/internal/gmailwatch"
+	"github.com/steipete/gogcli/internal/outbox"
 )
 
 var errNoNewMessages = gmailwatch.ErrNoNewMessages
@@ -33,13 +34,18 @@ type gmailWatchServer struct {
 	sleep           func(context.Context, time.Duration) error
 	hookClient      *http.Client
 	excludeLabelIDs map[string]struct{}
+	outbox          *outbox.Outbox
 	logf            func(string, ...any)
 	warnf           func(string, ...any)
 	now             func() time.Time
 }
 
 func (s *gmailWatchServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	handler := gmailwatch.HTTPHandler{
+	s.httpHandler().ServeHTTP(w, r)
+}
+
+func (s *gmailWatchServer) httpHandler() *gmailwatch.HTTPHandler {
+	return &gmailwatch.HTTPHandler{
 		Config: gmailwatch.HTTPConfig{
 			Path:        s.cfg.Path,
 			Account:     s.cfg.Account,
@@ -54,7 +60,6 @@ func (s *gmailWatchServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		Now:   s.currentTime,
 		Warnf: s.warnf,
 	}
-	handler.ServeHTTP(w, r)
 }
 
 func (s *gmailWatchServer) authorize(r *http.Request) bool {
@@ -87,6 +92,7 @@ func (s *gmailWatchServer) deliverHook(ctx context.Context, payload *gmailHookPa
 	sender := gmailwatch.HookSender{
 		URL:    s.cfg.HookURL,
 		Token:  s.cfg.HookToken,
+		Signer: s.cfg.HookSigner,
 		Client: s.hookClient,
 	}
 
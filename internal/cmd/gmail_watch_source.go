@@ -19,12 +19,14 @@ type gmailWatchSource struct {
 	maxBodyBytes    int
 	dateLocation    *time.Location
 	excludeLabelIDs map[string]struct{}
+	ruleHeaders     []string
+	ruleBody        bool
 	verbose         bool
 	logf            func(string, ...any)
 }
 
 func newGmailWatchSource(service *gmail.Service, cfg gmailWatchServeConfig, excludeLabelIDs map[string]struct{}, logf func(string, ...any)) *gmailWatchSource {
-	return &gmailWatchSource{
+	source := &gmailWatchSource{
 		service:         service,
 		includeBody:     cfg.IncludeBody,
 		maxBodyBytes:    cfg.MaxBodyBytes,
@@ -33,6 +35,11 @@ func newGmailWatchSource(service *gmail.Service, cfg gmailWatchServeConfig, excl
 		verbose:         cfg.VerboseOutput,
 		logf:            logf,
 	}
+	if cfg.Rules != nil {
+		source.ruleHeaders = cfg.Rules.Headers()
+		source.ruleBody = cfg.Rules.NeedsBody()
+	}
+	return source
 }
 
 func (s *gmailWatchSource) ListHistory(ctx context.Context, startID uint64, maxResults int64, historyTypes []string) (gmailwatch.HistoryPage, error) {
@@ -72,9 +79,10 @@ func (s *gmailWatchSource) FetchMessages(ctx context.Context, ids []string) (gma
 		Messages: make([]gmailwatch.Message, 0, len(ids)),
 	}
 	format := gmailWatchFormatMetadata
-	if s.includeBody {
+	if s.includeBody || s.ruleBody {
 		format = gmailFormatFull
 	}
+	metadataHeaders := append(append([]string(nil), gmailBasicMetadataHeaders...), s.ruleHeaders...)
 
 	for _, id := range ids {
 		if strings.TrimSpace(id) == "" {
@@ -82,7 +90,7 @@ func (s *gmailWatchSource) FetchMessages(ctx context.Context, ids []string) (gma
 		}
 		message, err := s.service.Users.Messages.Get("me", id).
 			Format(format).
-			MetadataHeaders(gmailBasicMetadataHeaders...).
+			MetadataHeaders(metadataHeaders...).
 			Context(ctx).
 			Do()
 		if err != nil {
@@ -116,6 +124,15 @@ func (s *gmailWatchSource) FetchMessages(ctx context.Context, ids []string) (gma
 			body := gmailcontent.BestBodyText(message.Payload)
 			item.Body, item.BodyTruncated = truncateUTF8Bytes(body, s.maxBodyBytes)
 		}
+		if len(s.ruleHeaders) > 0 {
+			item.Headers = make(map[string]string, len(s.ruleHeaders))
+			for _, name := range s.ruleHeaders {
+				item.Headers[name] = headerValue(message.Payload, name)
+			}
+		}
+		if s.ruleBody {
+			item.RuleBody = gmailcontent.BestBodyText(message.Payload)
+		}
 		batch.Messages = append(batch.Messages, item)
 	}
 
@@ -0,0 +1,614 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/api/gmail/v1"
+	"google.golang.org/api/idtoken"
+
+	"github.com/steipete/gogcli/internal/authclient"
+	"github.com/steipete/gogcli/internal/config"
+	"github.com/steipete/gogcli/internal/gmailwatch"
+	"github.com/steipete/gogcli/internal/googleapi"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+type GmailWatchSuperviseCmd struct {
+	Accounts      []string `name:"accounts" sep:"," help:"Accounts to supervise (repeatable, comma-separated). Default: every account with stored watch state"`
+	Bind          string   `name:"bind" help:"Bind address" default:"127.0.0.1"`
+	Port          int      `name:"port" help:"Listen port" default:"8788"`
+	Path          string   `name:"path" help:"Push handler path" default:"/gmail-pubsub"`
+	HealthPath    string   `name:"health-path" help:"Health endpoint path; <path>/<account> reports one account" default:"/healthz"`
+	Subscription  string   `name:"subscription" help:"Pull notifications from this Pub/Sub subscription instead of serving push requests"`
+	FetchDelay    string   `name:"fetch-delay" help:"Delay before fetching Gmail history (seconds or duration)" default:"3s"`
+	VerifyOIDC    bool     `name:"verify-oidc" help:"Verify Pub/Sub OIDC tokens"`
+	OIDCEmail     string   `name:"oidc-email" help:"Expected service account email"`
+	OIDCAudience  string   `name:"oidc-audience" help:"Expected OIDC audience"`
+	SharedToken   string   `name:"token" help:"Shared token for x-gog-token or ?token= (also required by the health endpoint when set)"`
+	HistoryTypes  []string `name:"history-types" help:"History types to include (repeatable, comma-separated: messageAdded,messageDeleted,labelAdded,labelRemoved). Default: messageAdded"`
+	ExcludeLabels string   `name:"exclude-labels" help:"List of Gmail label IDs to exclude from hook payload (e.g. SPAM,TRASH,Label_123). Set to empty string to disable." default:"SPAM,TRASH"`
+	RenewBefore   string   `name:"renew-before" help:"Renew each watch this long before it expires (seconds or duration)" default:"24h"`
+	RenewTTL      string   `name:"renew-ttl" help:"After renewing, set renewAfter to now + this duration (seconds or duration)"`
+	RenewCheck    string   `name:"renew-check" help:"How often to check watches for renewal (seconds or duration)" default:"10m"`
+	Outbox        bool     `name:"outbox" help:"Queue failed hook deliveries on disk per account and retry them with backoff"`
+	OutboxMax     int      `name:"outbox-max-attempts" help:"Delivery attempts before a queued payload moves to the dead-letter queue" default:"8"`
+}
+
+func (c *GmailWatchSuperviseCmd) Run(ctx context.Context, flags *RootFlags) error {
+	if googleapi.ReadOnly(ctx) && (flags == nil || !flags.DryRun) {
+		return fmt.Errorf("%w: Gmail watch supervise renews watches", googleapi.ErrReadOnly)
+	}
+	u := ui.FromContext(ctx)
+	subscription := strings.TrimSpace(c.Subscription)
+	pull := subscription != ""
+	if pull {
+		if _, err := projectIDFromPubSubSubscription(subscription); err != nil {
+			return err
+		}
+	}
+	if !strings.HasPrefix(c.Path, "/") || !strings.HasPrefix(c.HealthPath, "/") {
+		return usage("--path and --health-path must start with '/'")
+	}
+	if !pull && gmailwatch.PathMatches(c.Path, c.HealthPath) {
+		return usage("--health-path must not be under --path")
+	}
+	if c.Port <= 0 {
+		return usage("--port must be > 0")
+	}
+	if !pull && !c.VerifyOIDC && c.SharedToken == "" && !isLoopbackHost(c.Bind) {
+		return usage("--verify-oidc or --token required when binding non-loopback")
+	}
+	if (c.OIDCEmail != "" || c.OIDCAudience != "") && !c.VerifyOIDC {
+		return usage("--oidc-email and --oidc-audience require --verify-oidc")
+	}
+	if c.Outbox && c.OutboxMax <= 0 {
+		return usage("--outbox-max-attempts must be > 0")
+	}
+	loc, err := resolveOutputLocation(ctx, "", false, stderrWriter(ctx))
+	if err != nil {
+		return err
+	}
+	historyTypes, err := parseHistoryTypes(c.HistoryTypes)
+	if err != nil {
+		return err
+	}
+	fetchDelay, err := parseDurationSeconds(c.FetchDelay)
+	if err != nil {
+		return err
+	}
+	renewBefore, err := parseDurationSeconds(c.RenewBefore)
+	if err != nil {
+		return err
+	}
+	renewTTL, err := parseDurationSeconds(c.RenewTTL)
+	if err != nil {
+		return err
+	}
+	renewCheck, err := parseDurationSeconds(c.RenewCheck)
+	if err != nil {
+		return err
+	}
+	if fetchDelay < 0 || renewBefore < 0 || renewTTL < 0 {
+		return usage("--fetch-delay, --renew-before, and --renew-ttl must be >= 0")
+	}
+	if renewCheck <= 0 {
+		return usage("--renew-check must be > 0")
+	}
+
+	layout, err := commandLayout(ctx, config.PathKindConfig, config.PathKindState)
+	if err != nil {
+		return err
+	}
+	accounts := normalizeSupervisedAccounts(c.Accounts)
+	if len(accounts) == 0 {
+		accounts, err = listGmailWatchAccounts(layout)
+		if err != nil {
+			return err
+		}
+	}
+	if len(accounts) == 0 {
+		return usage("no stored watch state; run gog gmail watch start for each account first")
+	}
+
+	addr := net.JoinHostPort(c.Bind, strconv.Itoa(c.Port))
+	if dryRunErr := dryRunExit(ctx, flags, "gmail.watch.supervise", map[string]any{
+		"accounts":     accounts,
+		"listen":       addr,
+		"path":         c.Path,
+		"health_path":  c.HealthPath,
+		"subscription": subscription,
+		"auth": map[string]any{
+			"verify_oidc":       c.VerifyOIDC,
+			"oidc_email_set":    strings.TrimSpace(c.OIDCEmail) != "",
+			"oidc_audience_set": strings.TrimSpace(c.OIDCAudience) != "",
+			"shared_token_set":  c.SharedToken != "",
+		},
+		"fetch_delay_seconds":  fetchDelay.Seconds(),
+		"history_types":        historyTypes,
+		"exclude_labels":       splitCommaList(c.ExcludeLabels),
+		"renew_before_seconds": renewBefore.Seconds(),
+		"renew_ttl_seconds":    renewTTL.Seconds(),
+		"renew_check_seconds":  renewCheck.Seconds(),
+		"outbox":               gmailWatchOutboxSummary(c.Outbox, c.OutboxMax),
+	}); dryRunErr != nil {
+		return dryRunErr
+	}
+
+	validator := (*idtoken.Validator)(nil)
+	if c.VerifyOIDC && !pull {
+		validator, err = newOIDCValidator(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	selectedClient := strings.TrimSpace(flags.Client)
+	gmailFactory, err := gmailServiceFactory(ctx)
+	if err != nil {
+		return err
+	}
+	serviceFactory := func(ctx context.Context, account string) (*gmail.Service, error) {
+		if selectedClient != "" {
+			ctx = authclient.WithClient(ctx, selectedClient)
+		}
+		return gmailFactory(ctx, account)
+	}
+
+	base := gmailWatchServeConfig{
+		Path:          c.Path,
+		VerifyOIDC:    c.VerifyOIDC,
+		OIDCEmail:     c.OIDCEmail,
+		OIDCAudience:  c.OIDCAudience,
+		SharedToken:   c.SharedToken,
+		HookTimeout:   defaultHookRequestTimeoutSec * time.Second,
+		HistoryMax:    defaultHistoryMaxResults,
+		ResyncMax:     defaultHistoryResyncMax,
+		FetchDelay:    fetchDelay,
+		HistoryTypes:  historyTypes,
+		DateLocation:  loc,
+		ExcludeLabels: splitCommaList(c.ExcludeLabels),
+		VerboseOutput: flags.Verbose,
+	}
+	supervisor := &gmailWatchSupervisor{
+		path:        c.Path,
+		healthPath:  c.HealthPath,
+		pull:        pull,
+		renewBefore: renewBefore,
+		renewTTL:    renewTTL,
+		newService:  serviceFactory,
+		logf:        u.Err().Linef,
+		warnf:       u.Err().Linef,
+	}
+	for _, account := range accounts {
+		server, serverErr := newSupervisedWatchServer(ctx, account, base, validator, serviceFactory, u.Err().Linef)
+		if serverErr != nil {
+			return fmt.Errorf("%s: %w", account, serverErr)
+		}
+		if c.Outbox && server.cfg.HookURL != "" {
+			server.outbox, err = openGmailWatchOutbox(ctx, account, c.OutboxMax)
+			if err != nil {
+				return err
+			}
+			go server.runOutbox(ctx)
+		}
+		supervisor.add(server)
+	}
+
+	go supervisor.runRenewals(ctx, renewCheck)
+
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           supervisor,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	if !pull {
+		u.Err().Linef("watch: supervising %d accounts on %s%s (health %s)", len(accounts), addr, c.Path, c.HealthPath)
+		return listenAndServe(httpServer)
+	}
+
+	receiver, err := newGmailPubSubReceiver(ctx, subscription, gmailPubSubReceiveSettings{
+		MaxOutstandingMessages: 1,
+	})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := receiver.Close(); closeErr != nil {
+			u.Err().Linef("watch: failed to close Pub/Sub receiver: %v", closeErr)
+		}
+	}()
+	go func() {
+		if serveErr := listenAndServe(httpServer); serveErr != nil && !errors.Is(serveErr, http.ErrServerClosed) {
+			u.Err().Linef("watch: health endpoint failed: %v", serveErr)
+		}
+	}()
+	defer func() { _ = httpServer.Close() }()
+
+	u.Err().Linef("watch: supervising %d accounts from %s (health %s%s)", len(accounts), subscription, addr, c.HealthPath)
+	err = receiver.Receive(ctx, supervisor.handlePullMessage)
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
+
+// newSupervisedWatchServer builds the per-account processor from the shared
+// flags and the hook stored in the account's watch state.
+func newSupervisedWatchServer(
+	ctx context.Context,
+	account string,
+	base gmailWatchServeConfig,
+	validator *idtoken.Validator,
+	newService func(context.Context, string) (*gmail.Service, error),
+	logf func(string, ...any),
+) (*gmailWatchServer, error) {
+	store, err := loadGmailWatchStore(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	state := store.Get()
+
+	cfg := base
+	cfg.Account = account
+	cfg.MaxBodyBytes = defaultHookMaxBytes
+	if hook := state.Hook; hook != nil && strings.TrimSpace(hook.URL) != "" {
+		cfg.HookURL = hook.URL
+		cfg.HookToken = hook.Token
+		cfg.IncludeBody = hook.IncludeBody
+		if hook.MaxBytes > 0 {
+			cfg.MaxBodyBytes = hook.MaxBytes
+		}
+		cfg.HookSigner, err = loadGmailWatchHookSigner(ctx, account, hook)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		logf("watch: %s has no stored hook; notifications only advance its cursor", account)
+	}
+
+	return &gmailWatchServer{
+		cfg:             cfg,
+		store:           store,
+		validator:       validator,
+		newService:      newService,
+		hookClient:      &http.Client{Timeout: cfg.HookTimeout},
+		excludeLabelIDs: stringSet(cfg.ExcludeLabels),
+		logf:            logf,
+		warnf:           logf,
+	}, nil
+}
+
+type gmailWatchSupervisor struct {
+	servers     map[string]*gmailWatchServer
+	accounts    []string
+	path        string
+	healthPath  string
+	pull        bool
+	renewBefore time.Duration
+	renewTTL    time.Duration
+	newService  func(context.Context, string) (*gmail.Service, error)
+	logf        func(string, ...any)
+	warnf       func(string, ...any)
+	now         func() time.Time
+
+	mu     sync.Mutex
+	renews map[string]gmailWatchRenewResult
+}
+
+type gmailWatchRenewResult struct {
+	At  time.Time
+	Err string
+}
+
+func (s *gmailWatchSupervisor) add(server *gmailWatchServer) {
+	if s.servers == nil {
+		s.servers = map[string]*gmailWatchServer{}
+	}
+	key := strings.ToLower(server.cfg.Account)
+	s.servers[key] = server
+	s.accounts = append(s.accounts, key)
+}
+
+func (s *gmailWatchSupervisor) server(account string) *gmailWatchServer {
+	return s.servers[strings.ToLower(strings.TrimSpace(account))]
+}
+
+func (s *gmailWatchSupervisor) currentTime() time.Time {
+	if s.now != nil {
+		return s.now()
+	}
+	return time.Now()
+}
+
+func (s *gmailWatchSupervisor) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if gmailwatch.PathMatches(s.healthPath, r.URL.Path) {
+		s.serveHealth(w, r)
+		return
+	}
+	if s.pull {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	router := gmailwatch.Router{
+		Path:      s.path,
+		BodyLimit: defaultPushBodyLimitBytes,
+		Authorize: s.authorize,
+		Lookup: func(account string) *gmailwatch.HTTPHandler {
+			server := s.server(account)
+			if server == nil {
+				return nil
+			}
+			return server.httpHandler()
+		},
+		Warnf: s.warnf,
+	}
+	router.ServeHTTP(w, r)
+}
+
+// authorize uses the first account's auth settings; every supervised server
+// shares them.
+func (s *gmailWatchSupervisor) authorize(r *http.Request) bool {
+	if len(s.accounts) == 0 {
+		return false
+	}
+	return s.servers[s.accounts[0]].authorize(r)
+}
+
+func (s *gmailWatchSupervisor) handlePullMessage(ctx context.Context, msg *gmailPubSubMessage) {
+	payload, err := decodeGmailPullPayload(msg)
+	if err != nil {
+		s.warnf("watch: invalid pull data: %v", err)
+		msg.Ack()
+		return
+	}
+	server := s.server(payload.EmailAddress)
+	if server == nil {
+		s.warnf("watch: ignoring pull notification for unsupervised account %q", payload.EmailAddress)
+		msg.Ack()
+		return
+	}
+	server.handlePullMessage(ctx, msg)
+}
+
+func (s *gmailWatchSupervisor) runRenewals(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.renewDue(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *gmailWatchSupervisor) renewDue(ctx context.Context) {
+	for _, account := range s.accounts {
+		server := s.servers[account]
+		now := s.currentTime()
+		if !gmailwatch.RenewalDue(server.store.Get(), now, s.renewBefore) {
+			continue
+		}
+		result := gmailWatchRenewResult{At: now}
+		if err := s.renew(ctx, server, now); err != nil {
+			result.Err = err.Error()
+			s.warnf("watch: renew %s failed: %v", server.cfg.Account, err)
+		} else {
+			s.logf("watch: renewed %s", server.cfg.Account)
+		}
+		s.mu.Lock()
+		if s.renews == nil {
+			s.renews = map[string]gmailWatchRenewResult{}
+		}
+		s.renews[account] = result
+		s.mu.Unlock()
+	}
+}
+
+func (s *gmailWatchSupervisor) renew(ctx context.Context, server *gmailWatchServer, now time.Time) error {
+	state := server.store.Get()
+	if strings.TrimSpace(state.Topic) == "" {
+		return errors.New("stored watch state missing topic")
+	}
+	svc, err := s.newService(ctx, server.cfg.Account)
+	if err != nil {
+		return err
+	}
+	resp, err := requestGmailWatch(ctx, svc, state.Topic, state.Labels)
+	if err != nil {
+		return err
+	}
+	var renewAfterMs int64
+	if s.renewTTL > 0 {
+		renewAfterMs = now.Add(s.renewTTL).UnixMilli()
+	}
+	return server.store.RecordRenewal(resp.Expiration, renewAfterMs, now)
+}
+
+type gmailWatchHealth struct {
+	Account            string   `json:"account"`
+	Healthy            bool     `json:"healthy"`
+	Problems           []string `json:"problems,omitempty"`
+	HistoryID          string   `json:"history_id,omitempty"`
+	ExpiresAt          string   `json:"expires_at,omitempty"`
+	RenewAfter         string   `json:"renew_after,omitempty"`
+	LastRenewAt        string   `json:"last_renew_at,omitempty"`
+	LastRenewError     string   `json:"last_renew_error,omitempty"`
+	HookConfigured     bool     `json:"hook_configured"`
+	LastDeliveryStatus string   `json:"last_delivery_status,omitempty"`
+	LastDeliveryAt     string   `json:"last_delivery_at,omitempty"`
+	LastDeliveryNote   string   `json:"last_delivery_note,omitempty"`
+	RateLimitedUntil   string   `json:"rate_limited_until,omitempty"`
+	OutboxPending      int      `json:"outbox_pending,omitempty"`
+	OutboxDead         int      `json:"outbox_dead,omitempty"`
+}
+
+func (s *gmailWatchSupervisor) health(account string) gmailWatchHealth {
+	server := s.servers[account]
+	state := server.store.Get()
+	now := s.currentTime()
+	health := gmailWatchHealth{
+		Account:            server.cfg.Account,
+		HistoryID:          state.HistoryID,
+		ExpiresAt:          formatWatchHealthMs(state.ProviderExpirationMs),
+		RenewAfter:         formatWatchHealthMs(state.RenewAfterMs),
+		HookConfigured:     server.cfg.HookURL != "",
+		LastDeliveryStatus: state.LastDeliveryStatus,
+		LastDeliveryAt:     formatWatchHealthMs(state.LastDeliveryAtMs),
+		LastDeliveryNote:   state.LastDeliveryStatusNote,
+	}
+	if health.ExpiresAt == "" {
+		health.ExpiresAt = formatWatchHealthMs(state.ExpirationMs)
+	}
+
+	s.mu.Lock()
+	renew, renewed := s.renews[account]
+	s.mu.Unlock()
+	if renewed {
+		health.LastRenewAt = renew.At.UTC().Format(time.RFC3339)
+		health.LastRenewError = renew.Err
+		if renew.Err != "" {
+			health.Problems = append(health.Problems, "renewal failed")
+		}
+	}
+	if expiry := max(state.ProviderExpirationMs, state.ExpirationMs); expiry > 0 && now.UnixMilli() >= expiry {
+		health.Problems = append(health.Problems, "watch expired")
+	}
+	switch state.LastDeliveryStatus {
+	case gmailwatch.DeliveryStatusError, gmailwatch.DeliveryStatusHTTPError:
+		health.Problems = append(health.Problems, "last hook delivery failed")
+	}
+	if state.RateLimitedUntilMs > now.UnixMilli() {
+		health.RateLimitedUntil = formatWatchHealthMs(state.RateLimitedUntilMs)
+		health.Problems = append(health.Problems, "Gmail rate limited")
+	}
+	if server.outbox != nil {
+		if pending, err := server.outbox.Pending(); err == nil {
+			health.OutboxPending = len(pending)
+		}
+		if dead, err := server.outbox.Dead(); err == nil {
+			health.OutboxDead = len(dead)
+			if len(dead) > 0 {
+				health.Problems = append(health.Problems, "dead-lettered hook deliveries")
+			}
+		}
+	}
+	health.Healthy = len(health.Problems) == 0
+	return health
+}
+
+func (s *gmailWatchSupervisor) serveHealth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if token := s.sharedToken(); token != "" && !gmailwatch.SharedTokenMatches(r, token) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	rest := strings.Trim(strings.TrimPrefix(r.URL.Path, s.healthPath), "/")
+	var (
+		body    any
+		healthy = true
+	)
+	if rest == "" {
+		items := make([]gmailWatchHealth, 0, len(s.accounts))
+		for _, account := range s.accounts {
+			item := s.health(account)
+			healthy = healthy && item.Healthy
+			items = append(items, item)
+		}
+		body = map[string]any{"healthy": healthy, "accounts": items}
+	} else {
+		account := strings.ToLower(rest)
+		if s.servers[account] == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		item := s.health(account)
+		healthy = item.Healthy
+		body = item
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !healthy {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func (s *gmailWatchSupervisor) sharedToken() string {
+	if len(s.accounts) == 0 {
+		return ""
+	}
+	return s.servers[s.accounts[0]].cfg.SharedToken
+}
+
+func formatWatchHealthMs(ms int64) string {
+	if ms <= 0 {
+		return ""
+	}
+	return time.UnixMilli(ms).UTC().Format(time.RFC3339)
+}
+
+func normalizeSupervisedAccounts(values []string) []string {
+	var out []string
+	for _, value := range values {
+		for _, part := range splitCommaList(value) {
+			account := strings.ToLower(part)
+			if !slices.Contains(out, account) {
+				out = append(out, account)
+			}
+		}
+	}
+	return out
+}
+
+// listGmailWatchAccounts returns every account with a stored watch state,
+// preferring the primary state directory over the legacy one.
+func listGmailWatchAccounts(layout config.Layout) ([]string, error) {
+	dirs := []string{layout.GmailWatchDir()}
+	if !layout.ExplicitState && layout.LegacyGmailWatchDir() != dirs[0] {
+		dirs = append(dirs, layout.LegacyGmailWatchDir())
+	}
+	var accounts []string
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("list gmail watch state: %w", err)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+				continue
+			}
+			state, found, err := gmailwatch.ReadOptional(filepath.Join(dir, entry.Name()))
+			if err != nil || !found {
+				continue
+			}
+			account := strings.ToLower(strings.TrimSpace(state.Account))
+			if account != "" && !slices.Contains(accounts, account) {
+				accounts = append(accounts, account)
+			}
+		}
+	}
+	slices.Sort(accounts)
+	return accounts, nil
+}
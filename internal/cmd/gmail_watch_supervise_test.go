@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/api/gmail/v1"
+	"google.golang.org/api/option"
+)
+
+func newSuperviseGmailService(t *testing.T, watchCalls *atomic.Int32, expiration int64) *gmail.Service {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/users/me/watch"):
+			watchCalls.Add(1)
+			_ = json.NewEncoder(w).Encode(map[string]any{"historyId": "900", "expiration": strconv.FormatInt(expiration, 10)})
+		case strings.Contains(r.URL.Path, "/users/me/history"):
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"historyId": "200",
+				"history": []map[string]any{
+					{"messagesAdded": []map[string]any{{"message": map[string]any{"id": "m1"}}}},
+				},
+			})
+		case strings.HasSuffix(r.URL.Path, "/users/me/messages/m1"):
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": "m1", "threadId": "t1", "labelIds": []string{"INBOX"}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	svc, err := gmail.NewService(context.Background(),
+		option.WithoutAuthentication(),
+		option.WithHTTPClient(srv.Client()),
+		option.WithEndpoint(srv.URL+"/"),
+	)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	return svc
+}
+
+func seedSupervisedWatch(t *testing.T, account, hookURL string, state gmailWatchState) {
+	t.Helper()
+	store := newGmailWatchTestStore(t, account)
+	if err := store.Update(func(s *gmailWatchState) error {
+		*s = state
+		s.Account = account
+		s.Topic = "projects/p/topics/t"
+		s.HistoryID = "100"
+		if hookURL != "" {
+			s.Hook = &gmailWatchHook{URL: hookURL}
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("seed %s: %v", account, err)
+	}
+}
+
+func newTestSupervisor(t *testing.T, svc *gmail.Service, accounts ...string) *gmailWatchSupervisor {
+	t.Helper()
+	ctx := newCmdRuntimeOutputContext(t, io.Discard, io.Discard)
+	newService := func(context.Context, string) (*gmail.Service, error) { return svc, nil }
+	supervisor := &gmailWatchSupervisor{
+		path:        "/gmail-pubsub",
+		healthPath:  "/healthz",
+		renewBefore: 24 * time.Hour,
+		newService:  newService,
+		logf:        func(string, ...any) {},
+		warnf:       func(string, ...any) {},
+	}
+	base := gmailWatchServeConfig{Path: "/gmail-pubsub", SharedToken: "tok", HistoryMax: 100, ResyncMax: 10, HookTimeout: time.Second}
+	for _, account := range accounts {
+		server, err := newSupervisedWatchServer(ctx, account, base, nil, newService, func(string, ...any) {})
+		if err != nil {
+			t.Fatalf("newSupervisedWatchServer(%s): %v", account, err)
+		}
+		supervisor.add(server)
+	}
+	return supervisor
+}
+
+func supervisePush(t *testing.T, handler http.Handler, account string) int {
+	t.Helper()
+	push := pubsubPushEnvelope{}
+	push.Message.Data = base64.StdEncoding.EncodeToString([]byte(`{"emailAddress":"` + account + `","historyId":"200"}`))
+	body, _ := json.Marshal(push)
+	req := httptest.NewRequestWithContext(context.Background(), http.MethodPost, "/gmail-pubsub?token=tok", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr.Code
+}
+
+func TestGmailWatchSupervisor_RoutesByEmailAddress(t *testing.T) {
+	setWatchTestConfigHome(t)
+
+	var hookCalls atomic.Int32
+	hookSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hookCalls.Add(1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer hookSrv.Close()
+
+	future := time.Now().Add(72 * time.Hour).UnixMilli()
+	seedSupervisedWatch(t, "a@b.com", hookSrv.URL, gmailWatchState{ProviderExpirationMs: future})
+	seedSupervisedWatch(t, "c@d.com", hookSrv.URL, gmailWatchState{ProviderExpirationMs: future})
+
+	var watchCalls atomic.Int32
+	supervisor := newTestSupervisor(t, newSuperviseGmailService(t, &watchCalls, future), "a@b.com", "c@d.com")
+
+	if code := supervisePush(t, supervisor, "C@D.com"); code != http.StatusOK {
+		t.Fatalf("push status = %d", code)
+	}
+	if code := supervisePush(t, supervisor, "x@y.com"); code != http.StatusAccepted {
+		t.Fatalf("unknown account status = %d", code)
+	}
+	if got := supervisor.server("c@d.com").store.Get().HistoryID; got != "200" {
+		t.Fatalf("c@d.com history = %q", got)
+	}
+	if got := supervisor.server("a@b.com").store.Get().HistoryID; got != "100" {
+		t.Fatalf("a@b.com history = %q", got)
+	}
+	if hookCalls.Load() != 1 {
+		t.Fatalf("hook calls = %d", hookCalls.Load())
+	}
+
+	rr := httptest.NewRecorder()
+	supervisor.ServeHTTP(rr, httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/healthz?token=tok", nil))
+	var all struct {
+		Healthy  bool               `json:"healthy"`
+		Accounts []gmailWatchHealth `json:"accounts"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &all); err != nil || rr.Code != http.StatusOK {
+		t.Fatalf("health = %d %s err=%v", rr.Code, rr.Body.String(), err)
+	}
+	if !all.Healthy || len(all.Accounts) != 2 || all.Accounts[1].LastDeliveryStatus != "ok" {
+		t.Fatalf("health = %#v", all)
+	}
+
+	rr = httptest.NewRecorder()
+	supervisor.ServeHTTP(rr, httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/healthz/a@b.com", nil))
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("health without token = %d", rr.Code)
+	}
+	rr = httptest.NewRecorder()
+	supervisor.ServeHTTP(rr, httptest.NewRequestWithContext(context.Background(), http.MethodGet, "/healthz/nobody@b.com?token=tok", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("unknown account health = %d", rr.Code)
+	}
+}
+
+func TestGmailWatchSupervisor_RenewsDueWatches(t *testing.T) {
+	setWatchTestConfigHome(t)
+
+	now := time.Now()
+	seedSupervisedWatch(t, "a@b.com", "", gmailWatchState{ProviderExpirationMs: now.Add(time.Hour).UnixMilli()})
+	seedSupervisedWatch(t, "c@d.com", "", gmailWatchState{ProviderExpirationMs: now.Add(72 * time.Hour).UnixMilli()})
+
+	var watchCalls atomic.Int32
+	renewed := now.Add(7 * 24 * time.Hour).UnixMilli()
+	supervisor := newTestSupervisor(t, newSuperviseGmailService(t, &watchCalls, renewed), "a@b.com", "c@d.com")
+	supervisor.renewTTL = 12 * time.Hour
+
+	supervisor.renewDue(context.Background())
+
+	if watchCalls.Load() != 1 {
+		t.Fatalf("watch calls = %d, want 1", watchCalls.Load())
+	}
+	state := supervisor.server("a@b.com").store.Get()
+	if state.ProviderExpirationMs != renewed || state.HistoryID != "100" || state.RenewAfterMs == 0 {
+		t.Fatalf("renewed state = %#v", state)
+	}
+	health := supervisor.health("a@b.com")
+	if !health.Healthy || health.LastRenewAt == "" || health.HookConfigured {
+		t.Fatalf("health = %#v", health)
+	}
+	if got := supervisor.server("c@d.com").store.Get().ProviderExpirationMs; got == renewed {
+		t.Fatal("renewed a watch that was not due")
+	}
+}
+
+func TestGmailWatchSuperviseCmd_DryRunListsStoredAccounts(t *testing.T) {
+	setWatchTestConfigHome(t)
+	seedSupervisedWatch(t, "c@d.com", "", gmailWatchState{})
+	seedSupervisedWatch(t, "a@b.com", "", gmailWatchState{})
+
+	var stdout bytes.Buffer
+	ctx := newCmdRuntimeJSONOutputContext(t, &stdout, io.Discard)
+	err := runKong(t, &GmailWatchSuperviseCmd{}, nil, ctx, &RootFlags{DryRun: true, NoInput: true})
+	if ExitCode(err) != 0 {
+		t.Fatalf("exit code = %d: %v", ExitCode(err), err)
+	}
+	var got struct {
+		Request struct {
+			Accounts []string `json:"accounts"`
+		} `json:"request"`
+	}
+	if decodeErr := json.Unmarshal(stdout.Bytes(), &got); decodeErr != nil {
+		t.Fatalf("decode: %v\n%s", decodeErr, stdout.String())
+	}
+	if strings.Join(got.Request.Accounts, ",") != "a@b.com,c@d.com" {
+		t.Fatalf("accounts = %v", got.Request.Accounts)
+	}
+
+	err = runKong(t, &GmailWatchSuperviseCmd{}, []string{"--bind", "0.0.0.0"}, ctx, &RootFlags{DryRun: true, NoInput: true})
+	if err == nil || !strings.Contains(err.Error(), "--verify-oidc or --token") {
+		t.Fatalf("expected auth usage error, got %v", err)
+	}
+}
@@ -29,6 +29,7 @@ type gmailWatchServeConfig struct {
 	SharedToken   string
 	HookURL       string
 	HookToken     string
+	HookSigner    *gmailwatch.HookSigner
 	IncludeBody   bool
 	MaxBodyBytes  int
 	ExcludeLabels []string
@@ -41,6 +42,7 @@ type gmailWatchServeConfig struct {
 	PersistHook   bool
 	AllowNoHook   bool
 	VerboseOutput bool
+	Rules         *gmailwatch.Rules
 }
 
 var gmailHistoryTypes = []string{
@@ -13,16 +13,24 @@ import (
 	"time"
 	"unicode/utf8"
 
+	"github.com/alecthomas/kong"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
 
 type McpCmd struct {
-	AllowTool      []string `name:"allow-tool" aliases:"tool" sep:"," help:"Tool or service allowlist (default: all read-only tools). Examples: gmail.*,docs_get,sheets"`
-	AllowWrite     bool     `name:"allow-write" help:"Expose write tools. Write tools must also match --allow-tool when that flag is set."`
-	ListTools      bool     `name:"list-tools" help:"Print enabled MCP tools as JSON and exit"`
-	TimeoutSeconds int      `name:"timeout-seconds" help:"Per-tool subprocess timeout" default:"60"`
-	MaxOutputBytes int      `name:"max-output-bytes" help:"Max stdout/stderr bytes captured per tool call" default:"102400"`
+	AllowTool      []string      `name:"allow-tool" aliases:"tool" sep:"," help:"Tool or service allowlist (default: all read-only tools). Examples: gmail.*,docs_get,sheets"`
+	AllowWrite     bool          `name:"allow-write" help:"Expose write tools. Write tools must also match --allow-tool when that flag is set."`
+	ListTools      bool          `name:"list-tools" help:"Print enabled MCP tools as JSON and exit"`
+	GeneratedTools bool          `name:"generated-tools" help:"Also expose one typed tool per command, generated from the command schema"`
+	TimeoutSeconds int           `name:"timeout-seconds" help:"Per-tool subprocess timeout" default:"60"`
+	MaxOutputBytes int           `name:"max-output-bytes" help:"Max stdout/stderr bytes captured per tool call" default:"102400"`
+	Listen         string        `name:"listen" help:"Serve streamable HTTP (/mcp) and SSE (/sse) on this address instead of stdio, e.g. 127.0.0.1:8765"`
+	Token          string        `name:"token" help:"Bearer token HTTP clients must send with --listen" env:"GOG_MCP_TOKEN"`
+	TokenFile      string        `name:"token-file" type:"path" help:"Read the --listen bearer token from a file"`
+	AllowAccount   []string      `name:"allow-account" sep:"," help:"Extra accounts HTTP clients may select with the X-Gog-Account header (repeatable, comma-separated)"`
+	InProcess      bool          `name:"in-process" help:"Run tool calls inside the server process, reusing parsers, Google API clients, and the opened keyring, instead of starting gog per call"`
+	ResourcePoll   time.Duration `name:"resource-poll" help:"How often subscribed resources are re-read for change notifications (0 disables subscriptions)" default:"60s"`
 }
 
 type mcpToolRisk string
@@ -50,7 +58,7 @@ type mcpCommandResult struct {
 	Stderr   string `json:"stderr,omitempty"`
 }
 
-func (c *McpCmd) Run(ctx context.Context, flags *RootFlags) error {
+func (c *McpCmd) Run(ctx context.Context, kctx *kong.Context, flags *RootFlags) error {
 	self, err := os.Executable()
 	if err != nil {
 		return fmt.Errorf("resolve executable: %w", err)
@@ -62,20 +70,58 @@ func (c *McpCmd) Run(ctx context.Context, flags *RootFlags) error {
 		return usage("--max-output-bytes must be greater than zero")
 	}
 
-	tools := mcpEnabledTools(*c)
+	httpOpts, err := c.httpOptions(flags)
+	if err != nil {
+		return err
+	}
+
+	all := mcpAllTools()
+	if c.GeneratedTools && kctx != nil {
+		all = mcpMergeTools(all, mcpGeneratedTools(kctx.Model.Node, flags))
+	}
+	tools := mcpFilterTools(*c, all)
 	if len(tools) == 0 {
 		return usage("no MCP tools enabled")
 	}
+	if c.ResourcePoll < 0 {
+		return usage("--resource-poll must not be negative")
+	}
+	resources := mcpEnabledResources(*c)
+	prompts := mcpEnabledPrompts(*c)
 	if c.ListTools {
-		return mcpPrintTools(stdoutWriter(ctx), tools)
+		return mcpPrintTools(stdoutWriter(ctx), tools, resources, prompts)
 	}
 
-	baseArgs := mcpParentRootArgs(flags)
 	safetySuffix := mcpParentSafetyArgs(flags)
 	timeout := time.Duration(c.TimeoutSeconds) * time.Second
 	maxOutputBytes := c.MaxOutputBytes
-
-	s := newMCPServer()
+	var inProcess *mcpInProcessExecutor
+	if c.InProcess {
+		inProcess = newMCPInProcessExecutor()
+	}
+
+	fetch := mcpReadFetcher(mcpRunOptions{
+		self:           self,
+		safetySuffix:   safetySuffix,
+		timeout:        timeout,
+		maxOutputBytes: maxOutputBytes,
+		accessToken:    directAccessToken(flags),
+		inProcess:      inProcess,
+	}, flags)
+	registry := newMCPResourceRegistry(resources, fetch)
+	watcher := newMCPResourceWatcher(registry)
+	subscribe := c.ResourcePoll > 0 && len(resources) > 0
+	s := newMCPServer(
+		server.WithResourceCapabilities(subscribe, false),
+		server.WithPromptCapabilities(false),
+		server.WithHooks(watcher.hooks()),
+	)
+	watcher.server = s
+	registry.register(s)
+	registerMCPPrompts(s, prompts, fetch)
+	if subscribe {
+		go watcher.run(ctx, c.ResourcePoll)
+	}
 	for _, spec := range tools {
 		tool := spec
 		s.AddTool(newMCPTool(tool), func(reqCtx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -88,24 +134,30 @@ func (c *McpCmd) Run(ctx context.Context, flags *RootFlags) error {
 			return mcpRunGogTool(reqCtx, mcpRunOptions{
 				self:           self,
 				tool:           tool,
-				baseArgs:       baseArgs,
+				baseArgs:       mcpRootArgsForRequest(reqCtx, flags),
 				commandArgs:    childCommandArgs,
 				safetySuffix:   safetySuffix,
 				timeout:        timeout,
 				maxOutputBytes: maxOutputBytes,
 				accessToken:    directAccessToken(flags),
+				inProcess:      inProcess,
 			}), nil
 		})
 	}
+	if httpOpts.listen != "" {
+		return serveMCPHTTP(ctx, s, httpOpts)
+	}
 	return server.ServeStdio(s)
 }
 
-func newMCPServer() *server.MCPServer {
+func newMCPServer(opts ...server.ServerOption) *server.MCPServer {
 	return server.NewMCPServer(
 		"gog",
 		VersionString(),
-		server.WithToolCapabilities(false),
-		server.WithInputSchemaValidation(),
+		append([]server.ServerOption{
+			server.WithToolCapabilities(false),
+			server.WithInputSchemaValidation(),
+		}, opts...)...,
 	)
 }
 
@@ -130,9 +182,33 @@ type mcpRunOptions struct {
 	timeout        time.Duration
 	maxOutputBytes int
 	accessToken    string
+	// inProcess runs the command in this process instead of a child gog.
+	inProcess *mcpInProcessExecutor
 }
 
 func mcpRunGogTool(reqCtx context.Context, opts mcpRunOptions) *mcp.CallToolResult {
+	stdout, stderr, exitCode := mcpRunGog(reqCtx, opts)
+	result := mcpCommandResult{
+		Tool:     opts.tool.Name,
+		Service:  opts.tool.Service,
+		Risk:     string(opts.tool.Risk),
+		ExitCode: exitCode,
+		Stdout:   parseMCPStdout(stdout),
+		Stderr:   stderr,
+	}
+	callResult := mcp.NewToolResultStructuredOnly(result)
+	if exitCode != 0 {
+		callResult.IsError = true
+	}
+	return callResult
+}
+
+// mcpRunGog runs one child gog command and returns its bounded output and
+// exit code. A timeout reports exit code 124.
+func mcpRunGog(reqCtx context.Context, opts mcpRunOptions) (string, string, int) {
+	if opts.inProcess != nil {
+		return opts.inProcess.run(reqCtx, opts)
+	}
 	ctx, cancel := context.WithTimeout(reqCtx, opts.timeout)
 	defer cancel()
 
@@ -163,20 +239,7 @@ func mcpRunGogTool(reqCtx context.Context, opts mcpRunOptions) *mcp.CallToolResu
 	if ctx.Err() == context.DeadlineExceeded {
 		exitCode = 124
 	}
-
-	result := mcpCommandResult{
-		Tool:     opts.tool.Name,
-		Service:  opts.tool.Service,
-		Risk:     string(opts.tool.Risk),
-		ExitCode: exitCode,
-		Stdout:   parseMCPStdout(stdoutBuf.String()),
-		Stderr:   stderrBuf.String(),
-	}
-	callResult := mcp.NewToolResultStructuredOnly(result)
-	if exitCode != 0 {
-		callResult.IsError = true
-	}
-	return callResult
+	return stdoutBuf.String(), stderrBuf.String(), exitCode
 }
 
 func parseMCPStdout(s string) any {
@@ -243,7 +306,26 @@ func mcpParentSafetyArgs(flags *RootFlags) []string {
 }
 
 func mcpEnabledTools(cmd McpCmd) []mcpToolSpec {
-	all := mcpAllTools()
+	return mcpFilterTools(cmd, mcpAllTools())
+}
+
+// mcpMergeTools appends generated tools whose names are not already taken, so
+// hand-written tools keep their tighter schemas.
+func mcpMergeTools(tools []mcpToolSpec, generated []mcpToolSpec) []mcpToolSpec {
+	names := make(map[string]bool, len(tools))
+	for _, tool := range tools {
+		names[tool.Name] = true
+	}
+	for _, tool := range generated {
+		if !names[tool.Name] {
+			names[tool.Name] = true
+			tools = append(tools, tool)
+		}
+	}
+	return tools
+}
+
+func mcpFilterTools(cmd McpCmd, all []mcpToolSpec) []mcpToolSpec {
 	allow := splitCommaValues(cmd.AllowTool)
 	out := make([]mcpToolSpec, 0, len(all))
 	for _, tool := range all {
@@ -283,7 +365,7 @@ func mcpToolAllowed(tool mcpToolSpec, allow []string) bool {
 	return false
 }
 
-func mcpPrintTools(output io.Writer, tools []mcpToolSpec) error {
+func mcpPrintTools(output io.Writer, tools []mcpToolSpec, resources []mcpResourceSpec, prompts []mcpPromptSpec) error {
 	items := make([]map[string]string, 0, len(tools))
 	for _, tool := range tools {
 		items = append(items, map[string]string{
@@ -293,9 +375,25 @@ func mcpPrintTools(output io.Writer, tools []mcpToolSpec) error {
 			"description": tool.Description,
 		})
 	}
+	resourceItems := make([]map[string]string, 0, len(resources))
+	for _, resource := range resources {
+		resourceItems = append(resourceItems, map[string]string{
+			"name":         resource.Name,
+			"uri_template": resource.URITemplate,
+			"mime_type":    resource.MIMEType,
+			"description":  resource.Description,
+		})
+	}
+	promptItems := make([]map[string]string, 0, len(prompts))
+	for _, prompt := range prompts {
+		promptItems = append(promptItems, map[string]string{
+			"name":        prompt.Name,
+			"description": prompt.Description,
+		})
+	}
 	enc := json.NewEncoder(output)
 	enc.SetIndent("", "  ")
-	return enc.Encode(map[string]any{"tools": items})
+	return enc.Encode(map[string]any{"tools": items, "resources": resourceItems, "prompts": promptItems})
 }
 
 func requireMCPString(req mcp.CallToolRequest, key string) (string, error) {
@@ -0,0 +1,355 @@
+package cmd
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/alecthomas/kong"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// mcpGeneratedSkipRoots are command trees that never become generated tools:
+// local state and credentials, the server itself, and generic API passthrough.
+var mcpGeneratedSkipRoots = map[string]bool{
+	"__complete": true,
+	"api":        true,
+	"auth":       true,
+	"backup":     true,
+	"batch":      true,
+	"completion": true,
+	"config":     true,
+	"mcp":        true,
+	"schema":     true,
+	"time":       true,
+	"version":    true,
+}
+
+// mcpGeneratedSkipLeaves are long-running receivers that cannot finish inside
+// a tool call.
+var mcpGeneratedSkipLeaves = map[string]bool{
+	"poll":      true,
+	"pull":      true,
+	"serve":     true,
+	"supervise": true,
+}
+
+// mcpGeneratedSkipCommands are individual commands, by full path, that never
+// become generated tools.
+var mcpGeneratedSkipCommands = map[string]bool{
+	// --from/--to pick any stored account, bypassing the server's account pin.
+	"calendar mirror": true,
+	// Print the hook HMAC secret; a model holding it could forge signed hooks.
+	"gmail settings watch secret rotate": true,
+	"gmail settings watch secret show":   true,
+}
+
+// mcpLocalPathArgs name flags and positionals that read or write files on the
+// server host. Generated tools omit such flags and skip commands that require
+// one, so a model cannot reach the local filesystem through a tool call.
+// attach-column names a CSV column whose cells are local attachment paths.
+var mcpLocalPathArgs = map[string]bool{
+	"attach":        true,
+	"attach-column": true,
+	"attachment":    true,
+	"cert":          true,
+	"file":          true,
+	"image":         true,
+	"in-path":       true,
+	"key":           true,
+	"key-env":       true,
+	"key-stdin":     true,
+	"local-path":    true,
+	"out":           true,
+	"out-dir":       true,
+	"path":          true,
+	"state":         true,
+	"worker-dir":    true,
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+type mcpGeneratedArg struct {
+	property   string
+	flag       string
+	positional bool
+	kind       string
+}
+
+// mcpGeneratedTools derives one typed tool per runnable command from the Kong
+// model, the same source `gog schema` describes. Tool risk comes from the
+// readonly safety profile, and commands blocked by --enable-commands,
+// --enable-commands-exact, --disable-commands, or a baked profile are left out.
+func mcpGeneratedTools(root *kong.Node, flags *RootFlags) []mcpToolSpec {
+	if root == nil {
+		return nil
+	}
+	profile, _ := loadBakedSafetyProfile()
+	var out []mcpToolSpec
+	var walk func(*kong.Node)
+	walk = func(node *kong.Node) {
+		for _, child := range node.Children {
+			if child == nil || child.Type != kong.CommandNode || child.Hidden {
+				continue
+			}
+			path := commandNodePath(child)
+			if len(path) > 0 && mcpGeneratedSkipRoots[path[0]] {
+				continue
+			}
+			if hasCommandChildren(child) {
+				walk(child)
+				continue
+			}
+			// Top-level leaves are shortcuts for commands generated elsewhere.
+			if len(path) < 2 || mcpGeneratedSkipLeaves[path[len(path)-1]] || mcpGeneratedSkipCommands[strings.Join(path, " ")] || child.Passthrough {
+				continue
+			}
+			if !profile.allowsCommandPath(path) || !mcpCommandRulesAllow(flags, path) {
+				continue
+			}
+			if spec, ok := mcpGeneratedTool(child, path); ok {
+				out = append(out, spec)
+			}
+		}
+	}
+	walk(root)
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+func hasCommandChildren(node *kong.Node) bool {
+	for _, child := range node.Children {
+		if child != nil && child.Type == kong.CommandNode {
+			return true
+		}
+	}
+	return false
+}
+
+func mcpCommandRulesAllow(flags *RootFlags, path []string) bool {
+	if flags == nil {
+		return true
+	}
+	allow := parseEnabledCommands(flags.EnableCommands)
+	exact := parseEnabledCommands(flags.EnableCommandsExact)
+	if (len(allow) > 0 || len(exact) > 0) && !commandPathMatches(allow, path) && !commandPathMatchesExact(exact, path) {
+		return false
+	}
+	deny := parseEnabledCommands(flags.DisableCommands)
+	return len(deny) == 0 || !commandPathMatches(deny, path)
+}
+
+// mcpCommandRisk classifies a command path with the readonly profile rules.
+// Anything the profile does not explicitly allow is a write.
+func mcpCommandRisk(path []string) mcpToolRisk {
+	if commandPathMatches(mcpWriteCommandRules, path) {
+		return mcpRiskWrite
+	}
+	if commandPathMatches(mcpReadCommandRules, path) {
+		return mcpRiskRead
+	}
+	return mcpRiskWrite
+}
+
+func mcpGeneratedTool(node *kong.Node, path []string) (mcpToolSpec, bool) {
+	var options []mcp.ToolOption
+	var args []mcpGeneratedArg
+	seen := map[string]bool{}
+	add := func(value *kong.Value, flagName string, positional bool) bool {
+		name := value.Name
+		property := mcpPropertyName(name)
+		if mcpLocalPathArgs[mcpKebabName(name)] || strings.HasSuffix(name, "-file") ||
+			(value.Tag != nil && mcpLocalPathType(value.Tag.Type)) {
+			return !value.Required
+		}
+		kind, ok := mcpSchemaKind(value.Target)
+		if !ok || seen[property] {
+			return !value.Required
+		}
+		seen[property] = true
+		props := []mcp.PropertyOption{mcp.Description(strings.TrimSpace(value.Help))}
+		if value.Required {
+			props = append(props, mcp.Required())
+		}
+		if enum := value.EnumSlice(); len(enum) > 0 && kind == "string" {
+			props = append(props, mcp.Enum(enum...))
+		}
+		switch kind {
+		case "boolean":
+			options = append(options, mcp.WithBoolean(property, props...))
+		case "integer":
+			options = append(options, mcp.WithInteger(property, props...))
+		case "number":
+			options = append(options, mcp.WithNumber(property, props...))
+		case "array":
+			options = append(options, mcp.WithArray(property, append(props, mcp.WithStringItems())...))
+		default:
+			options = append(options, mcp.WithString(property, props...))
+		}
+		args = append(args, mcpGeneratedArg{property: property, flag: flagName, positional: positional, kind: kind})
+		return true
+	}
+	for _, positional := range node.Positional {
+		if positional != nil && !add(positional, "", true) {
+			return mcpToolSpec{}, false
+		}
+	}
+	for cur := node; cur != nil && cur.Type == kong.CommandNode; cur = cur.Parent {
+		for _, flag := range cur.Flags {
+			if flag == nil || flag.Hidden {
+				continue
+			}
+			if !add(flag.Value, flag.Name, false) {
+				return mcpToolSpec{}, false
+			}
+		}
+	}
+
+	description := strings.TrimSpace(node.Help)
+	if description == "" {
+		description = "Run gog " + strings.Join(path, " ")
+	}
+	risk := mcpCommandRisk(path)
+	commandPath := append([]string(nil), path...)
+	if risk == mcpRiskRead {
+		// Backstop for profile mistakes: read tools cannot mutate even if the
+		// command turns out to write.
+		commandPath = append([]string{"--readonly"}, commandPath...)
+	}
+	return mcpToolSpec{
+		Name:        mcpPropertyName(strings.Join(path, "_")),
+		Service:     path[0],
+		Risk:        risk,
+		Description: fmt.Sprintf("%s (gog %s)", description, strings.Join(path, " ")),
+		Options:     options,
+		BuildArgs: func(req mcp.CallToolRequest) ([]string, error) {
+			return mcpGeneratedArgs(commandPath, args, req.GetArguments())
+		},
+	}, true
+}
+
+// mcpGeneratedArgs renders flags as --name=value so values are never parsed as
+// flags, and places positionals after "--".
+func mcpGeneratedArgs(path []string, specs []mcpGeneratedArg, values map[string]any) ([]string, error) {
+	out := append([]string(nil), path...)
+	var positionals []string
+	for _, spec := range specs {
+		raw, ok := values[spec.property]
+		if !ok || raw == nil {
+			continue
+		}
+		rendered, err := mcpRenderValues(spec, raw)
+		if err != nil {
+			return nil, err
+		}
+		if spec.positional {
+			positionals = append(positionals, rendered...)
+			continue
+		}
+		for _, value := range rendered {
+			out = append(out, "--"+spec.flag+"="+value)
+		}
+	}
+	if len(positionals) > 0 {
+		out = append(out, "--")
+		out = append(out, positionals...)
+	}
+	return out, nil
+}
+
+func mcpRenderValues(spec mcpGeneratedArg, raw any) ([]string, error) {
+	items := []any{raw}
+	if spec.kind == "array" {
+		list, ok := raw.([]any)
+		if !ok {
+			return nil, fmt.Errorf("%s must be an array", spec.property)
+		}
+		items = list
+	}
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		var value string
+		switch typed := item.(type) {
+		case string:
+			if typed == "-" || strings.HasPrefix(typed, "@") {
+				return nil, fmt.Errorf("%s must be a literal value; @file and stdin forms are not allowed", spec.property)
+			}
+			value = typed
+		case bool:
+			value = strconv.FormatBool(typed)
+		case float64:
+			if spec.kind == "integer" && typed != float64(int64(typed)) {
+				return nil, fmt.Errorf("%s must be an integer", spec.property)
+			}
+			value = strconv.FormatFloat(typed, 'f', -1, 64)
+		default:
+			value = fmt.Sprint(typed)
+		}
+		out = append(out, value)
+	}
+	return out, nil
+}
+
+func mcpSchemaKind(target reflect.Value) (string, bool) {
+	if !target.IsValid() {
+		return "", false
+	}
+	typ := target.Type()
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == durationType {
+		return "string", true
+	}
+	switch typ.Kind() {
+	case reflect.Bool:
+		return "boolean", true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer", true
+	case reflect.Float32, reflect.Float64:
+		return "number", true
+	case reflect.String:
+		return "string", true
+	case reflect.Slice:
+		if typ.Elem().Kind() == reflect.String {
+			return "array", true
+		}
+	}
+	return "", false
+}
+
+func mcpLocalPathType(kind string) bool {
+	switch kind {
+	case "path", "existingfile", "existingdir", "filecontent":
+		return true
+	default:
+		return false
+	}
+}
+
+// mcpKebabName turns camelCase or kebab-case names into kebab-case.
+func mcpKebabName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('-')
+			}
+			r = unicode.ToLower(r)
+		}
+		if r == '_' {
+			r = '-'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func mcpPropertyName(name string) string {
+	return strings.ReplaceAll(mcpKebabName(name), "-", "_")
+}
@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/steipete/gogcli/internal/config"
+	"github.com/steipete/gogcli/internal/ui"
+)
+
+const (
+	mcpHTTPPath        = "/mcp"
+	mcpSSEPath         = "/sse"
+	mcpSSEMessagePath  = "/message"
+	mcpAccountHeader   = "X-Gog-Account"
+	mcpHTTPReadTimeout = 10 * time.Second
+	mcpShutdownTimeout = 5 * time.Second
+)
+
+type mcpAccountContextKey struct{}
+
+type mcpHTTPOptions struct {
+	listen   string
+	token    string
+	accounts map[string]string
+}
+
+// httpOptions validates the --listen settings. Every HTTP request must carry
+// the bearer token, and X-Gog-Account may only pick the server account or one
+// listed with --allow-account.
+func (c *McpCmd) httpOptions(flags *RootFlags) (mcpHTTPOptions, error) {
+	listen := strings.TrimSpace(c.Listen)
+	if listen == "" {
+		if strings.TrimSpace(c.Token) != "" || strings.TrimSpace(c.TokenFile) != "" || len(c.AllowAccount) > 0 {
+			return mcpHTTPOptions{}, usage("--token, --token-file, and --allow-account require --listen")
+		}
+		return mcpHTTPOptions{}, nil
+	}
+	if _, _, err := net.SplitHostPort(listen); err != nil {
+		return mcpHTTPOptions{}, usagef("invalid --listen %q: %v", listen, err)
+	}
+	token, err := c.resolveToken()
+	if err != nil {
+		return mcpHTTPOptions{}, err
+	}
+	accounts := map[string]string{}
+	if flags != nil {
+		if account := strings.TrimSpace(flags.Account); account != "" {
+			accounts[strings.ToLower(account)] = account
+		}
+	}
+	allowed := splitCommaValues(c.AllowAccount)
+	if len(allowed) > 0 && flags != nil && strings.TrimSpace(flags.AccessToken) != "" {
+		return mcpHTTPOptions{}, usage("--allow-account cannot be combined with --access-token")
+	}
+	for _, account := range allowed {
+		accounts[strings.ToLower(account)] = account
+	}
+	return mcpHTTPOptions{listen: listen, token: token, accounts: accounts}, nil
+}
+
+func (c *McpCmd) resolveToken() (string, error) {
+	direct := strings.TrimSpace(c.Token)
+	tokenFile := strings.TrimSpace(c.TokenFile)
+	if direct != "" && tokenFile != "" {
+		return "", usage("provide only one of --token or --token-file")
+	}
+	if tokenFile != "" {
+		path, err := config.ExpandPath(tokenFile)
+		if err != nil {
+			return "", fmt.Errorf("expand --token-file: %w", err)
+		}
+		raw, err := os.ReadFile(path) //nolint:gosec // explicit operator-provided secret file.
+		if err != nil {
+			return "", fmt.Errorf("read --token-file: %w", err)
+		}
+		direct = strings.TrimSpace(string(raw))
+	}
+	if direct == "" {
+		return "", usage("--listen requires --token, --token-file, or GOG_MCP_TOKEN")
+	}
+	return direct, nil
+}
+
+// newMCPHTTPHandler serves streamable HTTP on /mcp and the legacy SSE
+// transport on /sse and /message from the same MCP server.
+func newMCPHTTPHandler(s *server.MCPServer, opts mcpHTTPOptions) http.Handler {
+	streamable := server.NewStreamableHTTPServer(s, server.WithEndpointPath(mcpHTTPPath))
+	sse := server.NewSSEServer(s,
+		server.WithSSEEndpoint(mcpSSEPath),
+		server.WithMessageEndpoint(mcpSSEMessagePath),
+		server.WithUseFullURLForMessageEndpoint(false),
+	)
+	mux := http.NewServeMux()
+	mux.Handle(mcpHTTPPath, streamable)
+	mux.Handle(mcpSSEPath, sse.SSEHandler())
+	mux.Handle(mcpSSEMessagePath, sse.MessageHandler())
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !mcpBearerTokenMatches(r, opts.token) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="gog"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		requested := strings.TrimSpace(r.Header.Get(mcpAccountHeader))
+		if requested != "" {
+			account, ok := opts.accounts[strings.ToLower(requested)]
+			if !ok {
+				http.Error(w, "account not allowed", http.StatusForbidden)
+				return
+			}
+			r = r.WithContext(context.WithValue(r.Context(), mcpAccountContextKey{}, account))
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func mcpBearerTokenMatches(r *http.Request, expected string) bool {
+	if expected == "" {
+		return false
+	}
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return false
+	}
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1
+}
+
+// mcpRootArgsForRequest returns the child root args, swapping in the account
+// selected by the HTTP connection when there is one.
+func mcpRootArgsForRequest(ctx context.Context, flags *RootFlags) []string {
+	account, ok := ctx.Value(mcpAccountContextKey{}).(string)
+	if !ok || account == "" {
+		return mcpParentRootArgs(flags)
+	}
+	scoped := RootFlags{}
+	if flags != nil {
+		scoped = *flags
+	}
+	scoped.Account = account
+	return mcpParentRootArgs(&scoped)
+}
+
+func serveMCPHTTP(ctx context.Context, s *server.MCPServer, opts mcpHTTPOptions) error {
+	listener, err := (&net.ListenConfig{}).Listen(ctx, "tcp", opts.listen)
+	if err != nil {
+		return fmt.Errorf("listen %s: %w", opts.listen, err)
+	}
+	httpServer := &http.Server{
+		Handler:           newMCPHTTPHandler(s, opts),
+		ReadHeaderTimeout: mcpHTTPReadTimeout,
+	}
+	u := ui.FromContext(ctx)
+	u.Err().Linef("mcp: listening on http://%s%s (sse: %s)", listener.Addr(), mcpHTTPPath, mcpSSEPath)
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), mcpShutdownTimeout)
+			defer cancel()
+			_ = httpServer.Shutdown(shutdownCtx)
+		case <-done:
+		}
+	}()
+	if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("serve MCP: %w", err)
+	}
+	return nil
+}
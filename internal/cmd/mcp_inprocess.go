@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/steipete/gogcli/internal/app"
+)
+
+// mcpInProcessExecutor runs tool commands inside the server process instead of
+// re-executing gog for every call. Building the Kong model dominates startup,
+// so parsers are pooled; Google API clients and the opened keyring are shared
+// so each account's token is resolved and refreshed once per server.
+//
+// Calls go through the same parse and safety checks as a child process: the
+// argv still carries the server's safety flags, baked profile, and --readonly
+// backstop.
+type mcpInProcessExecutor struct {
+	description string
+	services    *googleServiceCache
+	secrets     *secretsStoreCache
+
+	mu      sync.Mutex
+	parsers []*reusableParser
+}
+
+func newMCPInProcessExecutor() *mcpInProcessExecutor {
+	return &mcpInProcessExecutor{
+		description: baseDescription(),
+		services:    newGoogleServiceCache(),
+		secrets:     &secretsStoreCache{},
+	}
+}
+
+func (e *mcpInProcessExecutor) getParser() (*reusableParser, error) {
+	e.mu.Lock()
+	if n := len(e.parsers); n > 0 {
+		parser := e.parsers[n-1]
+		e.parsers = e.parsers[:n-1]
+		e.mu.Unlock()
+		return parser, nil
+	}
+	e.mu.Unlock()
+	return newReusableParser(e.description)
+}
+
+func (e *mcpInProcessExecutor) putParser(parser *reusableParser) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.parsers = append(e.parsers, parser)
+}
+
+// run executes one command and returns its bounded output and exit code, like
+// mcpRunGog. A run that outlives the timeout is abandoned with exit code 124;
+// its parser is dropped rather than returned to the pool.
+func (e *mcpInProcessExecutor) run(reqCtx context.Context, opts mcpRunOptions) (string, string, int) {
+	ctx, cancel := context.WithTimeout(reqCtx, opts.timeout)
+	defer cancel()
+
+	parser, err := e.getParser()
+	if err != nil {
+		return "", fmt.Sprintf("build parser: %v", err), 1
+	}
+
+	args := make([]string, 0, len(opts.baseArgs)+len(opts.commandArgs)+len(opts.safetySuffix)+1)
+	args = append(args, opts.baseArgs...)
+	if token := strings.TrimSpace(opts.accessToken); token != "" {
+		// No argv is visible to other processes here, unlike the child path.
+		args = append(args, "--access-token="+token)
+	}
+	args = append(args, opts.safetySuffix...)
+	args = append(args, opts.commandArgs...)
+
+	stdoutBuf := newMCPLimitedBuffer(opts.maxOutputBytes)
+	stderrBuf := newMCPLimitedBuffer(opts.maxOutputBytes)
+	runtime := newDefaultRuntime()
+	runtime.IO = app.IO{In: strings.NewReader(""), Out: &stdoutBuf, Err: &stderrBuf}
+
+	done := make(chan int, 1)
+	go func() {
+		exitCode := 1
+		defer func() {
+			if r := recover(); r != nil {
+				_, _ = fmt.Fprintf(&stderrBuf, "internal error: %v\n", r)
+				done <- 1
+				return
+			}
+			done <- exitCode
+		}()
+		exitCode = ExitCode(executeWithOptions(ctx, args, runtime, executeOptions{
+			parser:       parser,
+			services:     e.services,
+			secrets:      e.secrets,
+			sharedLogger: true,
+		}))
+	}()
+
+	select {
+	case exitCode := <-done:
+		e.putParser(parser)
+		if ctx.Err() == context.DeadlineExceeded {
+			exitCode = 124
+		}
+		return stdoutBuf.String(), stderrBuf.String(), exitCode
+	case <-ctx.Done():
+		if ctx.Err() == context.DeadlineExceeded {
+			return "", fmt.Sprintf("timed out after %s", opts.timeout), 124
+		}
+		return "", ctx.Err().Error(), 1
+	}
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMCPInProcessExecutorReusesParserAndEnforcesSafety(t *testing.T) {
+	executor := newMCPInProcessExecutor()
+	opts := mcpRunOptions{
+		baseArgs:       []string{"--json", "--no-input", "--color=never"},
+		commandArgs:    []string{"time", "now", "--timezone=UTC"},
+		timeout:        10 * time.Second,
+		maxOutputBytes: 4096,
+		inProcess:      executor,
+	}
+
+	stdout, stderr, exitCode := mcpRunGog(t.Context(), opts)
+	if exitCode != 0 || !strings.Contains(stdout, `"timezone": "UTC"`) {
+		t.Fatalf("first run exit=%d stdout=%q stderr=%q", exitCode, stdout, stderr)
+	}
+	if len(executor.parsers) != 1 {
+		t.Fatalf("parser not returned to pool: %d", len(executor.parsers))
+	}
+
+	// The pooled parser must not carry --timezone into the next run.
+	opts.commandArgs = []string{"time", "now"}
+	stdout, _, exitCode = mcpRunGog(t.Context(), opts)
+	if exitCode != 0 || strings.Contains(stdout, `"timezone": "UTC"`) {
+		t.Fatalf("second run exit=%d stdout=%q", exitCode, stdout)
+	}
+
+	opts.safetySuffix = []string{"--disable-commands", "time"}
+	_, stderr, exitCode = mcpRunGog(t.Context(), opts)
+	if exitCode == 0 || !strings.Contains(stderr, "disabled") {
+		t.Fatalf("safety suffix not enforced: exit=%d stderr=%q", exitCode, stderr)
+	}
+	if len(executor.parsers) != 1 {
+		t.Fatalf("parser pool grew for sequential runs: %d", len(executor.parsers))
+	}
+}
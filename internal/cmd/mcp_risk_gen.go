@@ -0,0 +1,309 @@
+// Code generated by cmd/gen-mcp-risk; DO NOT EDIT.
+// Source: safety-profiles/readonly.yaml
+
+package cmd
+
+var mcpReadCommandRules = map[string]bool{
+	"appscript.content":           true,
+	"appscript.get":               true,
+	"auth.alias.list":             true,
+	"auth.credentials.list":       true,
+	"auth.doctor":                 true,
+	"auth.list":                   true,
+	"auth.service-account.status": true,
+	"auth.services":               true,
+	"auth.status":                 true,
+	"auth.tokens.list":            true,
+	"calendar.acl":                true,
+	"calendar.alias.list":         true,
+	"calendar.calendars":          true,
+	"calendar.colors":             true,
+	"calendar.conflicts":          true,
+	"calendar.event":              true,
+	"calendar.events":             true,
+	"calendar.export":             true,
+	"calendar.freebusy":           true,
+	"calendar.search":             true,
+	"calendar.stats":              true,
+	"calendar.team":               true,
+	"calendar.time":               true,
+	"calendar.users":              true,
+	"chat.messages.list":          true,
+	"chat.spaces.find":            true,
+	"chat.spaces.list":            true,
+	"chat.threads.list":           true,
+	"config.get":                  true,
+	"config.keys":                 true,
+	"config.list":                 true,
+	"config.no-send.list":         true,
+	"config.path":                 true,
+	"contacts.directory.list":     true,
+	"contacts.directory.search":   true,
+	"contacts.export":             true,
+	"contacts.get":                true,
+	"contacts.list":               true,
+	"contacts.other.list":         true,
+	"contacts.other.search":       true,
+	"contacts.search":             true,
+	"docs.cat":                    true,
+	"docs.comments.get":           true,
+	"docs.comments.list":          true,
+	"docs.export":                 true,
+	"docs.info":                   true,
+	"docs.list-tabs":              true,
+	"docs.named-range.list":       true,
+	"docs.structure":              true,
+	"download":                    true,
+	"drive.comments.get":          true,
+	"drive.comments.list":         true,
+	"drive.download":              true,
+	"drive.drives":                true,
+	"drive.get":                   true,
+	"drive.ls":                    true,
+	"drive.permissions":           true,
+	"drive.search":                true,
+	"drive.url":                   true,
+	"forms.get":                   true,
+	"forms.responses.get":         true,
+	"forms.responses.list":        true,
+	"gmail.attachment":            true,
+	"gmail.drafts.get":            true,
+	"gmail.drafts.list":           true,
+	"gmail.export":                true,
+	"gmail.get":                   true,
+	"gmail.history":               true,
+	"gmail.labels.get":            true,
+	"gmail.labels.list":           true,
+	"gmail.messages.search":       true,
+	"gmail.scheduled.list":        true,
+	"gmail.search":                true,
+	"gmail.subscriptions.list":    true,
+	"gmail.thread.attachments":    true,
+	"gmail.thread.get":            true,
+	"gmail.url":                   true,
+	"groups.list":                 true,
+	"groups.members":              true,
+	"keep.attachment":             true,
+	"keep.get":                    true,
+	"keep.list":                   true,
+	"keep.search":                 true,
+	"ls":                          true,
+	"me":                          true,
+	"open":                        true,
+	"people.get":                  true,
+	"people.me":                   true,
+	"people.relations":            true,
+	"people.search":               true,
+	"schema":                      true,
+	"search":                      true,
+	"sheets.chart.get":            true,
+	"sheets.chart.list":           true,
+	"sheets.export":               true,
+	"sheets.get":                  true,
+	"sheets.links.get":            true,
+	"sheets.metadata":             true,
+	"sheets.named-ranges.get":     true,
+	"sheets.named-ranges.list":    true,
+	"sheets.notes":                true,
+	"sheets.read-format":          true,
+	"sheets.validation.get":       true,
+	"sites.get":                   true,
+	"sites.list":                  true,
+	"sites.search":                true,
+	"sites.url":                   true,
+	"slides.export":               true,
+	"slides.info":                 true,
+	"slides.list-slides":          true,
+	"slides.read-slide":           true,
+	"slides.thumbnail":            true,
+	"status":                      true,
+	"tasks.get":                   true,
+	"tasks.list":                  true,
+	"tasks.lists.list":            true,
+	"time":                        true,
+	"version":                     true,
+	"whoami":                      true,
+}
+
+var mcpWriteCommandRules = map[string]bool{
+	"__complete":                      true,
+	"admin":                           true,
+	"appscript.create":                true,
+	"appscript.run":                   true,
+	"auth.add":                        true,
+	"auth.alias.set":                  true,
+	"auth.alias.unset":                true,
+	"auth.credentials.remove":         true,
+	"auth.credentials.set":            true,
+	"auth.keep":                       true,
+	"auth.keyring":                    true,
+	"auth.manage":                     true,
+	"auth.remove":                     true,
+	"auth.service-account.set":        true,
+	"auth.service-account.unset":      true,
+	"auth.tokens.delete":              true,
+	"auth.tokens.export":              true,
+	"auth.tokens.import":              true,
+	"backup":                          true,
+	"calendar.alias.set":              true,
+	"calendar.alias.unset":            true,
+	"calendar.create":                 true,
+	"calendar.create-calendar":        true,
+	"calendar.delete":                 true,
+	"calendar.find-slot":              true,
+	"calendar.focus-time":             true,
+	"calendar.import":                 true,
+	"calendar.mirror":                 true,
+	"calendar.move":                   true,
+	"calendar.out-of-office":          true,
+	"calendar.propose-time":           true,
+	"calendar.respond":                true,
+	"calendar.subscribe":              true,
+	"calendar.update":                 true,
+	"calendar.watch":                  true,
+	"calendar.working-location":       true,
+	"chat.dm.send":                    true,
+	"chat.dm.space":                   true,
+	"chat.messages.react":             true,
+	"chat.messages.reactions":         true,
+	"chat.messages.send":              true,
+	"chat.spaces.create":              true,
+	"classroom":                       true,
+	"completion":                      true,
+	"config.no-send.remove":           true,
+	"config.no-send.set":              true,
+	"config.set":                      true,
+	"config.unset":                    true,
+	"contacts.create":                 true,
+	"contacts.delete":                 true,
+	"contacts.other.delete":           true,
+	"contacts.update":                 true,
+	"docs.clear":                      true,
+	"docs.comments.add":               true,
+	"docs.comments.delete":            true,
+	"docs.comments.reply":             true,
+	"docs.comments.resolve":           true,
+	"docs.copy":                       true,
+	"docs.create":                     true,
+	"docs.delete":                     true,
+	"docs.edit":                       true,
+	"docs.find-replace":               true,
+	"docs.insert":                     true,
+	"docs.named-range.create":         true,
+	"docs.named-range.delete":         true,
+	"docs.named-range.replace":        true,
+	"docs.sed":                        true,
+	"docs.table-column.delete":        true,
+	"docs.table-column.insert":        true,
+	"docs.table-merge":                true,
+	"docs.table-row.delete":           true,
+	"docs.table-row.insert":           true,
+	"docs.table-unmerge":              true,
+	"docs.update":                     true,
+	"docs.write":                      true,
+	"drive.comments.create":           true,
+	"drive.comments.delete":           true,
+	"drive.comments.reply":            true,
+	"drive.comments.update":           true,
+	"drive.copy":                      true,
+	"drive.delete":                    true,
+	"drive.mkdir":                     true,
+	"drive.move":                      true,
+	"drive.rename":                    true,
+	"drive.share":                     true,
+	"drive.unshare":                   true,
+	"drive.upload":                    true,
+	"forms.add-question":              true,
+	"forms.create":                    true,
+	"forms.delete-question":           true,
+	"forms.move-question":             true,
+	"forms.publish":                   true,
+	"forms.update":                    true,
+	"forms.watch":                     true,
+	"gmail.archive":                   true,
+	"gmail.autoforward":               true,
+	"gmail.autoreply":                 true,
+	"gmail.batch.delete":              true,
+	"gmail.batch.modify":              true,
+	"gmail.delegates":                 true,
+	"gmail.drafts.create":             true,
+	"gmail.drafts.delete":             true,
+	"gmail.drafts.send":               true,
+	"gmail.drafts.update":             true,
+	"gmail.filters":                   true,
+	"gmail.forward":                   true,
+	"gmail.forwarding":                true,
+	"gmail.import":                    true,
+	"gmail.labels.create":             true,
+	"gmail.labels.delete":             true,
+	"gmail.labels.modify":             true,
+	"gmail.labels.rename":             true,
+	"gmail.labels.style":              true,
+	"gmail.mark-read":                 true,
+	"gmail.merge":                     true,
+	"gmail.messages.modify":           true,
+	"gmail.scheduled.cancel":          true,
+	"gmail.scheduled.run":             true,
+	"gmail.send":                      true,
+	"gmail.sendas":                    true,
+	"gmail.settings":                  true,
+	"gmail.subscriptions.unsubscribe": true,
+	"gmail.thread.modify":             true,
+	"gmail.track":                     true,
+	"gmail.trash":                     true,
+	"gmail.unread":                    true,
+	"gmail.vacation":                  true,
+	"gmail.watch":                     true,
+	"keep.create":                     true,
+	"keep.delete":                     true,
+	"login":                           true,
+	"logout":                          true,
+	"send":                            true,
+	"sheets.add-tab":                  true,
+	"sheets.append":                   true,
+	"sheets.batch-update":             true,
+	"sheets.chart.create":             true,
+	"sheets.chart.delete":             true,
+	"sheets.chart.update":             true,
+	"sheets.clear":                    true,
+	"sheets.copy":                     true,
+	"sheets.create":                   true,
+	"sheets.delete-dimension":         true,
+	"sheets.delete-tab":               true,
+	"sheets.find-replace":             true,
+	"sheets.format":                   true,
+	"sheets.freeze":                   true,
+	"sheets.insert":                   true,
+	"sheets.links.set":                true,
+	"sheets.merge":                    true,
+	"sheets.named-ranges.add":         true,
+	"sheets.named-ranges.delete":      true,
+	"sheets.named-ranges.update":      true,
+	"sheets.number-format":            true,
+	"sheets.rename-tab":               true,
+	"sheets.resize-columns":           true,
+	"sheets.resize-rows":              true,
+	"sheets.unmerge":                  true,
+	"sheets.update":                   true,
+	"sheets.update-note":              true,
+	"sheets.validation.clear":         true,
+	"sheets.validation.set":           true,
+	"slides.add-slide":                true,
+	"slides.copy":                     true,
+	"slides.create":                   true,
+	"slides.create-from-markdown":     true,
+	"slides.create-from-template":     true,
+	"slides.delete-slide":             true,
+	"slides.insert-text":              true,
+	"slides.replace-slide":            true,
+	"slides.replace-text":             true,
+	"slides.update-notes":             true,
+	"tasks.add":                       true,
+	"tasks.clear":                     true,
+	"tasks.delete":                    true,
+	"tasks.done":                      true,
+	"tasks.lists.create":              true,
+	"tasks.undo":                      true,
+	"tasks.update":                    true,
+	"upload":                          true,
+}
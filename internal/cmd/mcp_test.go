@@ -4,11 +4,18 @@ import (
 	"bytes"
 	"context"
 	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"strings"
 	"testing"
 
 	mcpclient "github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	"github.com/steipete/gogcli/internal/safetyprofile"
 )
 
 func TestMCPEnabledToolsDefaultReadOnly(t *testing.T) {
@@ -45,7 +52,7 @@ func TestMCPListToolsUsesRuntimeStdout(t *testing.T) {
 		ListTools:      true,
 		TimeoutSeconds: 60,
 		MaxOutputBytes: 1024,
-	}).Run(newCmdRuntimeOutputContext(t, &output, io.Discard), &RootFlags{})
+	}).Run(newCmdRuntimeOutputContext(t, &output, io.Discard), nil, &RootFlags{})
 	if err != nil {
 		t.Fatalf("Run: %v", err)
 	}
@@ -318,6 +325,110 @@ func TestMCPSheetsUpdateRejectsTrailingJSON(t *testing.T) {
 	}
 }
 
+func TestMCPListenRequiresToken(t *testing.T) {
+	t.Setenv("GOG_MCP_TOKEN", "")
+	_, err := (&McpCmd{Listen: "127.0.0.1:0"}).httpOptions(&RootFlags{})
+	if err == nil || !strings.Contains(err.Error(), "--token") {
+		t.Fatalf("expected token error, got %v", err)
+	}
+	_, err = (&McpCmd{AllowAccount: []string{"b@example.com"}}).httpOptions(&RootFlags{})
+	if err == nil || !strings.Contains(err.Error(), "require --listen") {
+		t.Fatalf("expected --listen error, got %v", err)
+	}
+	_, err = (&McpCmd{Listen: "127.0.0.1:0", Token: "secret", AllowAccount: []string{"b@example.com"}}).httpOptions(&RootFlags{AccessToken: "ya29"})
+	if err == nil || !strings.Contains(err.Error(), "--access-token") {
+		t.Fatalf("expected access token error, got %v", err)
+	}
+}
+
+func TestMCPHTTPRejectsMissingTokenAndUnknownAccount(t *testing.T) {
+	opts, err := (&McpCmd{Listen: "127.0.0.1:0", Token: "secret"}).httpOptions(&RootFlags{Account: "a@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(newMCPHTTPHandler(newMCPServer(), opts))
+	t.Cleanup(srv.Close)
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    int
+	}{
+		{name: "missing token", want: http.StatusUnauthorized},
+		{name: "wrong token", headers: map[string]string{"Authorization": "Bearer nope"}, want: http.StatusUnauthorized},
+		{name: "gog token header", headers: map[string]string{"X-Gog-Token": "secret"}, want: http.StatusUnauthorized},
+		{name: "unknown account", headers: map[string]string{"Authorization": "Bearer secret", "X-Gog-Account": "b@example.com"}, want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodPost, srv.URL+mcpHTTPPath, strings.NewReader(`{}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_ = resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
+
+func TestMCPHTTPSelectsAccountPerConnection(t *testing.T) {
+	flags := &RootFlags{Account: "a@example.com"}
+	opts, err := (&McpCmd{Listen: "127.0.0.1:0", Token: "secret", AllowAccount: []string{"b@example.com"}}).httpOptions(flags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := newMCPServer()
+	s.AddTool(mcp.NewTool("whoami"), func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(strings.Join(mcpRootArgsForRequest(ctx, flags), " ")), nil
+	})
+	srv := httptest.NewServer(newMCPHTTPHandler(s, opts))
+	t.Cleanup(srv.Close)
+
+	for _, tt := range []struct {
+		account string
+		want    string
+	}{
+		{account: "", want: "--account a@example.com"},
+		{account: "B@example.com", want: "--account b@example.com"},
+	} {
+		headers := map[string]string{"Authorization": "Bearer secret"}
+		if tt.account != "" {
+			headers[mcpAccountHeader] = tt.account
+		}
+		client, err := mcpclient.NewStreamableHttpClient(srv.URL+mcpHTTPPath, transport.WithHTTPHeaders(headers))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = client.Close() })
+		if err := client.Start(t.Context()); err != nil {
+			t.Fatal(err)
+		}
+		initRequest := mcp.InitializeRequest{}
+		initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+		initRequest.Params.ClientInfo = mcp.Implementation{Name: "gog-test", Version: "1"}
+		if _, err := client.Initialize(t.Context(), initRequest); err != nil {
+			t.Fatal(err)
+		}
+		result, err := client.CallTool(t.Context(), mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "whoami"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := mcpResultText(result); !strings.Contains(got, tt.want) {
+			t.Fatalf("account %q: args = %q, want %q", tt.account, got, tt.want)
+		}
+	}
+}
+
 func TestMCPLimitedBufferCapsDuringWrite(t *testing.T) {
 	buf := newMCPLimitedBuffer(5)
 	n, err := buf.Write([]byte("hello world"))
@@ -370,3 +481,252 @@ func mcpResultText(result *mcp.CallToolResult) string {
 	}
 	return text.String()
 }
+
+func TestMCPRiskRulesMatchReadonlyProfile(t *testing.T) {
+	raw, err := os.ReadFile("../../safety-profiles/readonly.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	profile, err := safetyprofile.Parse(string(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(profile.AllowRules) != len(mcpReadCommandRules) || len(profile.DenyRules) != len(mcpWriteCommandRules) {
+		t.Fatal("mcp_risk_gen.go is stale; run make mcp-risk")
+	}
+	for _, rule := range profile.AllowRules {
+		if !mcpReadCommandRules[rule] {
+			t.Fatalf("read rule %q missing; run make mcp-risk", rule)
+		}
+	}
+	for _, rule := range profile.DenyRules {
+		if !mcpWriteCommandRules[rule] {
+			t.Fatalf("write rule %q missing; run make mcp-risk", rule)
+		}
+	}
+}
+
+func TestMCPGeneratedToolsClassifyRiskAndRespectCommandRules(t *testing.T) {
+	parser, _, err := newParser("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tools := mcpGeneratedTools(parser.Model.Node, &RootFlags{})
+	list := findGeneratedTool(t, tools, "tasks_list")
+	add := findGeneratedTool(t, tools, "tasks_add")
+	if list.Risk != mcpRiskRead || add.Risk != mcpRiskWrite {
+		t.Fatalf("risk: tasks_list=%s tasks_add=%s", list.Risk, add.Risk)
+	}
+	for _, name := range []string{"mcp", "auth_add", "config_set", "api_call", "gmail_watch_serve", "drive_upload", "gmail_import", "calendar_mirror", "gmail_settings_watch_supervise", "gmail_settings_watch_secret_show", "gmail_settings_watch_secret_rotate"} {
+		if hasMCPTool(tools, name) {
+			t.Fatalf("generated tool %s should be excluded", name)
+		}
+	}
+	merge := newMCPTool(findGeneratedTool(t, tools, "gmail_merge"))
+	for _, property := range []string{"csv", "attach_column"} {
+		if _, ok := merge.InputSchema.Properties[property]; ok {
+			t.Fatalf("gmail_merge exposes local path arg %s", property)
+		}
+	}
+
+	filtered := mcpGeneratedTools(parser.Model.Node, &RootFlags{DisableCommands: "tasks.add"})
+	if hasMCPTool(filtered, "tasks_add") || !hasMCPTool(filtered, "tasks_list") {
+		t.Fatalf("--disable-commands not applied: %v", toolNames(filtered))
+	}
+	enabled := mcpGeneratedTools(parser.Model.Node, &RootFlags{EnableCommands: "calendar"})
+	for _, tool := range enabled {
+		if tool.Service != "calendar" {
+			t.Fatalf("--enable-commands leaked %s", tool.Name)
+		}
+	}
+}
+
+func TestMCPGeneratedToolBuildsArgs(t *testing.T) {
+	parser, _, err := newParser("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tools := mcpGeneratedTools(parser.Model.Node, &RootFlags{})
+	args, err := findGeneratedTool(t, tools, "tasks_add").BuildArgs(mcp.CallToolRequest{Params: mcp.CallToolParams{
+		Arguments: map[string]any{"tasklist_id": "--list", "title": "Buy milk", "repeat_count": float64(3)},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := strings.Join(args, " ")
+	for _, want := range []string{"tasks add ", "--title=Buy milk", "--repeat-count=3", "-- --list"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("args = %#v, missing %q", args, want)
+		}
+	}
+	args, err = findGeneratedTool(t, tools, "tasks_list").BuildArgs(mcp.CallToolRequest{Params: mcp.CallToolParams{
+		Arguments: map[string]any{"tasklist_id": "list1"},
+	}})
+	if err != nil || args[0] != "--readonly" {
+		t.Fatalf("read tool args = %#v err=%v", args, err)
+	}
+	_, err = findGeneratedTool(t, tools, "tasks_add").BuildArgs(mcp.CallToolRequest{Params: mcp.CallToolParams{
+		Arguments: map[string]any{"tasklist_id": "list1", "notes": "@/etc/passwd"},
+	}})
+	if err == nil || !strings.Contains(err.Error(), "literal value") {
+		t.Fatalf("expected @file rejection, got %v", err)
+	}
+}
+
+func findGeneratedTool(t *testing.T, tools []mcpToolSpec, name string) mcpToolSpec {
+	t.Helper()
+	for _, tool := range tools {
+		if tool.Name == name {
+			return tool
+		}
+	}
+	t.Fatalf("missing generated tool %s in %v", name, toolNames(tools))
+	return mcpToolSpec{}
+}
+
+func TestMCPResourcesBuildReadOnlyCommands(t *testing.T) {
+	type call struct {
+		name string
+		args []string
+		text bool
+	}
+	var calls []call
+	registry := newMCPResourceRegistry(mcpAllResources(), func(_ context.Context, name, _ string, args []string, text bool) (string, error) {
+		calls = append(calls, call{name: name, args: args, text: text})
+		return "ok", nil
+	})
+
+	for _, tt := range []struct {
+		uri      string
+		wantArgs string
+		wantMIME string
+		wantText bool
+	}{
+		{uri: "gdrive://file/f1", wantArgs: "drive get -- f1", wantMIME: "application/json"},
+		{uri: "gmail://thread/t1", wantArgs: "gmail thread get --sanitize-content --full -- t1", wantMIME: "application/json"},
+		{uri: "gdoc://doc1", wantArgs: "docs export --format=md --out=- -- doc1", wantMIME: "text/markdown", wantText: true},
+		{uri: "gcal://team%40example.com/2026-03-04", wantArgs: "calendar events --from=2026-03-04 --to=2026-03-04 --max=250 --all-pages -- team@example.com", wantMIME: "application/json"},
+	} {
+		calls = nil
+		text, mimeType, err := registry.read(t.Context(), tt.uri)
+		if err != nil {
+			t.Fatalf("read %s: %v", tt.uri, err)
+		}
+		if text != "ok" || mimeType != tt.wantMIME || len(calls) != 1 {
+			t.Fatalf("read %s = %q %q calls=%#v", tt.uri, text, mimeType, calls)
+		}
+		if got := strings.Join(calls[0].args, " "); got != tt.wantArgs || calls[0].text != tt.wantText {
+			t.Fatalf("read %s args = %q text=%t, want %q text=%t", tt.uri, got, calls[0].text, tt.wantArgs, tt.wantText)
+		}
+	}
+
+	for _, uri := range []string{"gcal://primary/tomorrow", "gdrive://folder/f1", "https://example.com"} {
+		if _, _, err := registry.read(t.Context(), uri); err == nil {
+			t.Fatalf("read %s: expected error", uri)
+		}
+	}
+}
+
+func TestMCPResourcesAndPromptsFollowAllowTool(t *testing.T) {
+	resources := mcpEnabledResources(McpCmd{AllowTool: []string{"docs"}})
+	if len(resources) != 1 || resources[0].Name != "docs_markdown" {
+		t.Fatalf("resources = %#v", resources)
+	}
+	prompts := mcpEnabledPrompts(McpCmd{AllowTool: []string{"gmail.*"}})
+	if len(prompts) != 1 || prompts[0].Name != "triage_inbox" {
+		t.Fatalf("prompts = %#v", prompts)
+	}
+}
+
+func TestMCPPromptsEmbedCommandOutput(t *testing.T) {
+	var got []string
+	fetch := func(_ context.Context, _, _ string, args []string, _ bool) (string, error) {
+		got = args
+		return `{"items":[]}`, nil
+	}
+
+	req := mcp.GetPromptRequest{}
+	req.Params.Arguments = map[string]string{"max": "500"}
+	result, err := buildMCPTriageInboxPrompt(t.Context(), req, fetch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(got, " ") != "gmail search --max=100 -- in:inbox is:unread newer_than:2d" {
+		t.Fatalf("triage args = %#v", got)
+	}
+	text, ok := result.Messages[0].Content.(mcp.TextContent)
+	if !ok || !strings.Contains(text.Text, `{"items":[]}`) {
+		t.Fatalf("triage prompt = %#v", result.Messages)
+	}
+
+	req.Params.Arguments = map[string]string{}
+	if _, err := buildMCPDocCommentsPrompt(t.Context(), req, fetch); err == nil {
+		t.Fatal("expected missing document_id error")
+	}
+	req.Params.Arguments = map[string]string{"document_id": "doc1", "include_resolved": "true"}
+	if _, err := buildMCPDocCommentsPrompt(t.Context(), req, fetch); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(got, " ") != "docs comments list --all --include-resolved -- doc1" {
+		t.Fatalf("comments args = %#v", got)
+	}
+}
+
+func TestMCPResourceWatcherNotifiesOnChange(t *testing.T) {
+	version := "v1"
+	registry := newMCPResourceRegistry(mcpAllResources(), func(context.Context, string, string, []string, bool) (string, error) {
+		return version, nil
+	})
+	watcher := newMCPResourceWatcher(registry)
+	s := newMCPServer(server.WithResourceCapabilities(true, false), server.WithHooks(watcher.hooks()))
+	watcher.server = s
+	registry.register(s)
+
+	session := &fakeMCPSession{id: "s1", notifications: make(chan mcp.JSONRPCNotification, 4)}
+	if err := s.RegisterSession(t.Context(), session); err != nil {
+		t.Fatal(err)
+	}
+	session.Initialize()
+	subscribe := `{"jsonrpc":"2.0","id":1,"method":"resources/subscribe","params":{"uri":"gdrive://file/f1"}}`
+	if resp := s.HandleMessage(s.WithContext(t.Context(), session), []byte(subscribe)); resp == nil {
+		t.Fatal("no subscribe response")
+	} else if _, isErr := resp.(mcp.JSONRPCError); isErr {
+		t.Fatalf("subscribe failed: %#v", resp)
+	}
+
+	watcher.poll(t.Context())
+	watcher.poll(t.Context())
+	if len(session.notifications) != 0 {
+		t.Fatalf("unchanged resource notified: %d", len(session.notifications))
+	}
+	version = "v2"
+	watcher.poll(t.Context())
+	select {
+	case n := <-session.notifications:
+		if n.Method != mcp.MethodNotificationResourceUpdated || n.Params.AdditionalFields["uri"] != "gdrive://file/f1" {
+			t.Fatalf("notification = %#v", n)
+		}
+	default:
+		t.Fatal("expected resources/updated notification")
+	}
+
+	s.UnregisterSession(t.Context(), session.id)
+	version = "v3"
+	watcher.poll(t.Context())
+	if len(session.notifications) != 0 {
+		t.Fatal("unregistered session was notified")
+	}
+}
+
+type fakeMCPSession struct {
+	id            string
+	initialized   bool
+	notifications chan mcp.JSONRPCNotification
+}
+
+func (s *fakeMCPSession) Initialize()       { s.initialized = true }
+func (s *fakeMCPSession) Initialized() bool { return s.initialized }
+func (s *fakeMCPSession) SessionID() string { return s.id }
+func (s *fakeMCPSession) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return s.notifications
+}
@@ -105,7 +105,7 @@ type CLI struct {
 	API           APICmd                `cmd:"" name:"api" help:"Google Discovery APIs and generic method calls"`
 	Config        ConfigCmd             `cmd:"" help:"Manage configuration"`
 	Schema        SchemaCmd             `cmd:"" help:"Machine-readable command/flag schema" aliases:"help-json,helpjson"`
-	Mcp           McpCmd                `cmd:"" name:"mcp" help:"Run a typed, allowlisted MCP server over stdio"`
+	Mcp           McpCmd                `cmd:"" name:"mcp" help:"Run a typed, allowlisted MCP server over stdio or HTTP"`
 	VersionCmd    VersionCmd            `cmd:"" name:"version" help:"Print version"`
 	Completion    CompletionCmd         `cmd:"" help:"Generate shell completion scripts"`
 	Complete      CompletionInternalCmd `cmd:"" name:"__complete" hidden:"" help:"Internal completion helper"`
@@ -117,8 +117,31 @@ func Execute(args []string) (err error) {
 	return executeWithRuntime(args, newDefaultRuntime())
 }
 
-func executeWithRuntime(args []string, runtime *app.Runtime) (err error) {
+func executeWithRuntime(args []string, runtime *app.Runtime) error {
+	return executeWithOptions(context.Background(), args, runtime, executeOptions{})
+}
+
+// executeOptions lets a long-lived host such as the in-process MCP executor
+// run many commands without paying per-run startup costs. The zero value is a
+// normal standalone run.
+type executeOptions struct {
+	// parser is reused instead of building the Kong model again. Kong resets
+	// every flag and argument to its default on each parse.
+	parser *reusableParser
+	// services shares Google API clients across runs.
+	services *googleServiceCache
+	// secrets shares the opened keyring across runs.
+	secrets *secretsStoreCache
+	// sharedLogger keeps the host's slog default; slog.SetDefault is
+	// process-wide and would leak between concurrent runs.
+	sharedLogger bool
+}
+
+func executeWithOptions(baseCtx context.Context, args []string, runtime *app.Runtime, opts executeOptions) (err error) {
 	runtime = normalizedRuntime(runtime)
+	if opts.secrets != nil {
+		opts.secrets.wrap(&runtime.Auth)
+	}
 	runtimeIO := runtime.IO
 
 	if len(args) == 0 {
@@ -136,9 +159,17 @@ func executeWithRuntime(args []string, runtime *app.Runtime) (err error) {
 		}
 	}
 
-	parser, cli, err := newParserWithWriters(helpDescription(runtime), runtimeIO.Out, runtimeIO.Err)
-	if err != nil {
-		return reportEarlyError(runtimeIO.Err, err)
+	var (
+		parser *kong.Kong
+		cli    *CLI
+	)
+	if opts.parser != nil {
+		parser, cli = opts.parser.bind(runtimeIO.Out, runtimeIO.Err)
+	} else {
+		parser, cli, err = newParserWithWriters(helpDescription(runtime), runtimeIO.Out, runtimeIO.Err)
+		if err != nil {
+			return reportEarlyError(runtimeIO.Err, err)
+		}
 	}
 	args = rewriteDocsCellUpdateContentArgs(parser.Model, args)
 	args = rewriteDesirePathArgs(parser.Model, args)
@@ -179,15 +210,17 @@ func executeWithRuntime(args []string, runtime *app.Runtime) (err error) {
 		return reportEarlyError(runtimeIO.Err, err)
 	}
 
-	logLevel := slog.LevelWarn
-	if cli.Verbose {
-		logLevel = slog.LevelDebug
+	if !opts.sharedLogger {
+		logLevel := slog.LevelWarn
+		if cli.Verbose {
+			logLevel = slog.LevelDebug
+		}
+		previousLogger := slog.Default()
+		slog.SetDefault(slog.New(slog.NewTextHandler(runtimeIO.Err, &slog.HandlerOptions{
+			Level: logLevel,
+		})))
+		defer slog.SetDefault(previousLogger)
 	}
-	previousLogger := slog.Default()
-	slog.SetDefault(slog.New(slog.NewTextHandler(runtimeIO.Err, &slog.HandlerOptions{
-		Level: logLevel,
-	})))
-	defer slog.SetDefault(previousLogger)
 
 	// Optional automatic JSON output when stdout is piped/non-TTY.
 	// We intentionally do this after parsing so `--plain` can override it.
@@ -204,8 +237,7 @@ func executeWithRuntime(args []string, runtime *app.Runtime) (err error) {
 		return reportEarlyError(runtimeIO.Err, err)
 	}
 
-	ctx := context.Background()
-	ctx = app.WithRuntime(ctx, runtime)
+	ctx := app.WithRuntime(baseCtx, runtime)
 	ctx = googleapi.WithReadOnly(ctx, cli.ReadOnly)
 	runtimeContext := ctx
 	serviceAccounts := func() (*config.ServiceAccountStore, error) {
@@ -249,6 +281,12 @@ func executeWithRuntime(args []string, runtime *app.Runtime) (err error) {
 		PhotosBaseURL:       os.Getenv("GOG_PHOTOS_BASE_URL"),
 		PhotosPickerBaseURL: os.Getenv("GOG_PHOTOS_PICKER_BASE_URL"),
 	}))
+	if opts.services != nil {
+		opts.services.wrap(&runtime.Services)
+	}
+	ctx = googleapi.WithAuditLogResolver(ctx, func() (*googleapi.AuditLog, error) {
+		return commandAuditLog(runtimeContext)
+	}, strings.Join(commandNodePath(kctx.Selected()), " "))
 	ctx = authclient.WithCredentialsReader(ctx, readCredentials)
 	ctx = authclient.WithSecretsStoreOpener(ctx, openTokens)
 	ctx = authclient.WithEmailReferenceUpdater(ctx, updateEmailReferences)
@@ -501,6 +539,35 @@ func newParserWithWriters(description string, stdout, stderr io.Writer) (*kong.K
 	return parser, cli, nil
 }
 
+// reusableParser is a Kong parser whose writers are rebound for each run. It
+// must not be shared by concurrent runs.
+type reusableParser struct {
+	parser *kong.Kong
+	cli    *CLI
+	stdout *switchWriter
+	stderr *switchWriter
+}
+
+func newReusableParser(description string) (*reusableParser, error) {
+	stdout := &switchWriter{w: io.Discard}
+	stderr := &switchWriter{w: io.Discard}
+	parser, cli, err := newParserWithWriters(description, stdout, stderr)
+	if err != nil {
+		return nil, err
+	}
+	return &reusableParser{parser: parser, cli: cli, stdout: stdout, stderr: stderr}, nil
+}
+
+func (p *reusableParser) bind(stdout, stderr io.Writer) (*kong.Kong, *CLI) {
+	p.stdout.w = stdout
+	p.stderr.w = stderr
+	return p.parser, p.cli
+}
+
+type switchWriter struct{ w io.Writer }
+
+func (s *switchWriter) Write(p []byte) (int, error) { return s.w.Write(p) }
+
 func baseDescription() string {
 	return "Google CLI for Gmail/Calendar/Chat/Classroom/Drive/Contacts/Tasks/Sheets/Docs/Slides/People/Forms/Meet/App Script/Analytics/Search Console/Groups/Admin/Keep/YouTube/Maps/Photos"
 }
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"io"
+	"os"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -358,6 +359,8 @@ func TestGmailNoSendBlocksBeforeAuth(t *testing.T) {
 		{"--gmail-no-send", "gmail", "forward", "msg-1", "--to", "a@example.com"},
 		{"--gmail-no-send", "gmail", "fwd", "msg-1", "--to", "a@example.com"},
 		{"--gmail-no-send", "gmail", "drafts", "send", "draft-1"},
+		{"--gmail-no-send", "gmail", "send", "--to", "a@example.com", "--subject", "S", "--body", "B", "--at", "tomorrow 9am"},
+		{"--gmail-no-send", "gmail", "schedule", "run"},
 	}
 	for _, args := range tests {
 		err := Execute(args)
@@ -383,6 +386,7 @@ func TestConfigGmailNoSendBlocksBeforeAuth(t *testing.T) {
 		{"gmail", "autoreply", "from:a@example.com", "--subject", "S", "--body", "B"},
 		{"gmail", "forward", "msg-1", "--to", "a@example.com"},
 		{"gmail", "drafts", "send", "draft-1"},
+		{"gmail", "scheduled", "run", "--loop"},
 	}
 	for _, args := range tests {
 		result := executeWithTestRuntime(t, args, runtime)
@@ -411,3 +415,23 @@ func TestConfigIndependentCommandsDoNotRequireHome(t *testing.T) {
 		}
 	}
 }
+
+func TestCorruptConfigDoesNotBlockLocalCommands(t *testing.T) {
+	t.Setenv("GOG_AUDIT_LOG", "")
+	store := config.NewConfigStore(config.Layout{ConfigDir: t.TempDir()})
+	if err := os.WriteFile(store.Path(), []byte(`{"audit_log":`), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	for _, args := range [][]string{
+		{"version"},
+		{"config", "list"},
+	} {
+		result := executeWithTestRuntime(t, args, &app.Runtime{Config: store})
+		if result.err != nil {
+			t.Fatalf("%v: %v\nstderr=%q", args, result.err, result.stderr)
+		}
+		if args[0] == "config" && (!strings.Contains(result.stdout, "Config file: "+store.Path()) || !strings.Contains(result.stderr, "parse config")) {
+			t.Fatalf("config list stdout=%q stderr=%q", result.stdout, result.stderr)
+		}
+	}
+}
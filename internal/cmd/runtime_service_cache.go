@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/steipete/gogcli/internal/app"
+	"github.com/steipete/gogcli/internal/authclient"
+	"github.com/steipete/gogcli/internal/googleapi"
+	"github.com/steipete/gogcli/internal/secrets"
+)
+
+// googleServiceCache memoizes Google API clients across runs that share a
+// process, so each account's OAuth token source is built and refreshed once.
+// Clients capture the auth client, access token, and read-only mode from the
+// context when they are created, so those are part of the key.
+type googleServiceCache struct {
+	mu    sync.Mutex
+	items map[string]any
+}
+
+func newGoogleServiceCache() *googleServiceCache {
+	return &googleServiceCache{items: map[string]any{}}
+}
+
+func (c *googleServiceCache) key(ctx context.Context, service string, account string) string {
+	token := ""
+	if accessToken := authclient.AccessTokenFromContext(ctx); accessToken != "" {
+		sum := sha256.Sum256([]byte(accessToken))
+		token = hex.EncodeToString(sum[:8])
+	}
+	return strings.Join([]string{
+		service,
+		strings.ToLower(strings.TrimSpace(account)),
+		authclient.ClientOverrideFromContext(ctx),
+		strconv.FormatBool(googleapi.ReadOnly(ctx)),
+		token,
+	}, "\x00")
+}
+
+func cachedGoogleService[T any](c *googleServiceCache, service string, factory func(context.Context, string) (T, error)) func(context.Context, string) (T, error) {
+	if factory == nil {
+		return nil
+	}
+	return func(ctx context.Context, account string) (T, error) {
+		key := c.key(ctx, service, account)
+		c.mu.Lock()
+		if cached, ok := c.items[key].(T); ok {
+			c.mu.Unlock()
+			return cached, nil
+		}
+		c.mu.Unlock()
+
+		// Token sources keep the creation context for refreshes, which must
+		// outlive the run that first asked for the client.
+		created, err := factory(context.WithoutCancel(ctx), account)
+		if err != nil {
+			return created, err
+		}
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if cached, ok := c.items[key].(T); ok {
+			return cached, nil
+		}
+		c.items[key] = created
+		return created, nil
+	}
+}
+
+// wrap replaces the account-scoped Google API factories with cached ones.
+// Download, export, and Zoom helpers are not clients, and Keep builds a
+// service-account client per call, so those stay as they are.
+func (c *googleServiceCache) wrap(services *app.Services) {
+	services.AdminDirectory = cachedGoogleService(c, "admin-directory", services.AdminDirectory)
+	services.AdminOrgUnit = cachedGoogleService(c, "admin-orgunit", services.AdminOrgUnit)
+	services.AppScript = cachedGoogleService(c, "appscript", services.AppScript)
+	services.AnalyticsAdmin = cachedGoogleService(c, "analytics-admin", services.AnalyticsAdmin)
+	services.AnalyticsData = cachedGoogleService(c, "analytics-data", services.AnalyticsData)
+	services.Calendar = cachedGoogleService(c, "calendar", services.Calendar)
+	services.Chat = cachedGoogleService(c, "chat", services.Chat)
+	services.Classroom = cachedGoogleService(c, "classroom", services.Classroom)
+	services.CloudIdentity = cachedGoogleService(c, "cloudidentity", services.CloudIdentity)
+	services.Docs = cachedGoogleService(c, "docs", services.Docs)
+	services.DocsHTTP = cachedGoogleService(c, "docs-http", services.DocsHTTP)
+	services.Drive = cachedGoogleService(c, "drive", services.Drive)
+	services.DriveActivity = cachedGoogleService(c, "drive-activity", services.DriveActivity)
+	services.DriveLabels = cachedGoogleService(c, "drive-labels", services.DriveLabels)
+	services.Forms = cachedGoogleService(c, "forms", services.Forms)
+	services.Gmail = cachedGoogleService(c, "gmail", services.Gmail)
+	services.GmailDelete = cachedGoogleService(c, "gmail-delete", services.GmailDelete)
+	services.Meet = cachedGoogleService(c, "meet", services.Meet)
+	services.PeopleContacts = cachedGoogleService(c, "people-contacts", services.PeopleContacts)
+	services.PeopleDirectory = cachedGoogleService(c, "people-directory", services.PeopleDirectory)
+	services.PeopleOther = cachedGoogleService(c, "people-other", services.PeopleOther)
+	services.Photos = cachedGoogleService(c, "photos", services.Photos)
+	services.PhotosPicker = cachedGoogleService(c, "photos-picker", services.PhotosPicker)
+	services.SearchConsole = cachedGoogleService(c, "searchconsole", services.SearchConsole)
+	services.Sheets = cachedGoogleService(c, "sheets", services.Sheets)
+	services.SitesDrive = cachedGoogleService(c, "sites-drive", services.SitesDrive)
+	services.Slides = cachedGoogleService(c, "slides", services.Slides)
+	services.Tasks = cachedGoogleService(c, "tasks", services.Tasks)
+	services.YouTubeAPIKey = cachedGoogleService(c, "youtube-apikey", services.YouTubeAPIKey)
+	services.YouTubeAccount = cachedGoogleService(c, "youtube-account", services.YouTubeAccount)
+	services.YouTubeComments = cachedGoogleService(c, "youtube-comments", services.YouTubeComments)
+	services.YouTubeWrite = cachedGoogleService(c, "youtube-write", services.YouTubeWrite)
+}
+
+// secretsStoreCache keeps the first successfully opened keyring for later
+// runs, so a locked keyring is unlocked once per process.
+type secretsStoreCache struct {
+	mu     sync.Mutex
+	store  secrets.Store
+	secret secrets.SecretStore
+}
+
+func (c *secretsStoreCache) wrap(auth *app.AuthOperations) {
+	openStore := auth.OpenSecretsStore
+	if openStore != nil {
+		auth.OpenSecretsStore = func() (secrets.Store, error) {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			if c.store != nil {
+				return c.store, nil
+			}
+			store, err := openStore()
+			if err != nil {
+				return nil, err
+			}
+			c.store = store
+			return store, nil
+		}
+	}
+	openSecret := auth.OpenSecretStore
+	if openSecret != nil {
+		auth.OpenSecretStore = func() (secrets.SecretStore, error) {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			if c.secret != nil {
+				return c.secret, nil
+			}
+			store, err := openSecret()
+			if err != nil {
+				return nil, err
+			}
+			c.secret = store
+			return store, nil
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/steipete/gogcli/internal/app"
+	"github.com/steipete/gogcli/internal/authclient"
+	"github.com/steipete/gogcli/internal/googleapi"
+)
+
+func TestGoogleServiceCacheKeysByAccountAndContext(t *testing.T) {
+	cache := newGoogleServiceCache()
+	created := 0
+	fail := true
+	type fakeService struct{ n int }
+	factory := cachedGoogleService(cache, "fake", func(ctx context.Context, account string) (*fakeService, error) {
+		if ctx.Err() != nil {
+			t.Fatalf("factory context already canceled")
+		}
+		if fail {
+			return nil, errors.New("keyring locked")
+		}
+		created++
+		return &fakeService{n: created}, nil
+	})
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	if _, err := factory(ctx, "a@example.com"); err == nil {
+		t.Fatal("expected factory error")
+	}
+	fail = false
+	first, err := factory(ctx, "a@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, _ := factory(t.Context(), "A@example.com")
+	other, _ := factory(t.Context(), "b@example.com")
+	readonly, _ := factory(googleapi.WithReadOnly(t.Context(), true), "a@example.com")
+	client, _ := factory(authclient.WithClient(t.Context(), "work"), "a@example.com")
+	if again != first || other == first || readonly == first || client == first || created != 4 {
+		t.Fatalf("cache reuse wrong: created=%d", created)
+	}
+
+	var services app.Services
+	cache.wrap(&services)
+	if services.Tasks != nil {
+		t.Fatal("nil factory became non-nil")
+	}
+}
@@ -22,6 +22,8 @@ type File struct {
 	CalendarAliases map[string]string `json:"calendar_aliases,omitempty"`
 	GmailNoSend     bool              `json:"gmail_no_send,omitempty"`
 	NoSendAccounts  map[string]bool   `json:"no_send_accounts,omitempty"`
+	AuditLog        string            `json:"audit_log,omitempty"`
+	AuditHashBodies bool              `json:"audit_hash_bodies,omitempty"`
 }
 
 var errConfigLockTimeout = errors.New("acquire config lock timeout")
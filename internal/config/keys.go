@@ -11,11 +11,13 @@ import (
 type Key string
 
 const (
-	KeyTimezone       Key = "timezone"
-	KeyKeyringBackend Key = "keyring_backend"
-	KeyGmailNoSend    Key = "gmail_no_send"
-	KeyYoutubeAPIKey  Key = "youtube_api_key"
-	KeyPlacesAPIKey   Key = "places_api_key"
+	KeyTimezone        Key = "timezone"
+	KeyKeyringBackend  Key = "keyring_backend"
+	KeyGmailNoSend     Key = "gmail_no_send"
+	KeyYoutubeAPIKey   Key = "youtube_api_key"
+	KeyPlacesAPIKey    Key = "places_api_key"
+	KeyAuditLog        Key = "audit_log"
+	KeyAuditHashBodies Key = "audit_hash_bodies"
 )
 
 type KeySpec struct {
@@ -32,6 +34,8 @@ var keyOrder = []Key{
 	KeyGmailNoSend,
 	KeyYoutubeAPIKey,
 	KeyPlacesAPIKey,
+	KeyAuditLog,
+	KeyAuditHashBodies,
 }
 
 var keySpecs = map[Key]KeySpec{
@@ -137,6 +141,53 @@ var keySpecs = map[Key]KeySpec{
 			return "(not set; set for Places API: config set places_api_key KEY or GOG_PLACES_API_KEY)"
 		},
 	},
+	KeyAuditLog: {
+		Key: KeyAuditLog,
+		Get: func(cfg File) string {
+			if v := os.Getenv("GOG_AUDIT_LOG"); v != "" {
+				return v
+			}
+
+			return cfg.AuditLog
+		},
+		Set: func(cfg *File, value string) error {
+			cfg.AuditLog = value
+			return nil
+		},
+		Unset: func(cfg *File) {
+			cfg.AuditLog = ""
+		},
+		EmptyHint: func() string {
+			return "(not set; set to append mutating API requests as JSONL: config set audit_log PATH or GOG_AUDIT_LOG)"
+		},
+	},
+	KeyAuditHashBodies: {
+		Key: KeyAuditHashBodies,
+		Get: func(cfg File) string {
+			if v := os.Getenv("GOG_AUDIT_HASH_BODIES"); v != "" {
+				if parsed, err := parseConfigBool(v); err == nil {
+					return boolConfigString(parsed)
+				}
+			}
+
+			return boolConfigString(cfg.AuditHashBodies)
+		},
+		Set: func(cfg *File, value string) error {
+			parsed, err := parseConfigBool(value)
+			if err != nil {
+				return err
+			}
+			cfg.AuditHashBodies = parsed
+
+			return nil
+		},
+		Unset: func(cfg *File) {
+			cfg.AuditHashBodies = false
+		},
+		EmptyHint: func() string {
+			return "false"
+		},
+	},
 }
 
 var (
@@ -63,6 +63,10 @@ func (l Layout) GmailAttachmentsDir() string {
 	return filepath.Join(l.ConfigDir, "gmail-attachments")
 }
 
+func (l Layout) GmailScheduledDir() string {
+	return filepath.Join(l.StateDir, "gmail-scheduled")
+}
+
 func (l Layout) PrimaryGmailWatchDir() string {
 	return filepath.Join(l.StateDir, "gmail-watch")
 }
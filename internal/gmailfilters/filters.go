@@ -0,0 +1,349 @@
+// Package gmailfilters reads declarative Gmail filter sets, from gog's YAML
+// format or Gmail's mailFilters XML export, and converts them to and from
+// the Gmail API representation.
+package gmailfilters
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"google.golang.org/api/gmail/v1"
+)
+
+const (
+	SizeLarger  = "larger"
+	SizeSmaller = "smaller"
+
+	labelInbox     = "INBOX"
+	labelUnread    = "UNREAD"
+	labelStarred   = "STARRED"
+	labelImportant = "IMPORTANT"
+	labelSpam      = "SPAM"
+	labelTrash     = "TRASH"
+)
+
+var (
+	errNoCriteria = errors.New("filter has no match criteria")
+	errNoActions  = errors.New("filter has no actions")
+)
+
+// categories maps the YAML category names to Gmail's category label IDs.
+var categories = map[string]string{
+	"personal":   "CATEGORY_PERSONAL",
+	"social":     "CATEGORY_SOCIAL",
+	"promotions": "CATEGORY_PROMOTIONS",
+	"updates":    "CATEGORY_UPDATES",
+	"forums":     "CATEGORY_FORUMS",
+}
+
+// Set is a desired filter set plus labels that must exist even when no
+// filter uses them.
+type Set struct {
+	Labels  []string
+	Filters []Filter
+}
+
+// Filter is one Gmail filter with labels referenced by name.
+type Filter struct {
+	Name string
+
+	From           string
+	To             string
+	Subject        string
+	Query          string
+	NegatedQuery   string
+	HasAttachment  bool
+	ExcludeChats   bool
+	Size           int64
+	SizeComparison string
+
+	AddLabels      []string
+	RemoveLabels   []string
+	Archive        bool
+	MarkRead       bool
+	Star           bool
+	Important      bool
+	NeverImportant bool
+	NeverSpam      bool
+	Trash          bool
+	Category       string
+	Forward        string
+}
+
+// Validate reports filters Gmail would reject.
+func (f Filter) Validate() error {
+	if f.From == "" && f.To == "" && f.Subject == "" && f.Query == "" && f.NegatedQuery == "" &&
+		!f.HasAttachment && f.Size == 0 {
+		return errNoCriteria
+	}
+
+	if len(f.AddLabels) == 0 && len(f.RemoveLabels) == 0 && !f.Archive && !f.MarkRead && !f.Star &&
+		!f.Important && !f.NeverImportant && !f.NeverSpam && !f.Trash && f.Category == "" && f.Forward == "" {
+		return errNoActions
+	}
+
+	if f.Size != 0 && f.SizeComparison != SizeLarger && f.SizeComparison != SizeSmaller {
+		return fmt.Errorf("size comparison must be %s or %s", SizeLarger, SizeSmaller)
+	}
+
+	if f.Important && f.NeverImportant {
+		return errors.New("important and never_important are mutually exclusive")
+	}
+
+	if f.Category != "" {
+		if _, ok := categories[f.Category]; !ok {
+			return fmt.Errorf("unknown category %q (use personal, social, promotions, updates, or forums)", f.Category)
+		}
+	}
+
+	return nil
+}
+
+// UserLabels returns the label names the filter adds or removes.
+func (f Filter) UserLabels() []string {
+	out := make([]string, 0, len(f.AddLabels)+len(f.RemoveLabels))
+	out = append(out, f.AddLabels...)
+
+	return append(out, f.RemoveLabels...)
+}
+
+// ToAPI converts f using lookup to map label names to IDs. Names lookup does
+// not know are returned in missing and left out of the action.
+func (f Filter) ToAPI(lookup func(name string) (string, bool)) (*gmail.Filter, []string) {
+	var missing []string
+
+	resolve := func(names []string) []string {
+		ids := make([]string, 0, len(names))
+		for _, name := range names {
+			id, ok := lookup(name)
+			if !ok {
+				missing = append(missing, name)
+
+				continue
+			}
+
+			ids = append(ids, id)
+		}
+
+		return ids
+	}
+
+	action := &gmail.FilterAction{
+		AddLabelIds:    resolve(f.AddLabels),
+		RemoveLabelIds: resolve(f.RemoveLabels),
+		Forward:        f.Forward,
+	}
+
+	addSystem := func(ok bool, id string) {
+		if ok && !slices.Contains(action.AddLabelIds, id) {
+			action.AddLabelIds = append(action.AddLabelIds, id)
+		}
+	}
+	removeSystem := func(ok bool, id string) {
+		if ok && !slices.Contains(action.RemoveLabelIds, id) {
+			action.RemoveLabelIds = append(action.RemoveLabelIds, id)
+		}
+	}
+
+	addSystem(f.Star, labelStarred)
+	addSystem(f.Important, labelImportant)
+	addSystem(f.Trash, labelTrash)
+	addSystem(f.Category != "", categories[f.Category])
+	removeSystem(f.Archive, labelInbox)
+	removeSystem(f.MarkRead, labelUnread)
+	removeSystem(f.NeverSpam, labelSpam)
+	removeSystem(f.NeverImportant, labelImportant)
+
+	criteria := &gmail.FilterCriteria{
+		From:          f.From,
+		To:            f.To,
+		Subject:       f.Subject,
+		Query:         f.Query,
+		NegatedQuery:  f.NegatedQuery,
+		HasAttachment: f.HasAttachment,
+		ExcludeChats:  f.ExcludeChats,
+		Size:          f.Size,
+	}
+	if f.Size != 0 {
+		criteria.SizeComparison = f.SizeComparison
+	}
+
+	return &gmail.Filter{Criteria: criteria, Action: action}, missing
+}
+
+// FromAPI converts a Gmail API filter, naming user labels via labelNames
+// (ID to name). Unknown label IDs are kept as-is.
+func FromAPI(filter *gmail.Filter, labelNames map[string]string) Filter {
+	var f Filter
+	if filter == nil {
+		return f
+	}
+
+	if c := filter.Criteria; c != nil {
+		f.From = strings.TrimSpace(c.From)
+		f.To = strings.TrimSpace(c.To)
+		f.Subject = strings.TrimSpace(c.Subject)
+		f.Query = strings.TrimSpace(c.Query)
+		f.NegatedQuery = strings.TrimSpace(c.NegatedQuery)
+		f.HasAttachment = c.HasAttachment
+		f.ExcludeChats = c.ExcludeChats
+		f.Size = c.Size
+
+		if c.Size != 0 {
+			f.SizeComparison = strings.ToLower(strings.TrimSpace(c.SizeComparison))
+		}
+	}
+
+	a := filter.Action
+	if a == nil {
+		return f
+	}
+
+	labelName := func(id string) string {
+		if name := strings.TrimSpace(labelNames[id]); name != "" {
+			return name
+		}
+
+		return id
+	}
+
+	for _, id := range a.AddLabelIds {
+		switch id {
+		case labelStarred:
+			f.Star = true
+		case labelImportant:
+			f.Important = true
+		case labelTrash:
+			f.Trash = true
+		default:
+			if category := categoryName(id); category != "" {
+				f.Category = category
+
+				continue
+			}
+
+			f.AddLabels = append(f.AddLabels, labelName(id))
+		}
+	}
+
+	for _, id := range a.RemoveLabelIds {
+		switch id {
+		case labelInbox:
+			f.Archive = true
+		case labelUnread:
+			f.MarkRead = true
+		case labelSpam:
+			f.NeverSpam = true
+		case labelImportant:
+			f.NeverImportant = true
+		default:
+			f.RemoveLabels = append(f.RemoveLabels, labelName(id))
+		}
+	}
+
+	f.Forward = strings.TrimSpace(a.Forward)
+
+	return f
+}
+
+func categoryName(id string) string {
+	for name, labelID := range categories {
+		if labelID == id {
+			return name
+		}
+	}
+
+	return ""
+}
+
+// Summary renders the filter as one line, e.g.
+// "from:news@example.com -> label Newsletters, archive".
+func (f Filter) Summary() string {
+	var match []string
+
+	add := func(prefix, value string) {
+		if value != "" {
+			match = append(match, prefix+value)
+		}
+	}
+
+	add("from:", f.From)
+	add("to:", f.To)
+	add("subject:", quoteIfSpaced(f.Subject))
+	add("", f.Query)
+	add("-", quoteIfSpaced(f.NegatedQuery))
+
+	if f.HasAttachment {
+		match = append(match, "has:attachment")
+	}
+
+	if f.Size != 0 {
+		match = append(match, fmt.Sprintf("%s:%d", f.SizeComparison, f.Size))
+	}
+
+	var actions []string
+	for _, label := range f.AddLabels {
+		actions = append(actions, "label "+label)
+	}
+
+	for _, label := range f.RemoveLabels {
+		actions = append(actions, "unlabel "+label)
+	}
+
+	flags := []struct {
+		set  bool
+		name string
+	}{
+		{f.Archive, "archive"},
+		{f.MarkRead, "mark read"},
+		{f.Star, "star"},
+		{f.Important, "important"},
+		{f.NeverImportant, "never important"},
+		{f.NeverSpam, "never spam"},
+		{f.Trash, "trash"},
+	}
+	for _, flag := range flags {
+		if flag.set {
+			actions = append(actions, flag.name)
+		}
+	}
+
+	if f.Category != "" {
+		actions = append(actions, "category "+f.Category)
+	}
+
+	if f.Forward != "" {
+		actions = append(actions, "forward "+f.Forward)
+	}
+
+	return strings.Join(match, " ") + " -> " + strings.Join(actions, ", ")
+}
+
+func quoteIfSpaced(value string) string {
+	if strings.ContainsAny(value, " \t") && !strings.HasPrefix(value, "(") && !strings.HasPrefix(value, `"`) {
+		return `"` + value + `"`
+	}
+
+	return value
+}
+
+// Parse reads a filter set as "yaml" or "xml"; "" or "auto" picks XML when
+// the data starts with "<".
+func Parse(data []byte, format string) (*Set, error) {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", "auto":
+		if strings.HasPrefix(strings.TrimSpace(strings.TrimPrefix(string(data), "\ufeff")), "<") {
+			return ParseXML(data)
+		}
+
+		return ParseYAML(data)
+	case "yaml", "yml":
+		return ParseYAML(data)
+	case "xml":
+		return ParseXML(data)
+	default:
+		return nil, fmt.Errorf("unknown filter format %q", format)
+	}
+}
@@ -0,0 +1,143 @@
+package gmailfilters
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseYAMLAndRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	data := []byte(`
+labels: [Archive/2026]
+filters:
+  - name: newsletters
+    match:
+      from: news@example.com
+      larger_than: 2MB
+    actions:
+      add_labels: [Newsletters]
+      archive: true
+      category: Promotions
+  - match: {query: "invoice has:attachment"}
+    actions: {star: true, never_spam: true}
+`)
+
+	set, err := ParseYAML(data)
+	if err != nil {
+		t.Fatalf("ParseYAML: %v", err)
+	}
+
+	if len(set.Labels) != 1 || len(set.Filters) != 2 {
+		t.Fatalf("set = %#v", set)
+	}
+
+	news := set.Filters[0]
+	if news.Size != 2<<20 || news.SizeComparison != SizeLarger || news.Category != "promotions" {
+		t.Fatalf("news = %#v", news)
+	}
+
+	lookup := func(name string) (string, bool) {
+		if name == "Newsletters" {
+			return "Label_7", true
+		}
+
+		return "", false
+	}
+
+	api, missing := news.ToAPI(lookup)
+	if len(missing) != 0 || api.Criteria.SizeComparison != SizeLarger {
+		t.Fatalf("ToAPI = %#v missing=%v", api, missing)
+	}
+
+	if strings.Join(api.Action.AddLabelIds, ",") != "Label_7,CATEGORY_PROMOTIONS" || strings.Join(api.Action.RemoveLabelIds, ",") != "INBOX" {
+		t.Fatalf("action = %#v", api.Action)
+	}
+
+	back := FromAPI(api, map[string]string{"Label_7": "Newsletters"})
+	if back.Summary() != "from:news@example.com larger:2097152 -> label Newsletters, archive, category promotions" {
+		t.Fatalf("Summary = %q", back.Summary())
+	}
+
+	out, err := MarshalYAML(&Set{Filters: []Filter{back}})
+	if err != nil {
+		t.Fatalf("MarshalYAML: %v", err)
+	}
+
+	again, err := ParseYAML(out)
+	if err != nil || len(again.Filters) != 1 || again.Filters[0].Summary() != back.Summary() {
+		t.Fatalf("round trip = %#v err=%v\n%s", again, err, out)
+	}
+
+	if _, missing := set.Filters[1].ToAPI(lookup); len(missing) != 0 {
+		t.Fatalf("missing = %v", missing)
+	}
+}
+
+func TestParseYAMLErrors(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"unknown field":  "filters:\n  - match: {frm: a}\n    actions: {archive: true}\n",
+		"no criteria":    "filters:\n  - actions: {archive: true}\n",
+		"no actions":     "filters:\n  - match: {from: a@b.c}\n",
+		"both sizes":     "filters:\n  - match: {larger_than: 1M, smaller_than: 2M}\n    actions: {archive: true}\n",
+		"bad unit":       "filters:\n  - match: {larger_than: 1GB}\n    actions: {archive: true}\n",
+		"bad category":   "filters:\n  - match: {from: a@b.c}\n    actions: {category: spam}\n",
+		"important both": "filters:\n  - match: {from: a@b.c}\n    actions: {important: true, never_important: true}\n",
+	}
+	for name, data := range cases {
+		if _, err := ParseYAML([]byte(data)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestParseXML(t *testing.T) {
+	t.Parallel()
+
+	data := []byte(`<?xml version='1.0' encoding='UTF-8'?>
+<feed xmlns='http://www.w3.org/2005/Atom' xmlns:apps='http://schemas.google.com/apps/2006'>
+  <title>Mail Filters</title>
+  <entry>
+    <category term='filter'></category>
+    <title>Mail Filter</title>
+    <apps:property name='from' value='billing@example.com'/>
+    <apps:property name='hasTheWord' value='invoice'/>
+    <apps:property name='label' value='Receipts'/>
+    <apps:property name='shouldArchive' value='true'/>
+    <apps:property name='shouldMarkAsRead' value='true'/>
+    <apps:property name='sizeOperator' value='s_ss'/>
+    <apps:property name='size' value='5'/>
+    <apps:property name='sizeUnit' value='s_smb'/>
+  </entry>
+  <entry>
+    <apps:property name='to' value='team@example.com'/>
+    <apps:property name='smartLabelToApply' value='^smartlabel_group'/>
+    <apps:property name='forwardTo' value='lead@example.com'/>
+  </entry>
+</feed>`)
+
+	set, err := Parse(data, "auto")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	if len(set.Filters) != 2 {
+		t.Fatalf("filters = %#v", set.Filters)
+	}
+
+	got := set.Filters[0]
+	if got.From != "billing@example.com" || got.Query != "invoice" || got.AddLabels[0] != "Receipts" ||
+		!got.Archive || !got.MarkRead || got.Size != 5<<20 || got.SizeComparison != SizeSmaller {
+		t.Fatalf("first = %#v", got)
+	}
+
+	if second := set.Filters[1]; second.Category != "forums" || second.Forward != "lead@example.com" {
+		t.Fatalf("second = %#v", second)
+	}
+
+	if _, err := ParseXML([]byte(`<feed><entry><apps:property name='from' value='x'/></entry></feed>`)); err == nil {
+		t.Fatalf("expected error for filter without actions")
+	}
+}
@@ -0,0 +1,142 @@
+package gmailfilters
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type xmlFeed struct {
+	Entries []xmlEntry `xml:"entry"`
+}
+
+type xmlEntry struct {
+	ID         string        `xml:"id"`
+	Properties []xmlProperty `xml:"property"`
+}
+
+type xmlProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+var smartLabels = map[string]string{
+	"^smartlabel_personal":     "personal",
+	"^smartlabel_social":       "social",
+	"^smartlabel_promo":        "promotions",
+	"^smartlabel_notification": "updates",
+	"^smartlabel_group":        "forums",
+}
+
+// ParseXML reads Gmail's mailFilters.xml export (Settings > Filters > Export),
+// which is also what gog gmail filters export writes.
+func ParseXML(data []byte) (*Set, error) {
+	var feed xmlFeed
+	if err := xml.NewDecoder(bytes.NewReader(data)).Decode(&feed); err != nil {
+		return nil, fmt.Errorf("parse filters XML: %w", err)
+	}
+
+	set := &Set{Filters: make([]Filter, 0, len(feed.Entries))}
+	for i, entry := range feed.Entries {
+		filter, err := filterFromXML(entry)
+		if err != nil {
+			return nil, fmt.Errorf("filter %d: %w", i+1, err)
+		}
+
+		set.Filters = append(set.Filters, filter)
+	}
+
+	return set, nil
+}
+
+func filterFromXML(entry xmlEntry) (Filter, error) {
+	var (
+		f        Filter
+		sizeUnit = "s_sb"
+	)
+
+	for _, prop := range entry.Properties {
+		value := strings.TrimSpace(prop.Value)
+		truthy := value == "true"
+
+		switch prop.Name {
+		case "from":
+			f.From = value
+		case "to":
+			f.To = value
+		case "subject":
+			f.Subject = value
+		case "hasTheWord":
+			f.Query = value
+		case "doesNotHaveTheWord":
+			f.NegatedQuery = value
+		case "hasAttachment":
+			f.HasAttachment = truthy
+		case "excludeChats":
+			f.ExcludeChats = truthy
+		case "size":
+			size, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return Filter{}, fmt.Errorf("invalid size %q", value)
+			}
+
+			f.Size = size
+		case "sizeOperator":
+			switch value {
+			case "s_sl":
+				f.SizeComparison = SizeLarger
+			case "s_ss":
+				f.SizeComparison = SizeSmaller
+			default:
+				return Filter{}, fmt.Errorf("unknown sizeOperator %q", value)
+			}
+		case "sizeUnit":
+			sizeUnit = value
+		case "label":
+			if value != "" {
+				f.AddLabels = append(f.AddLabels, value)
+			}
+		case "shouldArchive":
+			f.Archive = truthy
+		case "shouldMarkAsRead":
+			f.MarkRead = truthy
+		case "shouldStar":
+			f.Star = truthy
+		case "shouldTrash":
+			f.Trash = truthy
+		case "shouldNeverSpam":
+			f.NeverSpam = truthy
+		case "shouldAlwaysMarkAsImportant":
+			f.Important = truthy
+		case "shouldNeverMarkAsImportant":
+			f.NeverImportant = truthy
+		case "smartLabelToApply":
+			category, ok := smartLabels[value]
+			if !ok {
+				return Filter{}, fmt.Errorf("unknown smartLabelToApply %q", value)
+			}
+
+			f.Category = category
+		case "forwardTo":
+			f.Forward = value
+		}
+	}
+
+	switch sizeUnit {
+	case "s_sb":
+	case "s_skb":
+		f.Size <<= 10
+	case "s_smb":
+		f.Size <<= 20
+	default:
+		return Filter{}, fmt.Errorf("unknown sizeUnit %q", sizeUnit)
+	}
+
+	if err := f.Validate(); err != nil {
+		return Filter{}, err
+	}
+
+	return f, nil
+}
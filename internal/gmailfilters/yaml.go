@@ -0,0 +1,224 @@
+package gmailfilters
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+type setFile struct {
+	Labels  []string     `yaml:"labels,omitempty"`
+	Filters []filterSpec `yaml:"filters"`
+}
+
+type filterSpec struct {
+	Name    string      `yaml:"name,omitempty"`
+	Match   matchSpec   `yaml:"match"`
+	Actions actionsSpec `yaml:"actions"`
+}
+
+type matchSpec struct {
+	From          string `yaml:"from,omitempty"`
+	To            string `yaml:"to,omitempty"`
+	Subject       string `yaml:"subject,omitempty"`
+	Query         string `yaml:"query,omitempty"`
+	NegatedQuery  string `yaml:"negated_query,omitempty"`
+	HasAttachment bool   `yaml:"has_attachment,omitempty"`
+	ExcludeChats  bool   `yaml:"exclude_chats,omitempty"`
+	LargerThan    string `yaml:"larger_than,omitempty"`
+	SmallerThan   string `yaml:"smaller_than,omitempty"`
+}
+
+type actionsSpec struct {
+	AddLabels      []string `yaml:"add_labels,omitempty"`
+	RemoveLabels   []string `yaml:"remove_labels,omitempty"`
+	Archive        bool     `yaml:"archive,omitempty"`
+	MarkRead       bool     `yaml:"mark_read,omitempty"`
+	Star           bool     `yaml:"star,omitempty"`
+	Important      bool     `yaml:"important,omitempty"`
+	NeverImportant bool     `yaml:"never_important,omitempty"`
+	NeverSpam      bool     `yaml:"never_spam,omitempty"`
+	Trash          bool     `yaml:"trash,omitempty"`
+	Category       string   `yaml:"category,omitempty"`
+	Forward        string   `yaml:"forward,omitempty"`
+}
+
+// ParseYAML reads gog's filter file format:
+//
+//	labels: [Receipts]
+//	filters:
+//	  - name: newsletters
+//	    match: {from: news@example.com}
+//	    actions: {add_labels: [Newsletters], archive: true}
+func ParseYAML(data []byte) (*Set, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var file setFile
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("parse filters: %w", err)
+	}
+
+	set := &Set{Labels: trimNonEmpty(file.Labels), Filters: make([]Filter, 0, len(file.Filters))}
+	for i, spec := range file.Filters {
+		name := strings.TrimSpace(spec.Name)
+		if name == "" {
+			name = fmt.Sprintf("filter %d", i+1)
+		}
+
+		filter, err := filterFromSpec(spec)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		set.Filters = append(set.Filters, filter)
+	}
+
+	return set, nil
+}
+
+func filterFromSpec(spec filterSpec) (Filter, error) {
+	m, a := spec.Match, spec.Actions
+	f := Filter{
+		Name:           strings.TrimSpace(spec.Name),
+		From:           strings.TrimSpace(m.From),
+		To:             strings.TrimSpace(m.To),
+		Subject:        strings.TrimSpace(m.Subject),
+		Query:          strings.TrimSpace(m.Query),
+		NegatedQuery:   strings.TrimSpace(m.NegatedQuery),
+		HasAttachment:  m.HasAttachment,
+		ExcludeChats:   m.ExcludeChats,
+		AddLabels:      trimNonEmpty(a.AddLabels),
+		RemoveLabels:   trimNonEmpty(a.RemoveLabels),
+		Archive:        a.Archive,
+		MarkRead:       a.MarkRead,
+		Star:           a.Star,
+		Important:      a.Important,
+		NeverImportant: a.NeverImportant,
+		NeverSpam:      a.NeverSpam,
+		Trash:          a.Trash,
+		Category:       strings.ToLower(strings.TrimSpace(a.Category)),
+		Forward:        strings.TrimSpace(a.Forward),
+	}
+
+	switch {
+	case m.LargerThan != "" && m.SmallerThan != "":
+		return Filter{}, errors.New("match: set larger_than or smaller_than, not both")
+	case m.LargerThan != "":
+		size, err := ParseSize(m.LargerThan)
+		if err != nil {
+			return Filter{}, fmt.Errorf("match.larger_than: %w", err)
+		}
+
+		f.Size, f.SizeComparison = size, SizeLarger
+	case m.SmallerThan != "":
+		size, err := ParseSize(m.SmallerThan)
+		if err != nil {
+			return Filter{}, fmt.Errorf("match.smaller_than: %w", err)
+		}
+
+		f.Size, f.SizeComparison = size, SizeSmaller
+	}
+
+	if err := f.Validate(); err != nil {
+		return Filter{}, err
+	}
+
+	return f, nil
+}
+
+// ParseSize reads a byte count with an optional K/KB or M/MB suffix (powers
+// of 1024, as Gmail uses).
+func ParseSize(value string) (int64, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	digits := strings.TrimRightFunc(value, func(r rune) bool { return !unicode.IsDigit(r) })
+	unit := strings.TrimSpace(strings.TrimPrefix(value, digits))
+
+	n, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid size %q", value)
+	}
+
+	switch unit {
+	case "", "B":
+		return n, nil
+	case "K", "KB":
+		return n << 10, nil
+	case "M", "MB":
+		return n << 20, nil
+	default:
+		return 0, fmt.Errorf("invalid size unit %q (use B, KB, or MB)", unit)
+	}
+}
+
+// MarshalYAML renders set in the format ParseYAML reads.
+func MarshalYAML(set *Set) ([]byte, error) {
+	file := setFile{Labels: set.Labels, Filters: make([]filterSpec, 0, len(set.Filters))}
+	for _, f := range set.Filters {
+		spec := filterSpec{
+			Name: f.Name,
+			Match: matchSpec{
+				From:          f.From,
+				To:            f.To,
+				Subject:       f.Subject,
+				Query:         f.Query,
+				NegatedQuery:  f.NegatedQuery,
+				HasAttachment: f.HasAttachment,
+				ExcludeChats:  f.ExcludeChats,
+			},
+			Actions: actionsSpec{
+				AddLabels:      f.AddLabels,
+				RemoveLabels:   f.RemoveLabels,
+				Archive:        f.Archive,
+				MarkRead:       f.MarkRead,
+				Star:           f.Star,
+				Important:      f.Important,
+				NeverImportant: f.NeverImportant,
+				NeverSpam:      f.NeverSpam,
+				Trash:          f.Trash,
+				Category:       f.Category,
+				Forward:        f.Forward,
+			},
+		}
+
+		switch f.SizeComparison {
+		case SizeLarger:
+			spec.Match.LargerThan = strconv.FormatInt(f.Size, 10)
+		case SizeSmaller:
+			spec.Match.SmallerThan = strconv.FormatInt(f.Size, 10)
+		}
+
+		file.Filters = append(file.Filters, spec)
+	}
+
+	var buf bytes.Buffer
+
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+
+	if err := encoder.Encode(file); err != nil {
+		return nil, fmt.Errorf("encode filters: %w", err)
+	}
+
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("encode filters: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func trimNonEmpty(values []string) []string {
+	out := make([]string, 0, len(values))
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			out = append(out, value)
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,393 @@
+// Package gmailschedule stores scheduled Gmail sends. Each entry points at a
+// draft that a later run sends once its time has come.
+package gmailschedule
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/steipete/gogcli/internal/config"
+	"github.com/steipete/gogcli/internal/filelock"
+)
+
+const (
+	StatePending = "pending"
+	StateSent    = "sent"
+	StateFailed  = "failed"
+
+	DefaultMaxAttempts = 5
+	defaultLockTimeout = 5 * time.Second
+)
+
+var (
+	ErrInvalidID = errors.New("invalid schedule ID")
+	ErrNotFound  = errors.New("scheduled send not found")
+	ErrNotActive = errors.New("scheduled send already sent")
+)
+
+type Entry struct {
+	ID        string    `json:"id"`
+	Account   string    `json:"account"`
+	DraftID   string    `json:"draft_id"`
+	ThreadID  string    `json:"thread_id,omitempty"`
+	To        []string  `json:"to,omitempty"`
+	Subject   string    `json:"subject,omitempty"`
+	SendAt    time.Time `json:"send_at"`
+	CreatedAt time.Time `json:"created_at"`
+	State     string    `json:"state"`
+	Attempts  int       `json:"attempts,omitempty"`
+	LastError string    `json:"last_error,omitempty"`
+	SentAt    time.Time `json:"sent_at,omitzero"`
+	MessageID string    `json:"message_id,omitempty"`
+}
+
+// Due reports whether a pending entry should be sent at now.
+func (e Entry) Due(now time.Time) bool {
+	return e.State == StatePending && !e.SendAt.After(now)
+}
+
+// SendFunc sends the draft behind an entry and returns the sent message ID.
+type SendFunc func(ctx context.Context, entry Entry) (string, error)
+
+// PermanentError marks a send failure that retrying will not fix, such as a
+// deleted draft.
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+type RunResult struct {
+	Sent    []Entry
+	Failed  []Entry
+	Retried []Entry
+}
+
+type Options struct {
+	Now         func() time.Time
+	NewID       func() (string, error)
+	MaxAttempts int
+	LockTimeout time.Duration
+}
+
+type Repository struct {
+	dir         string
+	lock        *filelock.Lock
+	now         func() time.Time
+	newID       func() (string, error)
+	maxAttempts int
+}
+
+func New(dir string, options Options) *Repository {
+	now := options.Now
+	if now == nil {
+		now = time.Now
+	}
+
+	newID := options.NewID
+	if newID == nil {
+		newID = func() (string, error) {
+			id, err := uuid.NewV7()
+			if err != nil {
+				return "", fmt.Errorf("new UUID v7: %w", err)
+			}
+
+			return id.String(), nil
+		}
+	}
+
+	maxAttempts := options.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+
+	lockTimeout := options.LockTimeout
+	if lockTimeout <= 0 {
+		lockTimeout = defaultLockTimeout
+	}
+
+	return &Repository{
+		dir:         dir,
+		lock:        filelock.Shared(filepath.Join(dir, ".lock"), lockTimeout),
+		now:         now,
+		newID:       newID,
+		maxAttempts: maxAttempts,
+	}
+}
+
+func (r *Repository) Ensure() error {
+	if err := os.MkdirAll(r.dir, 0o700); err != nil {
+		return fmt.Errorf("ensure schedule dir: %w", err)
+	}
+
+	return nil
+}
+
+// Add stores a new pending entry and returns it with its ID and timestamps.
+func (r *Repository) Add(entry Entry) (*Entry, error) {
+	if err := r.Ensure(); err != nil {
+		return nil, err
+	}
+
+	err := r.lock.WithExclusive(func() error {
+		id, err := r.newID()
+		if err != nil {
+			return err
+		}
+
+		entry.ID = id
+		entry.State = StatePending
+		entry.CreatedAt = r.now().UTC()
+		entry.SendAt = entry.SendAt.UTC()
+
+		return r.writeUnlocked(&entry)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("add scheduled send: %w", err)
+	}
+
+	return &entry, nil
+}
+
+// List returns every entry ordered by send time.
+func (r *Repository) List() ([]Entry, error) {
+	return r.listUnlocked()
+}
+
+func (r *Repository) Get(id string) (*Entry, error) {
+	return r.readUnlocked(id)
+}
+
+// Cancel removes an entry that has not been sent yet. cleanup runs under the
+// lock before removal so a concurrent run cannot send a half-cancelled entry.
+func (r *Repository) Cancel(id string, cleanup func(Entry) error) (*Entry, error) {
+	var cancelled *Entry
+
+	err := r.lock.WithExclusive(func() error {
+		entry, err := r.readUnlocked(id)
+		if err != nil {
+			return err
+		}
+
+		if entry.State == StateSent {
+			return fmt.Errorf("%s: %w", entry.ID, ErrNotActive)
+		}
+
+		if cleanup != nil {
+			if err := cleanup(*entry); err != nil {
+				return err
+			}
+		}
+
+		if err := os.Remove(r.path(entry.ID)); err != nil {
+			return fmt.Errorf("remove scheduled send: %w", err)
+		}
+		cancelled = entry
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return cancelled, nil
+}
+
+// RunDue sends every due pending entry, oldest first, while holding the lock
+// so overlapping runs never send the same draft twice. limit <= 0 means all.
+func (r *Repository) RunDue(ctx context.Context, limit int, send SendFunc) (RunResult, error) {
+	var result RunResult
+
+	err := r.lock.WithExclusive(func() error {
+		entries, err := r.listUnlocked()
+		if err != nil {
+			return err
+		}
+
+		now := r.now()
+		processed := 0
+
+		for _, entry := range entries {
+			if !entry.Due(now) {
+				continue
+			}
+
+			if limit > 0 && processed >= limit {
+				break
+			}
+
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			processed++
+
+			messageID, sendErr := send(ctx, entry)
+			entry.Attempts++
+
+			var permanent *PermanentError
+
+			switch {
+			case sendErr == nil:
+				entry.State = StateSent
+				entry.SentAt = r.now().UTC()
+				entry.MessageID = messageID
+				entry.LastError = ""
+				result.Sent = append(result.Sent, entry)
+			case errors.As(sendErr, &permanent) || entry.Attempts >= r.maxAttempts:
+				entry.State = StateFailed
+				entry.LastError = sendErr.Error()
+				result.Failed = append(result.Failed, entry)
+			default:
+				entry.LastError = sendErr.Error()
+				result.Retried = append(result.Retried, entry)
+			}
+
+			if err := r.writeUnlocked(&entry); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return result, fmt.Errorf("run scheduled sends: %w", err)
+	}
+
+	return result, nil
+}
+
+// PruneSent removes sent entries older than the retention window.
+func (r *Repository) PruneSent(olderThan time.Duration) (int, error) {
+	removed := 0
+
+	err := r.lock.WithExclusive(func() error {
+		entries, err := r.listUnlocked()
+		if err != nil {
+			return err
+		}
+
+		cutoff := r.now().Add(-olderThan)
+		for _, entry := range entries {
+			if entry.State != StateSent || entry.SentAt.After(cutoff) {
+				continue
+			}
+
+			if err := os.Remove(r.path(entry.ID)); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("remove scheduled send %s: %w", entry.ID, err)
+			}
+			removed++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return removed, fmt.Errorf("prune scheduled sends: %w", err)
+	}
+
+	return removed, nil
+}
+
+func ValidateID(id string) error {
+	id = strings.TrimSpace(id)
+
+	parsed, err := uuid.Parse(id)
+	if err != nil || parsed.String() != id {
+		return fmt.Errorf("%w: %s", ErrInvalidID, id)
+	}
+
+	return nil
+}
+
+func (r *Repository) path(id string) string {
+	return filepath.Join(r.dir, id+".json")
+}
+
+func (r *Repository) readUnlocked(id string) (*Entry, error) {
+	if err := ValidateID(id); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(r.path(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+		}
+
+		return nil, fmt.Errorf("read scheduled send: %w", err)
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("decode scheduled send %s: %w", id, err)
+	}
+
+	return &entry, nil
+}
+
+func (r *Repository) listUnlocked() ([]Entry, error) {
+	dirEntries, err := os.ReadDir(r.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Entry{}, nil
+		}
+
+		return nil, fmt.Errorf("read schedule directory: %w", err)
+	}
+
+	entries := make([]Entry, 0, len(dirEntries))
+	for _, dirEntry := range dirEntries {
+		name := dirEntry.Name()
+		if dirEntry.IsDir() || filepath.Ext(name) != ".json" {
+			continue
+		}
+
+		entry, readErr := r.readUnlocked(strings.TrimSuffix(name, ".json"))
+		if readErr != nil {
+			if errors.Is(readErr, ErrNotFound) || errors.Is(readErr, ErrInvalidID) {
+				continue
+			}
+
+			return nil, readErr
+		}
+		entries = append(entries, *entry)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].SendAt.Equal(entries[j].SendAt) {
+			return entries[i].SendAt.Before(entries[j].SendAt)
+		}
+
+		return entries[i].ID < entries[j].ID
+	})
+
+	return entries, nil
+}
+
+func (r *Repository) writeUnlocked(entry *Entry) error {
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode scheduled send: %w", err)
+	}
+
+	if err := config.WriteFileAtomic(r.path(entry.ID), append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("write scheduled send: %w", err)
+	}
+
+	return nil
+}
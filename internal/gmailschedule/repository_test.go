@@ -0,0 +1,133 @@
+package gmailschedule
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func newTestRepository(t *testing.T, clock *testClock, maxAttempts int) *Repository {
+	t.Helper()
+
+	return New(t.TempDir(), Options{Now: clock.Now, MaxAttempts: maxAttempts})
+}
+
+func TestRunDueSendsOnlyDueEntries(t *testing.T) {
+	t.Parallel()
+
+	clock := &testClock{now: time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)}
+	repo := newTestRepository(t, clock, 3)
+
+	later, err := repo.Add(Entry{Account: "a@b.com", DraftID: "d2", SendAt: clock.now.Add(2 * time.Hour)})
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	soon, err := repo.Add(Entry{Account: "a@b.com", DraftID: "d1", SendAt: clock.now.Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	entries, err := repo.List()
+	if err != nil || len(entries) != 2 || entries[0].ID != soon.ID || entries[1].ID != later.ID {
+		t.Fatalf("List = %#v err=%v", entries, err)
+	}
+
+	var sent []string
+
+	send := func(_ context.Context, entry Entry) (string, error) {
+		sent = append(sent, entry.DraftID)
+
+		return "m-" + entry.DraftID, nil
+	}
+
+	result, err := repo.RunDue(context.Background(), 0, send)
+	if err != nil || len(result.Sent) != 0 || len(sent) != 0 {
+		t.Fatalf("early run = %#v sent=%v err=%v", result, sent, err)
+	}
+
+	clock.now = clock.now.Add(90 * time.Minute)
+
+	result, err = repo.RunDue(context.Background(), 0, send)
+	if err != nil || len(result.Sent) != 1 || result.Sent[0].MessageID != "m-d1" {
+		t.Fatalf("due run = %#v err=%v", result, err)
+	}
+
+	got, err := repo.Get(soon.ID)
+	if err != nil || got.State != StateSent || got.SentAt.IsZero() {
+		t.Fatalf("Get = %#v err=%v", got, err)
+	}
+
+	if _, err := repo.Cancel(soon.ID, nil); !errors.Is(err, ErrNotActive) {
+		t.Fatalf("Cancel sent err = %v", err)
+	}
+
+	clock.now = clock.now.Add(48 * time.Hour)
+
+	if removed, err := repo.PruneSent(24 * time.Hour); err != nil || removed != 1 {
+		t.Fatalf("PruneSent = %d err=%v", removed, err)
+	}
+}
+
+func TestRunDueRetriesThenFails(t *testing.T) {
+	t.Parallel()
+
+	clock := &testClock{now: time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)}
+	repo := newTestRepository(t, clock, 2)
+
+	flaky, err := repo.Add(Entry{DraftID: "flaky", SendAt: clock.now})
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	gone, err := repo.Add(Entry{DraftID: "gone", SendAt: clock.now})
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	send := func(_ context.Context, entry Entry) (string, error) {
+		if entry.DraftID == "gone" {
+			return "", &PermanentError{Err: errors.New("draft deleted")}
+		}
+
+		return "", errors.New("backend error")
+	}
+
+	result, err := repo.RunDue(context.Background(), 0, send)
+	if err != nil || len(result.Retried) != 1 || len(result.Failed) != 1 || result.Failed[0].ID != gone.ID {
+		t.Fatalf("first run = %#v err=%v", result, err)
+	}
+
+	result, err = repo.RunDue(context.Background(), 0, send)
+	if err != nil || len(result.Failed) != 1 || result.Failed[0].ID != flaky.ID || result.Failed[0].LastError != "backend error" {
+		t.Fatalf("second run = %#v err=%v", result, err)
+	}
+
+	var cleaned string
+
+	cancelled, err := repo.Cancel(flaky.ID, func(entry Entry) error {
+		cleaned = entry.DraftID
+
+		return nil
+	})
+	if err != nil || cancelled.ID != flaky.ID || cleaned != "flaky" {
+		t.Fatalf("Cancel = %#v cleaned=%q err=%v", cancelled, cleaned, err)
+	}
+
+	if _, err := repo.Get(flaky.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get cancelled err = %v", err)
+	}
+
+	if _, err := repo.Get("../escape"); !errors.Is(err, ErrInvalidID) {
+		t.Fatalf("Get invalid err = %v", err)
+	}
+}
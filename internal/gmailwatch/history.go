@@ -14,6 +14,11 @@ type Message struct {
 	Body          string   `json:"body,omitempty"`
 	BodyTruncated bool     `json:"bodyTruncated,omitempty"`
 	Labels        []string `json:"labels,omitempty"`
+
+	// Headers and RuleBody are fetched only for rules evaluation and are not
+	// part of the hook payload.
+	Headers  map[string]string `json:"-"`
+	RuleBody string            `json:"-"`
 }
 
 type Payload struct {
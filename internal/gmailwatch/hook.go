@@ -15,6 +15,7 @@ type HTTPDoer interface {
 type HookSender struct {
 	URL    string
 	Token  string
+	Signer *HookSigner
 	Client HTTPDoer
 }
 
@@ -43,6 +44,10 @@ func (s *HookSender) Send(ctx context.Context, payload *Payload) DeliveryResult
 		request.Header.Set("Authorization", "Bearer "+s.Token)
 	}
 
+	if s.Signer != nil {
+		s.Signer.Sign(request.Header, data)
+	}
+
 	response, err := s.Client.Do(request)
 	if err != nil {
 		return DeliveryResult{
@@ -88,41 +88,105 @@ type HTTPHandler struct {
 }
 
 func (h *HTTPHandler) ServeHTTP(response http.ResponseWriter, request *http.Request) {
-	if !PathMatches(h.Config.Path, request.URL.Path) {
-		response.WriteHeader(http.StatusNotFound)
+	payload, ok := acceptPush(response, request, h.Config.Path, h.Config.BodyLimit, h.Authorize, h.warnf)
+	if !ok {
+		return
+	}
+
+	h.handlePush(response, request, payload)
+}
+
+// Router serves one push endpoint for several accounts and hands each
+// notification to the handler for its emailAddress.
+type Router struct {
+	Path      string
+	BodyLimit int64
+	Authorize func(*http.Request) bool
+	Lookup    func(account string) *HTTPHandler
+	Warnf     func(string, ...any)
+}
+
+func (r *Router) ServeHTTP(response http.ResponseWriter, request *http.Request) {
+	payload, ok := acceptPush(response, request, r.Path, r.BodyLimit, r.Authorize, r.warnf)
+	if !ok {
+		return
+	}
+
+	if strings.TrimSpace(payload.EmailAddress) == "" {
+		r.warnf("watch: push without emailAddress")
+		response.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	var handler *HTTPHandler
+	if r.Lookup != nil {
+		handler = r.Lookup(payload.EmailAddress)
+	}
+
+	if handler == nil {
+		r.warnf("watch: ignoring push for unsupervised account %s", payload.EmailAddress)
+		response.WriteHeader(http.StatusAccepted)
 
 		return
 	}
 
+	handler.handlePush(response, request, payload)
+}
+
+func (r *Router) warnf(format string, args ...any) {
+	if r.Warnf != nil {
+		r.Warnf(format, args...)
+	}
+}
+
+func acceptPush(
+	response http.ResponseWriter,
+	request *http.Request,
+	path string,
+	bodyLimit int64,
+	authorize func(*http.Request) bool,
+	warnf func(string, ...any),
+) (PushPayload, bool) {
+	if !PathMatches(path, request.URL.Path) {
+		response.WriteHeader(http.StatusNotFound)
+
+		return PushPayload{}, false
+	}
+
 	if request.Method != http.MethodPost {
 		response.Header().Set("Allow", http.MethodPost)
 		response.WriteHeader(http.StatusMethodNotAllowed)
 
-		return
+		return PushPayload{}, false
 	}
 
-	if h.Authorize != nil && !h.Authorize(request) {
+	if authorize != nil && !authorize(request) {
 		response.WriteHeader(http.StatusUnauthorized)
 
-		return
+		return PushPayload{}, false
 	}
 
-	envelope, err := ParsePush(request, h.Config.BodyLimit)
+	envelope, err := ParsePush(request, bodyLimit)
 	if err != nil {
-		h.warnf("watch: invalid push payload: %v", err)
+		warnf("watch: invalid push payload: %v", err)
 		response.WriteHeader(http.StatusBadRequest)
 
-		return
+		return PushPayload{}, false
 	}
 
 	payload, err := DecodePushPayload(envelope)
 	if err != nil {
-		h.warnf("watch: invalid push data: %v", err)
+		warnf("watch: invalid push data: %v", err)
 		response.WriteHeader(http.StatusBadRequest)
 
-		return
+		return PushPayload{}, false
 	}
 
+	return payload, true
+}
+
+func (h *HTTPHandler) handlePush(response http.ResponseWriter, request *http.Request, payload PushPayload) {
 	if payload.EmailAddress != "" && !strings.EqualFold(payload.EmailAddress, h.Config.Account) {
 		h.warnf("watch: ignoring push for %s", payload.EmailAddress)
 		response.WriteHeader(http.StatusAccepted)
@@ -42,6 +42,62 @@ func TestHTTPHandlerProcessesPush(t *testing.T) {
 	}
 }
 
+func TestRouterDispatchesByEmailAddress(t *testing.T) {
+	t.Parallel()
+
+	var processed []string
+
+	handlerFor := func(account string) *HTTPHandler {
+		return &HTTPHandler{
+			Config: HTTPConfig{Account: account, HasHook: true},
+			Process: func(_ context.Context, notification Notification) (*ProcessedPayload, error) {
+				processed = append(processed, account+":"+notification.HistoryID)
+
+				return &ProcessedPayload{Payload: &Payload{HistoryID: notification.HistoryID}}, nil
+			},
+		}
+	}
+	handlers := map[string]*HTTPHandler{
+		"a@example.com": handlerFor("a@example.com"),
+		"b@example.com": handlerFor("b@example.com"),
+	}
+	router := &Router{
+		Path:      "/hook",
+		BodyLimit: 1024,
+		Authorize: func(request *http.Request) bool { return request.Header.Get("X-Test-Auth") != "deny" },
+		Lookup:    func(account string) *HTTPHandler { return handlers[account] },
+	}
+
+	cases := []struct {
+		account string
+		deny    bool
+		want    int
+	}{
+		{account: "b@example.com", want: http.StatusOK},
+		{account: "a@example.com", want: http.StatusOK},
+		{account: "c@example.com", want: http.StatusAccepted},
+		{account: "", want: http.StatusBadRequest},
+		{account: "a@example.com", deny: true, want: http.StatusUnauthorized},
+	}
+	for i, tc := range cases {
+		request := pushRequest(t, tc.account, "200", "")
+		if tc.deny {
+			request.Header.Set("X-Test-Auth", "deny")
+		}
+
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, request)
+
+		if response.Code != tc.want {
+			t.Fatalf("case %d: status = %d, want %d", i, response.Code, tc.want)
+		}
+	}
+
+	if len(processed) != 2 || processed[0] != "b@example.com:200" || processed[1] != "a@example.com:200" {
+		t.Fatalf("processed = %v", processed)
+	}
+}
+
 func TestHTTPHandlerMapsNoMessagesAndRateLimit(t *testing.T) {
 	t.Parallel()
 
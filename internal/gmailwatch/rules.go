@@ -0,0 +1,561 @@
+package gmailwatch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/textproto"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const DefaultRuleCommandTimeout = 30 * time.Second
+
+var (
+	errRuleNoMatch   = errors.New("rule has no match conditions")
+	errRuleNoActions = errors.New("rule has no actions")
+)
+
+// Rules is a parsed rules file. Rules run in file order against every message
+// in a payload; a matching rule with stop set ends evaluation for that message.
+type Rules struct {
+	Rules []Rule
+}
+
+type Rule struct {
+	Name    string
+	Match   RuleMatch
+	Actions RuleActions
+	Stop    bool
+}
+
+// RuleMatch holds case-insensitive regular expressions. Every condition that
+// is set must match; labels must all be present on the message.
+type RuleMatch struct {
+	From    *regexp.Regexp
+	To      *regexp.Regexp
+	Subject *regexp.Regexp
+	Body    *regexp.Regexp
+	Labels  []string
+	Headers map[string]*regexp.Regexp
+}
+
+type RuleActions struct {
+	AddLabels    []string
+	RemoveLabels []string
+	Archive      bool
+	MarkRead     bool
+	Forward      []string
+	Reply        *template.Template
+	Command      []string
+}
+
+type rulesFile struct {
+	Rules []ruleSpec `yaml:"rules"`
+}
+
+type ruleSpec struct {
+	Name    string      `yaml:"name"`
+	Match   matchSpec   `yaml:"match"`
+	Actions actionsSpec `yaml:"actions"`
+	Stop    bool        `yaml:"stop"`
+}
+
+type matchSpec struct {
+	From    string            `yaml:"from"`
+	To      string            `yaml:"to"`
+	Subject string            `yaml:"subject"`
+	Body    string            `yaml:"body"`
+	Labels  []string          `yaml:"labels"`
+	Headers map[string]string `yaml:"headers"`
+}
+
+type actionsSpec struct {
+	AddLabels    []string `yaml:"add_labels"`
+	RemoveLabels []string `yaml:"remove_labels"`
+	Archive      bool     `yaml:"archive"`
+	MarkRead     bool     `yaml:"mark_read"`
+	Forward      []string `yaml:"forward"`
+	Reply        string   `yaml:"reply"`
+	ReplyFile    string   `yaml:"reply_file"`
+	Command      []string `yaml:"command"`
+}
+
+// LoadRules reads and compiles a rules file. Relative reply_file paths are
+// resolved against the rules file's directory.
+func LoadRules(path string) (*Rules, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // operator-supplied rules path.
+	if err != nil {
+		return nil, fmt.Errorf("read rules: %w", err)
+	}
+
+	rules, err := ParseRules(data, filepath.Dir(path))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return rules, nil
+}
+
+func ParseRules(data []byte, baseDir string) (*Rules, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var file rulesFile
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("parse rules: %w", err)
+	}
+
+	if len(file.Rules) == 0 {
+		return nil, errors.New("rules file defines no rules")
+	}
+
+	rules := &Rules{Rules: make([]Rule, 0, len(file.Rules))}
+	for i, spec := range file.Rules {
+		name := strings.TrimSpace(spec.Name)
+		if name == "" {
+			name = fmt.Sprintf("rule %d", i+1)
+		}
+
+		rule, err := compileRule(name, spec, baseDir)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		rules.Rules = append(rules.Rules, rule)
+	}
+
+	return rules, nil
+}
+
+func compileRule(name string, spec ruleSpec, baseDir string) (Rule, error) {
+	rule := Rule{Name: name, Stop: spec.Stop}
+
+	var err error
+	if rule.Match, err = compileMatch(spec.Match); err != nil {
+		return Rule{}, err
+	}
+
+	if rule.Actions, err = compileActions(spec.Actions, baseDir); err != nil {
+		return Rule{}, err
+	}
+
+	return rule, nil
+}
+
+func compileMatch(spec matchSpec) (RuleMatch, error) {
+	var match RuleMatch
+
+	fields := []struct {
+		name    string
+		pattern string
+		target  **regexp.Regexp
+	}{
+		{"from", spec.From, &match.From},
+		{"to", spec.To, &match.To},
+		{"subject", spec.Subject, &match.Subject},
+		{"body", spec.Body, &match.Body},
+	}
+	for _, field := range fields {
+		if field.pattern == "" {
+			continue
+		}
+
+		re, err := compileRulePattern(field.pattern)
+		if err != nil {
+			return RuleMatch{}, fmt.Errorf("match.%s: %w", field.name, err)
+		}
+
+		*field.target = re
+	}
+
+	match.Labels = trimNonEmpty(spec.Labels)
+
+	if len(spec.Headers) > 0 {
+		match.Headers = make(map[string]*regexp.Regexp, len(spec.Headers))
+		for header, pattern := range spec.Headers {
+			key := textproto.CanonicalMIMEHeaderKey(strings.TrimSpace(header))
+			if key == "" {
+				return RuleMatch{}, errors.New("match.headers: empty header name")
+			}
+
+			re, err := compileRulePattern(pattern)
+			if err != nil {
+				return RuleMatch{}, fmt.Errorf("match.headers.%s: %w", key, err)
+			}
+
+			match.Headers[key] = re
+		}
+	}
+
+	if match.From == nil && match.To == nil && match.Subject == nil && match.Body == nil &&
+		len(match.Labels) == 0 && len(match.Headers) == 0 {
+		return RuleMatch{}, errRuleNoMatch
+	}
+
+	return match, nil
+}
+
+func compileRulePattern(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile("(?i)" + pattern)
+}
+
+func compileActions(spec actionsSpec, baseDir string) (RuleActions, error) {
+	actions := RuleActions{
+		AddLabels:    trimNonEmpty(spec.AddLabels),
+		RemoveLabels: trimNonEmpty(spec.RemoveLabels),
+		Archive:      spec.Archive,
+		MarkRead:     spec.MarkRead,
+		Forward:      trimNonEmpty(spec.Forward),
+		Command:      spec.Command,
+	}
+
+	if len(spec.Command) > 0 && strings.TrimSpace(spec.Command[0]) == "" {
+		return RuleActions{}, errors.New("actions.command: empty program")
+	}
+
+	replyText := spec.Reply
+	if spec.ReplyFile != "" {
+		if replyText != "" {
+			return RuleActions{}, errors.New("actions: set reply or reply_file, not both")
+		}
+
+		path := spec.ReplyFile
+		if !filepath.IsAbs(path) && baseDir != "" {
+			path = filepath.Join(baseDir, path)
+		}
+
+		data, err := os.ReadFile(path) //nolint:gosec // operator-supplied template path.
+		if err != nil {
+			return RuleActions{}, fmt.Errorf("actions.reply_file: %w", err)
+		}
+
+		replyText = string(data)
+	}
+
+	if strings.TrimSpace(replyText) != "" {
+		tmpl, err := template.New("reply").Option("missingkey=error").Parse(replyText)
+		if err != nil {
+			return RuleActions{}, fmt.Errorf("actions.reply: %w", err)
+		}
+
+		actions.Reply = tmpl
+	}
+
+	if len(actions.AddLabels) == 0 && len(actions.RemoveLabels) == 0 && !actions.Archive && !actions.MarkRead &&
+		len(actions.Forward) == 0 && actions.Reply == nil && len(actions.Command) == 0 {
+		return RuleActions{}, errRuleNoActions
+	}
+
+	return actions, nil
+}
+
+func trimNonEmpty(values []string) []string {
+	out := make([]string, 0, len(values))
+	for _, value := range values {
+		if trimmed := strings.TrimSpace(value); trimmed != "" {
+			out = append(out, trimmed)
+		}
+	}
+
+	if len(out) == 0 {
+		return nil
+	}
+
+	return out
+}
+
+// Headers lists the extra message headers the rules match on, so sources can
+// request them alongside the basic metadata headers.
+func (r *Rules) Headers() []string {
+	seen := map[string]struct{}{}
+
+	var out []string
+
+	for _, rule := range r.Rules {
+		for header := range rule.Match.Headers {
+			if _, ok := seen[header]; ok {
+				continue
+			}
+
+			seen[header] = struct{}{}
+			out = append(out, header)
+		}
+	}
+
+	return out
+}
+
+// NeedsBody reports whether any rule matches on the message body.
+func (r *Rules) NeedsBody() bool {
+	for _, rule := range r.Rules {
+		if rule.Match.Body != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Sends reports whether any rule forwards or replies.
+func (r *Rules) Sends() bool {
+	for _, rule := range r.Rules {
+		if len(rule.Actions.Forward) > 0 || rule.Actions.Reply != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *Rules) matchesLabels() bool {
+	for _, rule := range r.Rules {
+		if len(rule.Match.Labels) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Matches reports whether msg satisfies every condition in m. labelNames maps
+// label IDs to display names so rules may use either.
+func (m RuleMatch) Matches(msg Message, labelNames map[string]string) bool {
+	if !matchPattern(m.From, msg.From) || !matchPattern(m.To, msg.To) || !matchPattern(m.Subject, msg.Subject) {
+		return false
+	}
+
+	if m.Body != nil {
+		body := msg.RuleBody
+		if body == "" {
+			body = msg.Body
+		}
+
+		if !m.Body.MatchString(body) {
+			return false
+		}
+	}
+
+	for header, re := range m.Headers {
+		if !re.MatchString(msg.Headers[header]) {
+			return false
+		}
+	}
+
+	for _, want := range m.Labels {
+		if !hasLabel(msg.Labels, labelNames, want) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func matchPattern(re *regexp.Regexp, value string) bool {
+	return re == nil || re.MatchString(value)
+}
+
+func hasLabel(labelIDs []string, labelNames map[string]string, want string) bool {
+	for _, id := range labelIDs {
+		if strings.EqualFold(id, want) || strings.EqualFold(labelNames[id], want) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RuleHandler carries out rule actions against the mailbox.
+type RuleHandler interface {
+	LabelNames(ctx context.Context) (map[string]string, error)
+	ModifyLabels(ctx context.Context, msg Message, add, remove []string) error
+	Forward(ctx context.Context, msg Message, to []string) error
+	Reply(ctx context.Context, msg Message, body string) error
+	RunCommand(ctx context.Context, rule string, msg Message, argv []string) error
+}
+
+// RuleResult records one action taken for one message. Err is set when the
+// action failed; later actions still run.
+type RuleResult struct {
+	MessageID string
+	Rule      string
+	Action    string
+	Err       error
+}
+
+// Apply evaluates the rules against every message in payload and runs the
+// matching actions. Label changes from all matching rules are merged into a
+// single modify call per message.
+func (r *Rules) Apply(ctx context.Context, payload *Payload, handler RuleHandler) []RuleResult {
+	if r == nil || payload == nil || len(payload.Messages) == 0 {
+		return nil
+	}
+
+	var (
+		results    []RuleResult
+		labelNames map[string]string
+	)
+
+	if r.matchesLabels() {
+		names, err := handler.LabelNames(ctx)
+		if err != nil {
+			results = append(results, RuleResult{Action: "labels", Err: fmt.Errorf("list labels: %w", err)})
+		}
+
+		labelNames = names
+	}
+
+	for _, msg := range payload.Messages {
+		results = append(results, r.applyMessage(ctx, msg, labelNames, handler)...)
+	}
+
+	return results
+}
+
+func (r *Rules) applyMessage(ctx context.Context, msg Message, labelNames map[string]string, handler RuleHandler) []RuleResult {
+	var (
+		results      []RuleResult
+		add, remove  []string
+		labelRules   []string
+		matchedRules int
+	)
+
+	for _, rule := range r.Rules {
+		if !rule.Match.Matches(msg, labelNames) {
+			continue
+		}
+
+		matchedRules++
+
+		actions := rule.Actions
+		ruleAdd := actions.AddLabels
+		ruleRemove := actions.RemoveLabels
+
+		if actions.Archive {
+			ruleRemove = append(append([]string(nil), ruleRemove...), "INBOX")
+		}
+
+		if actions.MarkRead {
+			ruleRemove = append(append([]string(nil), ruleRemove...), "UNREAD")
+		}
+
+		if len(ruleAdd) > 0 || len(ruleRemove) > 0 {
+			add = append(add, ruleAdd...)
+			remove = append(remove, ruleRemove...)
+			labelRules = append(labelRules, rule.Name)
+		}
+
+		if len(actions.Forward) > 0 {
+			err := handler.Forward(ctx, msg, actions.Forward)
+			results = append(results, RuleResult{MessageID: msg.ID, Rule: rule.Name, Action: "forward", Err: err})
+		}
+
+		if actions.Reply != nil {
+			var body bytes.Buffer
+
+			err := actions.Reply.Execute(&body, msg)
+			if err == nil {
+				err = handler.Reply(ctx, msg, body.String())
+			} else {
+				err = fmt.Errorf("render reply: %w", err)
+			}
+
+			results = append(results, RuleResult{MessageID: msg.ID, Rule: rule.Name, Action: "reply", Err: err})
+		}
+
+		if len(actions.Command) > 0 {
+			err := handler.RunCommand(ctx, rule.Name, msg, actions.Command)
+			results = append(results, RuleResult{MessageID: msg.ID, Rule: rule.Name, Action: "command", Err: err})
+		}
+
+		if rule.Stop {
+			break
+		}
+	}
+
+	if len(add) > 0 || len(remove) > 0 {
+		err := handler.ModifyLabels(ctx, msg, dedupeFold(add), dedupeFold(remove))
+		results = append(results, RuleResult{
+			MessageID: msg.ID,
+			Rule:      strings.Join(labelRules, ","),
+			Action:    "labels",
+			Err:       err,
+		})
+	}
+
+	return results
+}
+
+func dedupeFold(values []string) []string {
+	seen := make(map[string]struct{}, len(values))
+	out := make([]string, 0, len(values))
+
+	for _, value := range values {
+		key := strings.ToLower(value)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+
+		seen[key] = struct{}{}
+		out = append(out, value)
+	}
+
+	return out
+}
+
+// RunRuleCommand runs argv without a shell. The message is written to stdin as
+// JSON and described in GOG_WATCH_* environment variables.
+func RunRuleCommand(ctx context.Context, account, rule string, msg Message, argv []string, timeout time.Duration) error {
+	if len(argv) == 0 {
+		return errors.New("empty command")
+	}
+
+	if timeout <= 0 {
+		timeout = DefaultRuleCommandTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	input, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("encode message: %w", err)
+	}
+
+	command := exec.CommandContext(ctx, argv[0], argv[1:]...) //nolint:gosec // operator-configured rule command.
+	command.Stdin = bytes.NewReader(input)
+	command.Env = append(os.Environ(),
+		"GOG_WATCH_ACCOUNT="+account,
+		"GOG_WATCH_RULE="+rule,
+		"GOG_WATCH_MESSAGE_ID="+msg.ID,
+		"GOG_WATCH_THREAD_ID="+msg.ThreadID,
+	)
+
+	output, err := command.CombinedOutput()
+	if err != nil {
+		if trimmed := strings.TrimSpace(string(output)); trimmed != "" {
+			return fmt.Errorf("%w: %s", err, truncateOutput(trimmed, 500))
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func truncateOutput(value string, limit int) string {
+	if len(value) <= limit {
+		return value
+	}
+
+	return value[:limit] + "..."
+}
@@ -0,0 +1,248 @@
+package gmailwatch
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+type ruleCall struct {
+	action    string
+	messageID string
+	values    []string
+}
+
+type fakeRuleHandler struct {
+	labelNames map[string]string
+	labelErr   error
+	forwardErr error
+	calls      []ruleCall
+}
+
+func (h *fakeRuleHandler) LabelNames(context.Context) (map[string]string, error) {
+	h.calls = append(h.calls, ruleCall{action: "label_names"})
+
+	return h.labelNames, h.labelErr
+}
+
+func (h *fakeRuleHandler) ModifyLabels(_ context.Context, msg Message, add, remove []string) error {
+	values := append(append([]string{"+"}, add...), append([]string{"-"}, remove...)...)
+	h.calls = append(h.calls, ruleCall{action: "labels", messageID: msg.ID, values: values})
+
+	return nil
+}
+
+func (h *fakeRuleHandler) Forward(_ context.Context, msg Message, to []string) error {
+	h.calls = append(h.calls, ruleCall{action: "forward", messageID: msg.ID, values: to})
+
+	return h.forwardErr
+}
+
+func (h *fakeRuleHandler) Reply(_ context.Context, msg Message, body string) error {
+	h.calls = append(h.calls, ruleCall{action: "reply", messageID: msg.ID, values: []string{body}})
+
+	return nil
+}
+
+func (h *fakeRuleHandler) RunCommand(_ context.Context, rule string, msg Message, argv []string) error {
+	h.calls = append(h.calls, ruleCall{action: "command", messageID: msg.ID, values: append([]string{rule}, argv...)})
+
+	return nil
+}
+
+func TestParseRulesErrors(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		input string
+		want  string
+	}{
+		"empty":          {"rules: []\n", "defines no rules"},
+		"unknown field":  {"rules:\n  - match: {from: a}\n    actions: {archive: true}\n    colour: red\n", "colour"},
+		"no match":       {"rules:\n  - name: x\n    actions: {archive: true}\n", "no match conditions"},
+		"no actions":     {"rules:\n  - name: x\n    match: {from: a}\n", "no actions"},
+		"bad regex":      {"rules:\n  - name: x\n    match: {subject: \"(\"}\n    actions: {archive: true}\n", "match.subject"},
+		"bad template":   {"rules:\n  - name: x\n    match: {from: a}\n    actions: {reply: \"{{.From\"}\n", "actions.reply"},
+		"reply and file": {"rules:\n  - name: x\n    match: {from: a}\n    actions: {reply: hi, reply_file: r.txt}\n", "not both"},
+	}
+	for name, tc := range cases {
+		_, err := ParseRules([]byte(tc.input), "")
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("%s: err = %v, want %q", name, err, tc.want)
+		}
+	}
+}
+
+func TestLoadRulesResolvesReplyFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "reply.txt"), []byte("Hi {{.From}}"), 0o600); err != nil {
+		t.Fatalf("write reply: %v", err)
+	}
+
+	path := filepath.Join(dir, "rules.yaml")
+
+	input := "rules:\n  - match: {subject: hello}\n    actions: {reply_file: reply.txt}\n"
+	if err := os.WriteFile(path, []byte(input), 0o600); err != nil {
+		t.Fatalf("write rules: %v", err)
+	}
+
+	rules, err := LoadRules(path)
+	if err != nil {
+		t.Fatalf("LoadRules: %v", err)
+	}
+
+	if rules.Rules[0].Name != "rule 1" || rules.Rules[0].Actions.Reply == nil || !rules.Sends() {
+		t.Fatalf("unexpected rules: %#v", rules.Rules[0])
+	}
+}
+
+func TestRuleMatch(t *testing.T) {
+	t.Parallel()
+
+	rules, err := ParseRules([]byte(`
+rules:
+  - name: list
+    match:
+      from: "@lists\\.example\\.com"
+      subject: "^\\[ops\\]"
+      body: "outage"
+      labels: [Ops]
+      headers:
+        list-id: "ops\\.example\\.com"
+    actions: {archive: true}
+`), "")
+	if err != nil {
+		t.Fatalf("ParseRules: %v", err)
+	}
+
+	if got := rules.Headers(); !reflect.DeepEqual(got, []string{"List-Id"}) {
+		t.Fatalf("Headers = %v", got)
+	}
+
+	if !rules.NeedsBody() || rules.Sends() {
+		t.Fatalf("NeedsBody/Sends = %v/%v", rules.NeedsBody(), rules.Sends())
+	}
+
+	msg := Message{
+		ID:       "m1",
+		From:     "Bot <bot@LISTS.example.com>",
+		Subject:  "[OPS] weekly",
+		RuleBody: "There was an Outage today",
+		Labels:   []string{"INBOX", "Label_7"},
+		Headers:  map[string]string{"List-Id": "<ops.example.com>"},
+	}
+	names := map[string]string{"Label_7": "ops"}
+
+	match := rules.Rules[0].Match
+	if !match.Matches(msg, names) {
+		t.Fatal("expected match")
+	}
+
+	if match.Matches(msg, nil) {
+		t.Fatal("label name should need the label map")
+	}
+
+	msg.Headers = nil
+	if match.Matches(msg, names) {
+		t.Fatal("missing header should not match")
+	}
+}
+
+func TestRulesApply(t *testing.T) {
+	t.Parallel()
+
+	rules, err := ParseRules([]byte(`
+rules:
+  - name: receipts
+    match: {subject: receipt}
+    actions:
+      add_labels: [Finance]
+      mark_read: true
+      forward: [books@example.com]
+  - name: archive-finance
+    match: {labels: [INBOX], from: shop}
+    actions:
+      add_labels: [finance]
+      archive: true
+    stop: true
+  - name: never
+    match: {from: shop}
+    actions: {command: [notify]}
+  - name: thanks
+    match: {subject: question}
+    actions: {reply: "Thanks {{.From}}, re: {{.Subject}}"}
+`), "")
+	if err != nil {
+		t.Fatalf("ParseRules: %v", err)
+	}
+
+	handler := &fakeRuleHandler{forwardErr: errors.New("boom")}
+	payload := &Payload{Messages: []Message{
+		{ID: "m1", From: "shop@example.com", Subject: "Your receipt", Labels: []string{"INBOX"}},
+		{ID: "m2", From: "ann@example.com", Subject: "A question"},
+	}}
+
+	results := rules.Apply(context.Background(), payload, handler)
+
+	wantCalls := []ruleCall{
+		{action: "label_names"},
+		{action: "forward", messageID: "m1", values: []string{"books@example.com"}},
+		{action: "labels", messageID: "m1", values: []string{"+", "Finance", "-", "UNREAD", "INBOX"}},
+		{action: "reply", messageID: "m2", values: []string{"Thanks ann@example.com, re: A question"}},
+	}
+	if !reflect.DeepEqual(handler.calls, wantCalls) {
+		t.Fatalf("calls = %#v", handler.calls)
+	}
+
+	if len(results) != 3 {
+		t.Fatalf("results = %#v", results)
+	}
+
+	if results[0].Action != "forward" || results[0].Err == nil {
+		t.Fatalf("forward result = %#v", results[0])
+	}
+
+	if results[1].Rule != "receipts,archive-finance" || results[1].Err != nil {
+		t.Fatalf("labels result = %#v", results[1])
+	}
+}
+
+func TestRunRuleCommand(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	out := filepath.Join(t.TempDir(), "out")
+	script := `cat > "$1"; printf '\n%s %s %s' "$GOG_WATCH_ACCOUNT" "$GOG_WATCH_RULE" "$GOG_WATCH_MESSAGE_ID" >> "$1"`
+
+	err := RunRuleCommand(context.Background(), "a@example.com", "notify", Message{ID: "m1", Subject: "S"},
+		[]string{"sh", "-c", script, "sh", out}, 0)
+	if err != nil {
+		t.Fatalf("RunRuleCommand: %v", err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+
+	want := `{"id":"m1","threadId":"","subject":"S"}` + "\na@example.com notify m1"
+	if string(data) != want {
+		t.Fatalf("output = %q, want %q", data, want)
+	}
+
+	err = RunRuleCommand(context.Background(), "a@example.com", "fail", Message{ID: "m1"},
+		[]string{"sh", "-c", "echo nope >&2; exit 3"}, 0)
+	if err == nil || !strings.Contains(err.Error(), "nope") {
+		t.Fatalf("expected command failure with output, got %v", err)
+	}
+}
@@ -0,0 +1,273 @@
+package gmailwatch
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/99designs/keyring"
+
+	"github.com/steipete/gogcli/internal/secrets"
+)
+
+var (
+	ErrNoHookSecret           = errors.New("no hook signing secret")
+	ErrHookSecretVersion      = errors.New("unknown hook signing secret version")
+	ErrHookSecretCurrent      = errors.New("cannot delete the current hook signing secret")
+	errHookSecretAccount      = errors.New("missing account")
+	errNilHookSecretStore     = errors.New("hook secret store is nil")
+	errMaxHookSecretVersions  = errors.New("hook signing secret version limit reached")
+	errInvalidHookSecretIndex = errors.New("invalid hook signing secret index")
+)
+
+const (
+	hookSecretPrefix         = "hook_secret_v"
+	hookSecretVersionsSuffix = "hook_secret_versions"
+	hookSecretCurrentSuffix  = "hook_secret_current"
+	maxHookSecretVersion     = 255
+)
+
+// HookSecrets is the set of signing secrets kept for one account. Older
+// versions stay available so receivers can be moved over gradually.
+type HookSecrets struct {
+	Current int
+	Keys    map[int]string
+}
+
+// Versions returns the stored versions in ascending order.
+func (h HookSecrets) Versions() []int {
+	versions := make([]int, 0, len(h.Keys))
+	for version := range h.Keys {
+		versions = append(versions, version)
+	}
+
+	slices.Sort(versions)
+
+	return versions
+}
+
+// Signer returns a HookSigner for the current secret.
+func (h HookSecrets) Signer() (*HookSigner, error) {
+	secret := h.Keys[h.Current]
+	if h.Current == 0 || secret == "" {
+		return nil, ErrNoHookSecret
+	}
+
+	return &HookSigner{KeyVersion: h.Current, Secret: []byte(secret)}, nil
+}
+
+// HookSecretStore keeps versioned hook signing secrets in the keyring, one
+// entry per version plus a version index and current pointer.
+type HookSecretStore struct {
+	store secrets.SecretStore
+}
+
+func NewHookSecretStore(store secrets.SecretStore) (*HookSecretStore, error) {
+	if store == nil {
+		return nil, errNilHookSecretStore
+	}
+
+	return &HookSecretStore{store: store}, nil
+}
+
+// Load reads every stored secret for account. An account without secrets
+// returns an empty set and no error.
+func (s *HookSecretStore) Load(account string) (HookSecrets, error) {
+	account = normalizeSecretAccount(account)
+	if account == "" {
+		return HookSecrets{}, errHookSecretAccount
+	}
+
+	out := HookSecrets{Keys: map[int]string{}}
+
+	versions, err := s.readVersions(account)
+	if err != nil {
+		return HookSecrets{}, err
+	}
+
+	for _, version := range versions {
+		value, readErr := s.read(hookSecretKey(account, hookSecretPrefix+strconv.Itoa(version)))
+		if readErr != nil {
+			return HookSecrets{}, fmt.Errorf("read hook secret v%d: %w", version, readErr)
+		}
+
+		if value != "" {
+			out.Keys[version] = value
+		}
+	}
+
+	current, err := s.read(hookSecretKey(account, hookSecretCurrentSuffix))
+	if err != nil {
+		return HookSecrets{}, fmt.Errorf("read current hook secret version: %w", err)
+	}
+
+	out.Current, _ = strconv.Atoi(current)
+	if out.Keys[out.Current] == "" {
+		out.Current = 0
+		for version := range out.Keys {
+			out.Current = max(out.Current, version)
+		}
+	}
+
+	return out, nil
+}
+
+// Rotate generates a new secret, stores it as the next version, and makes it
+// current. Earlier versions are kept until deleted.
+func (s *HookSecretStore) Rotate(account string) (int, string, error) {
+	existing, err := s.Load(account)
+	if err != nil {
+		return 0, "", err
+	}
+
+	account = normalizeSecretAccount(account)
+
+	next := 1
+	for version := range existing.Keys {
+		next = max(next, version+1)
+	}
+
+	if next > maxHookSecretVersion {
+		return 0, "", errMaxHookSecretVersions
+	}
+
+	secret, err := GenerateHookSecret()
+	if err != nil {
+		return 0, "", err
+	}
+
+	if err := s.write(hookSecretKey(account, hookSecretPrefix+strconv.Itoa(next)), secret); err != nil {
+		return 0, "", fmt.Errorf("store hook secret v%d: %w", next, err)
+	}
+
+	existing.Keys[next] = secret
+	if err := s.writeVersions(account, existing.Versions()); err != nil {
+		return 0, "", err
+	}
+
+	if err := s.write(hookSecretKey(account, hookSecretCurrentSuffix), strconv.Itoa(next)); err != nil {
+		return 0, "", fmt.Errorf("store current hook secret version: %w", err)
+	}
+
+	return next, secret, nil
+}
+
+// Delete removes one retired version. The current version cannot be deleted.
+func (s *HookSecretStore) Delete(account string, version int) error {
+	existing, err := s.Load(account)
+	if err != nil {
+		return err
+	}
+
+	account = normalizeSecretAccount(account)
+
+	if _, ok := existing.Keys[version]; !ok {
+		return fmt.Errorf("%w: %d", ErrHookSecretVersion, version)
+	}
+
+	if version == existing.Current {
+		return ErrHookSecretCurrent
+	}
+
+	delete(existing.Keys, version)
+
+	if err := s.writeVersions(account, existing.Versions()); err != nil {
+		return err
+	}
+
+	if err := s.store.DeleteSecret(hookSecretKey(account, hookSecretPrefix+strconv.Itoa(version))); err != nil &&
+		!errors.Is(err, keyring.ErrKeyNotFound) {
+		return fmt.Errorf("delete hook secret v%d: %w", version, err)
+	}
+
+	return nil
+}
+
+// GenerateHookSecret returns 32 random bytes encoded as base64.
+func GenerateHookSecret() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("generate hook secret: %w", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+func (s *HookSecretStore) readVersions(account string) ([]int, error) {
+	raw, err := s.read(hookSecretKey(account, hookSecretVersionsSuffix))
+	if err != nil {
+		return nil, fmt.Errorf("read hook secret versions: %w", err)
+	}
+
+	var versions []int
+
+	for part := range strings.SplitSeq(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		version, convErr := strconv.Atoi(part)
+		if convErr != nil || version < 1 || version > maxHookSecretVersion {
+			return nil, fmt.Errorf("%w: %q", errInvalidHookSecretIndex, part)
+		}
+
+		versions = append(versions, version)
+	}
+
+	return versions, nil
+}
+
+func (s *HookSecretStore) writeVersions(account string, versions []int) error {
+	parts := make([]string, 0, len(versions))
+	for _, version := range versions {
+		parts = append(parts, strconv.Itoa(version))
+	}
+
+	if err := s.write(hookSecretKey(account, hookSecretVersionsSuffix), strings.Join(parts, ",")); err != nil {
+		return fmt.Errorf("store hook secret versions: %w", err)
+	}
+
+	return nil
+}
+
+func (s *HookSecretStore) read(key string) (string, error) {
+	if s == nil || s.store == nil {
+		return "", errNilHookSecretStore
+	}
+
+	value, err := s.store.GetSecret(key)
+	if errors.Is(err, keyring.ErrKeyNotFound) {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("read secret: %w", err)
+	}
+
+	return string(value), nil
+}
+
+func (s *HookSecretStore) write(key, value string) error {
+	if s == nil || s.store == nil {
+		return errNilHookSecretStore
+	}
+
+	if err := s.store.SetSecret(key, []byte(value)); err != nil {
+		return fmt.Errorf("set secret: %w", err)
+	}
+
+	return nil
+}
+
+func normalizeSecretAccount(account string) string {
+	return strings.ToLower(strings.TrimSpace(account))
+}
+
+func hookSecretKey(account, suffix string) string {
+	return fmt.Sprintf("gmail-watch/%s/%s", strings.ReplaceAll(account, " ", ""), suffix)
+}
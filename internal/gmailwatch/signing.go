@@ -0,0 +1,108 @@
+package gmailwatch
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Hook signature headers. The signature covers "<timestamp>.<body>" so a
+// receiver can reject both tampered bodies and replays outside its window.
+const (
+	SignatureHeader           = "X-Gog-Signature"
+	SignatureTimestampHeader  = "X-Gog-Timestamp"
+	SignatureKeyVersionHeader = "X-Gog-Key-Version"
+	SignaturePrefix           = "sha256="
+	DefaultSignatureTolerance = 5 * time.Minute
+)
+
+var (
+	ErrSignatureMissing    = errors.New("missing hook signature headers")
+	ErrSignatureMismatch   = errors.New("hook signature mismatch")
+	ErrSignatureExpired    = errors.New("hook signature timestamp outside tolerance")
+	ErrSignatureUnknownKey = errors.New("unknown hook signing key version")
+)
+
+// HookSigner adds HMAC-SHA256 signature headers to hook requests.
+type HookSigner struct {
+	KeyVersion int
+	Secret     []byte
+	Now        func() time.Time
+}
+
+// Sign sets the timestamp, key version, and signature headers for body.
+func (s *HookSigner) Sign(header http.Header, body []byte) {
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+
+	timestamp := strconv.FormatInt(now().Unix(), 10)
+	header.Set(SignatureTimestampHeader, timestamp)
+	header.Set(SignatureKeyVersionHeader, strconv.Itoa(s.KeyVersion))
+	header.Set(SignatureHeader, SignaturePrefix+ComputeSignature(s.Secret, timestamp, body))
+}
+
+// ComputeSignature returns the hex HMAC-SHA256 of "<timestamp>.<body>".
+func ComputeSignature(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte{'.'})
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature checks the signature headers on a received hook request
+// against the secret for the advertised key version. A zero tolerance uses
+// DefaultSignatureTolerance.
+func VerifySignature(header http.Header, body []byte, secrets map[int][]byte, now time.Time, tolerance time.Duration) error {
+	timestamp := strings.TrimSpace(header.Get(SignatureTimestampHeader))
+	signature := strings.TrimSpace(header.Get(SignatureHeader))
+	versionRaw := strings.TrimSpace(header.Get(SignatureKeyVersionHeader))
+
+	if timestamp == "" || signature == "" || versionRaw == "" {
+		return ErrSignatureMissing
+	}
+
+	version, err := strconv.Atoi(versionRaw)
+	if err != nil {
+		return fmt.Errorf("%w: %q", ErrSignatureUnknownKey, versionRaw)
+	}
+
+	secret, ok := secrets[version]
+	if !ok || len(secret) == 0 {
+		return fmt.Errorf("%w: %d", ErrSignatureUnknownKey, version)
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: %q", ErrSignatureExpired, timestamp)
+	}
+
+	if tolerance <= 0 {
+		tolerance = DefaultSignatureTolerance
+	}
+
+	if skew := now.Sub(time.Unix(unix, 0)); skew > tolerance || skew < -tolerance {
+		return ErrSignatureExpired
+	}
+
+	got, err := hex.DecodeString(strings.TrimPrefix(signature, SignaturePrefix))
+	if err != nil || !strings.HasPrefix(signature, SignaturePrefix) {
+		return ErrSignatureMismatch
+	}
+
+	want, _ := hex.DecodeString(ComputeSignature(secret, timestamp, body))
+	if !hmac.Equal(got, want) {
+		return ErrSignatureMismatch
+	}
+
+	return nil
+}
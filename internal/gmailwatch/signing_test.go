@@ -0,0 +1,177 @@
+package gmailwatch
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/99designs/keyring"
+)
+
+func TestHookSenderSignsBody(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_700_000_000, 0)
+	secrets := map[int][]byte{1: []byte("old"), 2: []byte("new")}
+
+	var (
+		header http.Header
+		body   []byte
+	)
+
+	sender := &HookSender{
+		URL:    "https://example.com/hook",
+		Signer: &HookSigner{KeyVersion: 2, Secret: secrets[2], Now: func() time.Time { return now }},
+		Client: hookDoer(func(request *http.Request) (*http.Response, error) {
+			header = request.Header.Clone()
+			body, _ = io.ReadAll(request.Body)
+
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(""))}, nil
+		}),
+	}
+
+	if result := sender.Send(context.Background(), &Payload{HistoryID: "200"}); result.Err != nil {
+		t.Fatalf("Send: %v", result.Err)
+	}
+
+	if header.Get(SignatureTimestampHeader) != "1700000000" || header.Get(SignatureKeyVersionHeader) != "2" ||
+		!strings.HasPrefix(header.Get(SignatureHeader), SignaturePrefix) {
+		t.Fatalf("headers = %#v", header)
+	}
+
+	if err := VerifySignature(header, body, secrets, now.Add(time.Minute), 0); err != nil {
+		t.Fatalf("VerifySignature: %v", err)
+	}
+
+	cases := map[string]struct {
+		mutate func(http.Header) ([]byte, time.Time)
+		want   error
+	}{
+		"tampered body": {func(http.Header) ([]byte, time.Time) { return append(body, ' '), now }, ErrSignatureMismatch},
+		"replayed late": {func(http.Header) ([]byte, time.Time) { return body, now.Add(10 * time.Minute) }, ErrSignatureExpired},
+		"wrong key version": {func(h http.Header) ([]byte, time.Time) {
+			h.Set(SignatureKeyVersionHeader, "1")
+
+			return body, now
+		}, ErrSignatureMismatch},
+		"retired key": {func(h http.Header) ([]byte, time.Time) {
+			h.Set(SignatureKeyVersionHeader, "3")
+
+			return body, now
+		}, ErrSignatureUnknownKey},
+		"unsigned": {func(h http.Header) ([]byte, time.Time) {
+			h.Del(SignatureHeader)
+
+			return body, now
+		}, ErrSignatureMissing},
+	}
+	for name, tc := range cases {
+		h := header.Clone()
+		b, at := tc.mutate(h)
+
+		if err := VerifySignature(h, b, secrets, at, 0); !errors.Is(err, tc.want) {
+			t.Fatalf("%s: err = %v, want %v", name, err, tc.want)
+		}
+	}
+}
+
+type memoryHookSecrets struct {
+	mu     sync.Mutex
+	values map[string][]byte
+}
+
+func (m *memoryHookSecrets) SetSecret(key string, value []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.values[key] = append([]byte(nil), value...)
+
+	return nil
+}
+
+func (m *memoryHookSecrets) GetSecret(key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	value, ok := m.values[key]
+	if !ok {
+		return nil, keyring.ErrKeyNotFound
+	}
+
+	return value, nil
+}
+
+func (m *memoryHookSecrets) DeleteSecret(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.values, key)
+
+	return nil
+}
+
+func TestHookSecretStoreRotateAndDelete(t *testing.T) {
+	t.Parallel()
+
+	backend := &memoryHookSecrets{values: map[string][]byte{}}
+
+	store, err := NewHookSecretStore(backend)
+	if err != nil {
+		t.Fatalf("NewHookSecretStore: %v", err)
+	}
+
+	empty, err := store.Load("A@B.com")
+	if err != nil || empty.Current != 0 || len(empty.Keys) != 0 {
+		t.Fatalf("empty = %#v err=%v", empty, err)
+	}
+
+	if _, signerErr := empty.Signer(); !errors.Is(signerErr, ErrNoHookSecret) {
+		t.Fatalf("Signer err = %v", signerErr)
+	}
+
+	first, firstSecret, err := store.Rotate("A@B.com")
+	if err != nil || first != 1 || firstSecret == "" {
+		t.Fatalf("Rotate = %d %q %v", first, firstSecret, err)
+	}
+
+	second, secondSecret, err := store.Rotate("a@b.com")
+	if err != nil || second != 2 || secondSecret == firstSecret {
+		t.Fatalf("Rotate = %d %v", second, err)
+	}
+
+	loaded, err := store.Load("a@b.com")
+	if err != nil || loaded.Current != 2 || loaded.Keys[1] != firstSecret || loaded.Keys[2] != secondSecret {
+		t.Fatalf("loaded = %#v err=%v", loaded, err)
+	}
+
+	signer, err := loaded.Signer()
+	if err != nil || signer.KeyVersion != 2 || string(signer.Secret) != secondSecret {
+		t.Fatalf("Signer = %#v err=%v", signer, err)
+	}
+
+	if err := store.Delete("a@b.com", 2); !errors.Is(err, ErrHookSecretCurrent) {
+		t.Fatalf("delete current err = %v", err)
+	}
+
+	if err := store.Delete("a@b.com", 7); !errors.Is(err, ErrHookSecretVersion) {
+		t.Fatalf("delete unknown err = %v", err)
+	}
+
+	if err := store.Delete("a@b.com", 1); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	if _, ok := backend.values["gmail-watch/a@b.com/hook_secret_v1"]; ok {
+		t.Fatal("retired secret still stored")
+	}
+
+	loaded, err = store.Load("a@b.com")
+	if err != nil || loaded.Current != 2 || len(loaded.Keys) != 1 {
+		t.Fatalf("after delete = %#v err=%v", loaded, err)
+	}
+}
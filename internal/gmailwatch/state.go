@@ -19,6 +19,7 @@ type Hook struct {
 	Token       string `json:"token,omitempty"`
 	IncludeBody bool   `json:"includeBody,omitempty"`
 	MaxBytes    int    `json:"maxBytes,omitempty"`
+	Sign        bool   `json:"sign,omitempty"`
 }
 
 type State struct {
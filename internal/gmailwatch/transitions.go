@@ -6,6 +6,7 @@ const (
 	DeliveryStatusError     = "error"
 	DeliveryStatusHTTPError = "http_error"
 	DeliveryStatusOK        = "ok"
+	DeliveryStatusQueued    = "queued"
 	DeliveryStatusRateLimit = "rate_limited"
 )
 
@@ -107,3 +108,32 @@ func (r *Repository) RecordDelivery(status, note string, now time.Time) error {
 		return nil
 	})
 }
+
+// RecordRenewal stores a renewed watch expiration without touching the history
+// cursor or delivery status.
+func (r *Repository) RecordRenewal(expirationMs, renewAfterMs int64, now time.Time) error {
+	return r.Update(func(state *State) error {
+		state.ExpirationMs = expirationMs
+		state.ProviderExpirationMs = expirationMs
+		state.RenewAfterMs = renewAfterMs
+		state.UpdatedAtMs = now.UnixMilli()
+
+		return nil
+	})
+}
+
+// RenewalDue reports whether a watch should be renewed: once RenewAfterMs has
+// passed, or within margin of the provider expiration.
+func RenewalDue(state State, now time.Time, margin time.Duration) bool {
+	nowMs := now.UnixMilli()
+	if state.RenewAfterMs > 0 && nowMs >= state.RenewAfterMs {
+		return true
+	}
+
+	expiration := state.ProviderExpirationMs
+	if expiration <= 0 {
+		expiration = state.ExpirationMs
+	}
+
+	return expiration > 0 && nowMs >= expiration-margin.Milliseconds()
+}
@@ -119,3 +119,39 @@ func TestRepositoryRecordDelivery(t *testing.T) {
 		t.Fatalf("delivery state = %#v", state)
 	}
 }
+
+func TestRecordRenewalKeepsCursorAndRenewalDue(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 6, 13, 12, 0, 0, 0, time.UTC)
+	repository := NewMemory(State{
+		HistoryID:          "100",
+		ExpirationMs:       now.Add(time.Hour).UnixMilli(),
+		LastDeliveryStatus: DeliveryStatusOK,
+	}, Options{})
+
+	state := repository.Get()
+	if RenewalDue(state, now, 30*time.Minute) {
+		t.Fatal("renewal due too early")
+	}
+
+	if !RenewalDue(state, now, 2*time.Hour) {
+		t.Fatal("renewal not due inside margin")
+	}
+
+	state.RenewAfterMs = now.Add(-time.Minute).UnixMilli()
+	if !RenewalDue(state, now, 0) {
+		t.Fatal("renewal not due after renewAfterMs")
+	}
+
+	expiration := now.Add(7 * 24 * time.Hour).UnixMilli()
+	if err := repository.RecordRenewal(expiration, 0, now); err != nil {
+		t.Fatalf("RecordRenewal: %v", err)
+	}
+
+	renewed := repository.Get()
+	if renewed.HistoryID != "100" || renewed.LastDeliveryStatus != DeliveryStatusOK ||
+		renewed.ProviderExpirationMs != expiration || renewed.UpdatedAtMs != now.UnixMilli() {
+		t.Fatalf("renewed state = %#v", renewed)
+	}
+}
@@ -0,0 +1,294 @@
+package googleapi
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AuditLog appends one JSON line per mutating Google API request. Each line is
+// written with a single O_APPEND write, so separate gog processes can share a
+// log file.
+type AuditLog struct {
+	path       string
+	hashBodies bool
+
+	mu  sync.Mutex
+	now func() time.Time
+}
+
+// AuditEntry is one audit log line.
+type AuditEntry struct {
+	Time       string `json:"ts"`
+	Account    string `json:"account,omitempty"`
+	Command    string `json:"command,omitempty"`
+	Method     string `json:"method"`
+	Host       string `json:"host"`
+	Resource   string `json:"resource"`
+	Status     int    `json:"status,omitempty"`
+	Error      string `json:"error,omitempty"`
+	BodySHA256 string `json:"body_sha256,omitempty"`
+}
+
+func NewAuditLog(path string, hashBodies bool) *AuditLog {
+	return &AuditLog{path: path, hashBodies: hashBodies, now: time.Now}
+}
+
+func (l *AuditLog) Path() string {
+	return l.path
+}
+
+func (l *AuditLog) HashBodies() bool {
+	return l.hashBodies
+}
+
+func (l *AuditLog) write(entry AuditEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encode audit entry: %w", err)
+	}
+
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o700); err != nil {
+		return fmt.Errorf("create audit log dir: %w", err)
+	}
+
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600) //nolint:gosec // operator-configured audit path.
+	if err != nil {
+		return fmt.Errorf("open audit log: %w", err)
+	}
+
+	if _, err := f.Write(line); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("write audit log: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close audit log: %w", err)
+	}
+
+	return nil
+}
+
+type auditContextKey struct{}
+
+type auditContext struct {
+	resolve func() (*AuditLog, error)
+	command string
+}
+
+// auditTarget is a resolved audit log and the command it records.
+type auditTarget struct {
+	log     *AuditLog
+	command string
+}
+
+// WithAuditLog records mutating requests made with ctx, or by clients created
+// with ctx, to log under the given command path.
+func WithAuditLog(ctx context.Context, log *AuditLog, command string) context.Context {
+	if log == nil || strings.TrimSpace(log.path) == "" {
+		return ctx
+	}
+
+	return WithAuditLogResolver(ctx, func() (*AuditLog, error) { return log, nil }, command)
+}
+
+// WithAuditLogResolver is WithAuditLog for a log that is looked up only when
+// a Google API client is built, so commands that never reach Google do not
+// depend on the audit settings. resolve runs at most once; a nil log turns
+// auditing off.
+func WithAuditLogResolver(ctx context.Context, resolve func() (*AuditLog, error), command string) context.Context {
+	if resolve == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, auditContextKey{}, auditContext{resolve: sync.OnceValues(resolve), command: command})
+}
+
+func auditFromContext(ctx context.Context) (auditTarget, bool, error) {
+	if ctx == nil {
+		return auditTarget{}, false, nil
+	}
+
+	audit, ok := ctx.Value(auditContextKey{}).(auditContext)
+	if !ok {
+		return auditTarget{}, false, nil
+	}
+
+	log, err := audit.resolve()
+	if err != nil {
+		return auditTarget{}, false, err
+	}
+
+	if log == nil || strings.TrimSpace(log.path) == "" {
+		return auditTarget{}, false, nil
+	}
+
+	return auditTarget{log: log, command: audit.command}, true, nil
+}
+
+type auditTransport struct {
+	base    http.RoundTripper
+	account string
+	created auditTarget
+}
+
+// auditTransportFromContext wraps base when ctx carries an audit log. The
+// request context wins over the creation context so long-lived clients that
+// serve several commands record the command that made each request.
+func auditTransportFromContext(ctx context.Context, account string, base http.RoundTripper) (http.RoundTripper, error) {
+	audit, ok, err := auditFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return base, nil
+	}
+
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &auditTransport{base: base, account: account, created: audit}, nil
+}
+
+func (t *auditTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if request == nil || request.URL == nil || !auditedMethod(request.Method) {
+		return t.base.RoundTrip(request)
+	}
+
+	audit := t.created
+	if fromRequest, ok, err := auditFromContext(request.Context()); err == nil && ok {
+		audit = fromRequest
+	}
+
+	entry := AuditEntry{
+		Time:     audit.log.now().UTC().Format(time.RFC3339Nano),
+		Account:  t.account,
+		Command:  audit.command,
+		Method:   request.Method,
+		Host:     request.URL.Host,
+		Resource: request.URL.Path,
+	}
+
+	var bodyHash *hashingBody
+	if audit.log.hashBodies {
+		var err error
+
+		entry.BodySHA256, bodyHash, err = hashRequestBody(request)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	response, err := t.base.RoundTrip(request)
+	if response != nil {
+		entry.Status = response.StatusCode
+	}
+
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	if bodyHash != nil {
+		entry.BodySHA256 = bodyHash.sum()
+	}
+
+	if writeErr := audit.log.write(entry); writeErr != nil {
+		slog.Warn("audit log write failed", "path", audit.log.path, "err", writeErr)
+	}
+
+	return response, err
+}
+
+func auditedMethod(method string) bool {
+	switch method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	default:
+		return true
+	}
+}
+
+// hashRequestBody hashes a replayable body up front. Streaming bodies are
+// hashed as the transport reads them; their hash is recorded only if the body
+// was read to the end.
+func hashRequestBody(request *http.Request) (string, *hashingBody, error) {
+	if request.Body == nil || request.Body == http.NoBody {
+		return "", nil, nil
+	}
+
+	if _, err := ensureReplayableBody(request); err != nil {
+		return "", nil, err
+	}
+
+	if request.GetBody != nil {
+		body, err := request.GetBody()
+		if err != nil {
+			return "", nil, fmt.Errorf("read request body for audit: %w", err)
+		}
+		defer body.Close()
+
+		h := sha256.New()
+		if _, err := io.Copy(h, body); err != nil {
+			return "", nil, fmt.Errorf("hash request body: %w", err)
+		}
+
+		return hex.EncodeToString(h.Sum(nil)), nil, nil
+	}
+
+	wrapped := &hashingBody{ReadCloser: request.Body, hash: sha256.New()}
+	request.Body = wrapped
+
+	return "", wrapped, nil
+}
+
+type hashingBody struct {
+	io.ReadCloser
+	hash hash.Hash
+
+	mu   sync.Mutex
+	done bool
+}
+
+func (b *hashingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	_, _ = b.hash.Write(p[:n])
+	if errors.Is(err, io.EOF) {
+		b.done = true
+	}
+
+	return n, err
+}
+
+func (b *hashingBody) sum() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.done {
+		return ""
+	}
+
+	return hex.EncodeToString(b.hash.Sum(nil))
+}
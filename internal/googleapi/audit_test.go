@@ -0,0 +1,178 @@
+package googleapi
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type auditTestTransport struct {
+	bodies []string
+}
+
+func (t *auditTestTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	body := ""
+	if request.Body != nil {
+		raw, err := io.ReadAll(request.Body)
+		if err != nil {
+			return nil, err
+		}
+
+		body = string(raw)
+	}
+
+	t.bodies = append(t.bodies, body)
+
+	return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+}
+
+func TestAuditTransportRecordsMutatingRequests(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "audit.jsonl")
+	log := NewAuditLog(path, true)
+	log.now = func() time.Time { return time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC) }
+
+	base := &auditTestTransport{}
+	transport, err := auditTransportFromContext(WithAuditLog(context.Background(), log, "gmail labels create"), "a@example.com", base)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	get, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "https://gmail.googleapis.com/gmail/v1/users/me/labels", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	post, err := http.NewRequestWithContext(context.Background(), http.MethodPost, "https://gmail.googleapis.com/gmail/v1/users/me/labels?alt=json", strings.NewReader(`{"name":"x"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A shared client records the command from the request context.
+	deleteCtx := WithAuditLog(context.Background(), log, "drive delete")
+	del, err := http.NewRequestWithContext(deleteCtx, http.MethodDelete, "https://www.googleapis.com/drive/v3/files/f1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, request := range []*http.Request{get, post, del} {
+		response, roundTripErr := transport.RoundTrip(request)
+		if roundTripErr != nil {
+			t.Fatalf("%s: %v", request.Method, roundTripErr)
+		}
+
+		_ = response.Body.Close()
+	}
+
+	if len(base.bodies) != 3 || base.bodies[1] != `{"name":"x"}` {
+		t.Fatalf("body not forwarded intact: %#v", base.bodies)
+	}
+
+	entries := readAuditEntries(t, path)
+	if len(entries) != 2 {
+		t.Fatalf("entries = %#v", entries)
+	}
+
+	sum := sha256.Sum256([]byte(`{"name":"x"}`))
+	want := AuditEntry{
+		Time:       "2026-03-04T05:06:07Z",
+		Account:    "a@example.com",
+		Command:    "gmail labels create",
+		Method:     http.MethodPost,
+		Host:       "gmail.googleapis.com",
+		Resource:   "/gmail/v1/users/me/labels",
+		Status:     http.StatusOK,
+		BodySHA256: hex.EncodeToString(sum[:]),
+	}
+	if entries[0] != want {
+		t.Fatalf("post entry = %#v, want %#v", entries[0], want)
+	}
+
+	if entries[1].Command != "drive delete" || entries[1].Method != http.MethodDelete || entries[1].BodySHA256 != "" {
+		t.Fatalf("delete entry = %#v", entries[1])
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Mode().Perm() != 0o600 {
+		t.Fatalf("audit log mode = %v", info.Mode().Perm())
+	}
+}
+
+func TestAuditTransportDisabledWithoutLog(t *testing.T) {
+	base := &auditTestTransport{}
+	if got, err := auditTransportFromContext(context.Background(), "a@example.com", base); err != nil || got != base {
+		t.Fatalf("transport wrapped without audit log: %T, %v", got, err)
+	}
+
+	if got, err := auditTransportFromContext(WithAuditLog(context.Background(), NewAuditLog(" ", false), "x"), "", base); err != nil || got != base {
+		t.Fatalf("transport wrapped with empty path: %T, %v", got, err)
+	}
+
+	off := WithAuditLogResolver(context.Background(), func() (*AuditLog, error) { return nil, nil }, "x")
+	if got, err := auditTransportFromContext(off, "", base); err != nil || got != base {
+		t.Fatalf("transport wrapped with resolver returning no log: %T, %v", got, err)
+	}
+}
+
+func TestAuditLogResolverRunsOnceWhenClientIsBuilt(t *testing.T) {
+	calls := 0
+	resolveErr := errors.New("broken config")
+	ctx := WithAuditLogResolver(context.Background(), func() (*AuditLog, error) {
+		calls++
+		return nil, resolveErr
+	}, "gmail send")
+	if calls != 0 {
+		t.Fatalf("resolver ran before a client was built: %d", calls)
+	}
+
+	for range 2 {
+		if _, err := auditTransportFromContext(ctx, "a@example.com", &auditTestTransport{}); !errors.Is(err, resolveErr) {
+			t.Fatalf("err = %v", err)
+		}
+	}
+
+	if calls != 1 {
+		t.Fatalf("resolver calls = %d", calls)
+	}
+}
+
+func readAuditEntries(t *testing.T, path string) []AuditEntry {
+	t.Helper()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var entries []AuditEntry
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("decode %q: %v", scanner.Text(), err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	return entries
+}
@@ -148,10 +148,15 @@ func authenticatedTransportWithStoredScopeCheck(
 		}
 	}
 
-	return readOnlyTransportFromContext(ctx, NewRetryTransport(&oauth2.Transport{
+	audited, err := auditTransportFromContext(ctx, email, NewRetryTransport(&oauth2.Transport{
 		Source: ts,
 		Base:   newBaseTransport(),
-	})), nil
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	return readOnlyTransportFromContext(ctx, audited), nil
 }
 
 func optionsForAccountScopes(ctx context.Context, serviceLabel string, email string, scopes []string) ([]option.ClientOption, error) {
@@ -171,13 +176,13 @@ func optionsForServiceAccountScopes(ctx context.Context, serviceLabel string, em
 			return nil, err
 		}
 
-		return tokenSourceClientOptions(ctx, ts), nil
+		return tokenSourceClientOptions(ctx, email, ts)
 	}
 
 	if accessToken := authclient.AccessTokenFromContext(ctx); accessToken != "" {
 		slog.Debug("using direct access token", "serviceLabel", serviceLabel)
 
-		return tokenSourceClientOptions(ctx, oauth2.StaticTokenSource(&oauth2.Token{AccessToken: accessToken})), nil
+		return tokenSourceClientOptions(ctx, email, oauth2.StaticTokenSource(&oauth2.Token{AccessToken: accessToken}))
 	}
 
 	dependencies, err := requireAuthDependencies(ctx)
@@ -196,16 +201,21 @@ func optionsForServiceAccountScopes(ctx context.Context, serviceLabel string, em
 
 	slog.Debug("using required service account credentials", "email", email, "path", path)
 
-	return tokenSourceClientOptions(ctx, ts), nil
+	return tokenSourceClientOptions(ctx, email, ts)
 }
 
-func tokenSourceClientOptions(ctx context.Context, ts oauth2.TokenSource) []option.ClientOption {
+func tokenSourceClientOptions(ctx context.Context, email string, ts oauth2.TokenSource) ([]option.ClientOption, error) {
+	audited, err := auditTransportFromContext(ctx, email, NewRetryTransport(&oauth2.Transport{
+		Source: ts,
+		Base:   newBaseTransport(),
+	}))
+	if err != nil {
+		return nil, err
+	}
+
 	return []option.ClientOption{option.WithHTTPClient(&http.Client{
-		Transport: readOnlyTransportFromContext(ctx, NewRetryTransport(&oauth2.Transport{
-			Source: ts,
-			Base:   newBaseTransport(),
-		})),
-	})}
+		Transport: readOnlyTransportFromContext(ctx, audited),
+	})}, nil
 }
 
 func optionsForAccountScopesWithStoredScopeCheck(
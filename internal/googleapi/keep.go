@@ -43,7 +43,12 @@ func newKeepWithServiceAccount(
 		return nil, err
 	}
 
-	svc, err := keep.NewService(ctx, tokenSourceClientOptions(ctx, tokenSource)...)
+	opts, err := tokenSourceClientOptions(ctx, impersonateEmail, tokenSource)
+	if err != nil {
+		return nil, err
+	}
+
+	svc, err := keep.NewService(ctx, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("create keep service: %w", err)
 	}
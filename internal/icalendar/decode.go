@@ -0,0 +1,456 @@
+package icalendar
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+const maxReminders = 5
+
+var errNoEvents = errors.New("no VEVENT found in iCalendar data")
+
+// Decode reads VEVENTs as Google Calendar events. Every event carries its
+// ICalUID; modified instances of a series also carry OriginalStartTime, and
+// cancelled ones have Status "cancelled". Floating times and unknown zones
+// use X-WR-TIMEZONE, then defaultZone, then UTC.
+func Decode(r io.Reader, defaultZone string) ([]*calendar.Event, error) {
+	roots, err := Parse(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []*calendar.Event
+
+	for _, root := range roots {
+		if root.Name != "VCALENDAR" {
+			continue
+		}
+
+		resolver := zoneResolver{defaultLoc: time.UTC, vtimezones: map[string]*Component{}}
+
+		for _, name := range []string{defaultZone, root.Value("X-WR-TIMEZONE")} {
+			if loc, ok := LoadLocation(name); ok {
+				resolver.defaultLoc = loc
+			}
+		}
+
+		for _, child := range root.Children {
+			if child.Name == "VTIMEZONE" {
+				resolver.vtimezones[child.Value("TZID")] = child
+			}
+		}
+
+		for _, child := range root.Children {
+			if child.Name != "VEVENT" {
+				continue
+			}
+
+			event, err := resolver.event(child)
+			if err != nil {
+				return nil, fmt.Errorf("VEVENT %s: %w", child.Value("UID"), err)
+			}
+
+			events = append(events, event)
+		}
+	}
+
+	if len(events) == 0 {
+		return nil, errNoEvents
+	}
+
+	return events, nil
+}
+
+func (z zoneResolver) event(vevent *Component) (*calendar.Event, error) {
+	event := &calendar.Event{ICalUID: strings.TrimSpace(vevent.Value("UID"))}
+	if event.ICalUID == "" {
+		return nil, errors.New("missing UID")
+	}
+
+	for _, name := range []string{"RRULE", "RDATE", "EXDATE"} {
+		for _, prop := range vevent.All(name) {
+			event.Recurrence = append(event.Recurrence, z.recurrenceLine(prop))
+		}
+	}
+
+	recurring := len(event.Recurrence) > 0
+
+	start, ok := vevent.Prop("DTSTART")
+	if !ok {
+		return nil, errors.New("missing DTSTART")
+	}
+
+	var err error
+	if event.Start, err = z.dateTime(start, recurring); err != nil {
+		return nil, err
+	}
+
+	if end, ok := vevent.Prop("DTEND"); ok {
+		if event.End, err = z.dateTime(end, recurring); err != nil {
+			return nil, err
+		}
+	} else {
+		event.End, err = defaultEnd(event.Start, vevent.Value("DURATION"))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if rid, ok := vevent.Prop("RECURRENCE-ID"); ok {
+		if event.OriginalStartTime, err = z.dateTime(rid, false); err != nil {
+			return nil, err
+		}
+	}
+
+	event.Summary = UnescapeText(vevent.Value("SUMMARY"))
+	event.Description = UnescapeText(vevent.Value("DESCRIPTION"))
+	event.Location = UnescapeText(vevent.Value("LOCATION"))
+
+	switch strings.ToUpper(vevent.Value("STATUS")) {
+	case "CANCELLED":
+		event.Status = "cancelled"
+	case "TENTATIVE":
+		event.Status = "tentative"
+	case "CONFIRMED":
+		event.Status = "confirmed"
+	}
+
+	if strings.EqualFold(vevent.Value("TRANSP"), "TRANSPARENT") {
+		event.Transparency = "transparent"
+	}
+
+	switch strings.ToUpper(vevent.Value("CLASS")) {
+	case "PRIVATE":
+		event.Visibility = "private"
+	case "CONFIDENTIAL":
+		event.Visibility = "confidential"
+	case "PUBLIC":
+		event.Visibility = "public"
+	}
+
+	if seq, err := strconv.ParseInt(vevent.Value("SEQUENCE"), 10, 64); err == nil {
+		event.Sequence = seq
+	}
+
+	if organizer, ok := vevent.Prop("ORGANIZER"); ok {
+		if email := mailto(organizer.Value); email != "" {
+			event.Organizer = &calendar.EventOrganizer{Email: email, DisplayName: organizer.Param("CN")}
+		}
+	}
+
+	for _, prop := range vevent.All("ATTENDEE") {
+		if attendee := decodeAttendee(prop); attendee != nil {
+			event.Attendees = append(event.Attendees, attendee)
+		}
+	}
+
+	event.Reminders = decodeAlarms(vevent)
+	decodeConference(vevent, event)
+
+	return event, nil
+}
+
+// dateTime converts DTSTART-style values. Recurring events need a named
+// zone, so UTC times in a series are pinned to "UTC".
+func (z zoneResolver) dateTime(prop Property, recurring bool) (*calendar.EventDateTime, error) {
+	value := strings.TrimSpace(prop.Value)
+
+	if strings.EqualFold(prop.Param("VALUE"), "DATE") || len(value) == len(dateLayout) {
+		day, err := time.Parse(dateLayout, value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid date %q", prop.Name, value)
+		}
+
+		return &calendar.EventDateTime{Date: day.Format("2006-01-02")}, nil
+	}
+
+	if utc, ok := strings.CutSuffix(strings.ToUpper(value), "Z"); ok {
+		t, err := time.Parse(localLayout, utc)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid time %q", prop.Name, value)
+		}
+
+		out := &calendar.EventDateTime{DateTime: t.Format(time.RFC3339)}
+		if recurring {
+			out.TimeZone = "UTC"
+		}
+
+		return out, nil
+	}
+
+	wall, err := time.Parse(localLayout, value)
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid time %q", prop.Name, value)
+	}
+
+	t, zone := z.resolve(wall, prop.Param("TZID"))
+	if zone == "" && recurring {
+		t, zone = t.In(z.defaultLoc), z.defaultLoc.String()
+	}
+
+	return &calendar.EventDateTime{DateTime: t.Format(time.RFC3339), TimeZone: zone}, nil
+}
+
+// recurrenceLine renders RRULE/RDATE/EXDATE for the Google API, which only
+// understands IANA TZIDs: other zones are converted to UTC.
+func (z zoneResolver) recurrenceLine(prop Property) string {
+	tzid := prop.Param("TZID")
+	if tzid == "" || strings.EqualFold(prop.Param("VALUE"), "DATE") {
+		return prop.String()
+	}
+
+	out := Property{Name: prop.Name}
+
+	if loc, ok := LoadLocation(tzid); ok {
+		out.Params = map[string][]string{"TZID": {loc.String()}}
+		out.Value = prop.Value
+
+		return out.String()
+	}
+
+	var values []string
+
+	for value := range strings.SplitSeq(prop.Value, ",") {
+		wall, err := time.Parse(localLayout, strings.TrimSpace(value))
+		if err != nil {
+			values = append(values, value)
+
+			continue
+		}
+
+		t, _ := z.resolve(wall, tzid)
+		values = append(values, t.UTC().Format(utcLayout))
+	}
+
+	out.Value = strings.Join(values, ",")
+
+	return out.String()
+}
+
+func defaultEnd(start *calendar.EventDateTime, duration string) (*calendar.EventDateTime, error) {
+	var d time.Duration
+
+	if duration != "" {
+		var err error
+		if d, err = ParseDuration(duration); err != nil {
+			return nil, err
+		}
+	}
+
+	if start.Date != "" {
+		day, _ := time.Parse("2006-01-02", start.Date)
+		days := int(d / (24 * time.Hour))
+
+		if days < 1 {
+			days = 1
+		}
+
+		return &calendar.EventDateTime{Date: day.AddDate(0, 0, days).Format("2006-01-02")}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, start.DateTime)
+	if err != nil {
+		return nil, err
+	}
+
+	return &calendar.EventDateTime{DateTime: t.Add(d).Format(time.RFC3339), TimeZone: start.TimeZone}, nil
+}
+
+// ParseDuration parses an RFC 5545 duration such as "PT1H30M" or "-P1D".
+func ParseDuration(value string) (time.Duration, error) {
+	s := strings.ToUpper(strings.TrimSpace(value))
+	sign := time.Duration(1)
+
+	switch {
+	case strings.HasPrefix(s, "-"):
+		sign, s = -1, s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+
+	s, ok := strings.CutPrefix(s, "P")
+	if !ok || s == "" {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+
+	var (
+		total  time.Duration
+		inTime bool
+		digits string
+	)
+
+	units := map[bool]map[byte]time.Duration{
+		false: {'W': 7 * 24 * time.Hour, 'D': 24 * time.Hour},
+		true:  {'H': time.Hour, 'M': time.Minute, 'S': time.Second},
+	}
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+
+		switch {
+		case c == 'T':
+			inTime = true
+		case c >= '0' && c <= '9':
+			digits += string(c)
+		default:
+			unit, ok := units[inTime][c]
+			if !ok || digits == "" {
+				return 0, fmt.Errorf("invalid duration %q", value)
+			}
+
+			n, _ := strconv.Atoi(digits)
+			total += time.Duration(n) * unit
+			digits = ""
+		}
+	}
+
+	if digits != "" {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+
+	return sign * total, nil
+}
+
+func mailto(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) > 7 && strings.EqualFold(value[:7], "mailto:") {
+		value = value[7:]
+	}
+
+	if !strings.Contains(value, "@") {
+		return ""
+	}
+
+	return value
+}
+
+func decodeAttendee(prop Property) *calendar.EventAttendee {
+	email := mailto(prop.Value)
+	if email == "" {
+		email = mailto(prop.Param("EMAIL"))
+	}
+
+	if email == "" {
+		return nil
+	}
+
+	attendee := &calendar.EventAttendee{Email: email, DisplayName: prop.Param("CN")}
+
+	switch strings.ToUpper(prop.Param("ROLE")) {
+	case "OPT-PARTICIPANT", "NON-PARTICIPANT":
+		attendee.Optional = true
+	}
+
+	switch strings.ToUpper(prop.Param("CUTYPE")) {
+	case "RESOURCE", "ROOM":
+		attendee.Resource = true
+	}
+
+	switch strings.ToUpper(prop.Param("PARTSTAT")) {
+	case "ACCEPTED":
+		attendee.ResponseStatus = "accepted"
+	case "DECLINED":
+		attendee.ResponseStatus = "declined"
+	case "TENTATIVE":
+		attendee.ResponseStatus = "tentative"
+	default:
+		attendee.ResponseStatus = "needsAction"
+	}
+
+	return attendee
+}
+
+// decodeAlarms maps VALARMs that fire before the start to reminder
+// overrides; absolute and end-relative triggers have no Google equivalent.
+func decodeAlarms(vevent *Component) *calendar.EventReminders {
+	var overrides []*calendar.EventReminder
+
+	for _, alarm := range vevent.Children {
+		if alarm.Name != "VALARM" || len(overrides) == maxReminders {
+			continue
+		}
+
+		trigger, ok := alarm.Prop("TRIGGER")
+		if !ok || strings.EqualFold(trigger.Param("VALUE"), "DATE-TIME") || strings.EqualFold(trigger.Param("RELATED"), "END") {
+			continue
+		}
+
+		d, err := ParseDuration(trigger.Value)
+		if err != nil || d > 0 {
+			continue
+		}
+
+		method := "popup"
+		if strings.EqualFold(alarm.Value("ACTION"), "EMAIL") {
+			method = "email"
+		}
+
+		overrides = append(overrides, &calendar.EventReminder{Method: method, Minutes: int64(-d / time.Minute)})
+	}
+
+	if len(overrides) == 0 {
+		return nil
+	}
+
+	return &calendar.EventReminders{Overrides: overrides, ForceSendFields: []string{"UseDefault"}}
+}
+
+// decodeConference keeps Google Meet links as conference data; other join
+// links are appended to the description so they are not lost.
+func decodeConference(vevent *Component, event *calendar.Event) {
+	link := ""
+
+	for _, prop := range vevent.All("CONFERENCE") {
+		feature := strings.ToUpper(strings.Join(prop.Params["FEATURE"], ","))
+		if link == "" || strings.Contains(feature, "VIDEO") && !strings.HasPrefix(link, "https://") {
+			link = strings.TrimSpace(prop.Value)
+		}
+	}
+
+	if link == "" {
+		link = strings.TrimSpace(vevent.Value("X-GOOGLE-CONFERENCE"))
+	}
+
+	if link == "" {
+		return
+	}
+
+	if code, ok := meetCode(link); ok {
+		event.ConferenceData = &calendar.ConferenceData{
+			ConferenceId:       code,
+			ConferenceSolution: &calendar.ConferenceSolution{Key: &calendar.ConferenceSolutionKey{Type: "hangoutsMeet"}},
+			EntryPoints:        []*calendar.EntryPoint{{EntryPointType: "video", Uri: link}},
+		}
+
+		return
+	}
+
+	if !strings.Contains(event.Description, link) {
+		if event.Description != "" {
+			event.Description += "\n\n"
+		}
+
+		event.Description += "Join: " + link
+	}
+}
+
+func meetCode(link string) (string, bool) {
+	u, err := url.Parse(link)
+	if err != nil || !strings.EqualFold(u.Host, "meet.google.com") {
+		return "", false
+	}
+
+	code := strings.Trim(u.Path, "/")
+	if code == "" || strings.Contains(code, "/") {
+		return "", false
+	}
+
+	return code, true
+}
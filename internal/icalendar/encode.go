@@ -0,0 +1,382 @@
+package icalendar
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// Calendar describes the VCALENDAR wrapper for an export.
+type Calendar struct {
+	Name string
+	// TimeZone is the calendar's IANA zone, used for events without one.
+	TimeZone string
+	Events   []*calendar.Event
+}
+
+// Encode writes cal as an iCalendar file. Cancelled instances of recurring
+// events become EXDATEs on their series, modified instances become VEVENTs
+// with a RECURRENCE-ID, and every zone referenced by a TZID gets a
+// VTIMEZONE.
+func Encode(w io.Writer, cal Calendar) error {
+	root := &Component{Name: "VCALENDAR"}
+	root.Add("VERSION", "2.0")
+	root.Add("PRODID", ProdID)
+	root.Add("CALSCALE", "GREGORIAN")
+	root.Add("METHOD", "PUBLISH")
+
+	if cal.Name != "" {
+		root.Add("X-WR-CALNAME", EscapeText(cal.Name))
+	}
+
+	if cal.TimeZone != "" {
+		root.Add("X-WR-TIMEZONE", cal.TimeZone)
+	}
+
+	enc := &encoder{defaultZone: cal.TimeZone, zones: map[string]*time.Location{}}
+
+	masters := map[string]*Component{}
+
+	var events []*Component
+
+	cancelled := map[string][]*calendar.Event{}
+
+	for _, event := range cal.Events {
+		if event == nil {
+			continue
+		}
+
+		if event.Status == "cancelled" {
+			if event.RecurringEventId != "" {
+				cancelled[event.RecurringEventId] = append(cancelled[event.RecurringEventId], event)
+			}
+
+			continue
+		}
+
+		vevent, err := enc.event(event)
+		if err != nil {
+			return fmt.Errorf("event %s: %w", event.Id, err)
+		}
+
+		if event.RecurringEventId == "" && len(event.Recurrence) > 0 {
+			masters[event.Id] = vevent
+		}
+
+		events = append(events, vevent)
+	}
+
+	for _, masterID := range sortedKeys(cancelled) {
+		master, ok := masters[masterID]
+		if !ok {
+			continue
+		}
+
+		for _, instance := range cancelled[masterID] {
+			if instance.OriginalStartTime == nil {
+				continue
+			}
+
+			value, params := enc.dateTime(instance.OriginalStartTime)
+			master.Add("EXDATE", value, params...)
+		}
+	}
+
+	for _, name := range sortedKeys(enc.zones) {
+		root.Children = append(root.Children, Timezone(enc.zones[name], enc.minYear-1, enc.maxYear))
+	}
+
+	root.Children = append(root.Children, events...)
+
+	return Write(w, root)
+}
+
+type encoder struct {
+	defaultZone      string
+	zones            map[string]*time.Location
+	minYear, maxYear int
+}
+
+func (e *encoder) event(event *calendar.Event) (*Component, error) {
+	vevent := &Component{Name: "VEVENT"}
+
+	uid := event.ICalUID
+	if uid == "" {
+		uid = event.Id + "@google.com"
+	}
+
+	vevent.Add("UID", uid)
+
+	stamp := time.Now().UTC()
+	if updated, err := time.Parse(time.RFC3339, event.Updated); err == nil {
+		stamp = updated.UTC()
+	}
+
+	vevent.Add("DTSTAMP", stamp.Format(utcLayout))
+
+	if event.Start == nil {
+		return nil, fmt.Errorf("missing start time")
+	}
+
+	value, params := e.dateTime(event.Start)
+	vevent.Add("DTSTART", value, params...)
+
+	if event.End != nil {
+		value, params = e.dateTime(event.End)
+		vevent.Add("DTEND", value, params...)
+	}
+
+	if event.OriginalStartTime != nil {
+		value, params = e.dateTime(event.OriginalStartTime)
+		vevent.Add("RECURRENCE-ID", value, params...)
+	}
+
+	for _, line := range event.Recurrence {
+		prop, err := ParseLine(line)
+		if err != nil {
+			return nil, err
+		}
+
+		if tzid := prop.Param("TZID"); tzid != "" {
+			e.useZone(tzid)
+		}
+
+		vevent.Properties = append(vevent.Properties, prop)
+	}
+
+	addText(vevent, "SUMMARY", event.Summary)
+	addText(vevent, "DESCRIPTION", event.Description)
+	addText(vevent, "LOCATION", event.Location)
+
+	if event.Status != "" {
+		vevent.Add("STATUS", strings.ToUpper(event.Status))
+	}
+
+	transparency := "OPAQUE"
+	if event.Transparency == "transparent" {
+		transparency = "TRANSPARENT"
+	}
+
+	vevent.Add("TRANSP", transparency)
+
+	switch event.Visibility {
+	case "private":
+		vevent.Add("CLASS", "PRIVATE")
+	case "confidential":
+		vevent.Add("CLASS", "CONFIDENTIAL")
+	case "public":
+		vevent.Add("CLASS", "PUBLIC")
+	}
+
+	vevent.Add("SEQUENCE", fmt.Sprint(event.Sequence))
+
+	if created, err := time.Parse(time.RFC3339, event.Created); err == nil {
+		vevent.Add("CREATED", created.UTC().Format(utcLayout))
+		vevent.Add("LAST-MODIFIED", stamp.Format(utcLayout))
+	}
+
+	if event.Organizer != nil && event.Organizer.Email != "" {
+		vevent.Add("ORGANIZER", "mailto:"+event.Organizer.Email, "CN", event.Organizer.DisplayName)
+	}
+
+	for _, attendee := range event.Attendees {
+		if attendee == nil || attendee.Email == "" {
+			continue
+		}
+
+		role := "REQ-PARTICIPANT"
+		if attendee.Optional {
+			role = "OPT-PARTICIPANT"
+		}
+
+		cutype := "INDIVIDUAL"
+		if attendee.Resource {
+			cutype = "RESOURCE"
+		}
+
+		rsvp := ""
+		if attendee.ResponseStatus == "needsAction" {
+			rsvp = "TRUE"
+		}
+
+		vevent.Add("ATTENDEE", "mailto:"+attendee.Email,
+			"CN", attendee.DisplayName,
+			"CUTYPE", cutype,
+			"ROLE", role,
+			"PARTSTAT", partstat(attendee.ResponseStatus),
+			"RSVP", rsvp,
+		)
+	}
+
+	addConference(vevent, event)
+
+	if event.Reminders != nil {
+		for _, reminder := range event.Reminders.Overrides {
+			if reminder == nil {
+				continue
+			}
+
+			alarm := &Component{Name: "VALARM"}
+			if reminder.Method == "email" {
+				alarm.Add("ACTION", "EMAIL")
+				addText(alarm, "SUMMARY", event.Summary)
+				addText(alarm, "DESCRIPTION", "Reminder: "+event.Summary)
+			} else {
+				alarm.Add("ACTION", "DISPLAY")
+				addText(alarm, "DESCRIPTION", "Reminder: "+event.Summary)
+			}
+
+			alarm.Add("TRIGGER", formatTrigger(reminder.Minutes))
+			vevent.Children = append(vevent.Children, alarm)
+		}
+	}
+
+	return vevent, nil
+}
+
+// dateTime formats a Google start/end as a DATE, a local time with TZID, or
+// UTC when the zone is unknown.
+func (e *encoder) dateTime(dt *calendar.EventDateTime) (string, []string) {
+	if dt.Date != "" {
+		if day, err := time.Parse("2006-01-02", dt.Date); err == nil {
+			e.observe(day.Year())
+
+			return day.Format(dateLayout), []string{"VALUE", "DATE"}
+		}
+	}
+
+	t, err := time.Parse(time.RFC3339, dt.DateTime)
+	if err != nil {
+		return dt.DateTime, nil
+	}
+
+	e.observe(t.Year())
+
+	zone := dt.TimeZone
+	if zone == "" {
+		zone = e.defaultZone
+	}
+
+	if loc := e.useZone(zone); loc != nil && loc != time.UTC {
+		return t.In(loc).Format(localLayout), []string{"TZID", loc.String()}
+	}
+
+	return t.UTC().Format(utcLayout), nil
+}
+
+func (e *encoder) useZone(name string) *time.Location {
+	if name == "" || strings.EqualFold(name, "UTC") {
+		return time.UTC
+	}
+
+	if loc, ok := e.zones[name]; ok {
+		return loc
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil
+	}
+
+	e.zones[name] = loc
+
+	return loc
+}
+
+func (e *encoder) observe(year int) {
+	if e.minYear == 0 || year < e.minYear {
+		e.minYear = year
+	}
+
+	if year > e.maxYear {
+		e.maxYear = year
+	}
+
+	// Open-ended series keep going; cover the next few years too.
+	if now := time.Now().Year() + 2; e.maxYear < now {
+		e.maxYear = now
+	}
+}
+
+func addText(c *Component, name, value string) {
+	if strings.TrimSpace(value) != "" {
+		c.Add(name, EscapeText(value))
+	}
+}
+
+func partstat(status string) string {
+	switch status {
+	case "accepted":
+		return "ACCEPTED"
+	case "declined":
+		return "DECLINED"
+	case "tentative":
+		return "TENTATIVE"
+	default:
+		return "NEEDS-ACTION"
+	}
+}
+
+// addConference writes RFC 7986 CONFERENCE properties for the event's join
+// links, plus X-GOOGLE-CONFERENCE for clients that only read that.
+func addConference(vevent *Component, event *calendar.Event) {
+	video := event.HangoutLink
+
+	if event.ConferenceData != nil {
+		for _, entry := range event.ConferenceData.EntryPoints {
+			if entry == nil || entry.Uri == "" {
+				continue
+			}
+
+			feature := "VIDEO"
+
+			switch entry.EntryPointType {
+			case "video":
+				if video == "" {
+					video = entry.Uri
+				}
+			case "phone", "more":
+				feature = "PHONE"
+			}
+
+			vevent.Add("CONFERENCE", entry.Uri, "VALUE", "URI", "FEATURE", feature, "LABEL", entry.Label)
+		}
+	} else if video != "" {
+		vevent.Add("CONFERENCE", video, "VALUE", "URI", "FEATURE", "VIDEO")
+	}
+
+	if video != "" {
+		vevent.Add("X-GOOGLE-CONFERENCE", video)
+	}
+}
+
+func formatTrigger(minutes int64) string {
+	if minutes == 0 {
+		return "PT0S"
+	}
+
+	if minutes%(24*60) == 0 {
+		return fmt.Sprintf("-P%dD", minutes/(24*60))
+	}
+
+	if minutes%60 == 0 {
+		return fmt.Sprintf("-PT%dH", minutes/60)
+	}
+
+	return fmt.Sprintf("-PT%dM", minutes)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
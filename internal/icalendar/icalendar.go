@@ -0,0 +1,355 @@
+// Package icalendar converts Google Calendar events to and from RFC 5545
+// iCalendar data: VEVENTs with recurrence rules and exceptions, attendees,
+// alarms, conference links, and VTIMEZONE definitions.
+package icalendar
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// ProdID identifies gog as the producer of exported calendars.
+	ProdID = "-//gog//gogcli//EN"
+
+	maxLineOctets = 75
+)
+
+var errUnbalanced = errors.New("unbalanced BEGIN/END in iCalendar data")
+
+// Property is one content line: NAME;PARAM=value:VALUE.
+type Property struct {
+	Name   string
+	Params map[string][]string
+	Value  string
+}
+
+// Param returns the first value of a parameter.
+func (p Property) Param(name string) string {
+	if values := p.Params[strings.ToUpper(name)]; len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
+
+// Component is a BEGIN/END block such as VCALENDAR, VEVENT, or VTIMEZONE.
+type Component struct {
+	Name       string
+	Properties []Property
+	Children   []*Component
+}
+
+// Prop returns the first property with the given name.
+func (c *Component) Prop(name string) (Property, bool) {
+	for _, prop := range c.Properties {
+		if prop.Name == name {
+			return prop, true
+		}
+	}
+
+	return Property{}, false
+}
+
+// Value returns the raw value of the first property with the given name.
+func (c *Component) Value(name string) string {
+	prop, _ := c.Prop(name)
+
+	return prop.Value
+}
+
+// All returns every property with the given name.
+func (c *Component) All(name string) []Property {
+	var out []Property
+
+	for _, prop := range c.Properties {
+		if prop.Name == name {
+			out = append(out, prop)
+		}
+	}
+
+	return out
+}
+
+// Add appends a property.
+func (c *Component) Add(name, value string, params ...string) {
+	prop := Property{Name: name, Value: value}
+
+	for i := 0; i+1 < len(params); i += 2 {
+		if params[i+1] == "" {
+			continue
+		}
+
+		if prop.Params == nil {
+			prop.Params = map[string][]string{}
+		}
+
+		prop.Params[params[i]] = append(prop.Params[params[i]], params[i+1])
+	}
+
+	c.Properties = append(c.Properties, prop)
+}
+
+// Parse reads iCalendar data and returns its top-level components.
+func Parse(r io.Reader) ([]*Component, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		roots []*Component
+		stack []*Component
+	)
+
+	for n, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		prop, err := ParseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n+1, err)
+		}
+
+		switch prop.Name {
+		case "BEGIN":
+			comp := &Component{Name: strings.ToUpper(prop.Value)}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.Children = append(parent.Children, comp)
+			} else {
+				roots = append(roots, comp)
+			}
+
+			stack = append(stack, comp)
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1].Name != strings.ToUpper(prop.Value) {
+				return nil, fmt.Errorf("line %d: %w", n+1, errUnbalanced)
+			}
+
+			stack = stack[:len(stack)-1]
+		default:
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("line %d: property %s outside a component", n+1, prop.Name)
+			}
+
+			stack[len(stack)-1].Properties = append(stack[len(stack)-1].Properties, prop)
+		}
+	}
+
+	if len(stack) > 0 {
+		return nil, errUnbalanced
+	}
+
+	return roots, nil
+}
+
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64<<10), 16<<20)
+
+	var lines []string
+
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if len(lines) == 0 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines, scanner.Err()
+}
+
+// ParseLine parses one unfolded content line.
+func ParseLine(line string) (Property, error) {
+	var prop Property
+
+	i := strings.IndexAny(line, ";:")
+	if i <= 0 {
+		return prop, fmt.Errorf("invalid content line %q", line)
+	}
+
+	prop.Name = strings.ToUpper(line[:i])
+	rest := line[i:]
+
+	for strings.HasPrefix(rest, ";") {
+		rest = rest[1:]
+
+		eq := strings.IndexByte(rest, '=')
+		if eq <= 0 {
+			return prop, fmt.Errorf("invalid parameter in %q", line)
+		}
+
+		name := strings.ToUpper(rest[:eq])
+		rest = rest[eq+1:]
+
+		for {
+			var value string
+
+			if strings.HasPrefix(rest, `"`) {
+				end := strings.IndexByte(rest[1:], '"')
+				if end < 0 {
+					return prop, fmt.Errorf("unterminated quoted parameter in %q", line)
+				}
+
+				value = rest[1 : end+1]
+				rest = rest[end+2:]
+			} else {
+				end := strings.IndexAny(rest, ",;:")
+				if end < 0 {
+					return prop, fmt.Errorf("missing value in %q", line)
+				}
+
+				value = rest[:end]
+				rest = rest[end:]
+			}
+
+			if prop.Params == nil {
+				prop.Params = map[string][]string{}
+			}
+
+			prop.Params[name] = append(prop.Params[name], value)
+
+			if !strings.HasPrefix(rest, ",") {
+				break
+			}
+
+			rest = rest[1:]
+		}
+	}
+
+	if !strings.HasPrefix(rest, ":") {
+		return prop, fmt.Errorf("missing value in %q", line)
+	}
+
+	prop.Value = rest[1:]
+
+	return prop, nil
+}
+
+// String renders the property as an unfolded content line.
+func (p Property) String() string {
+	var b strings.Builder
+
+	b.WriteString(p.Name)
+
+	for _, name := range sortedKeys(p.Params) {
+		b.WriteString(";")
+		b.WriteString(name)
+		b.WriteString("=")
+
+		for i, value := range p.Params[name] {
+			if i > 0 {
+				b.WriteString(",")
+			}
+
+			b.WriteString(paramValue(value))
+		}
+	}
+
+	b.WriteString(":")
+	b.WriteString(p.Value)
+
+	return b.String()
+}
+
+func paramValue(value string) string {
+	value = strings.ReplaceAll(value, `"`, "'")
+	if strings.ContainsAny(value, ":;,") {
+		return `"` + value + `"`
+	}
+
+	return value
+}
+
+// Write renders components with CRLF line endings, folding lines longer
+// than 75 octets.
+func Write(w io.Writer, components ...*Component) error {
+	bw := bufio.NewWriter(w)
+
+	var write func(*Component)
+
+	write = func(c *Component) {
+		writeLine(bw, "BEGIN:"+c.Name)
+
+		for _, prop := range c.Properties {
+			writeLine(bw, prop.String())
+		}
+
+		for _, child := range c.Children {
+			write(child)
+		}
+
+		writeLine(bw, "END:"+c.Name)
+	}
+
+	for _, c := range components {
+		write(c)
+	}
+
+	return bw.Flush()
+}
+
+func writeLine(w *bufio.Writer, line string) {
+	limit := maxLineOctets
+
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+
+		_, _ = w.WriteString(line[:cut])
+		_, _ = w.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines start with a space, which counts.
+		limit = maxLineOctets - 1
+	}
+
+	_, _ = w.WriteString(line)
+	_, _ = w.WriteString("\r\n")
+}
+
+// EscapeText escapes a TEXT value.
+func EscapeText(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+	return replacer.Replace(value)
+}
+
+// UnescapeText reverses EscapeText.
+func UnescapeText(value string) string {
+	var b strings.Builder
+
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i+1 == len(value) {
+			b.WriteByte(value[i])
+
+			continue
+		}
+
+		i++
+
+		switch value[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(value[i])
+		}
+	}
+
+	return b.String()
+}
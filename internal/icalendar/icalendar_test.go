@@ -0,0 +1,224 @@
+package icalendar
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+func testSeries() []*calendar.Event {
+	return []*calendar.Event{
+		{
+			Id:          "series1",
+			ICalUID:     "series1@google.com",
+			Summary:     "Standup; daily, short",
+			Description: "Line one\nLine two with a long tail that definitely pushes the folded content line past seventy-five octets",
+			Start:       &calendar.EventDateTime{DateTime: "2026-03-02T09:00:00+01:00", TimeZone: "Europe/Berlin"},
+			End:         &calendar.EventDateTime{DateTime: "2026-03-02T09:15:00+01:00", TimeZone: "Europe/Berlin"},
+			Recurrence:  []string{"RRULE:FREQ=WEEKLY;BYDAY=MO,WE"},
+			Organizer:   &calendar.EventOrganizer{Email: "ann@example.com", DisplayName: "Ann"},
+			Attendees: []*calendar.EventAttendee{
+				{Email: "ann@example.com", ResponseStatus: "accepted"},
+				{Email: "bob@example.com", DisplayName: "Bob, Jr.", Optional: true, ResponseStatus: "needsAction"},
+			},
+			HangoutLink: "https://meet.google.com/abc-defg-hij",
+			ConferenceData: &calendar.ConferenceData{EntryPoints: []*calendar.EntryPoint{
+				{EntryPointType: "video", Uri: "https://meet.google.com/abc-defg-hij", Label: "meet.google.com/abc-defg-hij"},
+				{EntryPointType: "phone", Uri: "tel:+1-555-0100"},
+			}},
+			Reminders: &calendar.EventReminders{Overrides: []*calendar.EventReminder{{Method: "popup", Minutes: 10}}},
+			Created:   "2026-02-01T10:00:00Z",
+			Updated:   "2026-02-02T10:00:00Z",
+		},
+		{
+			Id:                "series1_20260304T080000Z",
+			RecurringEventId:  "series1",
+			Status:            "cancelled",
+			OriginalStartTime: &calendar.EventDateTime{DateTime: "2026-03-04T09:00:00+01:00", TimeZone: "Europe/Berlin"},
+		},
+		{
+			Id:                "series1_20260406T070000Z",
+			ICalUID:           "series1@google.com",
+			RecurringEventId:  "series1",
+			Summary:           "Standup (moved)",
+			Start:             &calendar.EventDateTime{DateTime: "2026-04-06T10:00:00+02:00", TimeZone: "Europe/Berlin"},
+			End:               &calendar.EventDateTime{DateTime: "2026-04-06T10:15:00+02:00", TimeZone: "Europe/Berlin"},
+			OriginalStartTime: &calendar.EventDateTime{DateTime: "2026-04-06T09:00:00+02:00", TimeZone: "Europe/Berlin"},
+		},
+		{
+			Id:      "allday",
+			ICalUID: "allday@google.com",
+			Summary: "Offsite",
+			Start:   &calendar.EventDateTime{Date: "2026-05-01"},
+			End:     &calendar.EventDateTime{Date: "2026-05-03"},
+		},
+	}
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, Calendar{Name: "Team", TimeZone: "Europe/Berlin", Events: testSeries()}); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	out := buf.String()
+
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		if len(line) > maxLineOctets {
+			t.Fatalf("unfolded line (%d octets): %q", len(line), line)
+		}
+	}
+
+	unfolded := strings.ReplaceAll(out, "\r\n ", "")
+	for _, want := range []string{
+		"BEGIN:VTIMEZONE\r\nTZID:Europe/Berlin\r\n",
+		"RRULE:FREQ=YEARLY;BYMONTH=3;BYDAY=-1SU",
+		"RRULE:FREQ=YEARLY;BYMONTH=10;BYDAY=-1SU",
+		"DTSTART;TZID=Europe/Berlin:20260302T090000",
+		"RRULE:FREQ=WEEKLY;BYDAY=MO,WE",
+		"EXDATE;TZID=Europe/Berlin:20260304T090000",
+		"RECURRENCE-ID;TZID=Europe/Berlin:20260406T090000",
+		`SUMMARY:Standup\; daily\, short`,
+		`DESCRIPTION:Line one\nLine two`,
+		`ATTENDEE;CN="Bob, Jr.";CUTYPE=INDIVIDUAL;PARTSTAT=NEEDS-ACTION;ROLE=OPT-PARTICIPANT;RSVP=TRUE:mailto:bob@example.com`,
+		"CONFERENCE;FEATURE=VIDEO;LABEL=meet.google.com/abc-defg-hij;VALUE=URI:https://meet.google.com/abc-defg-hij",
+		"X-GOOGLE-CONFERENCE:https://meet.google.com/abc-defg-hij",
+		"TRIGGER:-PT10M",
+		"DTSTART;VALUE=DATE:20260501",
+	} {
+		if !strings.Contains(unfolded, want) {
+			t.Errorf("missing %q in\n%s", want, unfolded)
+		}
+	}
+
+	events, err := Decode(strings.NewReader(out), "")
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+
+	if len(events) != 3 {
+		t.Fatalf("decoded %d events", len(events))
+	}
+
+	// The cancelled instance comes back as an EXDATE on the series.
+	original := testSeries()
+	original[0].Recurrence = append(original[0].Recurrence, "EXDATE;TZID=Europe/Berlin:20260304T090000")
+
+	if changes := Changes(original[0], events[0]); len(changes) != 0 {
+		t.Fatalf("series changed on round trip: %v\n%+v", changes, events[0])
+	}
+
+	if events[0].ConferenceData == nil || events[0].ConferenceData.ConferenceId != "abc-defg-hij" {
+		t.Fatalf("conference = %+v", events[0].ConferenceData)
+	}
+
+	if events[1].OriginalStartTime == nil || instant(events[1].OriginalStartTime) != "2026-04-06T07:00:00Z" {
+		t.Fatalf("exception = %+v", events[1].OriginalStartTime)
+	}
+
+	if changes := Changes(original[3], events[2]); len(changes) != 0 {
+		t.Fatalf("all-day event changed: %v", changes)
+	}
+}
+
+func TestDecodeForeignZones(t *testing.T) {
+	t.Parallel()
+
+	data := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n" +
+		"BEGIN:VTIMEZONE\r\nTZID:Custom Zone\r\n" +
+		"BEGIN:STANDARD\r\nDTSTART:16010101T030000\r\nRRULE:FREQ=YEARLY;BYMONTH=10;BYDAY=-1SU\r\nTZOFFSETFROM:+0200\r\nTZOFFSETTO:+0100\r\nEND:STANDARD\r\n" +
+		"BEGIN:DAYLIGHT\r\nDTSTART:16010101T020000\r\nRRULE:FREQ=YEARLY;BYMONTH=3;BYDAY=-1SU\r\nTZOFFSETFROM:+0100\r\nTZOFFSETTO:+0200\r\nEND:DAYLIGHT\r\n" +
+		"END:VTIMEZONE\r\n" +
+		"BEGIN:VEVENT\r\nUID:outlook-1\r\nDTSTART;TZID=Custom Zone:20260715T100000\r\nDURATION:PT1H30M\r\n" +
+		"SUMMARY:Summer\r\nCONFERENCE;VALUE=URI;FEATURE=VIDEO:https://zoom.example/j/1\r\n" +
+		"BEGIN:VALARM\r\nACTION:EMAIL\r\nTRIGGER:-P1D\r\nEND:VALARM\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:outlook-2\r\nDTSTART;TZID=Eastern Standard Time:20260115T100000\r\n" +
+		"DTEND;TZID=Eastern Standard Time:20260115T110000\r\n" +
+		"RRULE:FREQ=DAILY;COUNT=3\r\nEXDATE;TZID=Custom Zone:20260116T160000\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:floating\r\nDTSTART:20260115T100000\r\nEND:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+
+	events, err := Decode(strings.NewReader(data), "Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+
+	if got := instant(events[0].Start); got != "2026-07-15T08:00:00Z" {
+		t.Fatalf("custom zone start = %s", got)
+	}
+
+	if got := instant(events[0].End); got != "2026-07-15T09:30:00Z" {
+		t.Fatalf("duration end = %s", got)
+	}
+
+	if !strings.Contains(events[0].Description, "Join: https://zoom.example/j/1") {
+		t.Fatalf("description = %q", events[0].Description)
+	}
+
+	if r := events[0].Reminders; r == nil || len(r.Overrides) != 1 || r.Overrides[0].Method != "email" || r.Overrides[0].Minutes != 24*60 {
+		t.Fatalf("reminders = %+v", r)
+	}
+
+	if events[1].Start.TimeZone != "America/New_York" || instant(events[1].Start) != "2026-01-15T15:00:00Z" {
+		t.Fatalf("windows zone start = %+v", events[1].Start)
+	}
+
+	if got := strings.Join(events[1].Recurrence, "|"); got != "RRULE:FREQ=DAILY;COUNT=3|EXDATE:20260116T150000Z" {
+		t.Fatalf("recurrence = %s", got)
+	}
+
+	if events[2].Start.TimeZone != "Asia/Tokyo" || instant(events[2].End) != "2026-01-15T01:00:00Z" {
+		t.Fatalf("floating = %+v %+v", events[2].Start, events[2].End)
+	}
+}
+
+func TestTimezoneWithoutDST(t *testing.T) {
+	t.Parallel()
+
+	loc, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		t.Fatalf("LoadLocation: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, Timezone(loc, 2025, 2027)); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	want := "BEGIN:VTIMEZONE\r\nTZID:Asia/Kolkata\r\nBEGIN:STANDARD\r\nDTSTART:19700101T000000\r\n" +
+		"TZOFFSETFROM:+0530\r\nTZOFFSETTO:+0530\r\nTZNAME:IST\r\nEND:STANDARD\r\nEND:VTIMEZONE\r\n"
+	if buf.String() != want {
+		t.Fatalf("VTIMEZONE =\n%s", buf.String())
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]time.Duration{
+		"PT15M":      15 * time.Minute,
+		"-PT1H30M":   -90 * time.Minute,
+		"P1W":        7 * 24 * time.Hour,
+		"-P1DT2H":    -26 * time.Hour,
+		"PT0S":       0,
+		"+P2D":       48 * time.Hour,
+		"pt5m":       5 * time.Minute,
+		"P1DT0H0M1S": 24*time.Hour + time.Second,
+	}
+	for value, want := range cases {
+		if got, err := ParseDuration(value); err != nil || got != want {
+			t.Errorf("ParseDuration(%q) = %v, %v; want %v", value, got, err, want)
+		}
+	}
+
+	for _, bad := range []string{"", "P", "PT5", "P5H", "15M"} {
+		if _, err := ParseDuration(bad); err == nil {
+			t.Errorf("ParseDuration(%q) succeeded", bad)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package icalendar
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+)
+
+// Changes lists the fields an import would change on an existing Google
+// event, compared on what iCalendar can express: instants rather than zone
+// spellings, attendee emails rather than their responses.
+func Changes(existing, imported *calendar.Event) []string {
+	var changes []string
+
+	check := func(field string, a, b string) {
+		if a != b {
+			changes = append(changes, field)
+		}
+	}
+
+	check("summary", strings.TrimSpace(existing.Summary), strings.TrimSpace(imported.Summary))
+	check("description", strings.TrimSpace(existing.Description), strings.TrimSpace(imported.Description))
+	check("location", strings.TrimSpace(existing.Location), strings.TrimSpace(imported.Location))
+	check("start", instant(existing.Start), instant(imported.Start))
+	check("end", instant(existing.End), instant(imported.End))
+
+	if imported.OriginalStartTime == nil {
+		check("recurrence", recurrenceKey(existing.Recurrence), recurrenceKey(imported.Recurrence))
+	}
+
+	check("status", orDefault(existing.Status, "confirmed"), orDefault(imported.Status, "confirmed"))
+	check("transparency", orDefault(existing.Transparency, "opaque"), orDefault(imported.Transparency, "opaque"))
+	check("visibility", orDefault(existing.Visibility, "default"), orDefault(imported.Visibility, "default"))
+	check("attendees", attendeeKey(existing.Attendees), attendeeKey(imported.Attendees))
+
+	if imported.Reminders != nil {
+		check("reminders", reminderKey(existing.Reminders), reminderKey(imported.Reminders))
+	}
+
+	if imported.ConferenceData != nil {
+		check("conference", conferenceLink(existing), conferenceLink(imported))
+	}
+
+	return changes
+}
+
+// Merge applies the imported fields to a copy of existing, keeping what
+// iCalendar cannot carry (colors, attachments, extended properties).
+func Merge(existing, imported *calendar.Event) *calendar.Event {
+	merged := *existing
+	merged.Summary = imported.Summary
+	merged.Description = imported.Description
+	merged.Location = imported.Location
+	merged.Start = imported.Start
+	merged.End = imported.End
+	merged.Transparency = imported.Transparency
+	merged.Visibility = imported.Visibility
+
+	if imported.OriginalStartTime == nil {
+		merged.Recurrence = imported.Recurrence
+	}
+
+	if imported.Status != "cancelled" {
+		merged.Status = orDefault(imported.Status, "confirmed")
+	}
+
+	responses := map[string]string{}
+
+	for _, attendee := range existing.Attendees {
+		if attendee != nil {
+			responses[strings.ToLower(attendee.Email)] = attendee.ResponseStatus
+		}
+	}
+
+	merged.Attendees = nil
+
+	for _, attendee := range imported.Attendees {
+		copied := *attendee
+		if status, ok := responses[strings.ToLower(attendee.Email)]; ok {
+			copied.ResponseStatus = status
+		}
+
+		merged.Attendees = append(merged.Attendees, &copied)
+	}
+
+	if imported.Reminders != nil {
+		merged.Reminders = imported.Reminders
+	}
+
+	if imported.ConferenceData != nil {
+		merged.ConferenceData = imported.ConferenceData
+	}
+
+	return &merged
+}
+
+func instant(dt *calendar.EventDateTime) string {
+	if dt == nil {
+		return ""
+	}
+
+	if dt.Date != "" {
+		return dt.Date
+	}
+
+	t, err := time.Parse(time.RFC3339, dt.DateTime)
+	if err != nil {
+		return dt.DateTime
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
+
+func recurrenceKey(lines []string) string {
+	sorted := slices.Clone(lines)
+	slices.Sort(sorted)
+
+	return strings.Join(sorted, "\n")
+}
+
+func orDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+
+	return value
+}
+
+func attendeeKey(attendees []*calendar.EventAttendee) string {
+	var keys []string
+
+	for _, attendee := range attendees {
+		if attendee == nil {
+			continue
+		}
+
+		key := strings.ToLower(attendee.Email)
+		if attendee.Optional {
+			key += "?"
+		}
+
+		keys = append(keys, key)
+	}
+
+	slices.Sort(keys)
+
+	return strings.Join(keys, ",")
+}
+
+func reminderKey(reminders *calendar.EventReminders) string {
+	if reminders == nil || reminders.UseDefault {
+		return "default"
+	}
+
+	var keys []string
+
+	for _, reminder := range reminders.Overrides {
+		if reminder != nil {
+			keys = append(keys, reminder.Method+":"+time.Duration(reminder.Minutes*int64(time.Minute)).String())
+		}
+	}
+
+	slices.Sort(keys)
+
+	return strings.Join(keys, ",")
+}
+
+func conferenceLink(event *calendar.Event) string {
+	if event.ConferenceData != nil {
+		for _, entry := range event.ConferenceData.EntryPoints {
+			if entry != nil && entry.EntryPointType == "video" {
+				return entry.Uri
+			}
+		}
+	}
+
+	return event.HangoutLink
+}
@@ -0,0 +1,452 @@
+package icalendar
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "github.com/steipete/gogcli/internal/tzembed" // VTIMEZONE rules come from the embedded IANA database.
+)
+
+const (
+	localLayout = "20060102T150405"
+	utcLayout   = "20060102T150405Z"
+	dateLayout  = "20060102"
+
+	maxTimezoneYears = 60
+)
+
+var weekdayCodes = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+type transition struct {
+	at         time.Time
+	fromOffset int
+	toOffset   int
+	name       string
+	dst        bool
+}
+
+// Timezone builds a VTIMEZONE for loc covering the years from..to. Yearly
+// transitions that follow a fixed "nth weekday of month" rule collapse into
+// one RRULE; anything irregular is emitted as dated observances.
+func Timezone(loc *time.Location, from, to int) *Component {
+	if to < from {
+		from, to = to, from
+	}
+
+	if to-from > maxTimezoneYears {
+		from = to - maxTimezoneYears
+	}
+
+	tz := &Component{Name: "VTIMEZONE"}
+	tz.Add("TZID", loc.String())
+
+	transitions := zoneTransitions(loc, from, to)
+	if len(transitions) == 0 {
+		at := time.Date(from, time.January, 1, 0, 0, 0, 0, loc)
+		name, offset := at.Zone()
+		obs := &Component{Name: "STANDARD"}
+		obs.Add("DTSTART", "19700101T000000")
+		obs.Add("TZOFFSETFROM", formatOffset(offset))
+		obs.Add("TZOFFSETTO", formatOffset(offset))
+		obs.Add("TZNAME", name)
+		tz.Children = append(tz.Children, obs)
+
+		return tz
+	}
+
+	type ruleKey struct {
+		dst      bool
+		from, to int
+		name     string
+		month    time.Month
+		weekday  time.Weekday
+		clock    string
+	}
+
+	groups := map[ruleKey][]transition{}
+
+	var order []ruleKey
+
+	for _, tr := range transitions {
+		local := tr.at.In(time.FixedZone("", tr.fromOffset))
+		key := ruleKey{tr.dst, tr.fromOffset, tr.toOffset, tr.name, local.Month(), local.Weekday(), local.Format("150405")}
+
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+
+		groups[key] = append(groups[key], tr)
+	}
+
+	for _, key := range order {
+		group := groups[key]
+		byDay, ok := yearlyRule(group)
+
+		if !ok || len(group) < 2 {
+			for _, tr := range group {
+				tz.Children = append(tz.Children, observance(tr, ""))
+			}
+
+			continue
+		}
+
+		rule := fmt.Sprintf("FREQ=YEARLY;BYMONTH=%d;BYDAY=%s", int(key.month), byDay)
+		if last := group[len(group)-1]; last.at.Year() < to {
+			rule += ";UNTIL=" + last.at.UTC().Format(utcLayout)
+		}
+
+		tz.Children = append(tz.Children, observance(group[0], rule))
+	}
+
+	return tz
+}
+
+func observance(tr transition, rule string) *Component {
+	name := "STANDARD"
+	if tr.dst {
+		name = "DAYLIGHT"
+	}
+
+	obs := &Component{Name: name}
+	obs.Add("DTSTART", tr.at.In(time.FixedZone("", tr.fromOffset)).Format(localLayout))
+
+	if rule != "" {
+		obs.Add("RRULE", rule)
+	}
+
+	obs.Add("TZOFFSETFROM", formatOffset(tr.fromOffset))
+	obs.Add("TZOFFSETTO", formatOffset(tr.toOffset))
+	obs.Add("TZNAME", tr.name)
+
+	return obs
+}
+
+// yearlyRule returns the BYDAY value ("2SU", "-1SU") shared by transitions
+// in consecutive years, or false when they do not follow one rule.
+func yearlyRule(group []transition) (string, bool) {
+	nth, last := 0, true
+
+	for i, tr := range group {
+		if i > 0 && tr.at.Year() != group[i-1].at.Year()+1 {
+			return "", false
+		}
+
+		local := tr.at.In(time.FixedZone("", tr.fromOffset))
+		n := (local.Day()-1)/7 + 1
+
+		switch {
+		case i == 0:
+			nth = n
+		case nth != n:
+			nth = -1
+		}
+
+		if local.AddDate(0, 0, 7).Month() == local.Month() {
+			last = false
+		}
+	}
+
+	code := weekdayCodes[group[0].at.In(time.FixedZone("", group[0].fromOffset)).Weekday()]
+
+	switch {
+	case last:
+		return "-1" + code, true
+	case nth > 0:
+		return strconv.Itoa(nth) + code, true
+	default:
+		return "", false
+	}
+}
+
+func zoneTransitions(loc *time.Location, from, to int) []transition {
+	start := time.Date(from, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(to+1, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	var out []transition
+
+	prev := start
+	_, prevOffset := prev.In(loc).Zone()
+
+	for t := start.Add(24 * time.Hour); !t.After(end); t = t.Add(24 * time.Hour) {
+		_, offset := t.In(loc).Zone()
+		if offset == prevOffset {
+			prev = t
+
+			continue
+		}
+
+		// Binary search for the first second with the new offset.
+		lo, hi := prev, t
+		for hi.Sub(lo) > time.Second {
+			mid := lo.Add(hi.Sub(lo) / 2).Truncate(time.Second)
+			if _, midOffset := mid.In(loc).Zone(); midOffset == prevOffset {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+
+		name, _ := hi.In(loc).Zone()
+		out = append(out, transition{at: hi, fromOffset: prevOffset, toOffset: offset, name: name, dst: hi.In(loc).IsDST()})
+		prev, prevOffset = t, offset
+	}
+
+	return out
+}
+
+func formatOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign = "-"
+		seconds = -seconds
+	}
+
+	out := fmt.Sprintf("%s%02d%02d", sign, seconds/3600, seconds/60%60)
+	if seconds%60 != 0 {
+		out += fmt.Sprintf("%02d", seconds%60)
+	}
+
+	return out
+}
+
+func parseOffset(value string) (int, error) {
+	value = strings.TrimSpace(value)
+	if len(value) != 5 && len(value) != 7 || (value[0] != '+' && value[0] != '-') {
+		return 0, fmt.Errorf("invalid UTC offset %q", value)
+	}
+
+	var parts [3]int
+
+	for i := 0; i*2+1 < len(value); i++ {
+		n, err := strconv.Atoi(value[1+i*2 : 3+i*2])
+		if err != nil {
+			return 0, fmt.Errorf("invalid UTC offset %q", value)
+		}
+
+		parts[i] = n
+	}
+
+	seconds := parts[0]*3600 + parts[1]*60 + parts[2]
+	if value[0] == '-' {
+		seconds = -seconds
+	}
+
+	return seconds, nil
+}
+
+// windowsZones maps the Windows zone names Outlook and Exchange put in
+// TZID to IANA zones.
+var windowsZones = map[string]string{
+	"Alaskan Standard Time":          "America/Anchorage",
+	"Arabian Standard Time":          "Asia/Dubai",
+	"Atlantic Standard Time":         "America/Halifax",
+	"AUS Eastern Standard Time":      "Australia/Sydney",
+	"Central Europe Standard Time":   "Europe/Budapest",
+	"Central European Standard Time": "Europe/Warsaw",
+	"Central Standard Time":          "America/Chicago",
+	"China Standard Time":            "Asia/Shanghai",
+	"E. South America Standard Time": "America/Sao_Paulo",
+	"Eastern Standard Time":          "America/New_York",
+	"FLE Standard Time":              "Europe/Kyiv",
+	"GMT Standard Time":              "Europe/London",
+	"Hawaiian Standard Time":         "Pacific/Honolulu",
+	"India Standard Time":            "Asia/Kolkata",
+	"Israel Standard Time":           "Asia/Jerusalem",
+	"Korea Standard Time":            "Asia/Seoul",
+	"Mountain Standard Time":         "America/Denver",
+	"New Zealand Standard Time":      "Pacific/Auckland",
+	"Pacific Standard Time":          "America/Los_Angeles",
+	"Romance Standard Time":          "Europe/Paris",
+	"Russian Standard Time":          "Europe/Moscow",
+	"Singapore Standard Time":        "Asia/Singapore",
+	"Tokyo Standard Time":            "Asia/Tokyo",
+	"US Mountain Standard Time":      "America/Phoenix",
+	"UTC":                            "UTC",
+	"W. Europe Standard Time":        "Europe/Berlin",
+}
+
+// LoadLocation resolves a TZID to an IANA zone: plain IANA names, Windows
+// names, and prefixed forms like "/mozilla.org/20050126_1/America/New_York".
+func LoadLocation(tzid string) (*time.Location, bool) {
+	tzid = strings.Trim(strings.TrimSpace(tzid), `"`)
+	if tzid == "" {
+		return nil, false
+	}
+
+	if name, ok := windowsZones[tzid]; ok {
+		tzid = name
+	}
+
+	if loc, err := time.LoadLocation(tzid); err == nil {
+		return loc, true
+	}
+
+	parts := strings.Split(tzid, "/")
+	for i := 1; i < len(parts); i++ {
+		if loc, err := time.LoadLocation(strings.Join(parts[i:], "/")); err == nil && strings.Contains(strings.Join(parts[i:], "/"), "/") {
+			return loc, true
+		}
+	}
+
+	return nil, false
+}
+
+// zoneResolver converts local times in a TZID to instants, using the IANA
+// database when the TZID is known and the file's VTIMEZONE otherwise.
+type zoneResolver struct {
+	defaultLoc *time.Location
+	vtimezones map[string]*Component
+}
+
+// resolve returns the instant for a local wall-clock value in tzid, and the
+// IANA name when one applies.
+func (z zoneResolver) resolve(wall time.Time, tzid string) (time.Time, string) {
+	if tzid == "" {
+		return inLocation(wall, z.defaultLoc), z.defaultLoc.String()
+	}
+
+	if loc, ok := LoadLocation(tzid); ok {
+		return inLocation(wall, loc), loc.String()
+	}
+
+	if tz, ok := z.vtimezones[tzid]; ok {
+		if offset, ok := observanceOffset(tz, wall); ok {
+			return inLocation(wall, time.FixedZone(tzid, offset)), ""
+		}
+	}
+
+	return inLocation(wall, z.defaultLoc), z.defaultLoc.String()
+}
+
+func inLocation(wall time.Time, loc *time.Location) time.Time {
+	return time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), 0, loc)
+}
+
+// observanceOffset returns the TZOFFSETTO of the observance whose most
+// recent onset precedes wall.
+func observanceOffset(tz *Component, wall time.Time) (int, bool) {
+	var (
+		best   time.Time
+		offset int
+		found  bool
+	)
+
+	for _, obs := range tz.Children {
+		to, err := parseOffset(obs.Value("TZOFFSETTO"))
+		if err != nil {
+			continue
+		}
+
+		for _, onset := range observanceOnsets(obs, wall.Year()) {
+			if onset.After(wall) || (found && !onset.After(best)) {
+				continue
+			}
+
+			best, offset, found = onset, to, true
+		}
+	}
+
+	return offset, found
+}
+
+func observanceOnsets(obs *Component, year int) []time.Time {
+	start, err := time.Parse(localLayout, obs.Value("DTSTART"))
+	if err != nil {
+		return nil
+	}
+
+	onsets := []time.Time{start}
+
+	for _, rdate := range obs.All("RDATE") {
+		for value := range strings.SplitSeq(rdate.Value, ",") {
+			if t, err := time.Parse(localLayout, value); err == nil {
+				onsets = append(onsets, t)
+			}
+		}
+	}
+
+	rule := parseRule(obs.Value("RRULE"))
+	if rule["FREQ"] != "YEARLY" {
+		return onsets
+	}
+
+	month, _ := strconv.Atoi(rule["BYMONTH"])
+	if month < 1 || month > 12 {
+		return onsets
+	}
+
+	until, hasUntil := time.Time{}, false
+	if value := rule["UNTIL"]; value != "" {
+		if t, err := time.Parse(utcLayout, value); err == nil {
+			until, hasUntil = t, true
+		}
+	}
+
+	for _, y := range []int{year - 1, year} {
+		onset, ok := nthWeekday(y, time.Month(month), rule["BYDAY"], start)
+		if !ok || onset.Before(start) || (hasUntil && onset.After(until.Add(24*time.Hour))) {
+			continue
+		}
+
+		onsets = append(onsets, onset)
+	}
+
+	return onsets
+}
+
+func parseRule(value string) map[string]string {
+	out := map[string]string{}
+
+	for part := range strings.SplitSeq(value, ";") {
+		if key, val, ok := strings.Cut(part, "="); ok {
+			out[strings.ToUpper(key)] = val
+		}
+	}
+
+	return out
+}
+
+// nthWeekday evaluates a BYDAY value like "2SU" or "-1SU" (or a missing
+// BYDAY, meaning the DTSTART day) in the given month.
+func nthWeekday(year int, month time.Month, byDay string, start time.Time) (time.Time, bool) {
+	clock := func(day int) time.Time {
+		return time.Date(year, month, day, start.Hour(), start.Minute(), start.Second(), 0, time.UTC)
+	}
+
+	if byDay == "" {
+		return clock(start.Day()), true
+	}
+
+	if len(byDay) < 2 {
+		return time.Time{}, false
+	}
+
+	code := byDay[len(byDay)-2:]
+	weekday := slices.Index(weekdayCodes, code)
+	if weekday < 0 {
+		return time.Time{}, false
+	}
+
+	n := 1
+	if prefix := byDay[:len(byDay)-2]; prefix != "" {
+		var err error
+		if n, err = strconv.Atoi(prefix); err != nil || n == 0 {
+			return time.Time{}, false
+		}
+	}
+
+	if n > 0 {
+		first := clock(1)
+		offset := (weekday - int(first.Weekday()) + 7) % 7
+
+		return first.AddDate(0, 0, offset+(n-1)*7), true
+	}
+
+	last := clock(1).AddDate(0, 1, -1)
+	offset := (int(last.Weekday()) - weekday + 7) % 7
+
+	return last.AddDate(0, 0, -offset+(n+1)*7), true
+}
@@ -0,0 +1,204 @@
+package mailbox
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testMessage(id string, labels ...string) Message {
+	return Message{
+		ID:           id,
+		ThreadID:     "18f",
+		InternalDate: time.Date(2026, 4, 2, 10, 0, 0, 0, time.UTC),
+		Labels:       labels,
+		Raw:          []byte("From: Ann <ann@example.com>\r\nSubject: " + id + "\r\n\r\nhi\r\nFrom here on\r\n>From quoted"),
+	}
+}
+
+func TestMboxWriteAndResume(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "out.mbox")
+	names := map[string]string{"Label_1": "Clients/Acme"}
+
+	w, err := Open(FormatMbox, path, Options{LabelNames: names})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	if err := w.Write(testMessage("m1", "INBOX", "Label_1", "CATEGORY_UPDATES")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+
+	want := "From ann@example.com Thu Apr  2 10:00:00 2026\n" +
+		"X-GM-THRID: 399\n" +
+		"X-Gmail-Labels: Inbox,Clients/Acme,Category Updates\n" +
+		"From: Ann <ann@example.com>\nSubject: m1\n\nhi\n>From here on\n>>From quoted\n\n"
+	if string(data) != want {
+		t.Fatalf("mbox =\n%q\nwant\n%q", data, want)
+	}
+
+	// Simulate a run that died halfway through the next message.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+
+	_, _ = f.WriteString("From partial")
+	_ = f.Close()
+
+	w, err = Open(FormatMbox, path, Options{LabelNames: names})
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+
+	if !w.Has("m1") || w.Has("m2") {
+		t.Fatalf("resume state wrong")
+	}
+
+	if err := w.Write(testMessage("m2")); err != nil {
+		t.Fatalf("Write m2: %v", err)
+	}
+
+	_ = w.Close()
+
+	data, _ = os.ReadFile(path)
+	if strings.Contains(string(data), "partial") || strings.Count(string(data), "\nSubject: ") != 2 {
+		t.Fatalf("resumed mbox:\n%s", data)
+	}
+
+	w, err = Open(FormatMbox, path, Options{Restart: true})
+	if err != nil {
+		t.Fatalf("restart: %v", err)
+	}
+
+	defer w.Close()
+
+	if w.Has("m1") {
+		t.Fatalf("restart kept state")
+	}
+}
+
+func TestMaildirFoldersAndFlags(t *testing.T) {
+	t.Parallel()
+
+	root := filepath.Join(t.TempDir(), "Maildir")
+	names := map[string]string{"Label_1": "Clients/acme.com"}
+
+	w, err := Open(FormatMaildir, root, Options{LabelNames: names})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	if err := w.Write(testMessage("m1", "INBOX", "Label_1", "STARRED", "UNREAD")); err != nil {
+		t.Fatalf("Write m1: %v", err)
+	}
+
+	if err := w.Write(testMessage("m/2", "CATEGORY_SOCIAL")); err != nil {
+		t.Fatalf("Write m2: %v", err)
+	}
+
+	_ = w.Close()
+
+	for _, path := range []string{
+		"cur/1775124000.m1.gog:2,F",
+		".Clients.acme_com/cur/1775124000.m1.gog:2,F",
+		".Clients.acme_com/maildirfolder",
+		".Archive/cur/1775124000.m_2.gog:2,S",
+	} {
+		if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(path))); err != nil {
+			t.Errorf("missing %s: %v", path, err)
+		}
+	}
+
+	w, err = Open(FormatMaildir, root, Options{})
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+
+	defer w.Close()
+
+	if !w.Has("m1") || !w.Has("m/2") {
+		t.Fatalf("resume state lost")
+	}
+}
+
+func TestWalkReadsBackExports(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	names := map[string]string{"Label_1": "Clients/Acme"}
+
+	for _, format := range []string{FormatMbox, FormatMaildir} {
+		path := filepath.Join(dir, "out."+format)
+
+		w, err := Open(format, path, Options{LabelNames: names})
+		if err != nil {
+			t.Fatalf("Open %s: %v", format, err)
+		}
+
+		for _, msg := range []Message{testMessage("m1", "INBOX", "Label_1", "UNREAD"), testMessage("m2", "CATEGORY_SOCIAL")} {
+			if err := w.Write(msg); err != nil {
+				t.Fatalf("Write %s: %v", format, err)
+			}
+		}
+
+		_ = w.Close()
+
+		var entries []Entry
+
+		if err := Walk(path, func(entry Entry) error {
+			entries = append(entries, entry)
+
+			return nil
+		}); err != nil {
+			t.Fatalf("Walk %s: %v", format, err)
+		}
+
+		// Maildir stores m1 in the inbox and in its label folder.
+		want := map[string]int{FormatMbox: 2, FormatMaildir: 3}[format]
+		if len(entries) != want {
+			t.Fatalf("%s entries = %d, want %d", format, len(entries), want)
+		}
+
+		first := entries[0]
+		if !strings.Contains(string(first.Raw), "\nhi\nFrom here on\n>From quoted\n") || strings.HasSuffix(string(first.Raw), "\n\n") {
+			t.Fatalf("%s raw = %q", format, first.Raw)
+		}
+
+		if strings.Join(first.Labels, ",") != "Inbox,Unread,Clients/Acme" && strings.Join(first.Labels, ",") != "Inbox,Clients/Acme,Unread" {
+			t.Fatalf("%s labels = %v", format, first.Labels)
+		}
+	}
+
+	if err := Walk(filepath.Join(dir, "out.mbox"+StateSuffix), func(Entry) error { return nil }); err == nil {
+		t.Fatalf("expected error walking a non-mbox file")
+	}
+}
+
+func TestSystemLabelID(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{"Inbox": "INBOX", "unread": "UNREAD", "Category Promotions": "CATEGORY_PROMOTIONS", "Opened": "", "STARRED": "STARRED"}
+	for name, want := range cases {
+		if got, ok := SystemLabelID(name); !ok || got != want {
+			t.Errorf("SystemLabelID(%q) = %q, %v; want %q", name, got, ok, want)
+		}
+	}
+
+	if _, ok := SystemLabelID("Clients/Acme"); ok {
+		t.Errorf("user label reported as system")
+	}
+}
@@ -0,0 +1,185 @@
+package mailbox
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// MaildirStateFile is the export state file in the Maildir root. It has no
+// leading dot so Maildir++ readers do not take it for a folder.
+const MaildirStateFile = "gog-export.jsonl"
+
+// ArchiveFolder holds messages that carry no folder label, like Gmail's
+// All Mail.
+const ArchiveFolder = "Archive"
+
+// maildirFolders maps system labels that behave like folders. STARRED,
+// UNREAD and DRAFT become flags instead; IMPORTANT, CHAT and categories are
+// only kept in X-Gmail-Labels.
+var maildirFolders = map[string]string{
+	"INBOX": "",
+	"SENT":  "Sent",
+	"DRAFT": "Drafts",
+	"SPAM":  "Spam",
+	"TRASH": "Trash",
+}
+
+// maildirWriter writes a Maildir++ tree: INBOX is the root and every other
+// label is a ".Label.Sub" folder. A message with several labels is stored
+// once per folder, the way Gmail's IMAP view shows it.
+type maildirWriter struct {
+	root  string
+	state *state
+	names map[string]string
+}
+
+func openMaildir(root string, opts Options) (*maildirWriter, error) {
+	if err := ensureMaildir(root, false); err != nil {
+		return nil, err
+	}
+
+	st, err := openState(filepath.Join(root, MaildirStateFile), opts.Restart)
+	if err != nil {
+		return nil, err
+	}
+
+	return &maildirWriter{root: root, state: st, names: opts.LabelNames}, nil
+}
+
+func ensureMaildir(dir string, folder bool) error {
+	for _, sub := range []string{"cur", "new", "tmp"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o700); err != nil {
+			return err
+		}
+	}
+
+	if !folder {
+		return nil
+	}
+
+	marker := filepath.Join(dir, "maildirfolder")
+	if _, err := os.Stat(marker); err == nil {
+		return nil
+	}
+
+	return os.WriteFile(marker, nil, 0o600)
+}
+
+func (w *maildirWriter) Has(id string) bool {
+	return w.state.done[id]
+}
+
+func (w *maildirWriter) Write(msg Message) error {
+	data := withGmailHeaders(msg, w.names)
+	base := fmt.Sprintf("%d.%s.gog", msg.InternalDate.Unix(), safeFilePart(msg.ID))
+	name := base + ":2," + maildirFlags(msg.Labels)
+
+	for _, folder := range Folders(msg.Labels, w.names) {
+		dir := w.root
+		if folder != "" {
+			dir = filepath.Join(w.root, folderDir(folder))
+		}
+
+		if err := ensureMaildir(dir, folder != ""); err != nil {
+			return err
+		}
+
+		tmp := filepath.Join(dir, "tmp", base)
+		if err := os.WriteFile(tmp, data, 0o600); err != nil {
+			return err
+		}
+
+		if err := os.Rename(tmp, filepath.Join(dir, "cur", name)); err != nil {
+			return err
+		}
+	}
+
+	return w.state.record(stateEntry{ID: msg.ID})
+}
+
+func (w *maildirWriter) Close() error {
+	return w.state.close()
+}
+
+// Folders returns the Maildir folders (label names, "" for INBOX) a message
+// with the given label IDs is stored in.
+func Folders(labels []string, names map[string]string) []string {
+	var folders []string
+
+	add := func(folder string) {
+		if !slices.Contains(folders, folder) {
+			folders = append(folders, folder)
+		}
+	}
+
+	for _, id := range labels {
+		if folder, ok := maildirFolders[id]; ok {
+			add(folder)
+
+			continue
+		}
+
+		if _, ok := systemLabels[id]; ok || strings.HasPrefix(id, "CATEGORY_") {
+			continue
+		}
+
+		add(LabelName(id, names))
+	}
+
+	if len(folders) == 0 {
+		add(ArchiveFolder)
+	}
+
+	return folders
+}
+
+// folderDir maps a label name to its Maildir++ directory. Nested labels
+// ("Work/Clients") use "." as the separator, so dots inside a label name
+// become underscores.
+func folderDir(name string) string {
+	var parts []string
+
+	for part := range strings.SplitSeq(name, "/") {
+		part = strings.TrimSpace(strings.ReplaceAll(part, ".", "_"))
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	if len(parts) == 0 {
+		return "._"
+	}
+
+	return "." + strings.Join(parts, ".")
+}
+
+func maildirFlags(labels []string) string {
+	var flags string
+
+	if slices.Contains(labels, "DRAFT") {
+		flags += "D"
+	}
+
+	if slices.Contains(labels, "STARRED") {
+		flags += "F"
+	}
+
+	if !slices.Contains(labels, "UNREAD") {
+		flags += "S"
+	}
+
+	return flags
+}
+
+func safeFilePart(value string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+
+		return '_'
+	}, value)
+}
@@ -0,0 +1,126 @@
+package mailbox
+
+import (
+	"bytes"
+	"fmt"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// StateSuffix is appended to an mbox path to name its export state file.
+const StateSuffix = ".gog-export.jsonl"
+
+var mboxFromLine = regexp.MustCompile(`(?m)^(>*From )`)
+
+// mboxWriter writes mboxrd: "From " lines in bodies are quoted with ">"
+// and already quoted lines get one more, so readers can undo it exactly.
+type mboxWriter struct {
+	file  *os.File
+	state *state
+	names map[string]string
+	end   int64
+}
+
+func openMbox(path string, opts Options) (*mboxWriter, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+
+	if opts.Restart {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	st, err := openState(path+StateSuffix, opts.Restart)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o600) // #nosec G304 -- caller-selected export path.
+	if err != nil {
+		_ = st.close()
+
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		_ = st.close()
+
+		return nil, err
+	}
+
+	end := info.Size()
+	if len(st.done) > 0 && end > st.last.End {
+		// Drop a message that was being written when the last run stopped.
+		end = st.last.End
+		if err := file.Truncate(end); err != nil {
+			_ = file.Close()
+			_ = st.close()
+
+			return nil, err
+		}
+	}
+
+	return &mboxWriter{file: file, state: st, names: opts.LabelNames, end: end}, nil
+}
+
+func (w *mboxWriter) Has(id string) bool {
+	return w.state.done[id]
+}
+
+func (w *mboxWriter) Write(msg Message) error {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "From %s %s\n", envelopeSender(msg.Raw), msg.InternalDate.UTC().Format("Mon Jan _2 15:04:05 2006"))
+	buf.Write(mboxFromLine.ReplaceAll(withGmailHeaders(msg, w.names), []byte(">$1")))
+	buf.WriteByte('\n')
+
+	n, err := w.file.WriteAt(buf.Bytes(), w.end)
+	if err != nil {
+		return err
+	}
+
+	w.end += int64(n)
+
+	return w.state.record(stateEntry{ID: msg.ID, End: w.end})
+}
+
+func (w *mboxWriter) Close() error {
+	err := w.file.Close()
+	if stateErr := w.state.close(); err == nil {
+		err = stateErr
+	}
+
+	return err
+}
+
+// envelopeSender picks the address for the mbox "From " separator line.
+func envelopeSender(raw []byte) string {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return "MAILER-DAEMON"
+	}
+
+	for _, header := range []string{"Return-Path", "From", "Sender"} {
+		value := strings.TrimSpace(msg.Header.Get(header))
+		if value == "" || value == "<>" {
+			continue
+		}
+
+		if addr, err := mail.ParseAddress(value); err == nil {
+			return addr.Address
+		}
+
+		if value = strings.Trim(value, "<>"); !strings.ContainsAny(value, " \t") {
+			return value
+		}
+	}
+
+	return "MAILER-DAEMON"
+}
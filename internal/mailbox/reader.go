@@ -0,0 +1,370 @@
+package mailbox
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	labelUnread  = "Unread"
+	labelStarred = "Starred"
+)
+
+var errNoMessages = errors.New("no messages found (expected an mbox file, .eml file, Maildir, or directory of .eml files)")
+
+// Entry is one message read from a local archive.
+type Entry struct {
+	// Key identifies the message within the source, so an import can skip
+	// what an earlier run already handled.
+	Key string
+	Raw []byte
+	// Labels are label names from X-Gmail-Labels plus, for Maildir, the
+	// folder and the unread/starred flags.
+	Labels []string
+}
+
+// Walk calls fn for every message in path: an mbox file, a single .eml
+// file, a Maildir (cur/new folders, Maildir++ subfolders), or a directory
+// tree of .eml files.
+func Walk(path string, fn func(Entry) error) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	if info.IsDir() {
+		if isMaildir(path) {
+			return walkMaildir(path, fn)
+		}
+
+		return walkEMLDir(path, fn)
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".eml") {
+		return readEMLFile(path, "file:"+filepath.Base(path), nil, fn)
+	}
+
+	return walkMbox(path, fn)
+}
+
+func isMaildir(dir string) bool {
+	for _, sub := range []string{"cur", "new"} {
+		if info, err := os.Stat(filepath.Join(dir, sub)); err != nil || !info.IsDir() {
+			return false
+		}
+	}
+
+	return true
+}
+
+func walkMbox(path string, fn func(Entry) error) error {
+	file, err := os.Open(path) // #nosec G304 -- caller-selected import source.
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReaderSize(file, 64<<10)
+
+	var (
+		offset, start int64
+		current       bytes.Buffer
+		inMessage     bool
+		prevBlank     = true
+		found         bool
+	)
+
+	flush := func() error {
+		if !inMessage {
+			return nil
+		}
+
+		found = true
+		// Drop the blank line that separates messages.
+		raw := bytes.TrimSuffix(bytes.TrimSuffix(current.Bytes(), []byte("\n")), []byte("\r"))
+		raw = append([]byte(nil), raw...)
+		current.Reset()
+
+		return emit(fmt.Sprintf("mbox:%d", start), raw, nil, fn)
+	}
+
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			trimmed := bytes.TrimRight(line, "\r\n")
+
+			switch {
+			case prevBlank && bytes.HasPrefix(line, []byte("From ")):
+				if err := flush(); err != nil {
+					return err
+				}
+
+				inMessage = true
+				start = offset
+			case inMessage:
+				current.Write(unquoteFrom(line))
+			case len(trimmed) > 0:
+				return fmt.Errorf("%s: not an mbox file (no \"From \" separator line)", path)
+			}
+
+			prevBlank = len(trimmed) == 0
+			offset += int64(len(line))
+		}
+
+		if readErr == io.EOF {
+			break
+		}
+
+		if readErr != nil {
+			return readErr
+		}
+	}
+
+	if err := flush(); err != nil {
+		return err
+	}
+
+	if !found {
+		return errNoMessages
+	}
+
+	return nil
+}
+
+// unquoteFrom reverses mboxrd quoting: ">From " loses one ">".
+func unquoteFrom(line []byte) []byte {
+	quoted := bytes.TrimLeft(line, ">")
+	if len(quoted) < len(line) && bytes.HasPrefix(quoted, []byte("From ")) {
+		return line[1:]
+	}
+
+	return line
+}
+
+func walkMaildir(root string, fn func(Entry) error) error {
+	folders := []string{""}
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() && strings.HasPrefix(entry.Name(), ".") && entry.Name() != "." && entry.Name() != ".." &&
+			isMaildir(filepath.Join(root, entry.Name())) {
+			folders = append(folders, entry.Name())
+		}
+	}
+
+	found := false
+
+	for _, folder := range folders {
+		label := maildirLabel(folder)
+
+		for _, sub := range []string{"cur", "new"} {
+			dir := filepath.Join(root, folder, sub)
+
+			files, err := os.ReadDir(dir)
+			if err != nil {
+				return err
+			}
+
+			for _, file := range files {
+				if file.IsDir() || strings.HasPrefix(file.Name(), ".") {
+					continue
+				}
+
+				found = true
+				labels := maildirEntryLabels(label, sub, file.Name())
+				key := "maildir:" + filepath.ToSlash(filepath.Join(folder, sub, maildirBaseName(file.Name())))
+
+				if err := readEMLFile(filepath.Join(dir, file.Name()), key, labels, fn); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if !found {
+		return errNoMessages
+	}
+
+	return nil
+}
+
+// maildirLabel maps a Maildir++ folder to a label name: the root is the
+// inbox, ".Work.Clients" is "Work/Clients", and the Archive folder (All
+// Mail) has no label.
+func maildirLabel(folder string) string {
+	if folder == "" {
+		return systemLabels["INBOX"]
+	}
+
+	name := strings.ReplaceAll(strings.TrimPrefix(folder, "."), ".", "/")
+	if name == ArchiveFolder {
+		return ""
+	}
+
+	return name
+}
+
+func maildirEntryLabels(folderLabel, sub, name string) []string {
+	var labels []string
+
+	if folderLabel != "" {
+		labels = append(labels, folderLabel)
+	}
+
+	_, info, _ := strings.Cut(name, ":2,")
+	if sub == "new" || !strings.Contains(info, "S") {
+		labels = append(labels, labelUnread)
+	}
+
+	if strings.Contains(info, "F") {
+		labels = append(labels, labelStarred)
+	}
+
+	return labels
+}
+
+// maildirBaseName drops the ":2,flags" suffix, which changes when a mail
+// client marks a message read.
+func maildirBaseName(name string) string {
+	base, _, _ := strings.Cut(name, ":")
+
+	return base
+}
+
+func walkEMLDir(root string, fn func(Entry) error) error {
+	var paths []string
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.IsDir() && strings.EqualFold(filepath.Ext(path), ".eml") {
+			paths = append(paths, path)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(paths) == 0 {
+		return errNoMessages
+	}
+
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+
+		if err := readEMLFile(path, "file:"+filepath.ToSlash(rel), nil, fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func readEMLFile(path, key string, labels []string, fn func(Entry) error) error {
+	raw, err := os.ReadFile(path) // #nosec G304 -- caller-selected import source.
+	if err != nil {
+		return err
+	}
+
+	return emit(key, raw, labels, fn)
+}
+
+func emit(key string, raw []byte, labels []string, fn func(Entry) error) error {
+	for _, label := range HeaderLabels(raw) {
+		if !containsFold(labels, label) {
+			labels = append(labels, label)
+		}
+	}
+
+	return fn(Entry{Key: key, Raw: raw, Labels: labels})
+}
+
+// HeaderLabels returns the label names in a message's X-Gmail-Labels
+// header.
+func HeaderLabels(raw []byte) []string {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil
+	}
+
+	value := msg.Header.Get("X-Gmail-Labels")
+	if decoded, err := (&mime.WordDecoder{}).DecodeHeader(value); err == nil {
+		value = decoded
+	}
+
+	var labels []string
+
+	for part := range strings.SplitSeq(value, ",") {
+		part = strings.Trim(strings.TrimSpace(part), `"`)
+		if part != "" && !containsFold(labels, part) {
+			labels = append(labels, part)
+		}
+	}
+
+	return labels
+}
+
+func containsFold(values []string, want string) bool {
+	for _, value := range values {
+		if strings.EqualFold(value, want) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ignoredLabels are Takeout and export labels with no Gmail label to apply:
+// read state comes from Unread, archiving from a missing Inbox, and Gmail
+// rejects DRAFT and CHAT on import.
+var ignoredLabels = map[string]bool{
+	"opened":   true,
+	"archived": true,
+	"drafts":   true,
+	"chat":     true,
+}
+
+// SystemLabelID maps a display name from X-Gmail-Labels or a Maildir folder
+// to a Gmail system label ID. ok is false for user labels; an empty id with
+// ok true means the name should be dropped.
+func SystemLabelID(name string) (string, bool) {
+	key := strings.ToLower(strings.TrimSpace(name))
+	if ignoredLabels[key] {
+		return "", true
+	}
+
+	for id, display := range systemLabels {
+		if strings.EqualFold(display, key) || strings.EqualFold(id, key) {
+			return id, true
+		}
+	}
+
+	if category, ok := strings.CutPrefix(key, "category "); ok {
+		return "CATEGORY_" + strings.ToUpper(category), true
+	}
+
+	return "", false
+}
@@ -0,0 +1,509 @@
+// Package outbox is a small on-disk delivery queue for webhook-style payloads.
+//
+// Each entry is one JSON file. Pending entries live in pending/, entries that
+// exhausted their attempts move to dead/. File names sort in enqueue order, so
+// Flush delivers oldest first and stops at the first entry that is not yet due,
+// preserving order for receivers that care.
+package outbox
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steipete/gogcli/internal/config"
+	"github.com/steipete/gogcli/internal/filelock"
+)
+
+const (
+	DefaultMaxAttempts = 8
+	DefaultBaseDelay   = 5 * time.Second
+	DefaultMaxDelay    = time.Hour
+
+	StatePending = "pending"
+	StateDead    = "dead"
+
+	pendingDir = "pending"
+	deadDir    = "dead"
+	fileSuffix = ".json"
+)
+
+var (
+	ErrNotFound  = errors.New("outbox entry not found")
+	errInvalidID = errors.New("invalid outbox entry id")
+)
+
+type Entry struct {
+	ID            string          `json:"id"`
+	Kind          string          `json:"kind,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+	Attempts      int             `json:"attempts"`
+	NextAttemptAt time.Time       `json:"next_attempt_at,omitzero"`
+	LastError     string          `json:"last_error,omitempty"`
+	DeadAt        time.Time       `json:"dead_at,omitzero"`
+	Payload       json.RawMessage `json:"payload"`
+
+	// State is pending or dead; it comes from the directory, not the file.
+	State string `json:"-"`
+}
+
+type Options struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	Now         func() time.Time
+	LockTimeout time.Duration
+}
+
+type Outbox struct {
+	dir         string
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+	now         func() time.Time
+	lock        *filelock.Lock
+}
+
+// FlushResult counts what one Flush did.
+type FlushResult struct {
+	Delivered    int
+	Failed       int
+	DeadLettered int
+	Remaining    int
+}
+
+func New(dir string, options Options) *Outbox {
+	o := &Outbox{
+		dir:         dir,
+		maxAttempts: options.MaxAttempts,
+		baseDelay:   options.BaseDelay,
+		maxDelay:    options.MaxDelay,
+		now:         options.Now,
+	}
+
+	if o.maxAttempts <= 0 {
+		o.maxAttempts = DefaultMaxAttempts
+	}
+
+	if o.baseDelay <= 0 {
+		o.baseDelay = DefaultBaseDelay
+	}
+
+	if o.maxDelay <= 0 {
+		o.maxDelay = DefaultMaxDelay
+	}
+
+	if o.now == nil {
+		o.now = time.Now
+	}
+
+	lockTimeout := options.LockTimeout
+	if lockTimeout <= 0 {
+		lockTimeout = 30 * time.Second
+	}
+
+	o.lock = filelock.Shared(filepath.Join(dir, ".lock"), lockTimeout)
+
+	return o
+}
+
+func (o *Outbox) Dir() string {
+	return o.dir
+}
+
+func (o *Outbox) MaxAttempts() int {
+	return o.maxAttempts
+}
+
+// Enqueue stores payload after a failed first delivery attempt. cause is the
+// error from that attempt and may be nil when the entry is queued behind
+// others without being tried.
+func (o *Outbox) Enqueue(kind string, payload any, cause error) (Entry, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Entry{}, fmt.Errorf("encode outbox payload: %w", err)
+	}
+
+	id, err := o.newID()
+	if err != nil {
+		return Entry{}, err
+	}
+
+	now := o.now().UTC()
+	entry := Entry{
+		ID:            id,
+		Kind:          kind,
+		CreatedAt:     now,
+		NextAttemptAt: now,
+		Payload:       data,
+		State:         StatePending,
+	}
+
+	if cause != nil {
+		entry.Attempts = 1
+		entry.LastError = cause.Error()
+		entry.NextAttemptAt = now.Add(o.backoff(1))
+	}
+
+	if err := o.write(pendingDir, entry); err != nil {
+		return Entry{}, err
+	}
+
+	return entry, nil
+}
+
+func (o *Outbox) newID() (string, error) {
+	var suffix [4]byte
+	if _, err := rand.Read(suffix[:]); err != nil {
+		return "", fmt.Errorf("generate outbox id: %w", err)
+	}
+
+	return fmt.Sprintf("%019d-%s", o.now().UTC().UnixNano(), hex.EncodeToString(suffix[:])), nil
+}
+
+func (o *Outbox) backoff(attempts int) time.Duration {
+	delay := o.baseDelay
+	for i := 1; i < attempts && delay < o.maxDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, o.maxDelay)
+}
+
+// Pending lists queued entries, oldest first.
+func (o *Outbox) Pending() ([]Entry, error) {
+	return o.list(pendingDir, StatePending)
+}
+
+// Dead lists dead-lettered entries, oldest first.
+func (o *Outbox) Dead() ([]Entry, error) {
+	return o.list(deadDir, StateDead)
+}
+
+// HasPending reports whether any entry is waiting, so new payloads can queue
+// behind it instead of overtaking it.
+func (o *Outbox) HasPending() (bool, error) {
+	names, err := o.names(pendingDir)
+
+	return len(names) > 0, err
+}
+
+func (o *Outbox) list(sub, state string) ([]Entry, error) {
+	names, err := o.names(sub)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, len(names))
+	for _, name := range names {
+		entry, err := o.read(sub, name)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		entry.State = state
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func (o *Outbox) names(sub string) ([]string, error) {
+	items, err := os.ReadDir(filepath.Join(o.dir, sub))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("read outbox: %w", err)
+	}
+
+	names := make([]string, 0, len(items))
+	for _, item := range items {
+		if item.IsDir() || !strings.HasSuffix(item.Name(), fileSuffix) {
+			continue
+		}
+
+		names = append(names, strings.TrimSuffix(item.Name(), fileSuffix))
+	}
+
+	sort.Strings(names)
+
+	return names, nil
+}
+
+func (o *Outbox) path(sub, id string) string {
+	return filepath.Join(o.dir, sub, id+fileSuffix)
+}
+
+func (o *Outbox) read(sub, id string) (Entry, error) {
+	data, err := os.ReadFile(o.path(sub, id)) //nolint:gosec // ids come from the outbox directory or are validated.
+	if err != nil {
+		return Entry{}, err
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return Entry{}, fmt.Errorf("decode outbox entry %s: %w", id, err)
+	}
+
+	entry.ID = id
+
+	return entry, nil
+}
+
+func (o *Outbox) write(sub string, entry Entry) error {
+	if err := os.MkdirAll(filepath.Join(o.dir, sub), 0o700); err != nil {
+		return fmt.Errorf("create outbox dir: %w", err)
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encode outbox entry: %w", err)
+	}
+
+	if err := config.WriteFileAtomic(o.path(sub, entry.ID), append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("write outbox entry: %w", err)
+	}
+
+	return nil
+}
+
+// Flush delivers due pending entries in order. A failed entry is rescheduled
+// with exponential backoff and blocks the entries behind it; once it reaches
+// the attempt limit it moves to dead/ and the next entry is tried.
+func (o *Outbox) Flush(ctx context.Context, deliver func(context.Context, Entry) error) (FlushResult, error) {
+	var result FlushResult
+
+	err := o.withLock(func() error {
+		names, err := o.names(pendingDir)
+		if err != nil {
+			return err
+		}
+
+		for i, name := range names {
+			if ctx.Err() != nil {
+				result.Remaining = len(names) - i
+
+				return nil
+			}
+
+			entry, err := o.read(pendingDir, name)
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+
+			if err != nil {
+				return err
+			}
+
+			entry.State = StatePending
+
+			now := o.now().UTC()
+			if entry.NextAttemptAt.After(now) {
+				result.Remaining = len(names) - i
+
+				return nil
+			}
+
+			deliverErr := deliver(ctx, entry)
+			if deliverErr == nil {
+				if err := os.Remove(o.path(pendingDir, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+					return fmt.Errorf("remove delivered outbox entry: %w", err)
+				}
+
+				result.Delivered++
+
+				continue
+			}
+
+			result.Failed++
+			entry.Attempts++
+			entry.LastError = deliverErr.Error()
+
+			if entry.Attempts >= o.maxAttempts {
+				if err := o.moveToDead(entry, now); err != nil {
+					return err
+				}
+
+				result.DeadLettered++
+
+				continue
+			}
+
+			entry.NextAttemptAt = now.Add(o.backoff(entry.Attempts))
+			if err := o.write(pendingDir, entry); err != nil {
+				return err
+			}
+
+			result.Remaining = len(names) - i
+
+			return nil
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
+func (o *Outbox) withLock(fn func() error) error {
+	if err := os.MkdirAll(o.dir, 0o700); err != nil {
+		return fmt.Errorf("create outbox dir: %w", err)
+	}
+
+	return o.lock.WithExclusive(fn)
+}
+
+func (o *Outbox) moveToDead(entry Entry, now time.Time) error {
+	entry.DeadAt = now
+	entry.NextAttemptAt = time.Time{}
+
+	if err := o.write(deadDir, entry); err != nil {
+		return err
+	}
+
+	if err := os.Remove(o.path(pendingDir, entry.ID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove dead-lettered outbox entry: %w", err)
+	}
+
+	return nil
+}
+
+// Replay moves dead entries back to the pending queue with a fresh attempt
+// budget. With no ids, every dead entry is replayed.
+func (o *Outbox) Replay(ids ...string) ([]Entry, error) {
+	var replayed []Entry
+
+	err := o.withLock(func() error {
+		targets, err := o.resolve(deadDir, ids)
+		if err != nil {
+			return err
+		}
+
+		now := o.now().UTC()
+		for _, id := range targets {
+			entry, err := o.read(deadDir, id)
+			if err != nil {
+				return err
+			}
+
+			entry.Attempts = 0
+			entry.NextAttemptAt = now
+			entry.DeadAt = time.Time{}
+			entry.State = StatePending
+
+			if err := o.write(pendingDir, entry); err != nil {
+				return err
+			}
+
+			if err := os.Remove(o.path(deadDir, id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("remove replayed outbox entry: %w", err)
+			}
+
+			replayed = append(replayed, entry)
+		}
+
+		return nil
+	})
+
+	return replayed, err
+}
+
+// Drop deletes entries by id from either queue. With no ids, every dead entry
+// is dropped.
+func (o *Outbox) Drop(ids ...string) ([]string, error) {
+	var dropped []string
+
+	err := o.withLock(func() error {
+		if len(ids) == 0 {
+			names, err := o.names(deadDir)
+			if err != nil {
+				return err
+			}
+
+			for _, name := range names {
+				if err := os.Remove(o.path(deadDir, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+					return fmt.Errorf("drop outbox entry: %w", err)
+				}
+
+				dropped = append(dropped, name)
+			}
+
+			return nil
+		}
+
+		for _, id := range ids {
+			if err := validateID(id); err != nil {
+				return err
+			}
+
+			removed := false
+
+			for _, sub := range []string{pendingDir, deadDir} {
+				err := os.Remove(o.path(sub, id))
+				if err == nil {
+					removed = true
+
+					break
+				}
+
+				if !errors.Is(err, os.ErrNotExist) {
+					return fmt.Errorf("drop outbox entry: %w", err)
+				}
+			}
+
+			if !removed {
+				return fmt.Errorf("%w: %s", ErrNotFound, id)
+			}
+
+			dropped = append(dropped, id)
+		}
+
+		return nil
+	})
+
+	return dropped, err
+}
+
+func (o *Outbox) resolve(sub string, ids []string) ([]string, error) {
+	if len(ids) == 0 {
+		return o.names(sub)
+	}
+
+	for _, id := range ids {
+		if err := validateID(id); err != nil {
+			return nil, err
+		}
+
+		if _, err := os.Stat(o.path(sub, id)); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+			}
+
+			return nil, fmt.Errorf("stat outbox entry: %w", err)
+		}
+	}
+
+	return ids, nil
+}
+
+func validateID(id string) error {
+	if id == "" || strings.ContainsAny(id, `/\`) || strings.HasPrefix(id, ".") {
+		return fmt.Errorf("%w: %q", errInvalidID, id)
+	}
+
+	return nil
+}
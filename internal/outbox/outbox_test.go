@@ -0,0 +1,173 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func newTestOutbox(t *testing.T, clock *testClock, maxAttempts int) *Outbox {
+	t.Helper()
+
+	return New(t.TempDir(), Options{MaxAttempts: maxAttempts, BaseDelay: time.Second, MaxDelay: 4 * time.Second, Now: clock.Now})
+}
+
+func TestFlushDeliversInOrderAndBacksOff(t *testing.T) {
+	t.Parallel()
+
+	clock := &testClock{now: time.Unix(1000, 0)}
+	box := newTestOutbox(t, clock, 8)
+
+	first, err := box.Enqueue("test", map[string]int{"n": 1}, errors.New("down"))
+	if err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	if first.Attempts != 1 || !first.NextAttemptAt.Equal(clock.now.Add(time.Second)) {
+		t.Fatalf("first = %#v", first)
+	}
+
+	clock.now = clock.now.Add(time.Nanosecond)
+	if _, err := box.Enqueue("test", map[string]int{"n": 2}, nil); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	var delivered []string
+
+	deliver := func(_ context.Context, entry Entry) error {
+		delivered = append(delivered, string(entry.Payload))
+
+		return nil
+	}
+
+	result, err := box.Flush(context.Background(), deliver)
+	if err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	if result.Delivered != 0 || result.Remaining != 2 || len(delivered) != 0 {
+		t.Fatalf("not-due flush = %#v delivered=%v", result, delivered)
+	}
+
+	clock.now = clock.now.Add(time.Second)
+
+	failing := func(context.Context, Entry) error { return errors.New("still down") }
+
+	result, err = box.Flush(context.Background(), failing)
+	if err != nil || result.Failed != 1 || result.Remaining != 2 {
+		t.Fatalf("failing flush = %#v err=%v", result, err)
+	}
+
+	pending, err := box.Pending()
+	if err != nil || len(pending) != 2 {
+		t.Fatalf("Pending = %#v err=%v", pending, err)
+	}
+
+	if pending[0].Attempts != 2 || pending[0].LastError != "still down" ||
+		!pending[0].NextAttemptAt.Equal(clock.now.Add(2*time.Second)) {
+		t.Fatalf("rescheduled = %#v", pending[0])
+	}
+
+	clock.now = clock.now.Add(2 * time.Second)
+
+	result, err = box.Flush(context.Background(), deliver)
+	if err != nil || result.Delivered != 2 || result.Remaining != 0 {
+		t.Fatalf("flush = %#v err=%v", result, err)
+	}
+
+	if len(delivered) != 2 || delivered[0] != `{"n":1}` || delivered[1] != `{"n":2}` {
+		t.Fatalf("delivered = %v", delivered)
+	}
+
+	if has, err := box.HasPending(); err != nil || has {
+		t.Fatalf("HasPending = %v err=%v", has, err)
+	}
+}
+
+func TestFlushDeadLettersAndReplay(t *testing.T) {
+	t.Parallel()
+
+	clock := &testClock{now: time.Unix(1000, 0)}
+	box := newTestOutbox(t, clock, 2)
+
+	entry, err := box.Enqueue("test", "payload", errors.New("down"))
+	if err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	clock.now = clock.now.Add(time.Minute)
+
+	result, err := box.Flush(context.Background(), func(context.Context, Entry) error { return errors.New("gone") })
+	if err != nil || result.DeadLettered != 1 {
+		t.Fatalf("flush = %#v err=%v", result, err)
+	}
+
+	dead, err := box.Dead()
+	if err != nil || len(dead) != 1 || dead[0].ID != entry.ID || dead[0].State != StateDead || dead[0].DeadAt.IsZero() {
+		t.Fatalf("Dead = %#v err=%v", dead, err)
+	}
+
+	replayed, err := box.Replay()
+	if err != nil || len(replayed) != 1 || replayed[0].Attempts != 0 {
+		t.Fatalf("Replay = %#v err=%v", replayed, err)
+	}
+
+	result, err = box.Flush(context.Background(), func(context.Context, Entry) error { return nil })
+	if err != nil || result.Delivered != 1 {
+		t.Fatalf("replayed flush = %#v err=%v", result, err)
+	}
+
+	if _, err := box.Replay("missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Replay missing err = %v", err)
+	}
+}
+
+func TestDrop(t *testing.T) {
+	t.Parallel()
+
+	clock := &testClock{now: time.Unix(1000, 0)}
+	box := newTestOutbox(t, clock, 1)
+
+	pending, err := box.Enqueue("test", 1, nil)
+	if err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	if _, err := box.Drop(pending.ID); err != nil {
+		t.Fatalf("Drop pending: %v", err)
+	}
+
+	if _, err := box.Drop(pending.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Drop twice err = %v", err)
+	}
+
+	if _, err := box.Drop("../escape"); err == nil {
+		t.Fatal("expected invalid id error")
+	}
+
+	if _, err := box.Enqueue("test", 2, nil); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	if _, err := box.Flush(context.Background(), func(context.Context, Entry) error { return errors.New("no") }); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	dropped, err := box.Drop()
+	if err != nil || len(dropped) != 1 {
+		t.Fatalf("Drop all dead = %v err=%v", dropped, err)
+	}
+
+	if dead, _ := box.Dead(); len(dead) != 0 {
+		t.Fatalf("Dead after drop = %#v", dead)
+	}
+}
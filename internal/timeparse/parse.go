@@ -3,6 +3,7 @@ package timeparse
 import (
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -131,7 +132,50 @@ func ParseRangeExpr(expr string, now time.Time, loc *time.Location) (time.Time,
 		return parsed.Time, nil
 	}
 
-	return time.Time{}, fmt.Errorf("%w: %q (try: 2026-01-05, today, tomorrow, monday)", ErrInvalidTimeExpr, expr)
+	if t, ok := parseDayWithClock(expr, now, loc); ok {
+		return t, nil
+	}
+
+	return time.Time{}, fmt.Errorf("%w: %q (try: 2026-01-05, today, tomorrow 9am, monday 14:30)", ErrInvalidTimeExpr, expr)
+}
+
+// parseDayWithClock handles "<day expression> <clock>", e.g. "tomorrow 9am"
+// or "next monday 14:30".
+func parseDayWithClock(expr string, now time.Time, loc *time.Location) (time.Time, bool) {
+	i := strings.LastIndex(expr, " ")
+	if i <= 0 {
+		return time.Time{}, false
+	}
+
+	dayExpr := strings.TrimSpace(expr[:i])
+	if strings.EqualFold(dayExpr, "now") {
+		return time.Time{}, false
+	}
+
+	hour, minute, second, ok := ParseClock(expr[i+1:])
+	if !ok {
+		return time.Time{}, false
+	}
+
+	day, err := ParseRangeExpr(dayExpr, now, loc)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, second, 0, day.Location()), true
+}
+
+// ParseClock parses a time of day: 9am, 9:30pm, 14:30, or 14:30:15.
+func ParseClock(value string) (hour, minute, second int, ok bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+
+	for _, layout := range []string{"3pm", "3:04pm", "15:04", "15:04:05"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.Hour(), t.Minute(), t.Second(), true
+		}
+	}
+
+	return 0, 0, 0, false
 }
 
 // ParseSince parses --since values for tracking style queries.
@@ -225,3 +269,79 @@ func ParseWeekdayExpr(expr string, now time.Time) (time.Time, bool) {
 func startOfDay(t time.Time) time.Time {
 	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
 }
+
+// ParseWindow parses a search window such as "today", "tomorrow", "this
+// week", "next week", "next 3 days", or "next 5 workdays". Windows that
+// include today start at now. isWorkday defaults to Monday-Friday.
+func ParseWindow(expr string, now time.Time, loc *time.Location, isWorkday func(time.Weekday) bool) (time.Time, time.Time, error) {
+	if loc != nil {
+		now = now.In(loc)
+	}
+
+	if isWorkday == nil {
+		isWorkday = func(day time.Weekday) bool { return day != time.Saturday && day != time.Sunday }
+	}
+
+	fields := strings.Fields(strings.ToLower(strings.TrimSpace(expr)))
+	if len(fields) == 0 {
+		return time.Time{}, time.Time{}, ErrEmptyTimeExpr
+	}
+
+	today := startOfDay(now)
+	weekStart := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+
+	switch strings.Join(fields, " ") {
+	case "today":
+		return now, today.AddDate(0, 0, 1), nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), today.AddDate(0, 0, 2), nil
+	case "this week":
+		return now, weekStart.AddDate(0, 0, 7), nil
+	case "next week":
+		return weekStart.AddDate(0, 0, 7), weekStart.AddDate(0, 0, 14), nil
+	}
+
+	if fields[0] == "next" {
+		fields = fields[1:]
+	}
+
+	if len(fields) == 3 && fields[1] == "business" {
+		fields = []string{fields[0], "workdays"}
+	}
+
+	if len(fields) != 2 {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: %q (try: today, this week, next 3 days, next 5 workdays)", ErrInvalidTimeExpr, expr)
+	}
+
+	n, err := strconv.Atoi(fields[0])
+	if err != nil || n < 1 {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: %q", ErrInvalidTimeExpr, expr)
+	}
+
+	switch strings.TrimSuffix(fields[1], "s") {
+	case "day":
+		return now, today.AddDate(0, 0, n), nil
+	case "week":
+		return now, today.AddDate(0, 0, 7*n), nil
+	case "workday":
+		day := today
+
+		for counted := 0; ; day = day.AddDate(0, 0, 1) {
+			if isWorkday(day.Weekday()) {
+				counted++
+			}
+
+			if counted == n {
+				break
+			}
+
+			if day.Sub(today) > 366*24*time.Hour {
+				return time.Time{}, time.Time{}, fmt.Errorf("%w: no workdays in %q", ErrInvalidTimeExpr, expr)
+			}
+		}
+
+		return now, day.AddDate(0, 0, 1), nil
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: %q (try: today, this week, next 3 days, next 5 workdays)", ErrInvalidTimeExpr, expr)
+	}
+}
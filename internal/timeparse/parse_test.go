@@ -133,6 +133,10 @@ func TestParseRangeExpr(t *testing.T) {
 		{name: "next weekday", value: "next friday", wantDay: 20, wantMonth: time.February, wantHour: 0, wantWeek: time.Friday},
 		{name: "date", value: "2026-02-01", wantDay: 1, wantMonth: time.February, wantHour: 0, wantWeek: time.Sunday},
 		{name: "datetime", value: "2026-02-01T10:30:00", wantDay: 1, wantMonth: time.February, wantHour: 10, wantWeek: time.Sunday},
+		{name: "tomorrow with clock", value: "tomorrow 9am", wantDay: 14, wantMonth: time.February, wantHour: 9, wantWeek: time.Saturday},
+		{name: "weekday with clock", value: "next monday 14:30", wantDay: 16, wantMonth: time.February, wantHour: 14, wantWeek: time.Monday},
+		{name: "date with pm clock", value: "2026-02-01 9:15pm", wantDay: 1, wantMonth: time.February, wantHour: 21, wantWeek: time.Sunday},
+		{name: "bad clock", value: "tomorrow 25:00", wantErr: true},
 		{name: "invalid", value: "yolo", wantErr: true},
 	}
 
@@ -224,3 +228,51 @@ func TestParseSince(t *testing.T) {
 		})
 	}
 }
+
+//nolint:wsl_v5
+func TestParseWindow(t *testing.T) {
+	t.Parallel()
+
+	loc := time.UTC
+	now := time.Date(2026, 4, 8, 14, 30, 0, 0, loc) // Wednesday
+	testCases := []struct {
+		expr     string
+		wantFrom string
+		wantTo   string
+		wantErr  bool
+	}{
+		{expr: "today", wantFrom: "2026-04-08T14:30", wantTo: "2026-04-09T00:00"},
+		{expr: "tomorrow", wantFrom: "2026-04-09T00:00", wantTo: "2026-04-10T00:00"},
+		{expr: "this week", wantFrom: "2026-04-08T14:30", wantTo: "2026-04-13T00:00"},
+		{expr: "next week", wantFrom: "2026-04-13T00:00", wantTo: "2026-04-20T00:00"},
+		{expr: "next 3 days", wantFrom: "2026-04-08T14:30", wantTo: "2026-04-11T00:00"},
+		{expr: "next 5 workdays", wantFrom: "2026-04-08T14:30", wantTo: "2026-04-15T00:00"},
+		{expr: "2 business days", wantFrom: "2026-04-08T14:30", wantTo: "2026-04-10T00:00"},
+		{expr: "next 0 days", wantErr: true},
+		{expr: "someday", wantErr: true},
+		{expr: "", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.expr, func(t *testing.T) {
+			t.Parallel()
+			from, to, err := ParseWindow(tc.expr, now, loc, nil)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected error")
+				}
+
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseWindow: %v", err)
+			}
+			if got := from.Format("2006-01-02T15:04"); got != tc.wantFrom {
+				t.Fatalf("from = %s, want %s", got, tc.wantFrom)
+			}
+			if got := to.Format("2006-01-02T15:04"); got != tc.wantTo {
+				t.Fatalf("to = %s, want %s", got, tc.wantTo)
+			}
+		})
+	}
+}
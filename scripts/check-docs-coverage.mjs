@@ -19,6 +19,7 @@ const requiredFeatureDocs = [
   "raw-audit.md",
   "gmail-workflows.md",
   "watch.md",
+  "calendar-workflows.md",
   "email-tracking.md",
   "drive-audits.md",
   "contacts-dedupe.md",